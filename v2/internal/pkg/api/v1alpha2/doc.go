@@ -0,0 +1,5 @@
+// Package v1alpha2 is a copy of the ImageSetConfiguration and Metadata types of oc-mirror v1
+// (github.com/openshift/oc-mirror/pkg/api/v1alpha2), read when migrating to v2.
+// Only the types and their json representation are kept, so that the v2 module
+// does not depend on the v1 one.
+package v1alpha2
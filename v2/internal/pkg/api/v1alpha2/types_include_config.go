@@ -0,0 +1,50 @@
+package v1alpha2
+
+// IncludeConfig defines a list of packages for
+// operator version selection.
+type IncludeConfig struct {
+	// Packages to include.
+	Packages []IncludePackage `json:"packages" yaml:"packages"`
+}
+
+// IncludePackage contains a name (required) and channels and/or versions
+// (optional) to include in the diff. The full package is only included if no channels
+// or versions are specified.
+type IncludePackage struct {
+	// Name of package.
+	Name string `json:"name" yaml:"name"`
+	// Channels to include.
+	Channels []IncludeChannel `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// All channels containing these bundles are parsed for an upgrade graph.
+	IncludeBundle `json:",inline"`
+
+	// New field added due to the following filed cases for oc-mirror
+	// - CASE03657982
+	// - CASE03655018
+	// - CASE03676821
+	// Ability to override default channel.
+	DefaultChannel string `json:"defaultChannel,omitempty"`
+}
+
+// IncludeChannel contains a name (required) and versions (optional)
+// to include in the diff. The full channel is only included if no versions are specified.
+type IncludeChannel struct {
+	// Name of channel.
+	Name string `json:"name" yaml:"name"`
+
+	IncludeBundle `json:",inline"`
+}
+
+// IncludeBundle contains a name (required) and versions (optional) to
+// include in the diff. The full package or channel is only included if no
+// versions are specified.
+type IncludeBundle struct {
+	// MinVersion to include, plus all versions in the upgrade graph to the MaxVersion.
+	MinVersion string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+	// MaxVersion to include as the channel head version.
+	MaxVersion string `json:"maxVersion,omitempty" yaml:"maxVersion,omitempty"`
+	// MinBundle to include, plus all bundles in the upgrade graph to the channel head.
+	// Set this field only if the named bundle has no semantic version metadata.
+	MinBundle string `json:"minBundle,omitempty" yaml:"minBundle,omitempty"`
+}
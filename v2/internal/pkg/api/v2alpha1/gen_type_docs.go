@@ -0,0 +1,30 @@
+//go:build ignore
+
+// gen_type_docs.go generates zz_generated.type_docs.go, the doc comments of the configuration
+// types declared in the -sources files.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1/internal/typedocs"
+)
+
+func main() {
+	sources := flag.String("sources", "", "comma-separated list of the files declaring the configuration types")
+	flag.Parse()
+	types, fields, err := typedocs.Parse(strings.Split(*sources, ",")...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := typedocs.Generate("v2alpha1", "gen_type_docs.go", "configDocs", types, fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("zz_generated.type_docs.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
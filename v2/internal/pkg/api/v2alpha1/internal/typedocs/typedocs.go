@@ -0,0 +1,100 @@
+// Package typedocs reads the doc comments of the configuration types from their sources,
+// and generates the Go source of the documentation of the types, used at runtime.
+package typedocs
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// Parse returns the doc comments of the types declared in the files, by type name,
+// and the doc comments of their fields, by <type name>.<field name>
+func Parse(files ...string) (map[string]string, map[string]string, error) {
+	types := map[string]string{}
+	fields := map[string]string{}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				types[typeSpec.Name.Name] = commentText(genDecl.Doc, typeSpec.Doc)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						fields[typeSpec.Name.Name+"."+name.Name] = commentText(field.Doc)
+					}
+				}
+			}
+		}
+	}
+	return types, fields, nil
+}
+
+// Generate returns the Go source declaring varName, a TypeDocs of the types and fields docs
+func Generate(pkg, generator, varName string, types, fields map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by %s; DO NOT EDIT.\n\npackage %s\n\n", generator, pkg)
+	fmt.Fprintf(&b, "var %s = TypeDocs{\n", varName)
+	writeMap(&b, "Types", types)
+	writeMap(&b, "Fields", fields)
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+func writeMap(b *bytes.Buffer, name string, docs map[string]string) {
+	keys := make([]string, 0, len(docs))
+	for key := range docs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	fmt.Fprintf(b, "%s: map[string]string{\n", name)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%q: %q,\n", key, docs[key])
+	}
+	b.WriteString("},\n")
+}
+
+func commentText(groups ...*ast.CommentGroup) string {
+	for _, group := range groups {
+		text := strings.TrimSpace(group.Text())
+		if text == "" {
+			continue
+		}
+		// the lines of a sentence are joined, the indented lines are kept as is
+		var lines []string
+		joining := false
+		for _, line := range strings.Split(text, "\n") {
+			switch {
+			case strings.TrimSpace(line) == "":
+				joining = false
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+				lines = append(lines, line)
+				joining = false
+			case joining:
+				lines[len(lines)-1] += " " + line
+			default:
+				lines = append(lines, line)
+				joining = true
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package v2alpha1
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
@@ -25,6 +26,9 @@ type ImageSetConfigurationSpec struct {
 	Mirror Mirror `json:"mirror"`
 	// ArchiveSize is the size of the segmented archive in GB
 	ArchiveSize int64 `json:"archiveSize,omitempty"`
+	// Includes are the paths of imageset configuration fragments, relative
+	// to this file, merged before it. See config.ReadConfig for the merge rules.
+	Includes []string `json:"includes,omitempty"`
 }
 
 // DeleteImageSetConfiguration object kind.
@@ -43,6 +47,9 @@ type DeleteImageSetConfiguration struct {
 type DeleteImageSetConfigurationSpec struct {
 	// Delete defines the configuration for content types within the imageset.
 	Delete Delete `json:"delete"`
+	// Includes are the paths of delete imageset configuration fragments,
+	// relative to this file, merged before it.
+	Includes []string `json:"includes,omitempty"`
 }
 
 // Mirror defines the configuration for content types within the imageset.
@@ -61,8 +68,12 @@ type Mirror struct {
 	// types in the configuration.
 	BlockedImages []Image `json:"blockedImages,omitempty"`
 	// Samples defines the configuration for Sample content types.
-	// This is currently not implemented.
+	// The sample ImageStreams are read from the samples operator
+	// shipped in the mirrored release payloads.
 	Samples []SampleImages `json:"samples,omitempty"`
+	// CompositeCatalogs defines target catalogs built from the packages
+	// of several source catalogs.
+	CompositeCatalogs []CompositeCatalog `json:"compositeCatalogs,omitempty"`
 }
 
 // Delete defines the configuration for content types within the imageset.
@@ -77,7 +88,6 @@ type Delete struct {
 	// Helm define the configuration for Helm content types.
 	Helm Helm `json:"helm,omitempty"`
 	// Samples defines the configuration for Sample content types.
-	// This is currently not implemented.
 	Samples []SampleImages `json:"samples,omitempty"`
 }
 
@@ -96,15 +106,126 @@ type Platform struct {
 	// This new field will allow the diskToMirror functionality
 	// to copy from a release location on disk
 	Release string `json:"release,omitempty"`
+	// Releases is an explicit list of release payloads, mirrored without
+	// any update graph lookup, such as hotfix or pre-GA payloads that are
+	// not part of any channel. As the releases of the channels, their
+	// signatures are verified and added to the signature config map.
+	Releases []ReleasePayload `json:"releases,omitempty"`
 	// The kubeVirtContainer flag when set to true (default false)
 	// will be used to extract the kubeVirtContainer image
 	// from the release payload file 0000_50_installer_coreos-bootimages
 	KubeVirtContainer bool `json:"kubeVirtContainer,omitempty"`
+	// GraphBaseImage is the base image of the Cincinnati graph data image.
+	// It is either an image reference, which should be pinned by digest for
+	// reproducible builds, or an OCI layout on disk (oci:).
+	// When set to scratch, the graph data image only contains the graph data
+	// and GraphCopyHelper.
+	// Defaults to DefaultGraphBaseImage.
+	GraphBaseImage string `json:"graphBaseImage,omitempty"`
+	// GraphCopyHelper is the path on disk to a statically linked executable,
+	// built from cmd/graph-data-copier, that copies the graph data to the
+	// volume of the update service. It is required when GraphBaseImage is scratch.
+	GraphCopyHelper string `json:"graphCopyHelper,omitempty"`
+	// TrimGraph rewrites the Cincinnati graph data, so that its channels
+	// and blocked edges only reference the mirrored releases. The update
+	// service then only recommends updates to releases that can be pulled.
+	TrimGraph bool `json:"trimGraph,omitempty"`
+	// GraphLocalChannel, when set with TrimGraph, adds to the graph data
+	// a channel with this name, listing all the mirrored releases.
+	GraphLocalChannel string `json:"graphLocalChannel,omitempty"`
+	// BootImages lists the RHCOS boot artifacts (ISO, PXE, qcow2, OVA...)
+	// to download from the coreos stream metadata of the releases,
+	// found in the release payload file 0000_50_installer_coreos-bootimages
+	BootImages *BootImages `json:"bootImages,omitempty"`
 }
 
+// ReleasePayload identifies a release payload, either by version or by image.
+type ReleasePayload struct {
+	// Version of an OCP release, such as 4.16.3, resolved to the payload
+	// ReleasePayloadRepository:<version>-<architecture>
+	Version string `json:"version,omitempty"`
+	// Image is the pullspec of the release payload, pinned by digest
+	Image string `json:"image,omitempty"`
+	// Architecture of the payload resolved from Version
+	// (amd64, arm64, ppc64le, s390x or multi). Defaults to amd64.
+	Architecture string `json:"architecture,omitempty"`
+}
+
+// GetArchitecture returns the architecture of the payload, or the default one
+func (r ReleasePayload) GetArchitecture() string {
+	if r.Architecture == "" {
+		return DefaultPlatformArchitecture
+	}
+	return r.Architecture
+}
+
+// BootImages defines the RHCOS boot artifacts downloaded with the releases.
+// The artifacts are stored in the working-dir, and carried in the archive.
+type BootImages struct {
+	// Architectures are the coreos architectures of the artifacts
+	// (x86_64, aarch64, ppc64le, s390x). Defaults to x86_64.
+	Architectures []string `json:"architectures,omitempty"`
+	// Platforms are the coreos platforms of the artifacts,
+	// such as metal, qemu, vmware or openstack.
+	Platforms []string `json:"platforms"`
+	// Formats are the formats of the artifacts, such as iso, pxe,
+	// qcow2.gz or ova. All the formats of the platforms are downloaded when empty.
+	Formats []string `json:"formats,omitempty"`
+	// HTTPDir is a local directory, served over HTTP, to which the artifacts
+	// are copied on the mirror side (diskToMirror and mirrorToMirror).
+	HTTPDir string `json:"httpDir,omitempty"`
+	// OCIArtifact pushes the artifacts to the destination registry on the mirror
+	// side, as an image with one layer per artifact, tagged <coreos release>-<architecture>.
+	OCIArtifact bool `json:"ociArtifact,omitempty"`
+	// CABundle is a PEM file of certificate authorities trusted, in addition
+	// to the ones of the system, by the HTTPS hosts of the artifacts.
+	CABundle string `json:"caBundle,omitempty"`
+}
+
+// GetArchitectures returns the architectures of the boot artifacts, or the default one
+func (b BootImages) GetArchitectures() []string {
+	if len(b.Architectures) == 0 {
+		return []string{DefaultBootImagesArchitecture}
+	}
+	return b.Architectures
+}
+
+func (b BootImages) DeepCopy() *BootImages {
+	bootImagesCopy := &BootImages{
+		HTTPDir:     b.HTTPDir,
+		OCIArtifact: b.OCIArtifact,
+		CABundle:    b.CABundle,
+	}
+	bootImagesCopy.Architectures = append([]string(nil), b.Architectures...)
+	bootImagesCopy.Platforms = append([]string(nil), b.Platforms...)
+	bootImagesCopy.Formats = append([]string(nil), b.Formats...)
+	return bootImagesCopy
+}
+
+const (
+	// DefaultGraphBaseImage is the base image of the Cincinnati graph data image.
+	// It is pinned by digest, so that the graph data image is reproducible.
+	// The digest is the one of the ubi9/ubi image used in the examples of the README,
+	// published by Red Hat on registry.access.redhat.com and registry.redhat.io.
+	// To update it, when a new ubi9/ubi image is released with security fixes, take the
+	// digest of the latest tag from `skopeo inspect docker://registry.access.redhat.com/ubi9/ubi:latest`,
+	// or from the ubi9/ubi page of the Red Hat Ecosystem Catalog, and replace it here and in the README.
+	DefaultGraphBaseImage = "registry.access.redhat.com/ubi9/ubi@sha256:20f695d2a91352d4eaa25107535126727b5945bff38ed36a3e59590f495046f0"
+	// ScratchGraphBaseImage builds the graph data image without base image.
+	ScratchGraphBaseImage = "scratch"
+	// ReleasePayloadRepository is the repository of the OCP release payloads
+	ReleasePayloadRepository = "quay.io/openshift-release-dev/ocp-release"
+	// DefaultBootImagesArchitecture is the architecture of the boot artifacts when none is set.
+	DefaultBootImagesArchitecture = "x86_64"
+)
+
 func (p Platform) DeepCopy() Platform {
 	platformCopy := Platform{
-		Graph: p.Graph,
+		Graph:             p.Graph,
+		GraphBaseImage:    p.GraphBaseImage,
+		GraphCopyHelper:   p.GraphCopyHelper,
+		TrimGraph:         p.TrimGraph,
+		GraphLocalChannel: p.GraphLocalChannel,
 	}
 
 	platformCopy.Channels = make([]ReleaseChannel, len(p.Channels))
@@ -113,9 +234,24 @@ func (p Platform) DeepCopy() Platform {
 	platformCopy.Architectures = make([]string, len(p.Architectures))
 	copy(platformCopy.Architectures, p.Architectures)
 
+	platformCopy.Releases = make([]ReleasePayload, len(p.Releases))
+	copy(platformCopy.Releases, p.Releases)
+
+	if p.BootImages != nil {
+		platformCopy.BootImages = p.BootImages.DeepCopy()
+	}
+
 	return platformCopy
 }
 
+// GetGraphBaseImage returns the base image of the Cincinnati graph data image.
+func (p Platform) GetGraphBaseImage() string {
+	if p.GraphBaseImage != "" {
+		return p.GraphBaseImage
+	}
+	return DefaultGraphBaseImage
+}
+
 // ReleaseChannel defines the configuration for individual
 // OCP and OKD channels
 type ReleaseChannel struct {
@@ -132,6 +268,11 @@ type ReleaseChannel struct {
 	// ShortestPath mode calculates the shortest path
 	// between the min and mav version
 	ShortestPath bool `json:"shortestPath,omitempty"`
+	// EUSPath mode calculates the minimal releases for an EUS-to-EUS update
+	// between the min and max version, which must both be in EUS minors.
+	// The releases of the odd intermediate minors are only applied to the
+	// control plane, while the worker pools are paused.
+	EUSPath bool `json:"eusPath,omitempty"`
 	// Full mode set the MinVersion to the
 	// first release in the channel and the MaxVersion
 	// to the last release in the channel.
@@ -175,14 +316,24 @@ type Operator struct {
 	// Full defines whether all packages within the catalog
 	// or specified IncludeConfig will be mirrored or just channel heads.
 	Full bool `json:"full,omitempty"`
-	// SkipDependencies will not include dependencies
-	// of bundles included in the diff if true.
+	// SkipDependencies will not include the packages and GVKs
+	// required by the selected bundles, looked up in all the catalogs
+	// of the ImageSetConfiguration, if true.
 	SkipDependencies bool `json:"skipDependencies,omitempty"`
 	// path on disk for a template to use to complete catalogSource custom resource
 	// generated by oc-mirror
 	TargetCatalogSourceTemplate string `json:"targetCatalogSourceTemplate,omitempty"`
+	// CatalogBaseImage is the opm image on top of which the catalog is built,
+	// when Catalog is a file-based catalog directory, or a .json or .yaml file (dir://).
+	// Defaults to DefaultCatalogBaseImage.
+	CatalogBaseImage string `json:"catalogBaseImage,omitempty"`
 }
 
+// DefaultCatalogBaseImage is the opm image used to build catalogs
+// from file-based catalog directories. It is pinned to the version of the
+// operator-registry that oc-mirror builds the catalogs with.
+const DefaultCatalogBaseImage = "quay.io/operator-framework/opm:v1.47.0"
+
 // GetUniqueName determines the catalog name that will
 // be tracked in the metadata and built. This depends on what fields
 // are set between Catalog, TargetName, and TargetTag.
@@ -228,6 +379,43 @@ func (o Operator) IsFBCOCI() bool {
 	return strings.HasPrefix(o.Catalog, "oci:")
 }
 
+// IsFBCDir determines if the catalog is a file-based catalog directory, or file (dir://),
+// from which the catalog image is built.
+func (o Operator) IsFBCDir() bool {
+	return strings.HasPrefix(o.Catalog, "dir://")
+}
+
+// GetCatalogBaseImage returns the opm image used to build a catalog
+// from a file-based catalog directory.
+func (o Operator) GetCatalogBaseImage() string {
+	if o.CatalogBaseImage != "" {
+		return o.CatalogBaseImage
+	}
+	return DefaultCatalogBaseImage
+}
+
+// CompositeCatalog defines a single target catalog made of the packages
+// of several source catalogs. Each source catalog is filtered on its own,
+// and the results are merged in one declarative config, from which the
+// target catalog image is built.
+type CompositeCatalog struct {
+	// TargetCatalog is the name (including optional namespace) of the catalog
+	// image built on the disconnected registry. See Operator.TargetCatalog.
+	TargetCatalog string `json:"targetCatalog"`
+	// TargetTag is the tag the catalog image will be built with.
+	// Defaults to latest.
+	TargetTag string `json:"targetTag,omitempty"`
+	// CatalogBaseImage is the opm image on top of which the catalog is built.
+	// Defaults to DefaultCatalogBaseImage.
+	CatalogBaseImage string `json:"catalogBaseImage,omitempty"`
+	// path on disk for a template to use to complete catalogSource custom resource
+	// generated by oc-mirror
+	TargetCatalogSourceTemplate string `json:"targetCatalogSourceTemplate,omitempty"`
+	// Catalogs are the source catalogs, along with the packages
+	// to include from each of them.
+	Catalogs []Operator `json:"catalogs"`
+}
+
 // Helm defines the configuration for Helm chart download
 // and image mirroring
 type Helm struct {
@@ -272,8 +460,26 @@ type Image struct {
 }
 
 // SampleImages define the configuration
-// for Sameple content types.
-// Not implemented.
+// for Sample content types.
+// A sample entry selects ImageStreams from the samples operator
+// of the mirrored release payloads, by stream name and/or by tag.
 type SampleImages struct {
+	// Name of the sample ImageStream (i.e ruby, nodejs).
+	// When empty, the Tags filter is applied to all the sample ImageStreams.
 	Image `json:",inline"`
+	// Tags of the ImageStream to mirror.
+	// When empty, all the tags of the ImageStream are mirrored.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Matches determines whether the ImageStream tag `tag` of the ImageStream `stream`
+// is selected by this sample filter.
+func (s SampleImages) Matches(stream, tag string) bool {
+	if s.Name != "" && s.Name != stream {
+		return false
+	}
+	if len(s.Tags) == 0 {
+		return true
+	}
+	return slices.Contains(s.Tags, tag)
 }
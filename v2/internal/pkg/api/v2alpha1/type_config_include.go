@@ -25,6 +25,9 @@ type IncludePackage struct {
 
 	// All channels containing these bundles are parsed for an upgrade graph.
 	IncludeBundle `json:",inline"`
+
+	// SelectionPolicy applies to all the selected channels of the package.
+	SelectionPolicy `json:",inline"`
 }
 
 // IncludeChannel contains a name (required) and versions (optional)
@@ -34,6 +37,9 @@ type IncludeChannel struct {
 	Name string `json:"name" yaml:"name"`
 
 	IncludeBundle `json:",inline"`
+
+	// SelectionPolicy of the channel, on top of the one of its package.
+	SelectionPolicy `json:",inline"`
 }
 
 // IncludeBundle contains a name (required) and versions (optional) to
@@ -49,6 +55,35 @@ type IncludeBundle struct {
 	// MinBundle string `json:"minBundle,omitempty" yaml:"minBundle,omitempty"`
 }
 
+// SelectionPolicy narrows the bundles selected in a channel, after the filtering by version.
+// When a policy is set on a channel filtered neither by version nor with full, the newest bundle
+// selected by the policy replaces the channel head.
+// The upgrade edges of the channels are rewired so that the filtered catalog remains valid.
+type SelectionPolicy struct {
+	// Latest keeps only the latest N bundles of each channel.
+	Latest int `json:"latest,omitempty" yaml:"latest,omitempty"`
+	// CompatibleWithPlatform skips the bundles whose olm.maxOpenShiftVersion is lower than
+	// the highest OpenShift minor mirrored by the platform channels of the ImageSetConfiguration.
+	CompatibleWithPlatform bool `json:"compatibleWithPlatform,omitempty" yaml:"compatibleWithPlatform,omitempty"`
+	// SkipDeprecated skips the bundles marked deprecated in the catalog.
+	SkipDeprecated bool `json:"skipDeprecated,omitempty" yaml:"skipDeprecated,omitempty"`
+}
+
+// IsSet returns true when any policy is set
+func (p SelectionPolicy) IsSet() bool {
+	return p != SelectionPolicy{}
+}
+
+// Merge returns the policy p overlaid by the policies set in other
+func (p SelectionPolicy) Merge(other SelectionPolicy) SelectionPolicy {
+	if other.Latest > 0 {
+		p.Latest = other.Latest
+	}
+	p.CompatibleWithPlatform = p.CompatibleWithPlatform || other.CompatibleWithPlatform
+	p.SkipDeprecated = p.SkipDeprecated || other.SkipDeprecated
+	return p
+}
+
 // Encode IncludeConfig in an efficient, opaque format.
 func (ic *IncludeConfig) Encode(w io.Writer) error {
 	enc := gob.NewEncoder(w)
@@ -0,0 +1,17 @@
+package v2alpha1
+
+//go:generate go run gen_type_docs.go -sources type_config.go,type_config_include.go
+
+// TypeDocs are the doc comments of the configuration types and of their fields
+type TypeDocs struct {
+	// Types are the doc comments of the types, by type name
+	Types map[string]string
+	// Fields are the doc comments of the fields, by <type name>.<field name>
+	Fields map[string]string
+}
+
+// ConfigDocs returns the doc comments of the configuration types, as written
+// in the sources of this package when zz_generated.type_docs.go was generated
+func ConfigDocs() TypeDocs {
+	return configDocs
+}
@@ -0,0 +1,22 @@
+package v2alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1/internal/typedocs"
+)
+
+func TestConfigDocs(t *testing.T) {
+	t.Run("Testing ConfigDocs - should match the doc comments of the sources", func(t *testing.T) {
+		types, fields, err := typedocs.Parse("type_config.go", "type_config_include.go")
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		docs := ConfigDocs()
+		assert.Equal(t, types, docs.Types, "zz_generated.type_docs.go is out of date: run go generate")
+		assert.Equal(t, fields, docs.Fields, "zz_generated.type_docs.go is out of date: run go generate")
+		assert.NotEmpty(t, docs.Fields["Operator.Catalog"])
+	})
+}
@@ -20,6 +20,7 @@ const (
 	TypeGeneric
 	TypeKubeVirtContainer
 	TypeHelmImage
+	TypeSampleImage
 )
 
 // ImageTypeString defines the string
@@ -32,7 +33,9 @@ var imageTypeStrings = map[ImageType]string{
 	TypeOperatorBundle:       "operatorBundle",
 	TypeOperatorRelatedImage: "operatorRelatedImage",
 	TypeGeneric:              "generic",
+	TypeKubeVirtContainer:    "kubeVirtContainer",
 	TypeHelmImage:            "helmImage",
+	TypeSampleImage:          "sampleImage",
 }
 
 var imageStringsType = map[string]ImageType{
@@ -43,7 +46,9 @@ var imageStringsType = map[string]ImageType{
 	"operatorBundle":       TypeOperatorBundle,
 	"operatorRelatedImage": TypeOperatorRelatedImage,
 	"generic":              TypeGeneric,
+	"kubeVirtContainer":    TypeKubeVirtContainer,
 	"helmImage":            TypeHelmImage,
+	"sampleImage":          TypeSampleImage,
 }
 
 func (it ImageType) IsRelease() bool {
@@ -66,6 +71,10 @@ func (it ImageType) IsHelmImage() bool {
 	return it == TypeHelmImage
 }
 
+func (it ImageType) IsSampleImage() bool {
+	return it == TypeSampleImage
+}
+
 // String returns the string representation
 // of an Image Type
 func (it ImageType) String() string {
@@ -203,6 +203,7 @@ type CollectorSchema struct {
 	TotalOperatorImages   int
 	TotalAdditionalImages int
 	TotalHelmImages       int
+	TotalSampleImages     int
 	AllImages             []CopyImageSchema
 	CopyImageSchemaMap    CopyImageSchemaMap
 	CatalogToFBCMap       map[string]CatalogFilterResult // key is the mirror.operator.catalog
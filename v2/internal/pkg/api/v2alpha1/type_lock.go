@@ -0,0 +1,59 @@
+package v2alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImageSetConfigurationLock object kind.
+const ImageSetConfigurationLockKind = "ImageSetConfigurationLock"
+
+// ImageSetConfigurationLock is an ImageSetConfiguration where every moving input
+// is resolved: the release channels are replaced by the release payloads pinned by digest,
+// the catalogs and additional images are pinned by digest and the helm charts by version.
+// Mirroring with a lockfile copies exactly the locked images, without any
+// Cincinnati or catalog tag resolution.
+type ImageSetConfigurationLock struct {
+	metav1.TypeMeta `json:",inline"`
+	// ConfigDigest is the sha256 digest of the locked ImageSetConfiguration, with its
+	// files merged and its environment variables expanded.
+	ConfigDigest string `json:"configDigest"`
+	// ImageSetConfigurationSpec is the locked configuration, mirrored by --lockfile.
+	ImageSetConfigurationSpec `json:",inline"`
+	// Catalogs lists the bundles selected by the filtering of the locked catalogs.
+	Catalogs []LockedCatalog `json:"catalogs,omitempty"`
+	// Images are all the images resolved from the locked configuration.
+	Images []LockedImage `json:"images"`
+}
+
+// LockedCatalog is the filtered content of a locked catalog.
+type LockedCatalog struct {
+	// Catalog is the catalog image, pinned by digest.
+	Catalog string `json:"catalog"`
+	// Bundles selected from the catalog.
+	Bundles []LockedBundle `json:"bundles"`
+}
+
+// LockedBundle is a bundle selected from a locked catalog.
+type LockedBundle struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+}
+
+// LockedImage is an image of the locked configuration, pinned by digest.
+type LockedImage struct {
+	// Origin is the image as referenced by the configuration, a release payload, a catalog or a helm chart.
+	Origin string `json:"origin"`
+	// Image is Origin pinned by digest.
+	Image string `json:"image"`
+	// Type is the content type of the image.
+	Type ImageType `json:"type"`
+}
+
+// GetImageSetConfiguration returns the locked configuration as an ImageSetConfiguration.
+func (l ImageSetConfigurationLock) GetImageSetConfiguration() ImageSetConfiguration {
+	return ImageSetConfiguration{
+		TypeMeta:                  metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: ImageSetConfigurationKind},
+		ImageSetConfigurationSpec: l.ImageSetConfigurationSpec,
+	}
+}
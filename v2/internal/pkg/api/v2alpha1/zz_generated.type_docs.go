@@ -0,0 +1,109 @@
+// Code generated by gen_type_docs.go; DO NOT EDIT.
+
+package v2alpha1
+
+var configDocs = TypeDocs{
+	Types: map[string]string{
+		"BootImages":                      "BootImages defines the RHCOS boot artifacts downloaded with the releases. The artifacts are stored in the working-dir, and carried in the archive.",
+		"Chart":                           "Chart is the information an individual Helm chart",
+		"CompositeCatalog":                "CompositeCatalog defines a single target catalog made of the packages of several source catalogs. Each source catalog is filtered on its own, and the results are merged in one declarative config, from which the target catalog image is built.",
+		"Delete":                          "Delete defines the configuration for content types within the imageset.",
+		"DeleteImageSetConfiguration":     "DeleteImageSetConfiguration configures image set creation.",
+		"DeleteImageSetConfigurationSpec": "DeleteImageSetConfigurationSpec defines the global configuration for a delete imageset. This is to ensure a clean differentiation between delete and mirror and is designed to avoid accidental deletion of images (when using imagesetconfig)",
+		"Helm":                            "Helm defines the configuration for Helm chart download and image mirroring",
+		"Image":                           "Image contains image pull information.",
+		"ImageSetConfiguration":           "ImageSetConfiguration configures image set creation.",
+		"ImageSetConfigurationSpec":       "ImageSetConfigurationSpec defines the global configuration for an imageset.",
+		"IncludeBundle":                   "IncludeBundle contains a name (required) and versions (optional) to include in the diff. The full package or channel is only included if no versions are specified.",
+		"IncludeChannel":                  "IncludeChannel contains a name (required) and versions (optional) to include in the diff. The full channel is only included if no versions are specified.",
+		"IncludeConfig":                   "IncludeConfig defines a list of packages for operator version selection.",
+		"IncludePackage":                  "IncludePackage contains a name (required) and channels and/or versions (optional) to include in the diff. The full package is only included if no channels or versions are specified.",
+		"Mirror":                          "Mirror defines the configuration for content types within the imageset.",
+		"Operator":                        "Operator defines the configuration for operator catalog mirroring.",
+		"Platform":                        "Platform defines the configuration for OpenShift and OKD platform types.",
+		"ReleaseChannel":                  "ReleaseChannel defines the configuration for individual OCP and OKD channels",
+		"ReleasePayload":                  "ReleasePayload identifies a release payload, either by version or by image.",
+		"Repository":                      "Repository defines the configuration for a Helm repository.",
+		"SampleImages":                    "SampleImages define the configuration for Sample content types. A sample entry selects ImageStreams from the samples operator of the mirrored release payloads, by stream name and/or by tag.",
+		"SelectionPolicy":                 "SelectionPolicy narrows the bundles selected in a channel, after the filtering by version. When a policy is set on a channel filtered neither by version nor with full, the newest bundle selected by the policy replaces the channel head. The upgrade edges of the channels are rewired so that the filtered catalog remains valid.",
+	},
+	Fields: map[string]string{
+		"BootImages.Architectures":                     "Architectures are the coreos architectures of the artifacts (x86_64, aarch64, ppc64le, s390x). Defaults to x86_64.",
+		"BootImages.CABundle":                          "CABundle is a PEM file of certificate authorities trusted, in addition to the ones of the system, by the HTTPS hosts of the artifacts.",
+		"BootImages.Formats":                           "Formats are the formats of the artifacts, such as iso, pxe, qcow2.gz or ova. All the formats of the platforms are downloaded when empty.",
+		"BootImages.HTTPDir":                           "HTTPDir is a local directory, served over HTTP, to which the artifacts are copied on the mirror side (diskToMirror and mirrorToMirror).",
+		"BootImages.OCIArtifact":                       "OCIArtifact pushes the artifacts to the destination registry on the mirror side, as an image with one layer per artifact, tagged <coreos release>-<architecture>.",
+		"BootImages.Platforms":                         "Platforms are the coreos platforms of the artifacts, such as metal, qemu, vmware or openstack.",
+		"Chart.ImagePaths":                             "ImagePaths are custom JSON paths for images location in the helm manifest or templates",
+		"Chart.Name":                                   "Chart is the chart name as define in the Chart.yaml or in the Helm repo.",
+		"Chart.Path":                                   "Path defines the path on disk where the chart is stored. This is applicable for a local chart.",
+		"Chart.Version":                                "Version is the chart version as define in the Chart.yaml or in the Helm repo.",
+		"CompositeCatalog.CatalogBaseImage":            "CatalogBaseImage is the opm image on top of which the catalog is built. Defaults to DefaultCatalogBaseImage.",
+		"CompositeCatalog.Catalogs":                    "Catalogs are the source catalogs, along with the packages to include from each of them.",
+		"CompositeCatalog.TargetCatalog":               "TargetCatalog is the name (including optional namespace) of the catalog image built on the disconnected registry. See Operator.TargetCatalog.",
+		"CompositeCatalog.TargetCatalogSourceTemplate": "path on disk for a template to use to complete catalogSource custom resource generated by oc-mirror",
+		"CompositeCatalog.TargetTag":                   "TargetTag is the tag the catalog image will be built with. Defaults to latest.",
+		"Delete.AdditionalImages":                      "AdditionalImages defines the configuration for a list of individual image content types.",
+		"Delete.Helm":                                  "Helm define the configuration for Helm content types.",
+		"Delete.Operators":                             "Operators defines the configuration for Operator content types.",
+		"Delete.Platform":                              "Platform defines the configuration for OpenShift and OKD platform types.",
+		"Delete.Samples":                               "Samples defines the configuration for Sample content types.",
+		"DeleteImageSetConfigurationSpec.Delete":       "Delete defines the configuration for content types within the imageset.",
+		"DeleteImageSetConfigurationSpec.Includes":     "Includes are the paths of delete imageset configuration fragments, relative to this file, merged before it.",
+		"Helm.Local":                                   "Local is the configuration for locally stored helm charts",
+		"Helm.Repositories":                            "Repositories are the Helm repositories containing the charts",
+		"Image.Name":                                   "Name of the image. This should be an exact image pin (registry/namespace/name@sha256:<hash>) but is not required to be.",
+		"ImageSetConfigurationSpec.ArchiveSize":        "ArchiveSize is the size of the segmented archive in GB",
+		"ImageSetConfigurationSpec.Includes":           "Includes are the paths of imageset configuration fragments, relative to this file, merged before it. See config.ReadConfig for the merge rules.",
+		"ImageSetConfigurationSpec.Mirror":             "Mirror defines the configuration for content types within the imageset.",
+		"IncludeBundle.MaxVersion":                     "MaxVersion to include as the channel head version.",
+		"IncludeBundle.MinVersion":                     "MinVersion to include, plus all versions in the upgrade graph to the MaxVersion.",
+		"IncludeChannel.Name":                          "Name of channel.",
+		"IncludeConfig.Packages":                       "Packages to include.",
+		"IncludePackage.Channels":                      "Channels to include.",
+		"IncludePackage.DefaultChannel":                "",
+		"IncludePackage.Name":                          "Name of package.",
+		"Mirror.AdditionalImages":                      "AdditionalImages defines the configuration for a list of individual image content types.",
+		"Mirror.BlockedImages":                         "BlockedImages define a list of images that will be blocked from the mirroring process if they exist in other content types in the configuration.",
+		"Mirror.CompositeCatalogs":                     "CompositeCatalogs defines target catalogs built from the packages of several source catalogs.",
+		"Mirror.Helm":                                  "Helm define the configuration for Helm content types.",
+		"Mirror.Operators":                             "Operators defines the configuration for Operator content types.",
+		"Mirror.Platform":                              "Platform defines the configuration for OpenShift and OKD platform types.",
+		"Mirror.Samples":                               "Samples defines the configuration for Sample content types. The sample ImageStreams are read from the samples operator shipped in the mirrored release payloads.",
+		"Operator.Catalog":                             "Catalog image to mirror. This image must be pullable and available for subsequent pulls on later mirrors. This image should be an exact image pin (registry/namespace/name@sha256:<hash>) but is not required to be.",
+		"Operator.CatalogBaseImage":                    "CatalogBaseImage is the opm image on top of which the catalog is built, when Catalog is a file-based catalog directory, or a .json or .yaml file (dir://). Defaults to DefaultCatalogBaseImage.",
+		"Operator.Full":                                "Full defines whether all packages within the catalog or specified IncludeConfig will be mirrored or just channel heads.",
+		"Operator.SkipDependencies":                    "SkipDependencies will not include the packages and GVKs required by the selected bundles, looked up in all the catalogs of the ImageSetConfiguration, if true.",
+		"Operator.TargetCatalog":                       "TargetCatalog replaces TargetName and allows for specifying the exact URL of the target catalog, including any path-components (organization, namespace) of the target catalog's location on the disconnected registry. This answer some customers requests regarding restrictions on where images can be placed. The targetCatalog field consists of an optional namespace followed by the target image name, described in extended Backus–Naur form below:\n    target-catalog = [namespace '/'] target-name\n    target-name    = path-component\n    namespace      = path-component ['/' path-component]*\n    path-component = alpha-numeric [separator alpha-numeric]*\n    alpha-numeric  = /[a-z0-9]+/\n    separator      = /[_.]|__|[-]*/",
+		"Operator.TargetCatalogSourceTemplate":         "path on disk for a template to use to complete catalogSource custom resource generated by oc-mirror",
+		"Operator.TargetTag":                           "TargetTag is the tag the catalog image will be built with. If unset, the catalog will be publish with the provided tag in the Catalog field or a tag calculated from the partial digest.",
+		"Platform.Architectures":                       "Architectures defines one or more architectures to mirror for the release image. This is defined at the platform level to enable cross-channel upgrades.",
+		"Platform.BootImages":                          "BootImages lists the RHCOS boot artifacts (ISO, PXE, qcow2, OVA...) to download from the coreos stream metadata of the releases, found in the release payload file 0000_50_installer_coreos-bootimages",
+		"Platform.Channels":                            "Channels defines the configuration for individual OCP and OKD channels",
+		"Platform.Graph":                               "Graph defines whether Cincinnati graph data will downloaded and publish",
+		"Platform.GraphBaseImage":                      "GraphBaseImage is the base image of the Cincinnati graph data image. It is either an image reference, which should be pinned by digest for reproducible builds, or an OCI layout on disk (oci:). When set to scratch, the graph data image only contains the graph data and GraphCopyHelper. Defaults to DefaultGraphBaseImage.",
+		"Platform.GraphCopyHelper":                     "GraphCopyHelper is the path on disk to a statically linked executable, built from cmd/graph-data-copier, that copies the graph data to the volume of the update service. It is required when GraphBaseImage is scratch.",
+		"Platform.GraphLocalChannel":                   "GraphLocalChannel, when set with TrimGraph, adds to the graph data a channel with this name, listing all the mirrored releases.",
+		"Platform.KubeVirtContainer":                   "The kubeVirtContainer flag when set to true (default false) will be used to extract the kubeVirtContainer image from the release payload file 0000_50_installer_coreos-bootimages",
+		"Platform.Release":                             "This new field will allow the diskToMirror functionality to copy from a release location on disk",
+		"Platform.Releases":                            "Releases is an explicit list of release payloads, mirrored without any update graph lookup, such as hotfix or pre-GA payloads that are not part of any channel. As the releases of the channels, their signatures are verified and added to the signature config map.",
+		"Platform.TrimGraph":                           "TrimGraph rewrites the Cincinnati graph data, so that its channels and blocked edges only reference the mirrored releases. The update service then only recommends updates to releases that can be pulled.",
+		"ReleaseChannel.EUSPath":                       "EUSPath mode calculates the minimal releases for an EUS-to-EUS update between the min and max version, which must both be in EUS minors. The releases of the odd intermediate minors are only applied to the control plane, while the worker pools are paused.",
+		"ReleaseChannel.Full":                          "Full mode set the MinVersion to the first release in the channel and the MaxVersion to the last release in the channel.",
+		"ReleaseChannel.MaxVersion":                    "MaxVersion is maximum version in the release channel to mirror",
+		"ReleaseChannel.MinVersion":                    "MinVersion is minimum version in the release channel to mirror",
+		"ReleaseChannel.Name":                          "",
+		"ReleaseChannel.ShortestPath":                  "ShortestPath mode calculates the shortest path between the min and mav version",
+		"ReleaseChannel.Type":                          "Type of the platform in the context of this tool. See the PlatformType enum for options. OCP is the default.",
+		"ReleasePayload.Architecture":                  "Architecture of the payload resolved from Version (amd64, arm64, ppc64le, s390x or multi). Defaults to amd64.",
+		"ReleasePayload.Image":                         "Image is the pullspec of the release payload, pinned by digest",
+		"ReleasePayload.Version":                       "Version of an OCP release, such as 4.16.3, resolved to the payload ReleasePayloadRepository:<version>-<architecture>",
+		"Repository.Charts":                            "Charts is a list of charts to pull from the repo",
+		"Repository.Name":                              "Name is the name of the Helm repository",
+		"Repository.URL":                               "URL is the url of the Helm repository",
+		"SampleImages.Tags":                            "Tags of the ImageStream to mirror. When empty, all the tags of the ImageStream are mirrored.",
+		"SelectionPolicy.CompatibleWithPlatform":       "CompatibleWithPlatform skips the bundles whose olm.maxOpenShiftVersion is lower than the highest OpenShift minor mirrored by the platform channels of the ImageSetConfiguration.",
+		"SelectionPolicy.Latest":                       "Latest keeps only the latest N bundles of each channel.",
+		"SelectionPolicy.SkipDeprecated":               "SkipDeprecated skips the bundles marked deprecated in the catalog.",
+	},
+}
@@ -144,7 +144,7 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 								bundles := collectorSchema.CopyImageSchemaMap.BundlesByImage[img.Origin]
 								result.err = &mirrorErrorSchema{image: img, err: err, operators: operators, bundles: bundles}
 								spinner.Abort(false)
-							case img.Type.IsRelease() || img.Type.IsAdditionalImage() || img.Type.IsHelmImage():
+							case img.Type.IsRelease() || img.Type.IsAdditionalImage() || img.Type.IsHelmImage() || img.Type.IsSampleImage():
 								result.err = &mirrorErrorSchema{image: img, err: err}
 								spinner.Abort(false)
 							}
@@ -238,6 +238,7 @@ func logResults(log clog.PluggableLoggerInterface, copyMode string, copiedImages
 	logResult(log, copyModeMsg, "operator", copiedImages.TotalOperatorImages, collectorSchema.TotalOperatorImages)
 	logResult(log, copyModeMsg, "additional", copiedImages.TotalAdditionalImages, collectorSchema.TotalAdditionalImages)
 	logResult(log, copyModeMsg, "helm", copiedImages.TotalHelmImages, collectorSchema.TotalHelmImages)
+	logResult(log, copyModeMsg, "sample", copiedImages.TotalSampleImages, collectorSchema.TotalSampleImages)
 }
 
 func logResult(log clog.PluggableLoggerInterface, copyMode, imageType string, copied, total int) {
@@ -323,6 +324,8 @@ func incrementTotals(imgType v2alpha1.ImageType, copiedImages *v2alpha1.Collecto
 		copiedImages.TotalOperatorImages++
 	case v2alpha1.TypeHelmImage:
 		copiedImages.TotalHelmImages++
+	case v2alpha1.TypeSampleImage:
+		copiedImages.TotalSampleImages++
 	}
 }
 
@@ -130,6 +130,8 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 						o.CopiedImages.TotalOperatorImages++
 					case v2alpha1.TypeHelmImage:
 						o.CopiedImages.TotalHelmImages++
+					case v2alpha1.TypeSampleImage:
+						o.CopiedImages.TotalSampleImages++
 					}
 				case img.Type.IsOperator():
 					operators := collectorSchema.CopyImageSchemaMap.OperatorsByImage[img.Origin]
@@ -143,7 +145,7 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 					spinner.Abort(false)
 					mu.Unlock()
 					return NewUnsafeError(currentMirrorError)
-				case img.Type.IsAdditionalImage() || img.Type.IsHelmImage():
+				case img.Type.IsAdditionalImage() || img.Type.IsHelmImage() || img.Type.IsSampleImage():
 					errArray = append(errArray, mirrorErrorSchema{image: img, err: err})
 					spinner.Abort(false)
 				}
@@ -195,10 +197,17 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 				o.Log.Info(emoji.SpinnerCrossMark+" %d / %d helm images mirrored: Some helm images failed to mirror - please check the logs", o.CopiedImages.TotalHelmImages, collectorSchema.TotalHelmImages)
 			}
 		}
+		if collectorSchema.TotalSampleImages != 0 {
+			if o.CopiedImages.TotalSampleImages == collectorSchema.TotalSampleImages {
+				o.Log.Info(emoji.SpinnerCheckMark+" %d / %d sample images mirrored successfully", o.CopiedImages.TotalSampleImages, collectorSchema.TotalSampleImages)
+			} else {
+				o.Log.Info(emoji.SpinnerCrossMark+" %d / %d sample images mirrored: Some sample images failed to mirror - please check the logs", o.CopiedImages.TotalSampleImages, collectorSchema.TotalSampleImages)
+			}
+		}
 	} else {
 		o.Log.Info("=== Results ===")
 		totalImages := len(collectorSchema.AllImages)
-		totalImagesMirrored := o.CopiedImages.TotalAdditionalImages + o.CopiedImages.TotalOperatorImages + o.CopiedImages.TotalReleaseImages + o.CopiedImages.TotalHelmImages
+		totalImagesMirrored := o.CopiedImages.TotalAdditionalImages + o.CopiedImages.TotalOperatorImages + o.CopiedImages.TotalReleaseImages + o.CopiedImages.TotalHelmImages + o.CopiedImages.TotalSampleImages
 		if totalImagesMirrored == totalImages && totalImages != 0 {
 			o.Log.Info(emoji.SpinnerCheckMark+" %d / %d images deleted successfully", totalImagesMirrored, totalImages)
 		} else {
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+)
+
+const (
+	catalogTmpPrefix   string = "oc-mirror-catalog-"
+	catalogOutputTable string = "table"
+	catalogOutputJSON  string = "json"
+	catalogOutputYAML  string = "yaml"
+)
+
+var (
+	catalogLongDesc = templates.LongDesc(
+		`
+		Inspect operator catalogs, without mirroring: browse their packages, channels, bundles and related images,
+		or report the changes between two of them.
+
+		The catalogs are either images, pinned by digest or not, OCI layouts (oci://) or file-based catalog
+		directories (dir://). The catalog images are cached in the workspace when set, otherwise in a temporary one.
+		`,
+	)
+	catalogExamples = templates.Examples(
+		`
+# List the packages of a catalog, with their default channel and channels
+oc-mirror catalog inspect registry.redhat.io/redhat/redhat-operator-index:v4.16 --v2
+
+# List the channels of a package, with their head and bundle versions
+oc-mirror catalog inspect registry.redhat.io/redhat/redhat-operator-index:v4.16 --package aws-load-balancer-operator --v2
+
+# List the related images of a bundle of a catalog in an OCI layout, as YAML
+oc-mirror catalog inspect oci:///home/<user>/catalogs/redhat-operator-index --package aws-load-balancer-operator --bundle aws-load-balancer-operator.v1.1.1 -o yaml --v2
+
+# Print the operators entry of an imageset configuration selecting versions of a channel
+oc-mirror catalog inspect registry.redhat.io/redhat/redhat-operator-index:v4.16 --package aws-load-balancer-operator --channel stable-v1 --min-version 1.1.0 --snippet --v2
+
+# Report what a bump of the redhat-operator-index changes, by package
+oc-mirror catalog diff registry.redhat.io/redhat/redhat-operator-index@sha256:<digest A> registry.redhat.io/redhat/redhat-operator-index@sha256:<digest B> --v2
+
+# Report the changes as JSON, caching the catalog images in the workspace of the mirroring
+oc-mirror catalog diff registry.redhat.io/redhat/redhat-operator-index@sha256:<digest A> registry.redhat.io/redhat/redhat-operator-index:v4.16 --output json --workspace file:///home/<user>/oc-mirror/mirror1 --v2
+		`,
+	)
+)
+
+// DeclarativeConfigLoader loads the declarative config of the catalog of an operator
+type DeclarativeConfigLoader interface {
+	DeclarativeConfig(ctx context.Context, op v2alpha1.Operator) (*declcfg.DeclarativeConfig, error)
+}
+
+type CatalogSchema struct {
+	Log  clog.PluggableLoggerInterface
+	Opts *mirror.CopyOptions
+	// Out receives the reports
+	Out io.Writer
+	// Loader loads the declarative config of the catalogs
+	Loader DeclarativeConfigLoader
+	// Output is the format of the reports: table, json or yaml
+	Output string
+	// Package, Bundle, Channels, MinVersion, MaxVersion and Snippet select what inspect reports
+	Package    string
+	Bundle     string
+	Channels   []string
+	MinVersion string
+	MaxVersion string
+	Snippet    bool
+}
+
+// NewCatalogCommand - setup the 'catalog' sub command and its diff and inspect sub commands
+func NewCatalogCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &CatalogSchema{
+		Log:  log,
+		Opts: opts,
+		Out:  os.Stdout,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "catalog",
+		Short:   "Inspects operator catalogs",
+		Long:    catalogLongDesc,
+		Example: catalogExamples,
+		Args:    cobra.NoArgs,
+	}
+	cmd.PersistentFlags().StringVarP(&ex.Output, "output", "o", catalogOutputTable, "Format of the report: table, json or yaml")
+
+	diff := &cobra.Command{
+		Use:   "diff <old catalog> <new catalog>",
+		Short: "Reports the bundles, channel heads, default channels, deprecations and related images changed between two catalogs",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := ex.RunDiff(cmd.Context(), args[0], args[1]); err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	inspect := &cobra.Command{
+		Use:   "inspect <catalog>",
+		Short: "Reports the packages of a catalog, the channels and bundle versions of a package, or the related images of a bundle",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := ex.RunInspect(cmd.Context(), args[0]); err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	inspect.Flags().StringVar(&ex.Package, "package", "", "Package to report the channels of")
+	inspect.Flags().StringVar(&ex.Bundle, "bundle", "", "Bundle of the package to report the related images of")
+	inspect.Flags().BoolVar(&ex.Snippet, "snippet", false, "Print the operators entry of an imageset configuration selecting the package, as YAML unless --output json")
+	inspect.Flags().StringSliceVar(&ex.Channels, "channel", nil, "Channels of the package to select with --snippet. Defaults to the whole package")
+	inspect.Flags().StringVar(&ex.MinVersion, "min-version", "", "Lowest bundle version to select with --snippet")
+	inspect.Flags().StringVar(&ex.MaxVersion, "max-version", "", "Highest bundle version to select with --snippet")
+
+	cmd.AddCommand(diff, inspect)
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// RunDiff - load both catalogs and report their changes by package
+func (o CatalogSchema) RunDiff(ctx context.Context, oldCatalog, newCatalog string) error {
+	if err := o.validateOutput(); err != nil {
+		return err
+	}
+	loader, cleanup, err := o.loader()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	oldDC, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: oldCatalog})
+	if err != nil {
+		return err
+	}
+	newDC, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: newCatalog})
+	if err != nil {
+		return err
+	}
+	diff := operator.DiffCatalogs(oldCatalog, oldDC, newCatalog, newDC)
+
+	if o.Output == catalogOutputTable && len(diff.Packages) == 0 {
+		fmt.Fprintf(o.Out, "no changes between %s and %s\n", oldCatalog, newCatalog)
+		return nil
+	}
+	return o.writeReport(diff, []string{"PACKAGE", "CHANGE", "DETAILS"}, diff.Rows)
+}
+
+// RunInspect - load the catalog and report, depending on the selection, its packages,
+// the channels of a package, the related images of a bundle or the snippet selecting a package
+func (o CatalogSchema) RunInspect(ctx context.Context, catalog string) error {
+	if err := o.validateOutput(); err != nil {
+		return err
+	}
+	switch {
+	case o.Package == "" && (o.Bundle != "" || o.Snippet):
+		return fmt.Errorf("--bundle and --snippet require --package")
+	case o.Bundle != "" && o.Snippet:
+		return fmt.Errorf("--bundle can not be combined with --snippet")
+	case !o.Snippet && (len(o.Channels) > 0 || o.MinVersion != "" || o.MaxVersion != ""):
+		return fmt.Errorf("--channel, --min-version and --max-version require --snippet")
+	}
+
+	loader, cleanup, err := o.loader()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	dc, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: catalog})
+	if err != nil {
+		return err
+	}
+	ctlg := operator.NewOperatorCatalog(dc)
+
+	switch {
+	case o.Snippet:
+		include, err := ctlg.IncludePackage(o.Package, o.Channels, o.MinVersion, o.MaxVersion)
+		if err != nil {
+			return err
+		}
+		snippet := []v2alpha1.Operator{{Catalog: catalog, IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{include}}}}
+		if o.Output == catalogOutputJSON {
+			return o.writeJSON(snippet)
+		}
+		return o.writeYAML(snippet)
+	case o.Bundle != "":
+		bundle, err := ctlg.BundleInfo(o.Package, o.Bundle)
+		if err != nil {
+			return err
+		}
+		return o.writeReport(bundle, []string{"NAME", "IMAGE"}, func() [][]string {
+			var rows [][]string
+			for _, img := range bundle.RelatedImages {
+				rows = append(rows, []string{img.Name, img.Image})
+			}
+			return rows
+		})
+	case o.Package != "":
+		pkg, err := ctlg.PackageInfo(o.Package)
+		if err != nil {
+			return err
+		}
+		return o.writeReport(pkg, []string{"CHANNEL", "HEAD", "VERSIONS"}, func() [][]string {
+			var rows [][]string
+			for _, ch := range pkg.Channels {
+				name := ch.Name
+				if name == pkg.DefaultChannel {
+					name += " (default)"
+				}
+				var versions []string
+				for _, b := range ch.Bundles {
+					versions = append(versions, cmp.Or(b.Version, b.Name))
+				}
+				rows = append(rows, []string{name, ch.Head, strings.Join(versions, ",")})
+			}
+			return rows
+		})
+	default:
+		pkgs := ctlg.PackageInfos()
+		return o.writeReport(pkgs, []string{"PACKAGE", "DEFAULT CHANNEL", "CHANNELS"}, func() [][]string {
+			var rows [][]string
+			for _, pkg := range pkgs {
+				var channels []string
+				for _, ch := range pkg.Channels {
+					channels = append(channels, ch.Name)
+				}
+				rows = append(rows, []string{pkg.Name, pkg.DefaultChannel, strings.Join(channels, ",")})
+			}
+			return rows
+		})
+	}
+}
+
+func (o CatalogSchema) validateOutput() error {
+	if o.Output != catalogOutputTable && o.Output != catalogOutputJSON && o.Output != catalogOutputYAML {
+		return fmt.Errorf("--output must be one of %s, %s or %s", catalogOutputTable, catalogOutputJSON, catalogOutputYAML)
+	}
+	return nil
+}
+
+// loader returns the loader of the catalogs, working in the workspace when set,
+// otherwise in a temporary one removed by the returned cleanup
+func (o CatalogSchema) loader() (DeclarativeConfigLoader, func(), error) {
+	if o.Loader != nil {
+		return o.Loader, func() {}, nil
+	}
+	opts, cleanup, err := contentOpts(o.Opts, catalogTmpPrefix)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	return operator.NewCatalogLoader(o.Log, opts, mirror.New(mirror.NewMirrorCopy(), mirror.NewMirrorDelete()), manifest.New(o.Log)), cleanup, nil
+}
+
+// writeReport writes the report in the format of --output, as the rows under header for a table
+func (o CatalogSchema) writeReport(report any, header []string, rows func() [][]string) error {
+	switch o.Output {
+	case catalogOutputJSON:
+		return o.writeJSON(report)
+	case catalogOutputYAML:
+		return o.writeYAML(report)
+	default:
+		return o.writeTable(header, rows())
+	}
+}
+
+func (o CatalogSchema) writeYAML(report any) error {
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = o.Out.Write(data)
+	return err
+}
+
+func (o CatalogSchema) writeJSON(report any) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(o.Out, string(data))
+	return err
+}
+
+func (o CatalogSchema) writeTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(o.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(row, "\t"), "\t"))
+	}
+	return w.Flush()
+}
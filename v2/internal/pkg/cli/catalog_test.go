@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+)
+
+type mockDeclarativeConfigLoader map[string]*declcfg.DeclarativeConfig
+
+func (o mockDeclarativeConfigLoader) DeclarativeConfig(_ context.Context, op v2alpha1.Operator) (*declcfg.DeclarativeConfig, error) {
+	dc, ok := o[op.Catalog]
+	if !ok {
+		return nil, fmt.Errorf("unable to find catalog %s: manifest unknown", op.Catalog)
+	}
+	return dc, nil
+}
+
+func TestCatalog(t *testing.T) {
+	const (
+		oldCatalog = "registry.redhat.io/redhat/redhat-operator-index@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+		newCatalog = "registry.redhat.io/redhat/redhat-operator-index@sha256:25d123725cf91c20b497ca9dae8e0a6e8dedd8fe64f83757f3b41f6ac447eac0"
+	)
+	loader := mockDeclarativeConfigLoader{
+		oldCatalog: {
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v1"}},
+			Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v1", Entries: []declcfg.ChannelEntry{{Name: "albo.v1.1.0"}}}},
+			Bundles:  []declcfg.Bundle{{Schema: declcfg.SchemaBundle, Package: "aws-load-balancer-operator", Name: "albo.v1.1.0"}},
+		},
+		newCatalog: {
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v1"}},
+			Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v1", Entries: []declcfg.ChannelEntry{
+				{Name: "albo.v1.1.0"},
+				{Name: "albo.v1.2.0", Replaces: "albo.v1.1.0"},
+			}}},
+			Bundles: []declcfg.Bundle{
+				{Schema: declcfg.SchemaBundle, Package: "aws-load-balancer-operator", Name: "albo.v1.1.0"},
+				{Schema: declcfg.SchemaBundle, Package: "aws-load-balancer-operator", Name: "albo.v1.2.0"},
+			},
+		},
+	}
+	newCatalogSchema := func(output string) (*CatalogSchema, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &CatalogSchema{
+			Log:    clog.New("trace"),
+			Opts:   &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+			Out:    out,
+			Loader: loader,
+			Output: output,
+		}, out
+	}
+
+	t.Run("Testing RunDiff - should report the changes as a table", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputTable)
+		require.NoError(t, ex.RunDiff(context.Background(), oldCatalog, newCatalog))
+		assert.Equal(t, `PACKAGE                      CHANGE         DETAILS
+aws-load-balancer-operator   head changed   stable-v1: albo.v1.1.0 -> albo.v1.2.0
+aws-load-balancer-operator   bundle added   albo.v1.2.0
+`, out.String())
+	})
+
+	t.Run("Testing RunDiff - should report the changes as JSON", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputJSON)
+		require.NoError(t, ex.RunDiff(context.Background(), oldCatalog, newCatalog))
+		var diff operator.CatalogDiff
+		require.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+		assert.Equal(t, operator.DiffCatalogs(oldCatalog, loader[oldCatalog], newCatalog, loader[newCatalog]), diff)
+	})
+
+	t.Run("Testing RunDiff - should report identical catalogs", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputTable)
+		require.NoError(t, ex.RunDiff(context.Background(), oldCatalog, oldCatalog))
+		assert.Equal(t, "no changes between "+oldCatalog+" and "+oldCatalog+"\n", out.String())
+	})
+
+	t.Run("Testing RunInspect - should list the packages", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputTable)
+		require.NoError(t, ex.RunInspect(context.Background(), newCatalog))
+		assert.Equal(t, `PACKAGE                      DEFAULT CHANNEL   CHANNELS
+aws-load-balancer-operator   stable-v1         stable-v1
+`, out.String())
+	})
+
+	t.Run("Testing RunInspect - should report the channels of a package as YAML", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputYAML)
+		ex.Package = "aws-load-balancer-operator"
+		require.NoError(t, ex.RunInspect(context.Background(), newCatalog))
+		assert.Equal(t, `channels:
+- bundles:
+  - name: albo.v1.1.0
+    version: 1.1.0
+  - name: albo.v1.2.0
+    version: 1.2.0
+  head: albo.v1.2.0
+  name: stable-v1
+defaultChannel: stable-v1
+name: aws-load-balancer-operator
+`, out.String())
+	})
+
+	t.Run("Testing RunInspect - should print the snippet selecting a package", func(t *testing.T) {
+		ex, out := newCatalogSchema(catalogOutputTable)
+		ex.Package = "aws-load-balancer-operator"
+		ex.Channels = []string{"stable-v1"}
+		ex.MinVersion = "1.2.0"
+		ex.Snippet = true
+		require.NoError(t, ex.RunInspect(context.Background(), newCatalog))
+		assert.Equal(t, `- catalog: `+newCatalog+`
+  packages:
+  - channels:
+    - minVersion: 1.2.0
+      name: stable-v1
+    name: aws-load-balancer-operator
+`, out.String())
+	})
+
+	t.Run("Testing RunInspect - should fail", func(t *testing.T) {
+		ex, _ := newCatalogSchema(catalogOutputTable)
+		ex.Snippet = true
+		assert.EqualError(t, ex.RunInspect(context.Background(), newCatalog), "--bundle and --snippet require --package")
+		ex, _ = newCatalogSchema(catalogOutputTable)
+		ex.Package = "aws-load-balancer-operator"
+		ex.Channels = []string{"stable-v1"}
+		assert.EqualError(t, ex.RunInspect(context.Background(), newCatalog), "--channel, --min-version and --max-version require --snippet")
+		ex, _ = newCatalogSchema(catalogOutputTable)
+		ex.Package = "aws-load-balancer-operator"
+		ex.Bundle = "albo.v9.0.0"
+		assert.EqualError(t, ex.RunInspect(context.Background(), newCatalog), "bundle albo.v9.0.0 not found in package aws-load-balancer-operator")
+	})
+
+	t.Run("Testing RunDiff - should fail", func(t *testing.T) {
+		ex, _ := newCatalogSchema("csv")
+		assert.EqualError(t, ex.RunDiff(context.Background(), oldCatalog, newCatalog), "--output must be one of table, json or yaml")
+		ex, _ = newCatalogSchema(catalogOutputTable)
+		assert.EqualError(t, ex.RunDiff(context.Background(), oldCatalog, "registry.redhat.io/redhat/redhat-operator-index:v4.99"),
+			"unable to find catalog registry.redhat.io/redhat/redhat-operator-index:v4.99: manifest unknown")
+	})
+}
@@ -23,6 +23,8 @@ const (
 	dryRunOutDir                  string = "dry-run"
 	mappingFile                   string = "mapping.txt"
 	missingImgsFile               string = "missing.txt"
+	verifyOutDir                  string = "verify"
+	verifyReportFile              string = "verify-report.yaml"
 	clusterResourcesDir           string = "cluster-resources"
 	helmDir                       string = "helm"
 	helmChartDir                  string = "charts"
@@ -28,6 +28,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/samples"
 	"github.com/spf13/cobra"
 )
 
@@ -165,6 +166,7 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 					Operators:        converted.Delete.Operators,
 					AdditionalImages: converted.Delete.AdditionalImages,
 					Helm:             converted.Delete.Helm,
+					Samples:          converted.Delete.Samples,
 				},
 			},
 		}
@@ -232,13 +234,14 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 
 	client, _ := release.NewOCPClient(uuid.New(), o.Log)
 	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
-	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature, o.Manifest)
 	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
 	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
 	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
 
 	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
 	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+	o.SamplesCollector = samples.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
 	if o.V1Tags {
 		o.Operator = operator.WithV1Tags(o.Operator)
 		o.AdditionalImages = additional.WithV1Tags(o.AdditionalImages)
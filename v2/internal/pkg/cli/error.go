@@ -20,3 +20,24 @@ func (e *NormalStorageInterruptError) Is(err error) bool {
 	_, ok := err.(*NormalStorageInterruptError)
 	return ok
 }
+
+// DriftError is returned by the verify command when the destination registry
+// does not match the images expected from the imageset configuration
+type DriftError struct {
+	message string
+}
+
+func (e *DriftError) Error() string {
+	return e.message
+}
+
+func DriftErrorf(format string, a ...any) *DriftError {
+	return &DriftError{
+		message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *DriftError) Is(err error) bool {
+	_, ok := err.(*DriftError)
+	return ok
+}
@@ -46,7 +46,9 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/samples"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/spinners"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/state"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/version"
 	"github.com/spf13/cobra"
 )
@@ -115,12 +117,14 @@ type ExecutorSchema struct {
 	logFile                      *os.File
 	registryLogFile              *os.File
 	Config                       v2alpha1.ImageSetConfiguration
+	Lock                         *v2alpha1.ImageSetConfigurationLock
 	Opts                         *mirror.CopyOptions
 	WorkingDir                   string
 	Operator                     operator.CollectorInterface
 	Release                      release.CollectorInterface
 	AdditionalImages             additional.CollectorInterface
 	HelmCollector                helm.CollectorInterface
+	SamplesCollector             samples.CollectorInterface
 	Mirror                       mirror.MirrorInterface
 	Manifest                     manifest.ManifestInterface
 	Batch                        batch.BatchInterface
@@ -134,6 +138,7 @@ type ExecutorSchema struct {
 	MirrorUnArchiver             archive.UnArchiver
 	MakeDir                      MakeDirInterface
 	Delete                       delete.DeleteInterface
+	State                        state.Backend
 }
 
 type MakeDirInterface interface {
@@ -251,8 +256,13 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	}
 	cmd.AddCommand(version.NewVersionCommand(log))
 	cmd.AddCommand(NewDeleteCommand(log, opts))
+	cmd.AddCommand(NewLockCommand(log, opts))
+	cmd.AddCommand(NewVerifyCommand(log, opts))
+	cmd.AddCommand(NewMigrateStateCommand(log, opts))
+	cmd.AddCommand(NewISCCommand(log, opts))
+	cmd.AddCommand(NewCatalogCommand(log, opts))
 	// common flags
-	cmd.PersistentFlags().StringVarP(&opts.Global.ConfigPath, "config", "c", "", "Path to imageset configuration file")
+	cmd.PersistentFlags().StringVarP(&opts.Global.ConfigPath, "config", "c", "", "Path to imageset configuration file. A comma-separated list of files is merged in order, each file overlaying the previous ones")
 	cmd.MarkPersistentFlagFilename("config", "yaml")
 	cmd.PersistentFlags().StringVar(&opts.Global.CacheDir, "cache-dir", "", "oc-mirror cache directory location. Default is $HOME")
 	cmd.MarkPersistentFlagDirname("cache-dir")
@@ -270,6 +280,8 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.PersistentFlags().AddFlagSet(&flagDestOpts)
 	// copy-only options
 	cmd.Flags().StringVar(&opts.Global.From, "from", "", "Local storage directory for disk to mirror workflow")
+	cmd.Flags().StringVar(&opts.Global.LockFile, "lockfile", "", "Path to a lockfile generated by the lock command, mirrored instead of the imageset configuration. With --config, the configuration must be the one locked")
+	cmd.MarkFlagFilename("lockfile", "yaml")
 	cmd.Flags().BoolVarP(&opts.IsDryRun, "dry-run", "", false, "Print actions without mirroring images")
 	cmd.Flags().BoolVarP(&opts.Global.Quiet, "quiet", "q", false, "Enable detailed logging when copying images")
 	cmd.Flags().BoolVarP(&opts.Global.Force, "force", "f", false, "Force the copy and mirror functionality")
@@ -278,6 +290,16 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.Flags().BoolVar(&opts.Global.SecurePolicy, "secure-policy", false, "If set, will enable signature verification (secure policy for signature verification)")
 	cmd.Flags().IntVar(&opts.Global.MaxNestedPaths, "max-nested-paths", 0, "Number of nested paths, for destination registries that limit nested paths")
 	cmd.Flags().BoolVar(&opts.Global.StrictArchiving, "strict-archive", false, "If set, generates archives that are strictly less than archiveSize (set in the imageSetConfig). Mirroring will exit in error if a file being archived exceed archiveSize(GB)")
+	cmd.Flags().StringVar(&opts.Global.TrustBundlePath, "additional-trust-bundle", "", "Path to the PEM encoded CA bundle of the destination registry, added to the install-config snippet")
+	cmd.Flags().StringVar(&opts.Global.InstallRelease, "install-release", "", "Release installed by the install-config snippet, when several releases are mirrored: a version (4.16.3), an architecture (x86_64) or both (4.16.3-x86_64)")
+	cmd.Flags().StringVar(&opts.Global.GitOpsOutput, "gitops-output", "", "If set, generates in cluster-resources a GitOps output, one of (kustomize, acm, argocd)")
+	cmd.Flags().StringVar(&opts.Global.GitOpsNamespace, "gitops-namespace", "", "Namespace of the ACM Policy (default open-cluster-management-global-set) or of the Argo CD Application (default openshift-gitops)")
+	cmd.Flags().StringVar(&opts.Global.GitOpsClusterSet, "gitops-cluster-set", "global", "ACM cluster set bound to the namespace of the Policy, whose OpenShift clusters are selected by its Placement, used when --gitops-output is acm")
+	cmd.Flags().StringVar(&opts.Global.GitOpsRepoURL, "gitops-repo-url", "", "Git repository the cluster resources are pushed to, mandatory when --gitops-output is argocd")
+	cmd.Flags().StringVar(&opts.Global.GitOpsRepoPath, "gitops-repo-path", "cluster-resources", "Path of the cluster resources in the git repository, used when --gitops-output is argocd")
+	cmd.Flags().StringVar(&opts.Global.StateBackend, "state-backend", "", "Location where the state of the working-dir is kept between runs: file://<dir>, docker://<registry>/<repository>:<tag> or s3://<bucket>/<prefix>?region=<region>&endpoint=<url>, which must support conditional writes for the state to be locked")
+	cmd.Flags().BoolVar(&opts.Global.StateTLSVerify, "state-tls-verify", true, "Require HTTPS and verify certificates when accessing the registry or S3 state backend")
+	cmd.Flags().StringVar(&opts.Global.OpmBinary, "opm-binary", "", "Path to the opm binary matching the opm of the mirrored catalogs, building the serve cache of the filtered catalogs. Defaults to the opm of "+v2alpha1.DefaultCatalogBaseImage)
 	cmd.Flags().StringVar(&opts.RootlessStoragePath, "rootless-storage-path", "", "Override the default container rootless storage path (usually in etc/containers/storage.conf)")
 	HideFlags(cmd)
 
@@ -332,10 +354,12 @@ func (o ExecutorSchema) Validate(dest []string) error {
 		"logs",
 		"operator-catalogs",
 		"release-images",
+		"hold-samples",
+		"samples-images",
 		"signatures",
 	}
 
-	if len(o.Opts.Global.ConfigPath) == 0 {
+	if len(o.Opts.Global.ConfigPath) == 0 && len(o.Opts.Global.LockFile) == 0 {
 		return fmt.Errorf("use the --config flag it is mandatory")
 	}
 	if strings.Contains(dest[0], fileProtocol) && o.Opts.Global.From != "" {
@@ -365,6 +389,17 @@ func (o ExecutorSchema) Validate(dest []string) error {
 			return fmt.Errorf("--since flag needs to be in format yyyy-MM-dd")
 		}
 	}
+	if o.Opts.Global.TrustBundlePath != "" {
+		if _, err := os.Stat(o.Opts.Global.TrustBundlePath); err != nil {
+			return fmt.Errorf("--additional-trust-bundle: %w", err)
+		}
+	}
+	if o.Opts.Global.GitOpsOutput != "" && !slices.Contains(clusterresources.GitOpsFormats, o.Opts.Global.GitOpsOutput) {
+		return fmt.Errorf("--gitops-output must be one of %s", strings.Join(clusterresources.GitOpsFormats, ", "))
+	}
+	if o.Opts.Global.GitOpsOutput == clusterresources.GitOpsArgoCD && o.Opts.Global.GitOpsRepoURL == "" {
+		return fmt.Errorf("--gitops-repo-url is mandatory when --gitops-output is %s", clusterresources.GitOpsArgoCD)
+	}
 	if strings.Contains(dest[0], fileProtocol) && o.Opts.Global.WorkingDir != "" {
 		return fmt.Errorf("when destination is file://, mirrorToDisk workflow is assumed, and the --workspace argument is not needed")
 	}
@@ -388,9 +423,13 @@ func (o *ExecutorSchema) Complete(args []string) error {
 		o.Opts.Global.RegistriesConfPath = envOverride
 	}
 
-	o.Log.Debug("imagesetconfig file %s ", o.Opts.Global.ConfigPath)
-	// read the ImageSetConfiguration
-	cfg, err := config.ReadConfig(o.Opts.Global.ConfigPath, v2alpha1.ImageSetConfigurationKind)
+	configPath := o.Opts.Global.ConfigPath
+	if o.Opts.Global.LockFile != "" {
+		configPath = o.Opts.Global.LockFile
+	}
+	o.Log.Debug("imagesetconfig file %s ", configPath)
+	// read the ImageSetConfiguration, or the ImageSetConfigurationLock
+	cfg, err := config.ReadConfig(configPath, v2alpha1.ImageSetConfigurationKind)
 	if err != nil {
 		return err
 	}
@@ -401,7 +440,24 @@ func (o *ExecutorSchema) Complete(args []string) error {
 	md := mirror.NewMirrorDelete()
 	o.Manifest = manifest.New(o.Log)
 	o.Mirror = mirror.New(mc, md)
-	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+	switch cfg := cfg.(type) {
+	case v2alpha1.ImageSetConfigurationLock:
+		if o.Opts.Global.LockFile == "" {
+			return fmt.Errorf("%s is a lockfile: use the --lockfile flag", configPath)
+		}
+		o.Lock = &cfg
+		if err := o.checkConfigDigest(); err != nil {
+			return err
+		}
+		o.Config = cfg.GetImageSetConfiguration()
+	case v2alpha1.ImageSetConfiguration:
+		if o.Opts.Global.LockFile != "" {
+			return fmt.Errorf("%s is not a lockfile: use the lock command to generate it", configPath)
+		}
+		o.Config = cfg
+	default:
+		return fmt.Errorf("%s is not an imageset configuration", configPath)
+	}
 
 	// logic to check mode
 	var rootDir string
@@ -478,22 +534,23 @@ func (o *ExecutorSchema) Complete(args []string) error {
 	o.ImageBuilder = imagebuilder.NewBuilder(o.Log, *o.Opts)
 	o.CatalogBuilder = imagebuilder.NewGCRCatalogBuilder(o.Log, *o.Opts)
 	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
-	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature, o.Manifest)
 	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
 	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
 	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
 	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+	o.SamplesCollector = samples.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
 	o.ClusterResources = clusterresources.New(o.Log, o.Opts.Global.WorkingDir, o.Config, o.Opts.LocalStorageFQDN)
 	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
 
 	if o.Opts.IsMirrorToDisk() {
 		if o.Opts.Global.StrictArchiving {
-			o.MirrorArchiver, err = archive.NewMirrorArchive(o.Opts, rootDir, o.Opts.Global.ConfigPath, o.Opts.Global.WorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
+			o.MirrorArchiver, err = archive.NewMirrorArchive(o.Opts, rootDir, configPath, o.Opts.Global.WorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
 			if err != nil {
 				return err
 			}
 		} else {
-			o.MirrorArchiver, err = archive.NewPermissiveMirrorArchive(o.Opts, rootDir, o.Opts.Global.ConfigPath, o.Opts.Global.WorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
+			o.MirrorArchiver, err = archive.NewPermissiveMirrorArchive(o.Opts, rootDir, configPath, o.Opts.Global.WorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
 			if err != nil {
 				return err
 			}
@@ -511,6 +568,28 @@ func (o *ExecutorSchema) Complete(args []string) error {
 func (o *ExecutorSchema) Run(cmd *cobra.Command, args []string) error {
 	var err error
 
+	// the state of the working-dir is locked for the whole run
+	if o.Opts.Global.StateBackend != "" {
+		o.State, err = state.New(cmd.Context(), o.Log, o.Opts.Global.StateBackend, o.Opts.Global.StateTLSVerify)
+		if err != nil {
+			o.closeAll()
+			return err
+		}
+		if err := o.State.Lock(cmd.Context()); err != nil {
+			o.closeAll()
+			return err
+		}
+		defer func() {
+			if err := o.State.Unlock(context.Background()); err != nil {
+				o.Log.Warn("%v", err)
+			}
+		}()
+		if err := o.State.Pull(cmd.Context(), o.Opts.Global.WorkingDir); err != nil {
+			o.closeAll()
+			return err
+		}
+	}
+
 	switch {
 	case o.Opts.IsMirrorToDisk():
 		err = o.RunMirrorToDisk(cmd, args)
@@ -520,6 +599,10 @@ func (o *ExecutorSchema) Run(cmd *cobra.Command, args []string) error {
 		err = o.RunMirrorToMirror(cmd, args)
 	}
 
+	if err == nil && o.State != nil && !o.Opts.IsDryRun {
+		err = o.State.Push(cmd.Context(), o.Opts.Global.WorkingDir)
+	}
+
 	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
 
 	if err != nil {
@@ -877,13 +960,29 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			copiedSchema = cs
 		}
 
+		// publish the boot artifacts on the disconnected side
+		if err := o.Release.PublishBootImages(cmd.Context()); err != nil {
+			return err
+		}
+
 		//create IDMS/ITMS
 		forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
+		if err := o.ClusterResources.UpdateMirrorSetState(o.Opts.Destination, copiedSchema.AllImages); err != nil {
+			return err
+		}
 		err = o.ClusterResources.IDMS_ITMSGenerator(copiedSchema.AllImages, forceRepositoryScope)
 		if err != nil {
 			return err
 		}
 
+		// same mirrors, for hosts and for clusters older than 4.13
+		if err := o.ClusterResources.RegistriesConfGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+			return err
+		}
+		if err := o.ClusterResources.ICSPGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+			return err
+		}
+
 		err = o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages)
 		if err != nil {
 			return err
@@ -893,6 +992,12 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			return err
 		}
 
+		if len(o.Config.Mirror.Samples) > 0 {
+			if err := o.ClusterResources.ImageStreamGenerator(o.SamplesCollector.ImageStreams(), copiedSchema.AllImages); err != nil {
+				return err
+			}
+		}
+
 		// generate signature config map
 		err = o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages)
 		if err != nil {
@@ -915,6 +1020,16 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 				return err
 			}
 		}
+
+		// install-config snippet for disconnected installs
+		if err := o.installConfigGenerator(cmd.Context(), copiedSchema.AllImages); err != nil {
+			return err
+		}
+
+		// wrap the cluster resources for GitOps
+		if err := o.ClusterResources.GitOpsGenerator(o.gitOpsOptions()); err != nil {
+			return err
+		}
 	} else {
 		err = o.DryRun(cmd.Context(), collectorSchema.AllImages)
 		if err != nil {
@@ -978,13 +1093,29 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			copiedSchema = cs
 		}
 
+		// publish the boot artifacts on the disconnected side
+		if err := o.Release.PublishBootImages(cmd.Context()); err != nil {
+			return err
+		}
+
 		// create IDMS/ITMS
 		forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
+		if err := o.ClusterResources.UpdateMirrorSetState(o.Opts.Destination, copiedSchema.AllImages); err != nil {
+			return err
+		}
 		err = o.ClusterResources.IDMS_ITMSGenerator(copiedSchema.AllImages, forceRepositoryScope)
 		if err != nil {
 			return err
 		}
 
+		// same mirrors, for hosts and for clusters older than 4.13
+		if err := o.ClusterResources.RegistriesConfGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+			return err
+		}
+		if err := o.ClusterResources.ICSPGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+			return err
+		}
+
 		// create catalog source
 		err = o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages)
 		if err != nil {
@@ -995,6 +1126,12 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			return err
 		}
 
+		if len(o.Config.Mirror.Samples) > 0 {
+			if err := o.ClusterResources.ImageStreamGenerator(o.SamplesCollector.ImageStreams(), copiedSchema.AllImages); err != nil {
+				return err
+			}
+		}
+
 		// generate signature config map
 		err = o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages)
 		if err != nil {
@@ -1017,6 +1154,16 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 				return err
 			}
 		}
+
+		// install-config snippet for disconnected installs
+		if err := o.installConfigGenerator(cmd.Context(), copiedSchema.AllImages); err != nil {
+			return err
+		}
+
+		// wrap the cluster resources for GitOps
+		if err := o.ClusterResources.GitOpsGenerator(o.gitOpsOptions()); err != nil {
+			return err
+		}
 	} else {
 		err = o.DryRun(cmd.Context(), collectorSchema.AllImages)
 		if err != nil {
@@ -1037,6 +1184,47 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 	return nil
 }
 
+// installConfigGenerator - private utility to generate the install-config snippet
+// when releases are mirrored
+func (o *ExecutorSchema) installConfigGenerator(ctx context.Context, allRelatedImages []v2alpha1.CopyImageSchema) error {
+	if len(o.Config.Mirror.Platform.Channels) == 0 && o.Config.Mirror.Platform.Release == "" && len(o.Config.Mirror.Platform.Releases) == 0 {
+		return nil
+	}
+	installConfig := clusterresources.InstallConfigOptions{
+		AdditionalTrustBundle: o.Opts.Global.TrustBundlePath,
+		ForceRepositoryScope:  o.Opts.Global.MaxNestedPaths > 0,
+	}
+	releaseImage, err := o.Release.InstallReleaseImage(ctx, o.Opts.Global.InstallRelease)
+	switch {
+	case errors.Is(err, release.ErrSeveralReleases):
+		// the release is optional in the snippet: the mirroring itself must not fail
+		o.Log.Warn("%v: the install-config snippet is generated without releaseImage", err)
+		return o.ClusterResources.InstallConfigGenerator(allRelatedImages, installConfig)
+	case err != nil:
+		return err
+	}
+	installConfig.ReleaseImage = releaseImage
+	if o.Config.Mirror.Platform.Graph {
+		graphImage, err := o.Release.GraphImage()
+		if err != nil {
+			return err
+		}
+		installConfig.GraphImage = graphImage
+	}
+	return o.ClusterResources.InstallConfigGenerator(allRelatedImages, installConfig)
+}
+
+// gitOpsOptions - private utility to gather the GitOps output flags
+func (o *ExecutorSchema) gitOpsOptions() clusterresources.GitOpsOptions {
+	return clusterresources.GitOpsOptions{
+		Format:     o.Opts.Global.GitOpsOutput,
+		Namespace:  o.Opts.Global.GitOpsNamespace,
+		ClusterSet: o.Opts.Global.GitOpsClusterSet,
+		RepoURL:    o.Opts.Global.GitOpsRepoURL,
+		RepoPath:   o.Opts.Global.GitOpsRepoPath,
+	}
+}
+
 // setupLogsLevelAndDir - private utility to setup log
 // level and relevant directory
 func (o *ExecutorSchema) setupLogsLevelAndDir() error {
@@ -1087,6 +1275,21 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 	o.Log.Debug(collecAllPrefix+"total release images to %s %d ", o.Opts.Function, collectorSchema.TotalReleaseImages)
 	allRelatedImages = append(allRelatedImages, releaseImgs...)
 
+	if len(o.Config.Mirror.Samples) > 0 {
+		o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting sample images...")
+		// collect the sample images shipped in the collected releases
+		sImgs, err := o.SamplesCollector.SampleImagesCollector(ctx, releaseImgs)
+		if err != nil {
+			o.closeAll()
+			return v2alpha1.CollectorSchema{}, err
+		}
+		// exclude blocked images
+		sImgs = excludeImages(sImgs, o.Config.Mirror.BlockedImages)
+		collectorSchema.TotalSampleImages = len(sImgs)
+		o.Log.Debug(collecAllPrefix+"total sample images to %s %d ", o.Opts.Function, collectorSchema.TotalSampleImages)
+		allRelatedImages = append(allRelatedImages, sImgs...)
+	}
+
 	o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting operator images...")
 	// collect operators
 	operatorImgs, err := o.Operator.OperatorImageCollector(ctx)
@@ -1128,6 +1331,19 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 	o.Log.Debug(collecAllPrefix+"total helm images to %s %d ", o.Opts.Function, collectorSchema.TotalHelmImages)
 	allRelatedImages = append(allRelatedImages, hImgs...)
 
+	// the images collected from a lockfile are mirrored by their locked digest,
+	// and the catalogs must select the locked bundles
+	if o.Lock != nil && !o.Opts.IsDiskToMirror() {
+		allRelatedImages, err = o.applyLock(allRelatedImages)
+		if err == nil {
+			err = o.checkLockedCatalogs(ctx, collectorSchema.CatalogToFBCMap)
+		}
+		if err != nil {
+			o.closeAll()
+			return v2alpha1.CollectorSchema{}, err
+		}
+	}
+
 	// OCPBUGS-43731 - remove duplicates
 	allRelatedImages = slices.CompactFunc(allRelatedImages, func(a, b v2alpha1.CopyImageSchema) bool {
 		if o.Opts.Function == string(mirror.DeleteMode) {
@@ -1162,8 +1378,9 @@ func (o *ExecutorSchema) RebuildCatalogs(ctx context.Context, operatorImgs v2alp
 		for _, copyImage := range oImgs {
 
 			if copyImage.Type == v2alpha1.TypeOperatorCatalog {
-				if o.Opts.IsMirrorToMirror() && strings.Contains(copyImage.Source, o.Opts.LocalStorageFQDN) {
+				if o.Opts.IsMirrorToMirror() && strings.Contains(copyImage.Source, o.Opts.LocalStorageFQDN) && !strings.Contains(copyImage.Destination, o.Opts.LocalStorageFQDN) {
 					// CLID-275: this is the ref to the already rebuilt catalog, which needs to be mirrored to destination.
+					// Catalogs built from a file-based catalog directory are copied from the cache to the cache, and still need to be built.
 					continue
 				}
 				p := mpb.New()
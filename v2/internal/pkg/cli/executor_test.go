@@ -15,11 +15,14 @@ import (
 
 	"github.com/distribution/distribution/v3/configuration"
 	"github.com/distribution/distribution/v3/registry"
+	imagev1 "github.com/openshift/api/image/v1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -368,6 +371,44 @@ func TestRunMirrorToMirror(t *testing.T) {
 		}
 	})
 
+	t.Run("Testing Executor : mirrorToMirror several releases without --install-release should pass", func(t *testing.T) {
+		releasesCfg := cfg
+		releasesCfg.Mirror.Platform.Channels = []v2alpha1.ReleaseChannel{{Name: "stable-4.16", MinVersion: "4.16.1", MaxVersion: "4.16.3"}}
+		collector := &Collector{Log: log, Config: releasesCfg, Opts: *opts, Releases: []string{"4.16.1-x86_64", "4.16.3-x86_64"}}
+		batch := &Batch{Log: log, Config: releasesCfg, Opts: *opts}
+		installConfig := &clusterresources.InstallConfigOptions{ReleaseImage: "unset"}
+		cr := MockClusterResources{InstallConfig: installConfig}
+
+		ex := &ExecutorSchema{
+			Log:                 log,
+			Config:              releasesCfg,
+			Opts:                opts,
+			Operator:            collector,
+			Release:             collector,
+			AdditionalImages:    collector,
+			HelmCollector:       collector,
+			Mirror:              Mirror{},
+			Batch:               batch,
+			MakeDir:             MakeDir{},
+			LogsDir:             "/tmp/",
+			ClusterResources:    cr,
+			LocalStorageService: *reg,
+		}
+
+		res := &cobra.Command{}
+		res.SetContext(context.Background())
+		res.SilenceUsage = true
+		err := ex.Run(res, []string{"docker://test"})
+		assert.NoError(t, err)
+		// the install-config snippet is generated without the release
+		assert.Equal(t, "", installConfig.ReleaseImage)
+
+		opts.Global.InstallRelease = "x86_64"
+		defer func() { opts.Global.InstallRelease = "" }()
+		err = ex.Run(res, []string{"docker://test"})
+		assert.ErrorContains(t, err, "several mirrored releases match x86_64")
+	})
+
 	t.Run("Testing Executor : mirrorToMirror --dry-run should pass", func(t *testing.T) {
 		opts.IsDryRun = true
 		collector := &Collector{Log: log, Config: cfg, Opts: *opts, Fail: false}
@@ -573,6 +614,23 @@ func TestExecutorValidate(t *testing.T) {
 		opts.Global.WorkingDir = "" //reset
 		assert.Equal(t, "when destination is docker://, either --from (assumes disk to mirror workflow) or --workspace (assumes mirror to mirror workflow) need to be provided", ex.Validate([]string{"docker://test"}).Error())
 
+		// should not be able to generate an unknown GitOps output
+		opts.Global.WorkingDir = "file://test"
+		opts.Global.GitOpsOutput = "flux"
+		assert.Equal(t, "--gitops-output must be one of kustomize, acm, argocd", ex.Validate([]string{"docker://test"}).Error())
+
+		// should not be able to generate an Argo CD Application without git repository
+		opts.Global.GitOpsOutput = "argocd"
+		assert.Equal(t, "--gitops-repo-url is mandatory when --gitops-output is argocd", ex.Validate([]string{"docker://test"}).Error())
+		opts.Global.GitOpsRepoURL = "https://git.example.com/fleet/mirror.git"
+		assert.NoError(t, ex.Validate([]string{"docker://test"}))
+		opts.Global.GitOpsOutput = ""  //reset
+		opts.Global.GitOpsRepoURL = "" //reset
+
+		// should not be able to use a missing trust bundle
+		opts.Global.TrustBundlePath = "/no/such/ca.crt"
+		assert.Equal(t, "--additional-trust-bundle: stat /no/such/ca.crt: no such file or directory", ex.Validate([]string{"docker://test"}).Error())
+		opts.Global.TrustBundlePath = "" //reset
 	})
 }
 
@@ -914,11 +972,12 @@ type Mirror struct {
 // for this test scenario we only need to mock
 // ReleaseImageCollector, OperatorImageCollector and Batchr
 type Collector struct {
-	Log    clog.PluggableLoggerInterface
-	Config v2alpha1.ImageSetConfiguration
-	Opts   mirror.CopyOptions
-	Fail   bool
-	Name   string
+	Log      clog.PluggableLoggerInterface
+	Config   v2alpha1.ImageSetConfiguration
+	Opts     mirror.CopyOptions
+	Fail     bool
+	Name     string
+	Releases []string
 }
 
 type Batch struct {
@@ -945,6 +1004,7 @@ type MockMirrorUnArchiver struct {
 }
 
 type MockClusterResources struct {
+	InstallConfig *clusterresources.InstallConfigOptions
 }
 
 type MockMakeDir struct {
@@ -981,6 +1041,9 @@ func (o MockMirrorUnArchiver) Unarchive() error {
 	return nil
 }
 
+func (o MockClusterResources) UpdateMirrorSetState(destination string, allRelatedImages []v2alpha1.CopyImageSchema) error {
+	return nil
+}
 func (o MockClusterResources) IDMS_ITMSGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
 	return nil
 }
@@ -998,6 +1061,29 @@ func (o MockClusterResources) ClusterCatalogGenerator(allRelatedImages []v2alpha
 	return nil
 }
 
+func (o MockClusterResources) ImageStreamGenerator(imageStreams []imagev1.ImageStream, allRelatedImages []v2alpha1.CopyImageSchema) error {
+	return nil
+}
+
+func (o MockClusterResources) RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
+	return nil
+}
+
+func (o MockClusterResources) ICSPGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
+	return nil
+}
+
+func (o MockClusterResources) InstallConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema, installConfig clusterresources.InstallConfigOptions) error {
+	if o.InstallConfig != nil {
+		*o.InstallConfig = installConfig
+	}
+	return nil
+}
+
+func (o MockClusterResources) GitOpsGenerator(gitOps clusterresources.GitOpsOptions) error {
+	return nil
+}
+
 func (o Batch) Worker(ctx context.Context, collectorSchema v2alpha1.CollectorSchema, opts mirror.CopyOptions) (v2alpha1.CollectorSchema, error) {
 	copiedImages := v2alpha1.CollectorSchema{
 		AllImages:             []v2alpha1.CopyImageSchema{},
@@ -1049,6 +1135,20 @@ func (o *Collector) ReleaseImage(ctx context.Context) (string, error) {
 	return "quay.io/openshift-release-dev/ocp-release:4.13.10-x86_64", nil
 }
 
+func (o *Collector) InstallReleaseImage(ctx context.Context, selector string) (string, error) {
+	switch {
+	case len(o.Releases) > 1 && selector == "":
+		return "", release.ErrSeveralReleases
+	case len(o.Releases) > 1:
+		return "", fmt.Errorf("several mirrored releases match %s", selector)
+	}
+	return "quay.io/openshift-release-dev/ocp-release:4.13.10-x86_64", nil
+}
+
+func (o *Collector) PublishBootImages(ctx context.Context) error {
+	return nil
+}
+
 func (o *Collector) AdditionalImagesCollector(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
 	if o.Fail {
 		return []v2alpha1.CopyImageSchema{}, fmt.Errorf("forced error additionalImages collector")
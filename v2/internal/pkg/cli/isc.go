@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/isc"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/migrate"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+)
+
+const iscTmpPrefix string = "oc-mirror-isc-"
+
+var (
+	iscLongDesc = templates.LongDesc(
+		`
+		Validate, lint, explain, convert and diff image set configurations, without mirroring.
+		`,
+	)
+	iscExamples = templates.Examples(
+		`
+# Report all the errors of an image set configuration, with their line numbers
+oc-mirror isc validate -c ./isc.yaml --v2
+
+# Check as well the packages, channels and versions against the catalogs and the update graph
+oc-mirror isc validate -c ./isc.yaml --content --v2
+
+# Report the content that an image set configuration mirrors unexpectedly
+oc-mirror isc lint -c ./isc.yaml --v2
+
+# Document the fields of the packages of the operators
+oc-mirror isc explain mirror.operators.packages --v2
+
+# Translate a v1alpha2 image set configuration to v2alpha1
+oc-mirror isc convert -c ./isc-v1.yaml --output ./isc.yaml --v2
+
+# Report the images that a new image set configuration adds and removes
+oc-mirror isc diff ./isc.yaml ./isc-new.yaml --v2
+
+# Write the JSON Schema of the image set configurations, for editor integration
+oc-mirror isc convert --json-schema --output ./isc.schema.json --v2
+		`,
+	)
+)
+
+type ISCSchema struct {
+	Log  clog.PluggableLoggerInterface
+	Opts *mirror.CopyOptions
+	// Out receives the reports and the generated documents without --output
+	Out io.Writer
+	// ContentLoader loads the catalogs and the update graph with --content
+	ContentLoader isc.ContentLoader
+	Content       bool
+	Kind          string
+	Recursive     bool
+	JSONSchema    bool
+	Output        string
+}
+
+// NewISCCommand - setup the 'isc' sub command and its
+// validate, lint, explain, convert and diff sub commands
+func NewISCCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &ISCSchema{
+		Log:  log,
+		Opts: opts,
+		Out:  os.Stdout,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "isc",
+		Short:   "Validates, lints, explains, converts and diffs image set configurations",
+		Long:    iscLongDesc,
+		Example: iscExamples,
+		Args:    cobra.NoArgs,
+	}
+	run := func(f func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+		return func(cmd *cobra.Command, args []string) {
+			if err := f(cmd, args); err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Reports all the errors of an image set configuration",
+		Args:  cobra.NoArgs,
+		Run:   run(func(cmd *cobra.Command, _ []string) error { return ex.RunValidate(cmd.Context()) }),
+	}
+	validate.Flags().BoolVar(&ex.Content, "content", false, "Check the packages, channels and versions against the catalogs and the update graph. The catalogs are cached in --workspace when set")
+	lint := &cobra.Command{
+		Use:   "lint",
+		Short: "Reports the content that an image set configuration mirrors unexpectedly",
+		Args:  cobra.NoArgs,
+		Run:   run(func(*cobra.Command, []string) error { return ex.RunLint() }),
+	}
+	explain := &cobra.Command{
+		Use:   "explain [field path]",
+		Short: "Documents the fields of the image set configurations",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   run(func(_ *cobra.Command, args []string) error { return ex.RunExplain(args) }),
+	}
+	explain.Flags().StringVar(&ex.Kind, "kind", v2alpha1.ImageSetConfigurationKind, "Kind of the configuration: ImageSetConfiguration or DeleteImageSetConfiguration")
+	explain.Flags().BoolVar(&ex.Recursive, "recursive", false, "List the fields of the fields")
+	convert := &cobra.Command{
+		Use:   "convert",
+		Short: "Translates a v1alpha2 image set configuration to v2alpha1, or writes the JSON Schema of the configurations",
+		Args:  cobra.NoArgs,
+		Run:   run(func(*cobra.Command, []string) error { return ex.RunConvert() }),
+	}
+	convert.Flags().StringVar(&ex.Kind, "kind", v2alpha1.ImageSetConfigurationKind, "Kind of the configuration of the JSON Schema: ImageSetConfiguration or DeleteImageSetConfiguration")
+	convert.Flags().BoolVar(&ex.JSONSchema, "json-schema", false, "Write the JSON Schema of the configuration kind instead of converting a configuration")
+	convert.Flags().StringVar(&ex.Output, "output", "", "Path of the generated document. Defaults to the standard output")
+
+	cmd.AddCommand(validate, lint, explain, convert, NewISCDiffCommand(log, opts))
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// RunValidate - report all the errors of the configuration, and fail when there are some.
+// With --content, the configuration is checked against the catalogs and the update graph as well.
+func (o ISCSchema) RunValidate(ctx context.Context) error {
+	validate := isc.Validate
+	if o.Content {
+		loader, cleanup, err := o.contentLoader()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		validate = func(data []byte) []isc.Issue { return isc.CheckContent(ctx, data, loader) }
+	}
+	issues, err := o.check(validate)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(o.Out, "%s is valid\n", o.Opts.Global.ConfigPath)
+	}
+	return nil
+}
+
+// RunLint - report the content that the configuration mirrors unexpectedly,
+// and fail when some of it fails the mirroring
+func (o ISCSchema) RunLint() error {
+	_, err := o.check(isc.Lint)
+	return err
+}
+
+func (o ISCSchema) check(f func([]byte) []isc.Issue) ([]isc.Issue, error) {
+	if len(o.Opts.Global.ConfigPath) == 0 {
+		return nil, fmt.Errorf("use the --config flag it is mandatory")
+	}
+	// the files, includes and environment variables are composed as by the mirroring
+	data, composed, err := config.Compose(o.Opts.Global.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	issues := f(data)
+	for _, issue := range issues {
+		if composed {
+			// the positions in the composed document are not the ones in the files
+			issue.Line, issue.Column = 0, 0
+		}
+		fmt.Fprintf(o.Out, "%s:%s\n", o.Opts.Global.ConfigPath, issue)
+	}
+	if isc.HasErrors(issues) {
+		return issues, fmt.Errorf("%s is not valid", o.Opts.Global.ConfigPath)
+	}
+	return issues, nil
+}
+
+// RunExplain - document a field of the configuration
+func (o ISCSchema) RunExplain(args []string) error {
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+	return isc.Explain(o.Out, o.Kind, path, o.Recursive)
+}
+
+// RunConvert - translate a v1alpha2 configuration to v2alpha1,
+// or generate the JSON Schema of a configuration kind
+func (o ISCSchema) RunConvert() error {
+	var data []byte
+	if o.JSONSchema {
+		schema, err := isc.JSONSchema(o.Kind)
+		if err != nil {
+			return err
+		}
+		data = append(schema, '\n')
+	} else {
+		if len(o.Opts.Global.ConfigPath) == 0 {
+			return fmt.Errorf("use the --config flag it is mandatory")
+		}
+		v1Config, err := migrate.ReadConfig(o.Opts.Global.ConfigPath)
+		if err != nil {
+			return err
+		}
+		converted, unsupported, err := migrate.ConvertConfig(v1Config)
+		if err != nil {
+			return err
+		}
+		for _, u := range unsupported {
+			o.Log.Warn("unsupported v1 field %s", u)
+		}
+		if data, err = yaml.Marshal(converted); err != nil {
+			return err
+		}
+	}
+
+	if o.Output == "" {
+		_, err := o.Out.Write(data)
+		return err
+	}
+	return os.WriteFile(o.Output, data, 0644)
+}
+
+// contentLoader returns the loader of the catalogs and of the update graph, working in the
+// workspace when set, otherwise in a temporary one removed by the returned cleanup
+func (o ISCSchema) contentLoader() (isc.ContentLoader, func(), error) {
+	if o.ContentLoader != nil {
+		return o.ContentLoader, func() {}, nil
+	}
+	opts, cleanup, err := contentOpts(o.Opts, iscTmpPrefix)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	mfst := manifest.New(o.Log)
+	return contentLoader{
+		CatalogLoader:    operator.NewCatalogLoader(o.Log, opts, mirror.New(mirror.NewMirrorCopy(), mirror.NewMirrorDelete()), mfst),
+		CincinnatiSchema: &release.CincinnatiSchema{Log: o.Log, Opts: opts, Manifest: mfst},
+	}, cleanup, nil
+}
+
+// contentOpts returns the options of the commands loading the catalogs or the update graph
+// without mirroring, working in the workspace when set, otherwise in a temporary one
+// removed by the returned cleanup
+func contentOpts(copyOpts *mirror.CopyOptions, tmpPrefix string) (mirror.CopyOptions, func(), error) {
+	cleanup := func() {}
+	opts := *copyOpts
+	global := *opts.Global
+	opts.Global = &global
+	if opts.Global.WorkingDir != "" {
+		if !strings.HasPrefix(opts.Global.WorkingDir, fileProtocol) {
+			return opts, cleanup, fmt.Errorf("--workspace flag must have a file:// protocol prefix")
+		}
+		opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(opts.Global.WorkingDir, fileProtocol), workingDir)
+	} else {
+		tmpDir, err := os.MkdirTemp("", tmpPrefix)
+		if err != nil {
+			return opts, cleanup, err
+		}
+		cleanup = func() { os.RemoveAll(tmpDir) }
+		opts.Global.WorkingDir = filepath.Join(tmpDir, workingDir)
+	}
+	opts.Mode = mirror.MirrorToDisk
+	opts.RemoveSignatures = true
+	return opts, cleanup, nil
+}
+
+// contentLoader loads the catalogs as the operator collector, and the update graph as the release collector
+type contentLoader struct {
+	*operator.CatalogLoader
+	*release.CincinnatiSchema
+}
@@ -0,0 +1,427 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	imgmanifest "github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const iscDiffTmpPrefix string = "oc-mirror-isc-diff-"
+
+var (
+	iscDiffLongDesc = templates.LongDesc(
+		`
+		Report the images added and removed between two image set configurations.
+
+		The images of both configurations are collected as a mirrorToDisk dry-run, sharing the cache and the filtered
+		catalogs of the workspace when set, otherwise of a temporary one. The releases, catalogs, bundles, related images,
+		helm images, additional images and sample images that the new configuration adds or removes are reported, with the
+		operators and bundles referencing them.
+
+		The size deltas are estimated from the manifests of the added and removed images, counting each blob once.
+		The blobs shared with the images present in both configurations are counted as well.
+		`,
+	)
+	iscDiffExamples = templates.Examples(
+		`
+# Report the images that the new image set configuration adds and removes
+oc-mirror isc diff ./isc.yaml ./isc-new.yaml --v2
+
+# Share the cache and the filtered catalogs with the mirrorToDisk workflow of /home/<user>/oc-mirror/mirror1
+oc-mirror isc diff ./isc.yaml ./isc-new.yaml --workspace file:///home/<user>/oc-mirror/mirror1 --v2
+		`,
+	)
+)
+
+// iscDiffSections are the sections of the diff report, by image type
+var iscDiffSections = []struct {
+	title string
+	types []v2alpha1.ImageType
+}{
+	{"releases", []v2alpha1.ImageType{v2alpha1.TypeOCPRelease}},
+	{"release content", []v2alpha1.ImageType{v2alpha1.TypeOCPReleaseContent, v2alpha1.TypeKubeVirtContainer, v2alpha1.TypeCincinnatiGraph}},
+	{"catalogs", []v2alpha1.ImageType{v2alpha1.TypeOperatorCatalog}},
+	{"bundles", []v2alpha1.ImageType{v2alpha1.TypeOperatorBundle}},
+	{"related images", []v2alpha1.ImageType{v2alpha1.TypeOperatorRelatedImage}},
+	{"helm images", []v2alpha1.ImageType{v2alpha1.TypeHelmImage}},
+	{"additional images", []v2alpha1.ImageType{v2alpha1.TypeGeneric}},
+	{"sample images", []v2alpha1.ImageType{v2alpha1.TypeSampleImage}},
+}
+
+type ISCDiffSchema struct {
+	ExecutorSchema
+	// Out receives the diff report
+	Out io.Writer
+	// EstimateSizes enables the estimation of the size deltas
+	EstimateSizes bool
+	tmpDir        string
+	// blobSizes returns the sizes of the manifests and blobs of an image, by digest
+	blobSizes func(ctx context.Context, imgRef string) (map[string]int64, error)
+}
+
+// iscDiff is the delta between the images of two image set configurations
+type iscDiff struct {
+	Added   []iscDiffImage
+	Removed []iscDiffImage
+	// AddedSize and RemovedSize are the estimated sizes of the added and removed images
+	AddedSize   int64
+	RemovedSize int64
+	// SizeErrors are the images whose size could not be estimated
+	SizeErrors int
+}
+
+// iscDiffImage is an image added or removed by the new image set configuration
+type iscDiffImage struct {
+	Origin string
+	Source string
+	Type   v2alpha1.ImageType
+	// Operators and Bundles are the operators and the bundles referencing the image
+	Operators []string
+	Bundles   []string
+}
+
+// NewISCDiffCommand - setup all the relevant support structs
+// to eventually execute the 'isc diff' sub command
+func NewISCDiffCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	mkd := MakeDir{}
+	ex := &ISCDiffSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: mkd,
+		},
+		Out: os.Stdout,
+	}
+	ex.blobSizes = ex.imageBlobSizes
+
+	cmd := &cobra.Command{
+		Use:     "diff <old config> <new config>",
+		Short:   "Reports the images added and removed between two image set configurations",
+		Long:    iscDiffLongDesc,
+		Example: iscDiffExamples,
+		Args:    cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateDiff()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteDiff()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage()
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunDiff(cmd.Context(), args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&ex.EstimateSizes, "estimate-sizes", true, "Estimate the size deltas from the manifests of the added and removed images")
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// ValidateDiff - cobra validation
+func (o ISCDiffSchema) ValidateDiff() error {
+	if len(o.Opts.Global.WorkingDir) > 0 && !strings.HasPrefix(o.Opts.Global.WorkingDir, fileProtocol) {
+		return fmt.Errorf("--workspace flag must have a file:// protocol prefix")
+	}
+	return nil
+}
+
+// CompleteDiff - setup the workspace, the logs and the local storage
+// for the collection of the images in mirrorToDisk dry-run mode
+func (o *ISCDiffSchema) CompleteDiff() error {
+	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok {
+		o.Opts.Global.RegistriesConfPath = envOverride
+	}
+
+	mc := mirror.NewMirrorCopy()
+	md := mirror.NewMirrorDelete()
+	o.Manifest = manifest.New(o.Log)
+	o.Mirror = mirror.New(mc, md)
+
+	// both configurations are collected as a mirrorToDisk dry-run, in the workspace when set,
+	// otherwise in a temporary one, removed once the diff is reported
+	var err error
+	o.Opts.Mode = mirror.MirrorToDisk
+	o.Opts.IsDryRun = true
+	if o.Opts.Global.WorkingDir != "" {
+		o.Opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol), workingDir)
+	} else {
+		o.tmpDir, err = os.MkdirTemp("", iscDiffTmpPrefix)
+		if err != nil {
+			return err
+		}
+		o.Opts.Global.WorkingDir = filepath.Join(o.tmpDir, workingDir)
+	}
+	o.Opts.Destination = fileProtocol + filepath.Dir(o.Opts.Global.WorkingDir)
+	// nolint: errcheck
+	o.Opts.DestImage.TlsVerify = false
+
+	err = o.setupLogsLevelAndDir()
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s / isc diff", o.Opts.Mode)
+
+	o.Opts.MultiArch = "all"
+	o.Opts.RemoveSignatures = true
+
+	if o.isLocalStoragePortBound() {
+		return fmt.Errorf("%d is already bound and cannot be used", o.Opts.Global.Port)
+	}
+	o.Opts.LocalStorageFQDN = "localhost:" + strconv.Itoa(int(o.Opts.Global.Port))
+
+	err = o.setupWorkingDir()
+	if err != nil {
+		return err
+	}
+	return o.setupLocalStorageDir()
+}
+
+// RunDiff - collect the images of both configurations and report their delta
+func (o *ISCDiffSchema) RunDiff(ctx context.Context, args []string) error {
+	startTime := time.Now()
+	if o.tmpDir != "" {
+		defer os.RemoveAll(o.tmpDir)
+	}
+	o.Log.Debug(startMessage, o.Opts.Global.Port)
+	go startLocalRegistry(&o.LocalStorageService, o.localStorageInterruptChannel)
+	defer o.closeAll()
+
+	oldSchema, err := o.collect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	newSchema, err := o.collect(ctx, args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := diffCollections(oldSchema, newSchema)
+	if o.EstimateSizes {
+		o.Log.Info(emoji.LeftPointingMagnifyingGlass + " estimating the size deltas...")
+		o.estimateSizes(ctx, &diff)
+	}
+	if err := diff.write(o.Out, o.EstimateSizes); err != nil {
+		return err
+	}
+
+	o.Log.Info("isc diff time : %v", time.Since(startTime))
+	return nil
+}
+
+// collect collects the images of a configuration
+func (o *ISCDiffSchema) collect(ctx context.Context, configPath string) (v2alpha1.CollectorSchema, error) {
+	o.Log.Debug("imagesetconfig file %s ", configPath)
+	cfg, err := config.ReadConfig(configPath, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return v2alpha1.CollectorSchema{}, err
+	}
+	isc, ok := cfg.(v2alpha1.ImageSetConfiguration)
+	if !ok {
+		return v2alpha1.CollectorSchema{}, fmt.Errorf("%s is not an ImageSetConfiguration", configPath)
+	}
+	o.Config = isc
+	o.setupDryRunCollectors()
+	o.Log.Info(emoji.SleuthOrSpy+"  collecting the images of %s", configPath)
+	return o.CollectAll(ctx)
+}
+
+// diffCollections returns the images of the new collection missing in the old one,
+// and the images of the old collection missing in the new one, by origin
+func diffCollections(oldSchema, newSchema v2alpha1.CollectorSchema) iscDiff {
+	return iscDiff{
+		Added:   missingImages(newSchema, oldSchema),
+		Removed: missingImages(oldSchema, newSchema),
+	}
+}
+
+// missingImages returns the images of the collection from missing in the collection in
+func missingImages(from, in v2alpha1.CollectorSchema) []iscDiffImage {
+	origins := map[string]bool{}
+	for _, img := range in.AllImages {
+		origins[img.Origin] = true
+	}
+	var missing []iscDiffImage
+	for _, img := range from.AllImages {
+		if origins[img.Origin] {
+			continue
+		}
+		origins[img.Origin] = true
+		diffImg := iscDiffImage{Origin: img.Origin, Source: img.Source, Type: img.Type}
+		if img.Type.IsOperator() {
+			diffImg.Operators = slices.Sorted(maps.Keys(from.CopyImageSchemaMap.OperatorsByImage[img.Origin]))
+			diffImg.Bundles = slices.Sorted(maps.Values(from.CopyImageSchemaMap.BundlesByImage[img.Origin]))
+			diffImg.Bundles = slices.Compact(diffImg.Bundles)
+		}
+		missing = append(missing, diffImg)
+	}
+	slices.SortFunc(missing, func(a, b iscDiffImage) int { return strings.Compare(a.Origin, b.Origin) })
+	return missing
+}
+
+// estimateSizes sums the sizes of the blobs of the added images, and of the removed images,
+// each blob being counted once
+func (o *ISCDiffSchema) estimateSizes(ctx context.Context, diff *iscDiff) {
+	sum := func(images []iscDiffImage) int64 {
+		blobs := map[string]int64{}
+		for _, img := range images {
+			sizes, err := o.blobSizes(ctx, img.Source)
+			if err != nil {
+				o.Log.Warn("unable to estimate the size of %s: %v", img.Origin, err)
+				diff.SizeErrors++
+				continue
+			}
+			maps.Copy(blobs, sizes)
+		}
+		var total int64
+		for _, size := range blobs {
+			total += size
+		}
+		return total
+	}
+	diff.AddedSize = sum(diff.Added)
+	diff.RemovedSize = sum(diff.Removed)
+}
+
+// imageBlobSizes returns the sizes of the manifests and of the blobs of an image,
+// including the ones of all the architectures of a manifest list
+func (o *ISCDiffSchema) imageBlobSizes(ctx context.Context, imgRef string) (map[string]int64, error) {
+	srcRef, err := alltransports.ParseImageName(imgRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source name %s: %v", imgRef, err)
+	}
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	img, err := srcRef.NewImageSource(ctx, sourceCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer img.Close()
+
+	manifestBytes, mime, err := img.GetManifest(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := imgmanifest.Digest(manifestBytes)
+	if err != nil {
+		return nil, err
+	}
+	sizes := map[string]int64{digest.String(): int64(len(manifestBytes))}
+
+	manifests := [][]byte{manifestBytes}
+	mimes := []string{mime}
+	if imgmanifest.MIMETypeIsMultiImage(mime) {
+		manifestList, err := imgmanifest.ListFromBlob(manifestBytes, mime)
+		if err != nil {
+			return nil, err
+		}
+		manifests, mimes = nil, nil
+		for _, instance := range manifestList.Instances() {
+			singleArchManifest, singleArchMime, err := img.GetManifest(ctx, &instance)
+			if err != nil {
+				return nil, err
+			}
+			sizes[instance.String()] = int64(len(singleArchManifest))
+			manifests = append(manifests, singleArchManifest)
+			mimes = append(mimes, singleArchMime)
+		}
+	}
+	for i, manifestBytes := range manifests {
+		singleArchManifest, err := imgmanifest.FromBlob(manifestBytes, mimes[i])
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling manifest: %v", err)
+		}
+		for _, layer := range singleArchManifest.LayerInfos() {
+			sizes[layer.Digest.String()] = layer.Size
+		}
+		sizes[singleArchManifest.ConfigInfo().Digest.String()] = singleArchManifest.ConfigInfo().Size
+	}
+	return sizes, nil
+}
+
+// write writes the report of the diff, by section
+func (d iscDiff) write(w io.Writer, withSizes bool) error {
+	var b strings.Builder
+	for _, section := range iscDiffSections {
+		var lines []string
+		for _, change := range []struct {
+			sign   string
+			images []iscDiffImage
+		}{{"+", d.Added}, {"-", d.Removed}} {
+			for _, img := range change.images {
+				if !slices.Contains(section.types, img.Type) {
+					continue
+				}
+				line := fmt.Sprintf("  %s %s", change.sign, img.Origin)
+				var refs []string
+				if len(img.Bundles) > 0 {
+					refs = append(refs, "bundles: "+strings.Join(img.Bundles, ", "))
+				}
+				if len(img.Operators) > 0 {
+					refs = append(refs, "operators: "+strings.Join(img.Operators, ", "))
+				}
+				if len(refs) > 0 {
+					line += " (" + strings.Join(refs, "; ") + ")"
+				}
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "%s:\n%s\n", section.title, strings.Join(lines, "\n"))
+		}
+	}
+
+	fmt.Fprintf(&b, "%d images added, %d images removed", len(d.Added), len(d.Removed))
+	if withSizes {
+		fmt.Fprintf(&b, ", estimated size +%s -%s", units.BytesSize(float64(d.AddedSize)), units.BytesSize(float64(d.RemovedSize)))
+		if d.SizeErrors > 0 {
+			fmt.Fprintf(&b, " (%d images could not be estimated)", d.SizeErrors)
+		}
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
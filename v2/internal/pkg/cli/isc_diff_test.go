@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestISCDiff(t *testing.T) {
+	const (
+		release1 = "quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64"
+		release2 = "quay.io/openshift-release-dev/ocp-release:4.16.2-x86_64"
+		catalog  = "registry.redhat.io/redhat/redhat-operator-index:v4.16"
+		bundle1  = "registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		bundle2  = "registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		related  = "registry.redhat.io/albo/aws-load-balancer-rhel8-operator@sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		ubi      = "registry.redhat.io/ubi9/ubi:latest"
+	)
+	img := func(origin string, imgType v2alpha1.ImageType) v2alpha1.CopyImageSchema {
+		return v2alpha1.CopyImageSchema{Origin: origin, Source: "docker://" + origin, Type: imgType}
+	}
+	oldSchema := v2alpha1.CollectorSchema{
+		AllImages: []v2alpha1.CopyImageSchema{
+			img(release1, v2alpha1.TypeOCPRelease),
+			img(catalog, v2alpha1.TypeOperatorCatalog),
+			img(bundle1, v2alpha1.TypeOperatorBundle),
+			img(ubi, v2alpha1.TypeGeneric),
+		},
+		CopyImageSchemaMap: v2alpha1.CopyImageSchemaMap{
+			OperatorsByImage: map[string]map[string]struct{}{bundle1: {"aws-load-balancer-operator": {}}},
+			BundlesByImage:   map[string]map[string]string{bundle1: {bundle1: "aws-load-balancer-operator.v1.1.0"}},
+		},
+	}
+	newSchema := v2alpha1.CollectorSchema{
+		AllImages: []v2alpha1.CopyImageSchema{
+			img(release2, v2alpha1.TypeOCPRelease),
+			img(catalog, v2alpha1.TypeOperatorCatalog),
+			img(bundle2, v2alpha1.TypeOperatorBundle),
+			img(related, v2alpha1.TypeOperatorRelatedImage),
+			img(ubi, v2alpha1.TypeGeneric),
+		},
+		CopyImageSchemaMap: v2alpha1.CopyImageSchemaMap{
+			OperatorsByImage: map[string]map[string]struct{}{
+				bundle2: {"aws-load-balancer-operator": {}},
+				related: {"aws-load-balancer-operator": {}},
+			},
+			BundlesByImage: map[string]map[string]string{
+				bundle2: {bundle2: "aws-load-balancer-operator.v1.2.0"},
+				related: {bundle2: "aws-load-balancer-operator.v1.2.0"},
+			},
+		},
+	}
+
+	newISCDiffSchema := func(sizes map[string]map[string]int64) (*ISCDiffSchema, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		ex := &ISCDiffSchema{
+			ExecutorSchema: ExecutorSchema{
+				Log:  clog.New("trace"),
+				Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+			},
+			Out: out,
+			blobSizes: func(_ context.Context, imgRef string) (map[string]int64, error) {
+				blobs, ok := sizes[imgRef]
+				if !ok {
+					return nil, fmt.Errorf("manifest unknown")
+				}
+				return blobs, nil
+			},
+		}
+		return ex, out
+	}
+
+	t.Run("Testing diffCollections - should report the added and removed images by origin", func(t *testing.T) {
+		diff := diffCollections(oldSchema, newSchema)
+		assert.Equal(t, []iscDiffImage{
+			{Origin: release2, Source: "docker://" + release2, Type: v2alpha1.TypeOCPRelease},
+			{Origin: bundle2, Source: "docker://" + bundle2, Type: v2alpha1.TypeOperatorBundle, Operators: []string{"aws-load-balancer-operator"}, Bundles: []string{"aws-load-balancer-operator.v1.2.0"}},
+			{Origin: related, Source: "docker://" + related, Type: v2alpha1.TypeOperatorRelatedImage, Operators: []string{"aws-load-balancer-operator"}, Bundles: []string{"aws-load-balancer-operator.v1.2.0"}},
+		}, diff.Added)
+		assert.Equal(t, []iscDiffImage{
+			{Origin: release1, Source: "docker://" + release1, Type: v2alpha1.TypeOCPRelease},
+			{Origin: bundle1, Source: "docker://" + bundle1, Type: v2alpha1.TypeOperatorBundle, Operators: []string{"aws-load-balancer-operator"}, Bundles: []string{"aws-load-balancer-operator.v1.1.0"}},
+		}, diff.Removed)
+	})
+
+	t.Run("Testing estimateSizes - should count each blob once", func(t *testing.T) {
+		ex, out := newISCDiffSchema(map[string]map[string]int64{
+			"docker://" + release2: {"sha256:a": 1024 * 1024, "sha256:b": 1024},
+			"docker://" + bundle2:  {"sha256:b": 1024, "sha256:c": 1024},
+			"docker://" + release1: {"sha256:d": 2048},
+		})
+		diff := diffCollections(oldSchema, newSchema)
+		ex.estimateSizes(context.Background(), &diff)
+		assert.Equal(t, int64(1024*1024+2048), diff.AddedSize)
+		assert.Equal(t, int64(2048), diff.RemovedSize)
+		assert.Equal(t, 2, diff.SizeErrors)
+
+		assert.NoError(t, diff.write(ex.Out, true))
+		assert.Equal(t, `releases:
+  + `+release2+`
+  - `+release1+`
+bundles:
+  + `+bundle2+` (bundles: aws-load-balancer-operator.v1.2.0; operators: aws-load-balancer-operator)
+  - `+bundle1+` (bundles: aws-load-balancer-operator.v1.1.0; operators: aws-load-balancer-operator)
+related images:
+  + `+related+` (bundles: aws-load-balancer-operator.v1.2.0; operators: aws-load-balancer-operator)
+3 images added, 2 images removed, estimated size +1.002MiB -2KiB (2 images could not be estimated)
+`, out.String())
+	})
+
+	t.Run("Testing write - should report identical configurations", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, diffCollections(oldSchema, oldSchema).write(out, false))
+		assert.Equal(t, "0 images added, 0 images removed\n", out.String())
+	})
+
+	t.Run("Testing ValidateDiff - should require the file:// prefix of the workspace", func(t *testing.T) {
+		ex, _ := newISCDiffSchema(nil)
+		ex.Opts.Global.WorkingDir = "/tmp/workspace"
+		assert.EqualError(t, ex.ValidateDiff(), "--workspace flag must have a file:// protocol prefix")
+		ex.Opts.Global.WorkingDir = "file:///tmp/workspace"
+		assert.NoError(t, ex.ValidateDiff())
+	})
+}
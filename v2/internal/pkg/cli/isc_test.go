@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+type mockISCContentLoader struct{}
+
+func (o mockISCContentLoader) DeclarativeConfig(context.Context, v2alpha1.Operator) (*declcfg.DeclarativeConfig, error) {
+	return &declcfg.DeclarativeConfig{}, nil
+}
+
+func (o mockISCContentLoader) ChannelVersions(context.Context, v2alpha1.ReleaseChannel, string) ([]semver.Version, error) {
+	return nil, nil
+}
+
+func TestISC(t *testing.T) {
+	newISCSchema := func(t *testing.T, config string) (*ISCSchema, *bytes.Buffer) {
+		configPath := filepath.Join(t.TempDir(), "isc.yaml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+		out := &bytes.Buffer{}
+		return &ISCSchema{
+			Log:  clog.New("trace"),
+			Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{ConfigPath: configPath}},
+			Out:  out,
+			Kind: v2alpha1.ImageSetConfigurationKind,
+		}, out
+	}
+
+	t.Run("Testing RunValidate - should report the errors with the file and line", func(t *testing.T) {
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minversion: 4.16.1
+`)
+		err := ex.RunValidate(context.Background())
+		assert.EqualError(t, err, ex.Opts.Global.ConfigPath+" is not valid")
+		assert.Equal(t, ex.Opts.Global.ConfigPath+`:7:7: error: mirror.platform.channels[0]: unknown field "minversion" in ReleaseChannel, did you mean "minVersion"? field names are case-sensitive`+"\n", out.String())
+	})
+
+	t.Run("Testing RunValidate - should accept a valid configuration", func(t *testing.T) {
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest
+`)
+		assert.NoError(t, ex.RunValidate(context.Background()))
+		assert.Equal(t, ex.Opts.Global.ConfigPath+" is valid\n", out.String())
+	})
+
+	t.Run("Testing RunValidate - should check the content with --content", func(t *testing.T) {
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.1
+`)
+		ex.Content = true
+		ex.ContentLoader = mockISCContentLoader{}
+		err := ex.RunValidate(context.Background())
+		assert.EqualError(t, err, ex.Opts.Global.ConfigPath+" is not valid")
+		assert.Equal(t, ex.Opts.Global.ConfigPath+`:6:13: error: mirror.platform.channels[0].name: channel "stable-4.16" has no release for amd64`+"\n", out.String())
+	})
+
+	t.Run("Testing RunValidate - should compose the files and the environment variables", func(t *testing.T) {
+		t.Setenv("OCP_VERSION", "4.16")
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-${OCP_VERSION}
+      minVersion: ${OCP_VERSION}.1
+`)
+		other := filepath.Join(filepath.Dir(ex.Opts.Global.ConfigPath), "other.yaml")
+		assert.NoError(t, os.WriteFile(other, []byte(`kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:${OCP_VERSION}
+`), 0644))
+		ex.Opts.Global.ConfigPath += "," + other
+		assert.NoError(t, ex.RunValidate(context.Background()))
+		assert.Equal(t, ex.Opts.Global.ConfigPath+" is valid\n", out.String())
+	})
+
+	t.Run("Testing RunLint - should not fail on warnings", func(t *testing.T) {
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+`)
+		assert.NoError(t, ex.RunLint())
+		assert.Contains(t, out.String(), ex.Opts.Global.ConfigPath+":6:7: warning: mirror.platform.channels[0]: only the latest release")
+	})
+
+	t.Run("Testing RunConvert - should translate a v1alpha2 configuration", func(t *testing.T) {
+		ex, out := newISCSchema(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v1alpha2
+mirror:
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.14
+    packages:
+    - name: aws-load-balancer-operator
+`)
+		assert.NoError(t, ex.RunConvert())
+		assert.Contains(t, out.String(), "apiVersion: mirror.openshift.io/v2alpha1\n")
+		assert.Contains(t, out.String(), "- name: aws-load-balancer-operator\n")
+	})
+
+	t.Run("Testing RunConvert - should write the JSON Schema", func(t *testing.T) {
+		ex, _ := newISCSchema(t, "")
+		ex.Opts.Global.ConfigPath = ""
+		ex.JSONSchema = true
+		ex.Output = filepath.Join(t.TempDir(), "isc.schema.json")
+		assert.NoError(t, ex.RunConvert())
+		data, err := os.ReadFile(ex.Output)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"$schema": "http://json-schema.org/draft-07/schema#"`)
+	})
+}
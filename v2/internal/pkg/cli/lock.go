@@ -0,0 +1,570 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/uuid"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/additional"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/helm"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/imagebuilder"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/samples"
+)
+
+const (
+	lockFileSuffix string = ".lock.yaml"
+	lockTmpPrefix  string = "oc-mirror-lock-"
+)
+
+var (
+	lockLongDesc = templates.LongDesc(
+		`
+		Resolve every moving input of an image set configuration into a lockfile.
+
+		The release channels are resolved to the release payloads pinned by digest, the catalogs and additional images
+		are pinned by digest, and the helm charts by version. The lockfile also lists the bundles selected in each catalog
+		and all the images of the image set, pinned by digest.
+
+		Mirroring with --lockfile instead of --config copies exactly the locked images, without any Cincinnati or catalog
+		tag resolution, so that the mirrorToDisk workflow can be replayed identically. The mirroring fails when the
+		filtered catalogs do not select exactly the locked bundles, or when the configuration given with --config is not
+		the locked one.
+		`,
+	)
+	lockExamples = templates.Examples(
+		`
+# Lock an image set configuration to isc.lock.yaml
+oc-mirror lock -c ./isc.yaml --v2
+
+# Mirror To Disk the locked images
+oc-mirror --lockfile ./isc.lock.yaml file:///home/<user>/oc-mirror/mirror1 --v2
+		`,
+	)
+)
+
+type LockSchema struct {
+	ExecutorSchema
+	Output string
+	tmpDir string
+}
+
+// NewLockCommand - setup all the relevant support structs
+// to eventually execute the 'lock' sub command
+func NewLockCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	mkd := MakeDir{}
+	ex := &LockSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: mkd,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "lock",
+		Short:   "Resolves an image set configuration to exact digests in a lockfile",
+		Long:    lockLongDesc,
+		Example: lockExamples,
+		Args:    cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateLock()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteLock(cmd.Context())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage()
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunLock(cmd)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&ex.Output, "output", "", "Path of the lockfile. Defaults to <config name>"+lockFileSuffix+" next to the image set configuration")
+	cmd.MarkFlagFilename("output", "yaml")
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// ValidateLock - cobra validation
+func (o LockSchema) ValidateLock() error {
+	if len(o.Opts.Global.ConfigPath) == 0 {
+		return fmt.Errorf("use the --config flag it is mandatory")
+	}
+	if len(o.Opts.Global.WorkingDir) > 0 && !strings.HasPrefix(o.Opts.Global.WorkingDir, fileProtocol) {
+		return fmt.Errorf("--workspace flag must have a file:// protocol prefix")
+	}
+	return nil
+}
+
+// CompleteLock - read the image set configuration, pin its catalogs and additional images,
+// and setup the collectors in mirrorToDisk dry-run mode
+func (o *LockSchema) CompleteLock(ctx context.Context) error {
+	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok {
+		o.Opts.Global.RegistriesConfPath = envOverride
+	}
+
+	o.Log.Debug("imagesetconfig file %s ", o.Opts.Global.ConfigPath)
+	cfg, err := config.ReadConfig(o.Opts.Global.ConfigPath, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return err
+	}
+	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+	if o.Output == "" {
+		o.Output = strings.TrimSuffix(o.Opts.Global.ConfigPath, filepath.Ext(o.Opts.Global.ConfigPath)) + lockFileSuffix
+	}
+
+	mc := mirror.NewMirrorCopy()
+	md := mirror.NewMirrorDelete()
+	o.Manifest = manifest.New(o.Log)
+	o.Mirror = mirror.New(mc, md)
+
+	// the collectors run as a mirrorToDisk dry-run, in the workspace when set,
+	// otherwise in a temporary one, removed once the lockfile is written
+	o.Opts.Mode = mirror.MirrorToDisk
+	o.Opts.IsDryRun = true
+	if o.Opts.Global.WorkingDir != "" {
+		o.Opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol), workingDir)
+	} else {
+		o.tmpDir, err = os.MkdirTemp("", lockTmpPrefix)
+		if err != nil {
+			return err
+		}
+		o.Opts.Global.WorkingDir = filepath.Join(o.tmpDir, workingDir)
+	}
+	o.Opts.Destination = fileProtocol + filepath.Dir(o.Opts.Global.WorkingDir)
+	// nolint: errcheck
+	o.Opts.DestImage.TlsVerify = false
+
+	err = o.setupLogsLevelAndDir()
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s / lock", o.Opts.Mode)
+
+	o.Opts.MultiArch = "all"
+	o.Opts.RemoveSignatures = true
+
+	if o.isLocalStoragePortBound() {
+		return fmt.Errorf("%d is already bound and cannot be used", o.Opts.Global.Port)
+	}
+	o.Opts.LocalStorageFQDN = "localhost:" + strconv.Itoa(int(o.Opts.Global.Port))
+
+	err = o.setupWorkingDir()
+	if err != nil {
+		return err
+	}
+	err = o.setupLocalStorageDir()
+	if err != nil {
+		return err
+	}
+
+	// the catalogs and additional images are pinned before the collection,
+	// so that the collectors resolve the same content as a mirroring with the lockfile
+	o.Log.Info(emoji.Pushpin + " pinning catalogs and additional images...")
+	err = o.pinConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.setupDryRunCollectors()
+	return nil
+}
+
+// setupDryRunCollectors - setup the collectors of the configuration,
+// for the commands collecting the images without mirroring them
+func (o *ExecutorSchema) setupDryRunCollectors() {
+	client, _ := release.NewOCPClient(uuid.New(), o.Log)
+
+	o.ImageBuilder = imagebuilder.NewBuilder(o.Log, *o.Opts)
+	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature, o.Manifest)
+	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
+	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+	o.SamplesCollector = samples.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
+}
+
+// RunLock - collect the images of the pinned configuration and write the lockfile
+func (o *LockSchema) RunLock(cmd *cobra.Command) error {
+	startTime := time.Now()
+	if o.tmpDir != "" {
+		defer os.RemoveAll(o.tmpDir)
+	}
+	o.Log.Debug(startMessage, o.Opts.Global.Port)
+	go startLocalRegistry(&o.LocalStorageService, o.localStorageInterruptChannel)
+	defer o.closeAll()
+
+	collectorSchema, err := o.CollectAll(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	configDigest, err := config.Digest(o.Opts.Global.ConfigPath)
+	if err != nil {
+		return err
+	}
+	lock, err := o.lockConfig(cmd.Context(), collectorSchema)
+	if err != nil {
+		return err
+	}
+	lock.ConfigDigest = configDigest
+
+	lockData, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.Output, lockData, 0644); err != nil {
+		return err
+	}
+
+	o.Log.Info(emoji.PageFacingUp+" %d images locked in %s", len(lock.Images), o.Output)
+	o.Log.Info("lock time     : %v", time.Since(startTime))
+	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
+	return nil
+}
+
+// pinConfig pins by digest the catalogs and the additional images of the configuration.
+// The catalogs keep their tag as targetTag, and the additional images their tag
+// next to the digest, so that they are mirrored to the same destination as before.
+func (o *LockSchema) pinConfig(ctx context.Context) error {
+	pinCatalog := func(op *v2alpha1.Operator) error {
+		imgSpec, err := image.ParseRef(op.Catalog)
+		if err != nil {
+			return err
+		}
+		if imgSpec.Transport != dockerProtocol || imgSpec.IsImageByDigest() {
+			return nil
+		}
+		digest, err := o.digestOf(ctx, imgSpec)
+		if err != nil {
+			return fmt.Errorf("unable to pin catalog %s: %v", op.Catalog, err)
+		}
+		if op.TargetTag == "" {
+			op.TargetTag = imgSpec.Tag
+		}
+		op.Catalog = imgSpec.Name + "@sha256:" + digest
+		return nil
+	}
+	for i := range o.Config.Mirror.Operators {
+		if err := pinCatalog(&o.Config.Mirror.Operators[i]); err != nil {
+			return err
+		}
+	}
+	for i := range o.Config.Mirror.CompositeCatalogs {
+		for j := range o.Config.Mirror.CompositeCatalogs[i].Catalogs {
+			if err := pinCatalog(&o.Config.Mirror.CompositeCatalogs[i].Catalogs[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, img := range o.Config.Mirror.AdditionalImages {
+		imgSpec, err := image.ParseRef(img.Name)
+		if err != nil {
+			// skipped by the additional images collector
+			continue
+		}
+		if imgSpec.Transport != dockerProtocol || imgSpec.IsImageByDigest() {
+			continue
+		}
+		digest, err := o.digestOf(ctx, imgSpec)
+		if err != nil {
+			return fmt.Errorf("unable to pin additional image %s: %v", img.Name, err)
+		}
+		o.Config.Mirror.AdditionalImages[i].Name = imgSpec.Reference + "@sha256:" + digest
+	}
+	return nil
+}
+
+// lockConfig builds the lock of the collected images: the releases and the helm charts of the
+// configuration are pinned, and all the collected images are resolved to their digest
+func (o *LockSchema) lockConfig(ctx context.Context, collectorSchema v2alpha1.CollectorSchema) (v2alpha1.ImageSetConfigurationLock, error) {
+	lock := v2alpha1.ImageSetConfigurationLock{
+		TypeMeta:                  o.Config.TypeMeta,
+		ImageSetConfigurationSpec: o.Config.ImageSetConfigurationSpec,
+		Images:                    []v2alpha1.LockedImage{},
+	}
+	lock.TypeMeta.Kind = v2alpha1.ImageSetConfigurationLockKind
+	lock.Mirror.Platform = o.Config.Mirror.Platform.DeepCopy()
+
+	pinned := map[string]string{}
+	for _, img := range collectorSchema.AllImages {
+		origin, ok := o.lockableOrigin(img)
+		if !ok {
+			continue
+		}
+		if _, found := pinned[origin]; !found {
+			pinnedImage, err := o.pinnedImage(ctx, origin)
+			if err != nil {
+				return lock, fmt.Errorf("unable to lock image %s: %v", origin, err)
+			}
+			pinned[origin] = pinnedImage
+		}
+		lock.Images = append(lock.Images, v2alpha1.LockedImage{Origin: origin, Image: pinned[origin], Type: img.Type})
+	}
+
+	platform := &lock.Mirror.Platform
+	if platform.Release != "" {
+		o.Log.Warn("platform.release %s is mirrored from disk: the release channels are not locked", platform.Release)
+	} else {
+		releases := []v2alpha1.ReleasePayload{}
+		for _, img := range lock.Images {
+			if img.Type == v2alpha1.TypeOCPRelease && !slices.ContainsFunc(releases, func(r v2alpha1.ReleasePayload) bool { return r.Image == img.Image }) {
+				releases = append(releases, v2alpha1.ReleasePayload{Image: img.Image})
+			}
+		}
+		platform.Channels = nil
+		platform.Releases = releases
+		if len(releases) == 0 {
+			platform.Releases = nil
+		}
+	}
+
+	if err := o.lockHelmCharts(&lock.Mirror.Helm); err != nil {
+		return lock, err
+	}
+
+	catalogs, err := lockedCatalogs(ctx, collectorSchema.CatalogToFBCMap)
+	if err != nil {
+		return lock, err
+	}
+	lock.Catalogs = catalogs
+	return lock, nil
+}
+
+// lockedCatalogs returns the bundles selected by the filtering of the collected catalogs
+func lockedCatalogs(ctx context.Context, catalogToFBCMap map[string]v2alpha1.CatalogFilterResult) ([]v2alpha1.LockedCatalog, error) {
+	var catalogs []v2alpha1.LockedCatalog
+	for _, key := range slices.Sorted(maps.Keys(catalogToFBCMap)) {
+		result := catalogToFBCMap[key]
+		// full catalogs are not filtered: the catalog digest already locks all its bundles
+		if result.FilteredConfigPath == "" {
+			continue
+		}
+		dc, err := declcfg.LoadFS(ctx, os.DirFS(result.FilteredConfigPath))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the filtered catalog %s: %v", result.OperatorFilter.Catalog, err)
+		}
+		catalog := v2alpha1.LockedCatalog{Catalog: result.OperatorFilter.Catalog, Bundles: []v2alpha1.LockedBundle{}}
+		for _, bundle := range dc.Bundles {
+			catalog.Bundles = append(catalog.Bundles, v2alpha1.LockedBundle{Package: bundle.Package, Name: bundle.Name, Image: bundle.Image})
+		}
+		slices.SortFunc(catalog.Bundles, func(a, b v2alpha1.LockedBundle) int {
+			return strings.Compare(a.Package+"/"+a.Name, b.Package+"/"+b.Name)
+		})
+		catalogs = append(catalogs, catalog)
+	}
+	return catalogs, nil
+}
+
+// lockHelmCharts pins the version of the helm charts of the repositories to the version
+// downloaded by the helm collector, which is the latest one of the repository index
+func (o *LockSchema) lockHelmCharts(helmConfig *v2alpha1.Helm) error {
+	files, err := filepath.Glob(filepath.Join(o.Opts.Global.WorkingDir, helmDir, helmChartDir, "*.tgz"))
+	if err != nil {
+		return err
+	}
+	downloaded := map[string]*semver.Version{}
+	for _, file := range files {
+		chart, err := loader.Load(file)
+		if err != nil {
+			return fmt.Errorf("unable to read helm chart %s: %v", file, err)
+		}
+		version, err := semver.NewVersion(chart.Metadata.Version)
+		if err != nil {
+			continue
+		}
+		if latest, ok := downloaded[chart.Metadata.Name]; !ok || version.GreaterThan(latest) {
+			downloaded[chart.Metadata.Name] = version
+		}
+	}
+
+	repositories := slices.Clone(helmConfig.Repositories)
+	for i, repo := range repositories {
+		repositories[i].Charts = slices.Clone(repo.Charts)
+		if repo.Charts == nil {
+			o.Log.Warn("helm repository %s: no chart listed, the charts published after the lock will be mirrored as well", repo.Name)
+			continue
+		}
+		for j, chart := range repo.Charts {
+			if chart.Version != "" {
+				continue
+			}
+			version, ok := downloaded[chart.Name]
+			if !ok {
+				return fmt.Errorf("unable to lock the version of helm chart %s of repository %s", chart.Name, repo.Name)
+			}
+			repositories[i].Charts[j].Version = version.Original()
+		}
+	}
+	helmConfig.Repositories = repositories
+	return nil
+}
+
+// applyLock replaces the source of the images collected by tag by the image pinned in the lockfile.
+// Any collected image missing from the lockfile fails the mirroring, as the locked content drifted.
+func (o *ExecutorSchema) applyLock(images []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
+	pinned := map[string]string{}
+	for _, img := range o.Lock.Images {
+		pinned[img.Origin] = img.Image
+	}
+	for i, img := range images {
+		origin, ok := o.lockableOrigin(img)
+		if !ok {
+			continue
+		}
+		pinnedImage, found := pinned[origin]
+		if !found {
+			return nil, fmt.Errorf("image %s is not in the lockfile %s", origin, o.Opts.Global.LockFile)
+		}
+		if img.Source == dockerProtocol+origin && pinnedImage != origin {
+			o.Log.Debug(collecAllPrefix+"using locked image %s for %s", pinnedImage, origin)
+			images[i].Source = dockerProtocol + pinnedImage
+		}
+	}
+	return images, nil
+}
+
+// checkLockedCatalogs fails when the bundles selected by the filtering of the collected catalogs
+// are not exactly the bundles of the lockfile, as the locked content drifted
+func (o *ExecutorSchema) checkLockedCatalogs(ctx context.Context, catalogToFBCMap map[string]v2alpha1.CatalogFilterResult) error {
+	catalogs, err := lockedCatalogs(ctx, catalogToFBCMap)
+	if err != nil {
+		return err
+	}
+	locked := map[string][]v2alpha1.LockedBundle{}
+	for _, catalog := range o.Lock.Catalogs {
+		locked[catalog.Catalog] = catalog.Bundles
+	}
+	var errs []error
+	for _, catalog := range catalogs {
+		lockedBundles, found := locked[catalog.Catalog]
+		if !found {
+			errs = append(errs, fmt.Errorf("catalog %s is not in the lockfile %s", catalog.Catalog, o.Opts.Global.LockFile))
+			continue
+		}
+		delete(locked, catalog.Catalog)
+		for _, bundle := range catalog.Bundles {
+			if !slices.Contains(lockedBundles, bundle) {
+				errs = append(errs, fmt.Errorf("bundle %s of catalog %s is not in the lockfile %s", bundle.Name, catalog.Catalog, o.Opts.Global.LockFile))
+			}
+		}
+		for _, bundle := range lockedBundles {
+			if !slices.Contains(catalog.Bundles, bundle) {
+				errs = append(errs, fmt.Errorf("locked bundle %s of catalog %s is not selected anymore", bundle.Name, catalog.Catalog))
+			}
+		}
+	}
+	for _, catalog := range slices.Sorted(maps.Keys(locked)) {
+		errs = append(errs, fmt.Errorf("locked catalog %s was not collected", catalog))
+	}
+	return errors.Join(errs...)
+}
+
+// checkConfigDigest fails when the imageset configuration given along with the lockfile
+// is not the configuration locked, as the lockfile is then outdated
+func (o *ExecutorSchema) checkConfigDigest() error {
+	if o.Opts.Global.ConfigPath == "" {
+		return nil
+	}
+	configDigest, err := config.Digest(o.Opts.Global.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if configDigest != o.Lock.ConfigDigest {
+		return fmt.Errorf("%s changed since the lockfile %s was generated: run the lock command again", o.Opts.Global.ConfigPath, o.Opts.Global.LockFile)
+	}
+	return nil
+}
+
+// lockableOrigin returns the origin of a collected image, unless it is built by oc-mirror
+func (o *ExecutorSchema) lockableOrigin(img v2alpha1.CopyImageSchema) (string, bool) {
+	origin := img.Origin
+	if origin == "" {
+		origin = img.Source
+	}
+	origin = strings.TrimPrefix(origin, dockerProtocol)
+	if img.Type == v2alpha1.TypeCincinnatiGraph || strings.HasPrefix(origin, o.Opts.LocalStorageFQDN+"/") {
+		return "", false
+	}
+	return origin, true
+}
+
+// pinnedImage returns origin pinned by digest.
+// Images outside of a registry (oci, dir) are returned as is.
+func (o *ExecutorSchema) pinnedImage(ctx context.Context, origin string) (string, error) {
+	imgSpec, err := image.ParseRef(origin)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case imgSpec.Transport != dockerProtocol:
+		return origin, nil
+	case imgSpec.IsImageByDigest():
+		return imgSpec.Name + "@" + imgSpec.Algorithm + ":" + imgSpec.Digest, nil
+	default:
+		digest, err := o.digestOf(ctx, imgSpec)
+		if err != nil {
+			return "", err
+		}
+		return imgSpec.Name + "@sha256:" + digest, nil
+	}
+}
+
+// digestOf returns the digest of the manifest of the image in the source registry
+func (o *ExecutorSchema) digestOf(ctx context.Context, imgSpec image.ImageSpec) (string, error) {
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return "", err
+	}
+	return o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+}
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const (
+	lockedDigest  = "0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d"
+	releaseDigest = "sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+)
+
+// lockManifest resolves every image by tag to lockedDigest
+type lockManifest struct {
+	manifest.ManifestInterface
+	resolved []string
+}
+
+func (o *lockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	if imgRef == "docker://quay.io/unknown/image:latest" {
+		return "", fmt.Errorf("manifest unknown")
+	}
+	o.resolved = append(o.resolved, imgRef)
+	return lockedDigest, nil
+}
+
+func TestLock(t *testing.T) {
+	newLockSchema := func(t *testing.T, cfg v2alpha1.ImageSetConfiguration) *LockSchema {
+		global := &mirror.GlobalOptions{WorkingDir: t.TempDir()}
+		_, sharedOpts := mirror.SharedImageFlags()
+		_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+		_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+		return &LockSchema{
+			ExecutorSchema: ExecutorSchema{
+				Log:      clog.New("trace"),
+				Config:   cfg,
+				Manifest: &lockManifest{},
+				Opts: &mirror.CopyOptions{
+					Global:           global,
+					SrcImage:         srcOpts,
+					Mode:             mirror.MirrorToDisk,
+					LocalStorageFQDN: "localhost:55000",
+				},
+			},
+		}
+	}
+
+	t.Run("Testing pinConfig - should pin catalogs and additional images by digest", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					Operators: []v2alpha1.Operator{
+						{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+						{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.16", TargetTag: "v1"},
+						{Catalog: "oci:///tmp/catalog"},
+					},
+					CompositeCatalogs: []v2alpha1.CompositeCatalog{
+						{TargetCatalog: "approved/index", Catalogs: []v2alpha1.Operator{{Catalog: "registry.redhat.io/redhat/community-operator-index:v4.16"}}},
+					},
+					AdditionalImages: []v2alpha1.Image{
+						{Name: "registry.redhat.io/ubi9/ubi:latest"},
+						{Name: "registry.redhat.io/ubi9/ubi-minimal@sha256:" + lockedDigest},
+					},
+				},
+			},
+		})
+		assert.NoError(t, ex.pinConfig(context.Background()))
+
+		mirrorCfg := ex.Config.Mirror
+		assert.Equal(t, "registry.redhat.io/redhat/redhat-operator-index@sha256:"+lockedDigest, mirrorCfg.Operators[0].Catalog)
+		assert.Equal(t, "v4.16", mirrorCfg.Operators[0].TargetTag)
+		assert.Equal(t, "registry.redhat.io/redhat/certified-operator-index@sha256:"+lockedDigest, mirrorCfg.Operators[1].Catalog)
+		assert.Equal(t, "v1", mirrorCfg.Operators[1].TargetTag)
+		assert.Equal(t, "oci:///tmp/catalog", mirrorCfg.Operators[2].Catalog)
+		assert.Equal(t, "registry.redhat.io/redhat/community-operator-index@sha256:"+lockedDigest, mirrorCfg.CompositeCatalogs[0].Catalogs[0].Catalog)
+		assert.Equal(t, "registry.redhat.io/ubi9/ubi:latest@sha256:"+lockedDigest, mirrorCfg.AdditionalImages[0].Name)
+		assert.Equal(t, "registry.redhat.io/ubi9/ubi-minimal@sha256:"+lockedDigest, mirrorCfg.AdditionalImages[1].Name)
+		assert.Len(t, ex.Manifest.(*lockManifest).resolved, 4)
+	})
+
+	t.Run("Testing pinConfig - should fail when a catalog can not be resolved", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{Operators: []v2alpha1.Operator{{Catalog: "quay.io/unknown/image:latest"}}},
+			},
+		})
+		assert.EqualError(t, ex.pinConfig(context.Background()), "unable to pin catalog quay.io/unknown/image:latest: manifest unknown")
+	})
+
+	t.Run("Testing lockConfig - should lock releases, helm charts, bundles and images", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{
+			TypeMeta: v2alpha1.ImageSetConfigurationLock{}.GetImageSetConfiguration().TypeMeta,
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					Platform: v2alpha1.Platform{
+						Channels: []v2alpha1.ReleaseChannel{{Name: "stable-4.16", MinVersion: "4.16.1", MaxVersion: "4.16.3"}},
+						Graph:    true,
+					},
+					Helm: v2alpha1.Helm{Repositories: []v2alpha1.Repository{
+						{Name: "podinfo", URL: "https://stefanprodan.github.io/podinfo", Charts: []v2alpha1.Chart{{Name: "podinfo"}, {Name: "other", Version: "1.0.0"}}},
+					}},
+				},
+			},
+		})
+		chartsDir := filepath.Join(ex.Opts.Global.WorkingDir, helmDir, helmChartDir)
+		assert.NoError(t, os.MkdirAll(chartsDir, 0755))
+		for _, version := range []string{"6.4.0", "6.5.0"} {
+			_, err := chartutil.Save(&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "podinfo", Version: version}}, chartsDir)
+			assert.NoError(t, err)
+		}
+		filteredDir := filepath.Join(t.TempDir(), "filtered")
+		assert.NoError(t, declcfg.WriteFS(declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo"}},
+			Bundles: []declcfg.Bundle{
+				{Schema: declcfg.SchemaBundle, Package: "foo", Name: "foo.v0.2.0", Image: "quay.io/foo/bundle@sha256:" + lockedDigest},
+				{Schema: declcfg.SchemaBundle, Package: "foo", Name: "foo.v0.1.0", Image: "quay.io/foo/bundle@sha256:" + lockedDigest},
+			},
+		}, filteredDir, declcfg.WriteJSON, ".json"))
+
+		collectorSchema := v2alpha1.CollectorSchema{
+			AllImages: []v2alpha1.CopyImageSchema{
+				{Source: "docker://quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Origin: "docker://quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Type: v2alpha1.TypeOCPRelease},
+				{Source: "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + releaseDigest, Origin: "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + releaseDigest, Type: v2alpha1.TypeOCPReleaseContent},
+				{Source: "docker://localhost:55000/openshift/graph-image:latest", Origin: "docker://localhost:55000/openshift/graph-image:latest", Type: v2alpha1.TypeCincinnatiGraph},
+				{Source: "docker://ghcr.io/stefanprodan/podinfo:6.5.0", Origin: "ghcr.io/stefanprodan/podinfo:6.5.0", Type: v2alpha1.TypeHelmImage},
+			},
+			CatalogToFBCMap: map[string]v2alpha1.CatalogFilterResult{
+				"docker://quay.io/foo/catalog@sha256:" + lockedDigest: {
+					OperatorFilter:     v2alpha1.Operator{Catalog: "quay.io/foo/catalog@sha256:" + lockedDigest},
+					FilteredConfigPath: filteredDir,
+				},
+				"docker://quay.io/foo/full-catalog@sha256:" + lockedDigest: {
+					OperatorFilter: v2alpha1.Operator{Catalog: "quay.io/foo/full-catalog@sha256:" + lockedDigest, Full: true},
+				},
+			},
+		}
+
+		lock, err := ex.lockConfig(context.Background(), collectorSchema)
+		assert.NoError(t, err)
+		assert.Equal(t, v2alpha1.ImageSetConfigurationLockKind, lock.Kind)
+		assert.Equal(t, []v2alpha1.LockedImage{
+			{Origin: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Image: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Type: v2alpha1.TypeOCPRelease},
+			{Origin: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + releaseDigest, Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + releaseDigest, Type: v2alpha1.TypeOCPReleaseContent},
+			{Origin: "ghcr.io/stefanprodan/podinfo:6.5.0", Image: "ghcr.io/stefanprodan/podinfo@sha256:" + lockedDigest, Type: v2alpha1.TypeHelmImage},
+		}, lock.Images)
+		assert.Nil(t, lock.Mirror.Platform.Channels)
+		assert.Equal(t, []v2alpha1.ReleasePayload{{Image: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest}}, lock.Mirror.Platform.Releases)
+		assert.True(t, lock.Mirror.Platform.Graph)
+		assert.Equal(t, []v2alpha1.Chart{{Name: "podinfo", Version: "6.5.0"}, {Name: "other", Version: "1.0.0"}}, lock.Mirror.Helm.Repositories[0].Charts)
+		// the configuration itself is left untouched
+		assert.Len(t, ex.Config.Mirror.Platform.Channels, 1)
+		assert.Empty(t, ex.Config.Mirror.Helm.Repositories[0].Charts[0].Version)
+		assert.Equal(t, []v2alpha1.LockedCatalog{{
+			Catalog: "quay.io/foo/catalog@sha256:" + lockedDigest,
+			Bundles: []v2alpha1.LockedBundle{
+				{Package: "foo", Name: "foo.v0.1.0", Image: "quay.io/foo/bundle@sha256:" + lockedDigest},
+				{Package: "foo", Name: "foo.v0.2.0", Image: "quay.io/foo/bundle@sha256:" + lockedDigest},
+			},
+		}}, lock.Catalogs)
+	})
+
+	t.Run("Testing lockConfig - should fail when a helm chart was not downloaded", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					Helm: v2alpha1.Helm{Repositories: []v2alpha1.Repository{{Name: "podinfo", Charts: []v2alpha1.Chart{{Name: "podinfo"}}}}},
+				},
+			},
+		})
+		_, err := ex.lockConfig(context.Background(), v2alpha1.CollectorSchema{})
+		assert.EqualError(t, err, "unable to lock the version of helm chart podinfo of repository podinfo")
+	})
+
+	t.Run("Testing applyLock - should mirror the locked digests", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{})
+		ex.Opts.Global.LockFile = "isc.lock.yaml"
+		ex.Lock = &v2alpha1.ImageSetConfigurationLock{Images: []v2alpha1.LockedImage{
+			{Origin: "ghcr.io/stefanprodan/podinfo:6.5.0", Image: "ghcr.io/stefanprodan/podinfo@sha256:" + lockedDigest, Type: v2alpha1.TypeHelmImage},
+			{Origin: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Image: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Type: v2alpha1.TypeOCPRelease},
+		}}
+
+		images, err := ex.applyLock([]v2alpha1.CopyImageSchema{
+			{Source: "docker://ghcr.io/stefanprodan/podinfo:6.5.0", Destination: "docker://localhost:55000/stefanprodan/podinfo:6.5.0", Origin: "ghcr.io/stefanprodan/podinfo:6.5.0", Type: v2alpha1.TypeHelmImage},
+			{Source: "docker://quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Destination: "docker://localhost:55000/openshift/release-images:4.16.3-x86_64", Origin: "quay.io/openshift-release-dev/ocp-release@" + releaseDigest, Type: v2alpha1.TypeOCPRelease},
+			{Source: "docker://localhost:55000/openshift/graph-image:latest", Destination: "docker://localhost:55000/openshift/graph-image:latest", Type: v2alpha1.TypeCincinnatiGraph},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "docker://ghcr.io/stefanprodan/podinfo@sha256:"+lockedDigest, images[0].Source)
+		assert.Equal(t, "docker://localhost:55000/stefanprodan/podinfo:6.5.0", images[0].Destination)
+		assert.Equal(t, "docker://quay.io/openshift-release-dev/ocp-release@"+releaseDigest, images[1].Source)
+
+		_, err = ex.applyLock([]v2alpha1.CopyImageSchema{
+			{Source: "docker://ghcr.io/stefanprodan/podinfo:6.6.0", Origin: "ghcr.io/stefanprodan/podinfo:6.6.0", Type: v2alpha1.TypeHelmImage},
+		})
+		assert.EqualError(t, err, "image ghcr.io/stefanprodan/podinfo:6.6.0 is not in the lockfile isc.lock.yaml")
+	})
+
+	t.Run("Testing checkLockedCatalogs - should fail when the filtered bundles differ from the locked ones", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{})
+		ex.Opts.Global.LockFile = "isc.lock.yaml"
+		catalog := "quay.io/foo/catalog@sha256:" + lockedDigest
+		bundle := func(version string) v2alpha1.LockedBundle {
+			return v2alpha1.LockedBundle{Package: "foo", Name: "foo.v" + version, Image: "quay.io/foo/bundle:v" + version}
+		}
+		filteredDir := filepath.Join(t.TempDir(), "filtered")
+		assert.NoError(t, declcfg.WriteFS(declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo"}},
+			Bundles: []declcfg.Bundle{
+				{Schema: declcfg.SchemaBundle, Package: "foo", Name: "foo.v0.2.0", Image: "quay.io/foo/bundle:v0.2.0"},
+				{Schema: declcfg.SchemaBundle, Package: "foo", Name: "foo.v0.3.0", Image: "quay.io/foo/bundle:v0.3.0"},
+			},
+		}, filteredDir, declcfg.WriteJSON, ".json"))
+		catalogToFBCMap := map[string]v2alpha1.CatalogFilterResult{
+			"docker://" + catalog: {OperatorFilter: v2alpha1.Operator{Catalog: catalog}, FilteredConfigPath: filteredDir},
+		}
+
+		ex.Lock = &v2alpha1.ImageSetConfigurationLock{Catalogs: []v2alpha1.LockedCatalog{
+			{Catalog: catalog, Bundles: []v2alpha1.LockedBundle{bundle("0.2.0"), bundle("0.3.0")}},
+		}}
+		assert.NoError(t, ex.checkLockedCatalogs(context.Background(), catalogToFBCMap))
+
+		ex.Lock = &v2alpha1.ImageSetConfigurationLock{Catalogs: []v2alpha1.LockedCatalog{
+			{Catalog: catalog, Bundles: []v2alpha1.LockedBundle{bundle("0.1.0"), bundle("0.2.0")}},
+			{Catalog: "quay.io/foo/other-catalog@sha256:" + lockedDigest, Bundles: []v2alpha1.LockedBundle{}},
+		}}
+		err := ex.checkLockedCatalogs(context.Background(), catalogToFBCMap)
+		assert.EqualError(t, err, "bundle foo.v0.3.0 of catalog "+catalog+" is not in the lockfile isc.lock.yaml\n"+
+			"locked bundle foo.v0.1.0 of catalog "+catalog+" is not selected anymore\n"+
+			"locked catalog quay.io/foo/other-catalog@sha256:"+lockedDigest+" was not collected")
+	})
+
+	t.Run("Testing checkConfigDigest - should fail when the configuration is not the locked one", func(t *testing.T) {
+		ex := newLockSchema(t, v2alpha1.ImageSetConfiguration{})
+		ex.Opts.Global.LockFile = "isc.lock.yaml"
+		ex.Opts.Global.ConfigPath = filepath.Join(t.TempDir(), "isc.yaml")
+		assert.NoError(t, os.WriteFile(ex.Opts.Global.ConfigPath, []byte("kind: ImageSetConfiguration\n"), 0644))
+		configDigest, err := config.Digest(ex.Opts.Global.ConfigPath)
+		assert.NoError(t, err)
+
+		ex.Lock = &v2alpha1.ImageSetConfigurationLock{ConfigDigest: configDigest}
+		assert.NoError(t, ex.checkConfigDigest())
+
+		ex.Lock.ConfigDigest = "sha256:" + lockedDigest
+		assert.EqualError(t, ex.checkConfigDigest(), ex.Opts.Global.ConfigPath+" changed since the lockfile isc.lock.yaml was generated: run the lock command again")
+	})
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/migrate"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const migrateConfigSuffix string = "-v2alpha1.yaml"
+
+var (
+	migrateStateLongDesc = templates.LongDesc(
+		`
+		Migrate the metadata of oc-mirror v1 to the working-dir of oc-mirror v2.
+
+		The v1 metadata is read from the storageConfig of the v1 image set configuration (local directory or registry),
+		or from an oc-mirror-workspace with --v1-workspace. The digests of all the images mirrored by v1 are written to the
+		history of the working-dir, so that the next mirrorToDisk to this directory only archives the new blobs.
+
+		The v1alpha2 image set configuration is translated to v2alpha1. The fields that v2 does not support, such as
+		storageConfig or targetName, are reported.
+		`,
+	)
+	migrateStateExamples = templates.Examples(
+		`
+# Migrate the metadata of the storageConfig of isc-v1.yaml to /home/<user>/oc-mirror/mirror1
+oc-mirror migrate-state -c ./isc-v1.yaml file:///home/<user>/oc-mirror/mirror1 --v2
+
+# Migrate the metadata of a v1 workspace, and write the v2alpha1 configuration to isc.yaml
+oc-mirror migrate-state -c ./isc-v1.yaml --v1-workspace ./oc-mirror-workspace --output ./isc.yaml file:///home/<user>/oc-mirror/mirror1 --v2
+		`,
+	)
+)
+
+type MigrateStateSchema struct {
+	ExecutorSchema
+	V1Workspace string
+	Output      string
+}
+
+// NewMigrateStateCommand - setup all the relevant support structs
+// to eventually execute the 'migrate-state' sub command
+func NewMigrateStateCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	mkd := MakeDir{}
+	ex := &MigrateStateSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: mkd,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "migrate-state",
+		Short:   "Migrates the metadata and image set configuration of oc-mirror v1 to v2",
+		Long:    migrateStateLongDesc,
+		Example: migrateStateExamples,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateMigrateState(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteMigrateState(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			err = ex.RunMigrateState(cmd.Context())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&ex.V1Workspace, "v1-workspace", "", "oc-mirror-workspace directory of v1, read instead of the storageConfig of the image set configuration")
+	cmd.Flags().StringVar(&ex.Output, "output", "", "Path of the v2alpha1 image set configuration. Defaults to <config name>"+migrateConfigSuffix+" next to the v1 image set configuration")
+	cmd.MarkFlagFilename("output", "yaml")
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// ValidateMigrateState - cobra validation
+func (o MigrateStateSchema) ValidateMigrateState(args []string) error {
+	if len(o.Opts.Global.ConfigPath) == 0 {
+		return fmt.Errorf("use the --config flag it is mandatory")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("the destination directory is missing in the command arguments")
+	}
+	if !strings.HasPrefix(args[0], fileProtocol) {
+		return fmt.Errorf("the destination directory argument must have a file:// protocol prefix")
+	}
+	return nil
+}
+
+// CompleteMigrateState - setup the working-dir of the destination directory
+func (o *MigrateStateSchema) CompleteMigrateState(args []string) error {
+	if o.Output == "" {
+		o.Output = strings.TrimSuffix(o.Opts.Global.ConfigPath, filepath.Ext(o.Opts.Global.ConfigPath)) + migrateConfigSuffix
+	}
+	o.Opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(args[0], fileProtocol), workingDir)
+	err := o.MakeDir.makeDirAll(o.Opts.Global.WorkingDir, 0755)
+	if err != nil {
+		return err
+	}
+	return o.setupLogsLevelAndDir()
+}
+
+// RunMigrateState - translate the v1 image set configuration, and write
+// the digests of the v1 metadata to the history of the working-dir
+func (o *MigrateStateSchema) RunMigrateState(ctx context.Context) error {
+	v1Config, err := migrate.ReadConfig(o.Opts.Global.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	isc, unsupported, err := migrate.ConvertConfig(v1Config)
+	if err != nil {
+		return err
+	}
+	for _, u := range unsupported {
+		o.Log.Warn("unsupported v1 field %s", u)
+	}
+	iscData, err := yaml.Marshal(isc)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.Output, iscData, 0644); err != nil {
+		return err
+	}
+	o.Log.Info("v2alpha1 image set configuration written to %s", o.Output)
+
+	meta, err := migrate.ReadMetadata(ctx, v1Config, o.V1Workspace)
+	if err != nil {
+		return err
+	}
+	blobs := migrate.HistoryBlobs(meta)
+	hist, err := history.NewHistory(o.Opts.Global.WorkingDir, time.Time{}, o.Log, history.OSFileCreator{})
+	if err != nil {
+		return err
+	}
+	if _, err := hist.Append(blobs); err != nil {
+		return err
+	}
+	o.Log.Info("%d digests of the v1 sequence %d added to the history of %s", len(blobs), meta.PastMirror.Sequence, o.Opts.Global.WorkingDir)
+	o.Log.Info("the next mirrorToDisk to %s with %s archives only the new content", filepath.Dir(o.Opts.Global.WorkingDir), o.Output)
+	return nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestMigrateState(t *testing.T) {
+	const (
+		manifestDigest = "sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+		layerDigest    = "sha256:1ff4ea896d6b958aa060e04eb090f70c563ad0650e6b362c1a1d67582acb3b8e"
+		v1ISC          = `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v1alpha2
+storageConfig:
+  local:
+    path: /tmp/not-used
+mirror:
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.14
+    targetName: my-index
+    packages:
+    - name: aws-load-balancer-operator
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest
+`
+	)
+
+	newMigrateStateSchema := func(t *testing.T) *MigrateStateSchema {
+		configPath := filepath.Join(t.TempDir(), "isc.yaml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(v1ISC), 0644))
+		return &MigrateStateSchema{
+			ExecutorSchema: ExecutorSchema{
+				Log:     clog.New("trace"),
+				MakeDir: MakeDir{},
+				Opts: &mirror.CopyOptions{
+					Global: &mirror.GlobalOptions{ConfigPath: configPath, LogLevel: "info"},
+				},
+			},
+		}
+	}
+
+	t.Run("Testing ValidateMigrateState - should validate the arguments", func(t *testing.T) {
+		ex := newMigrateStateSchema(t)
+		assert.NoError(t, ex.ValidateMigrateState([]string{"file:///tmp/mirror"}))
+		assert.EqualError(t, ex.ValidateMigrateState([]string{}), "the destination directory is missing in the command arguments")
+		assert.EqualError(t, ex.ValidateMigrateState([]string{"docker://localhost:5000"}), "the destination directory argument must have a file:// protocol prefix")
+		ex.Opts.Global.ConfigPath = ""
+		assert.EqualError(t, ex.ValidateMigrateState([]string{"file:///tmp/mirror"}), "use the --config flag it is mandatory")
+	})
+
+	t.Run("Testing RunMigrateState - should migrate a v1 workspace", func(t *testing.T) {
+		v1Workspace := t.TempDir()
+		meta := v1alpha2.NewMetadata()
+		meta.PastMirror = v1alpha2.PastMirror{
+			Sequence: 2,
+			Associations: []v1alpha2.Association{
+				{Name: "registry.redhat.io/ubi9/ubi:latest", ID: manifestDigest, Type: v1alpha2.TypeGeneric, LayerDigests: []string{layerDigest}},
+			},
+		}
+		metaData, err := json.Marshal(&meta)
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(filepath.Join(v1Workspace, "publish"), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(v1Workspace, "publish", ".metadata.json"), metaData, 0644))
+
+		ex := newMigrateStateSchema(t)
+		ex.V1Workspace = v1Workspace
+		destination := t.TempDir()
+		assert.NoError(t, ex.CompleteMigrateState([]string{"file://" + destination}))
+		defer ex.logFile.Close()
+		assert.Equal(t, strings.TrimSuffix(ex.Opts.Global.ConfigPath, ".yaml")+migrateConfigSuffix, ex.Output)
+		assert.Equal(t, filepath.Join(destination, workingDir), ex.Opts.Global.WorkingDir)
+
+		assert.NoError(t, ex.RunMigrateState(context.Background()))
+
+		iscData, err := os.ReadFile(ex.Output)
+		assert.NoError(t, err)
+		var isc v2alpha1.ImageSetConfiguration
+		assert.NoError(t, yaml.Unmarshal(iscData, &isc))
+		assert.Equal(t, "mirror.openshift.io/v2alpha1", isc.APIVersion)
+		assert.Equal(t, "my-index", isc.Mirror.Operators[0].TargetCatalog)
+		assert.Equal(t, "registry.redhat.io/ubi9/ubi:latest", isc.Mirror.AdditionalImages[0].Name)
+
+		historyFiles, err := os.ReadDir(filepath.Join(ex.Opts.Global.WorkingDir, ".history"))
+		assert.NoError(t, err)
+		assert.Len(t, historyFiles, 1)
+		historyData, err := os.ReadFile(filepath.Join(ex.Opts.Global.WorkingDir, ".history", historyFiles[0].Name()))
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{manifestDigest, layerDigest}, strings.Fields(string(historyData)))
+	})
+
+	t.Run("Testing RunMigrateState - should fail without v1 metadata", func(t *testing.T) {
+		ex := newMigrateStateSchema(t)
+		ex.V1Workspace = t.TempDir()
+		assert.NoError(t, ex.CompleteMigrateState([]string{"file://" + t.TempDir()}))
+		defer ex.logFile.Close()
+		assert.EqualError(t, ex.RunMigrateState(context.Background()), "no v1 metadata found in directory "+ex.V1Workspace)
+	})
+}
@@ -0,0 +1,442 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/additional"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/helm"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/imagebuilder"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/samples"
+)
+
+// verifyDriftExitCode is the exit code of the verify command when the destination drifted
+const verifyDriftExitCode int = 2
+
+var (
+	verifyLongDesc = templates.LongDesc(
+		`
+		Verify that a destination registry contains all the images of an image set configuration.
+
+		The images expected in the destination registry are computed as in the diskToMirror workflow, from the
+		workspace of a previous mirroring. Each of them is checked in the destination registry, and the command reports:
+		- the missing images
+		- the images whose digest differs from the mirrored one
+		- the images that could not be checked, with the error
+		- with --extra-repositories, the repositories of the destination that are not part of the image set
+
+		The report is written to working-dir/verify/verify-report.yaml. The command exits with code 2 when
+		the destination registry drifted, and with code 1 when some images could not be checked, or on any other error.
+		`,
+	)
+	verifyExamples = templates.Examples(
+		`
+# Verify the images mirrored from the archives in /home/<user>/oc-mirror/mirror1
+oc-mirror verify -c ./isc.yaml --workspace file:///home/<user>/oc-mirror/mirror1 docker://localhost:6000 --v2
+		`,
+	)
+)
+
+type VerifySchema struct {
+	ExecutorSchema
+	// ImageTimeout is the timeout for checking an image
+	ImageTimeout time.Duration
+	// CheckExtraRepositories lists the repositories of the destination that are not part of the image set
+	CheckExtraRepositories bool
+	// listRepositories lists the repositories of the registry, using the catalog API
+	listRepositories func(ctx context.Context, registry string) ([]string, error)
+}
+
+// verifyReport is the drift of the destination registry
+type verifyReport struct {
+	Destination       string        `json:"destination"`
+	ExpectedImages    int           `json:"expectedImages"`
+	Missing           []verifyImage `json:"missing"`
+	DigestMismatch    []verifyImage `json:"digestMismatch"`
+	Unchecked         []verifyImage `json:"unchecked"`
+	ExtraRepositories []string      `json:"extraRepositories"`
+}
+
+type verifyImage struct {
+	Origin         string `json:"origin,omitempty"`
+	Destination    string `json:"destination"`
+	ExpectedDigest string `json:"expectedDigest,omitempty"`
+	ActualDigest   string `json:"actualDigest,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+func (r verifyReport) drifted() bool {
+	return len(r.Missing) > 0 || len(r.DigestMismatch) > 0 || len(r.ExtraRepositories) > 0
+}
+
+// NewVerifyCommand - setup all the relevant support structs
+// to eventually execute the 'verify' sub command
+func NewVerifyCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	mkd := MakeDir{}
+	ex := &VerifySchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: mkd,
+		},
+	}
+	ex.listRepositories = ex.catalogRepositories
+
+	cmd := &cobra.Command{
+		Use:     "verify",
+		Short:   "Verifies that a destination registry contains the images of an image set configuration",
+		Long:    verifyLongDesc,
+		Example: verifyExamples,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateVerify(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteVerify(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage()
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunVerify(cmd)
+			if errors.Is(err, &DriftError{}) {
+				log.Error("%v ", err)
+				os.Exit(verifyDriftExitCode)
+			}
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&opts.Global.MaxNestedPaths, "max-nested-paths", 0, "Number of nested paths, for destination registries that limit nested paths")
+	cmd.Flags().DurationVar(&ex.ImageTimeout, "image-timeout", 10*time.Minute, "Timeout for checking an image")
+	cmd.Flags().BoolVar(&ex.CheckExtraRepositories, "extra-repositories", false, "Report as drift the repositories of the destination that are not part of the image set, listed with the catalog API")
+
+	// hide flags
+	HideFlags(cmd)
+
+	return cmd
+}
+
+// ValidateVerify - cobra validation
+func (o VerifySchema) ValidateVerify(args []string) error {
+	if len(o.Opts.Global.ConfigPath) == 0 {
+		return fmt.Errorf("use the --config flag it is mandatory")
+	}
+	if len(o.Opts.Global.WorkingDir) == 0 {
+		return fmt.Errorf("use the --workspace flag, it is mandatory when using the verify command")
+	}
+	if !strings.HasPrefix(o.Opts.Global.WorkingDir, fileProtocol) {
+		return fmt.Errorf("--workspace flag must have a file:// protocol prefix")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("the destination registry is missing in the command arguments")
+	}
+	if !strings.HasPrefix(args[0], dockerProtocol) {
+		return fmt.Errorf("the destination registry argument must have a docker:// protocol prefix")
+	}
+	return nil
+}
+
+// CompleteVerify - read the image set configuration, and setup the collectors
+// in diskToMirror mode against the workspace of a previous mirroring
+func (o *VerifySchema) CompleteVerify(args []string) error {
+	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok {
+		o.Opts.Global.RegistriesConfPath = envOverride
+	}
+
+	o.Log.Debug("imagesetconfig file %s ", o.Opts.Global.ConfigPath)
+	cfg, err := config.ReadConfig(o.Opts.Global.ConfigPath, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return err
+	}
+	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+
+	mc := mirror.NewMirrorCopy()
+	md := mirror.NewMirrorDelete()
+	o.Manifest = manifest.New(o.Log)
+	o.Mirror = mirror.New(mc, md)
+
+	o.Opts.Mode = mirror.DiskToMirror
+	o.Opts.Destination = args[0]
+	// source is the local cache, which is HTTP
+	// nolint: errcheck
+	o.Opts.SrcImage.TlsVerify = false
+	o.Opts.Global.WorkingDir = strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol)
+	if filepath.Base(o.Opts.Global.WorkingDir) != workingDir {
+		o.Opts.Global.WorkingDir = filepath.Join(o.Opts.Global.WorkingDir, workingDir)
+	}
+	if _, err := os.Stat(o.Opts.Global.WorkingDir); err != nil {
+		return fmt.Errorf("the workspace %s of a previous mirroring was not found: %v", o.Opts.Global.WorkingDir, err)
+	}
+
+	err = o.setupLogsLevelAndDir()
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s / verify", o.Opts.Mode)
+
+	o.Opts.MultiArch = "all"
+	o.Opts.RemoveSignatures = true
+
+	if o.isLocalStoragePortBound() {
+		return fmt.Errorf("%d is already bound and cannot be used", o.Opts.Global.Port)
+	}
+	o.Opts.LocalStorageFQDN = "localhost:" + strconv.Itoa(int(o.Opts.Global.Port))
+
+	err = o.setupLocalStorageDir()
+	if err != nil {
+		return err
+	}
+
+	client, _ := release.NewOCPClient(uuid.New(), o.Log)
+
+	o.ImageBuilder = imagebuilder.NewBuilder(o.Log, *o.Opts)
+	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature, o.Manifest)
+	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
+	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+	o.SamplesCollector = samples.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
+	return nil
+}
+
+// RunVerify - collect the images expected in the destination registry, check them
+// and write the report. A DriftError is returned when the destination drifted.
+func (o *VerifySchema) RunVerify(cmd *cobra.Command) error {
+	startTime := time.Now()
+	o.Log.Debug(startMessage, o.Opts.Global.Port)
+	go startLocalRegistry(&o.LocalStorageService, o.localStorageInterruptChannel)
+	defer o.closeAll()
+
+	collectorSchema, err := o.CollectAll(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if o.Opts.Global.MaxNestedPaths > 0 {
+		collectorSchema.AllImages, err = withMaxNestedPaths(collectorSchema.AllImages, o.Opts.Global.MaxNestedPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	report, err := o.verify(cmd.Context(), collectorSchema.AllImages)
+	if err != nil {
+		return err
+	}
+	reportPath, err := o.writeVerifyReport(report)
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.PageFacingUp+" verify report in : %s", reportPath)
+	o.Log.Info("verify time     : %v", time.Since(startTime))
+	// the drift is not known as long as some images are not checked
+	if len(report.Unchecked) > 0 {
+		return fmt.Errorf("%d/%d images of %s could not be checked, %d images missing, %d images with a different digest, %d extra repositories",
+			len(report.Unchecked), report.ExpectedImages, o.Opts.Destination, len(report.Missing), len(report.DigestMismatch), len(report.ExtraRepositories))
+	}
+	if report.drifted() {
+		return DriftErrorf("%s drifted: %d/%d images missing, %d images with a different digest, %d extra repositories",
+			o.Opts.Destination, len(report.Missing), report.ExpectedImages, len(report.DigestMismatch), len(report.ExtraRepositories))
+	}
+	o.Log.Info(emoji.WavingHandSign+" all %d images are mirrored to %s", report.ExpectedImages, o.Opts.Destination)
+	return nil
+}
+
+// verify checks each expected image in the destination registry, compares its digest
+// with the mirrored one, and lists the repositories not expected in the destination.
+// The images which can not be checked are reported as unchecked, with the error.
+func (o *VerifySchema) verify(ctx context.Context, allImages []v2alpha1.CopyImageSchema) (verifyReport, error) {
+	report := verifyReport{
+		Destination:       o.Opts.Destination,
+		Missing:           []verifyImage{},
+		DigestMismatch:    []verifyImage{},
+		Unchecked:         []verifyImage{},
+		ExtraRepositories: []string{},
+	}
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return report, err
+	}
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return report, err
+	}
+
+	expectedRepos := map[string]bool{}
+	checked := map[string]bool{}
+	for _, img := range allImages {
+		if checked[img.Destination] {
+			continue
+		}
+		checked[img.Destination] = true
+		report.ExpectedImages++
+		destSpec, err := image.ParseRef(img.Destination)
+		if err != nil {
+			return report, err
+		}
+		expectedRepos[destSpec.Name] = true
+
+		result := verifyImage{Origin: strings.TrimPrefix(img.Origin, dockerProtocol), Destination: destSpec.Reference}
+		exists, err := o.checkImage(ctx, sourceCtx, destCtx, img, &result)
+		switch {
+		case err != nil:
+			o.Log.Warn(emoji.Warning+"  %v", err)
+			result.Error = err.Error()
+			report.Unchecked = append(report.Unchecked, result)
+		case !exists:
+			o.Log.Debug("missing %s", img.Destination)
+			report.Missing = append(report.Missing, result)
+		case result.ExpectedDigest != result.ActualDigest:
+			o.Log.Debug("digest mismatch %s: expected %s, got %s", img.Destination, result.ExpectedDigest, result.ActualDigest)
+			report.DigestMismatch = append(report.DigestMismatch, result)
+		}
+	}
+
+	if !o.CheckExtraRepositories {
+		return report, nil
+	}
+	// the destination is a registry, optionally followed by a namespace
+	destination := strings.TrimSuffix(strings.TrimPrefix(o.Opts.Destination, dockerProtocol), "/")
+	registry, _, _ := strings.Cut(destination, "/")
+	repos, err := o.listRepositories(ctx, registry)
+	if err != nil {
+		o.Log.Warn(emoji.Warning+"  unable to list the repositories of %s, extra repositories are not verified: %v", registry, err)
+		return report, nil
+	}
+	for _, repo := range repos {
+		ref := registry + "/" + repo
+		if (ref == destination || strings.HasPrefix(ref, destination+"/")) && !expectedRepos[ref] {
+			report.ExtraRepositories = append(report.ExtraRepositories, ref)
+		}
+	}
+	slices.Sort(report.ExtraRepositories)
+	return report, nil
+}
+
+// checkImage checks that the image exists in the destination registry, within the image timeout,
+// and then sets the expected and actual digests of the result
+func (o *VerifySchema) checkImage(ctx context.Context, sourceCtx, destCtx *types.SystemContext, img v2alpha1.CopyImageSchema, result *verifyImage) (bool, error) {
+	if o.ImageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.ImageTimeout)
+		defer cancel()
+	}
+	// the mirror checks the image within the command timeout of its options
+	opts := *o.Opts
+	global := *o.Opts.Global
+	global.CommandTimeout = o.ImageTimeout
+	opts.Global = &global
+	exists, err := o.Mirror.Check(ctx, img.Destination, &opts, false)
+	if err != nil {
+		return false, fmt.Errorf("unable to check %s: %v", img.Destination, err)
+	}
+	if !exists {
+		return false, nil
+	}
+	result.ExpectedDigest, err = o.expectedDigest(ctx, sourceCtx, img)
+	if err != nil {
+		return true, fmt.Errorf("unable to get the digest of %s: %v", img.Source, err)
+	}
+	result.ActualDigest, err = o.Manifest.GetDigest(ctx, destCtx, img.Destination)
+	if err != nil {
+		return true, fmt.Errorf("unable to get the digest of %s: %v", img.Destination, err)
+	}
+	return true, nil
+}
+
+// expectedDigest returns the digest of the image copied to the destination,
+// which is the digest of the source when it is pinned, otherwise the digest in the local cache
+func (o *VerifySchema) expectedDigest(ctx context.Context, sourceCtx *types.SystemContext, img v2alpha1.CopyImageSchema) (string, error) {
+	srcSpec, err := image.ParseRef(img.Source)
+	if err != nil {
+		return "", err
+	}
+	if srcSpec.IsImageByDigest() {
+		return srcSpec.Digest, nil
+	}
+	return o.Manifest.GetDigest(ctx, sourceCtx, img.Source)
+}
+
+// writeVerifyReport writes the report to working-dir/verify/verify-report.yaml
+func (o *VerifySchema) writeVerifyReport(report verifyReport) (string, error) {
+	outDir := filepath.Join(o.Opts.Global.WorkingDir, verifyOutDir)
+	if err := o.MakeDir.makeDirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	reportPath := filepath.Join(outDir, verifyReportFile)
+	return reportPath, os.WriteFile(reportPath, data, 0644)
+}
+
+// catalogRepositories lists the repositories of the destination registry with the catalog API
+func (o *VerifySchema) catalogRepositories(ctx context.Context, registry string) ([]string, error) {
+	nameOpts := []name.Option{name.StrictValidation}
+	remoteOpts := []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithContext(ctx),
+	}
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	if destCtx.DockerInsecureSkipTLSVerify == types.OptionalBoolTrue {
+		nameOpts = append(nameOpts, name.Insecure)
+		transport := remote.DefaultTransport.(*http.Transport).Clone()
+		// nolint: gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+		remoteOpts = append(remoteOpts, remote.WithTransport(transport))
+	}
+	reg, err := name.NewRegistry(registry, nameOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return remote.Catalog(ctx, reg, remoteOpts...)
+}
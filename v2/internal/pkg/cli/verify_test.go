@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// verifyMirror reports the missing images as not found
+type verifyMirror struct {
+	Mirror
+	missing []string
+}
+
+func (o verifyMirror) Check(ctx context.Context, dest string, opts *mirror.CopyOptions, asCopySrc bool) (bool, error) {
+	for _, missing := range o.missing {
+		if dest == missing {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// verifyManifest resolves the images from a map of digests
+type verifyManifest struct {
+	manifest.ManifestInterface
+	digests map[string]string
+}
+
+func (o verifyManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	digest, ok := o.digests[imgRef]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown")
+	}
+	return digest, nil
+}
+
+func TestVerify(t *testing.T) {
+	const (
+		digestA = "f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+		digestB = "0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d"
+	)
+	allImages := []v2alpha1.CopyImageSchema{
+		{Source: "docker://localhost:55000/openshift-release-dev/ocp-release@sha256:" + digestA, Destination: "docker://mirror.example.com:5000/ns/openshift-release-dev/ocp-release:4.16.3-x86_64", Origin: "docker://quay.io/openshift-release-dev/ocp-release@sha256:" + digestA, Type: v2alpha1.TypeOCPRelease},
+		{Source: "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:" + digestA, Destination: "docker://mirror.example.com:5000/ns/openshift-release-dev/ocp-v4.0-art-dev:sha256-" + digestA, Origin: "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:" + digestA, Type: v2alpha1.TypeOCPReleaseContent},
+		{Source: "docker://localhost:55000/ubi9/ubi:latest", Destination: "docker://mirror.example.com:5000/ns/ubi9/ubi:latest", Origin: "docker://registry.redhat.io/ubi9/ubi:latest", Type: v2alpha1.TypeGeneric},
+		{Source: "docker://localhost:55000/ubi9/ubi-minimal:latest", Destination: "docker://mirror.example.com:5000/ns/ubi9/ubi-minimal:latest", Origin: "docker://registry.redhat.io/ubi9/ubi-minimal:latest", Type: v2alpha1.TypeGeneric},
+		// duplicated destinations are checked once
+		{Source: "docker://localhost:55000/ubi9/ubi-minimal:latest", Destination: "docker://mirror.example.com:5000/ns/ubi9/ubi-minimal:latest", Origin: "docker://registry.redhat.io/ubi9/ubi-minimal:latest", Type: v2alpha1.TypeGeneric},
+	}
+
+	newVerifySchema := func(t *testing.T, missing []string, digests map[string]string, repos []string) *VerifySchema {
+		global := &mirror.GlobalOptions{WorkingDir: t.TempDir()}
+		_, sharedOpts := mirror.SharedImageFlags()
+		_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+		_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+		_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+		return &VerifySchema{
+			ImageTimeout:           time.Minute,
+			CheckExtraRepositories: true,
+			ExecutorSchema: ExecutorSchema{
+				Log:      clog.New("trace"),
+				Mirror:   verifyMirror{missing: missing},
+				Manifest: verifyManifest{digests: digests},
+				MakeDir:  MakeDir{},
+				Opts: &mirror.CopyOptions{
+					Global:           global,
+					SrcImage:         srcOpts,
+					DestImage:        destOpts,
+					Mode:             mirror.DiskToMirror,
+					Destination:      "docker://mirror.example.com:5000/ns",
+					LocalStorageFQDN: "localhost:55000",
+				},
+			},
+			listRepositories: func(ctx context.Context, registry string) ([]string, error) {
+				if repos == nil {
+					return nil, fmt.Errorf("catalog API is not supported by %s", registry)
+				}
+				return repos, nil
+			},
+		}
+	}
+
+	t.Run("Testing verify - should not report any drift", func(t *testing.T) {
+		ex := newVerifySchema(t, nil,
+			map[string]string{
+				"docker://localhost:55000/ubi9/ubi:latest":                    digestB,
+				"docker://localhost:55000/ubi9/ubi-minimal:latest":            digestB,
+				allImages[0].Destination:                                      digestA,
+				allImages[1].Destination:                                      digestA,
+				"docker://mirror.example.com:5000/ns/ubi9/ubi:latest":         digestB,
+				"docker://mirror.example.com:5000/ns/ubi9/ubi-minimal:latest": digestB,
+			},
+			[]string{"ns/openshift-release-dev/ocp-release", "ns/openshift-release-dev/ocp-v4.0-art-dev", "ns/ubi9/ubi", "ns/ubi9/ubi-minimal", "other-ns/ubi9/ubi"},
+		)
+		report, err := ex.verify(context.Background(), allImages)
+		assert.NoError(t, err)
+		assert.False(t, report.drifted())
+		assert.Equal(t, 4, report.ExpectedImages)
+	})
+
+	t.Run("Testing verify - should report missing, mismatched and extra repositories", func(t *testing.T) {
+		ex := newVerifySchema(t,
+			[]string{allImages[1].Destination},
+			map[string]string{
+				"docker://localhost:55000/ubi9/ubi:latest":                    digestB,
+				"docker://localhost:55000/ubi9/ubi-minimal:latest":            digestB,
+				allImages[0].Destination:                                      digestB,
+				"docker://mirror.example.com:5000/ns/ubi9/ubi:latest":         digestB,
+				"docker://mirror.example.com:5000/ns/ubi9/ubi-minimal:latest": digestB,
+			},
+			[]string{"ns/openshift-release-dev/ocp-release", "ns/ubi9/ubi", "ns/ubi9/ubi-minimal", "ns/ubi9/ubi-micro", "ns", "other-ns/ubi9/ubi"},
+		)
+		report, err := ex.verify(context.Background(), allImages)
+		assert.NoError(t, err)
+		assert.True(t, report.drifted())
+		assert.Equal(t, []verifyImage{{
+			Origin:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:" + digestA,
+			Destination: "mirror.example.com:5000/ns/openshift-release-dev/ocp-v4.0-art-dev:sha256-" + digestA,
+		}}, report.Missing)
+		assert.Equal(t, []verifyImage{{
+			Origin:         "quay.io/openshift-release-dev/ocp-release@sha256:" + digestA,
+			Destination:    "mirror.example.com:5000/ns/openshift-release-dev/ocp-release:4.16.3-x86_64",
+			ExpectedDigest: digestA,
+			ActualDigest:   digestB,
+		}}, report.DigestMismatch)
+		assert.Equal(t, []string{"mirror.example.com:5000/ns", "mirror.example.com:5000/ns/ubi9/ubi-micro"}, report.ExtraRepositories)
+
+		reportPath, err := ex.writeVerifyReport(report)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(ex.Opts.Global.WorkingDir, verifyOutDir, verifyReportFile), reportPath)
+		data, err := os.ReadFile(reportPath)
+		assert.NoError(t, err)
+		var written verifyReport
+		assert.NoError(t, yaml.Unmarshal(data, &written))
+		assert.Equal(t, report, written)
+	})
+
+	t.Run("Testing verify - should skip the extra repositories when the catalog API is not available", func(t *testing.T) {
+		ex := newVerifySchema(t, []string{allImages[2].Destination, allImages[3].Destination}, map[string]string{
+			allImages[0].Destination: digestA,
+			allImages[1].Destination: digestA,
+		}, nil)
+		report, err := ex.verify(context.Background(), allImages)
+		assert.NoError(t, err)
+		assert.Len(t, report.Missing, 2)
+		assert.Empty(t, report.ExtraRepositories)
+	})
+
+	t.Run("Testing verify - should report the images which can not be checked and check the others", func(t *testing.T) {
+		ex := newVerifySchema(t, []string{allImages[1].Destination}, map[string]string{
+			"docker://localhost:55000/ubi9/ubi:latest":                    digestB,
+			"docker://localhost:55000/ubi9/ubi-minimal:latest":            digestB,
+			"docker://mirror.example.com:5000/ns/ubi9/ubi:latest":         digestB,
+			"docker://mirror.example.com:5000/ns/ubi9/ubi-minimal:latest": digestA,
+		}, []string{})
+		report, err := ex.verify(context.Background(), allImages)
+		assert.NoError(t, err)
+		assert.Equal(t, []verifyImage{{
+			Origin:         "quay.io/openshift-release-dev/ocp-release@sha256:" + digestA,
+			Destination:    "mirror.example.com:5000/ns/openshift-release-dev/ocp-release:4.16.3-x86_64",
+			ExpectedDigest: digestA,
+			Error:          "unable to get the digest of " + allImages[0].Destination + ": manifest unknown",
+		}}, report.Unchecked)
+		assert.Len(t, report.Missing, 1)
+		assert.Len(t, report.DigestMismatch, 1)
+	})
+
+	t.Run("Testing verify - should not list the repositories without --extra-repositories", func(t *testing.T) {
+		ex := newVerifySchema(t, []string{allImages[0].Destination, allImages[1].Destination, allImages[2].Destination, allImages[3].Destination}, nil,
+			[]string{"ns/ubi9/ubi-micro"})
+		ex.CheckExtraRepositories = false
+		report, err := ex.verify(context.Background(), allImages)
+		assert.NoError(t, err)
+		assert.Empty(t, report.ExtraRepositories)
+	})
+
+	t.Run("Testing ValidateVerify - should validate the arguments", func(t *testing.T) {
+		ex := newVerifySchema(t, nil, nil, nil)
+		ex.Opts.Global.WorkingDir = "file:///tmp/mirror"
+		assert.EqualError(t, ex.ValidateVerify([]string{"docker://mirror.example.com:5000"}), "use the --config flag it is mandatory")
+		ex.Opts.Global.ConfigPath = "isc.yaml"
+		assert.NoError(t, ex.ValidateVerify([]string{"docker://mirror.example.com:5000"}))
+		assert.EqualError(t, ex.ValidateVerify([]string{}), "the destination registry is missing in the command arguments")
+		assert.EqualError(t, ex.ValidateVerify([]string{"file:///tmp/mirror"}), "the destination registry argument must have a docker:// protocol prefix")
+		ex.Opts.Global.WorkingDir = "/tmp/mirror"
+		assert.EqualError(t, ex.ValidateVerify([]string{"docker://mirror.example.com:5000"}), "--workspace flag must have a file:// protocol prefix")
+		ex.Opts.Global.WorkingDir = ""
+		assert.EqualError(t, ex.ValidateVerify([]string{"docker://mirror.example.com:5000"}), "use the --workspace flag, it is mandatory when using the verify command")
+	})
+
+	t.Run("Testing DriftError - should be detected through wrapping", func(t *testing.T) {
+		err := fmt.Errorf("verify: %w", DriftErrorf("drifted"))
+		assert.ErrorIs(t, err, &DriftError{})
+		assert.NotErrorIs(t, fmt.Errorf("other"), &DriftError{})
+	})
+}
@@ -0,0 +1,18 @@
+// Subset of the Red Hat Advanced Cluster Management governance and placement APIs
+// https://github.com/open-cluster-management-io/config-policy-controller
+// https://github.com/open-cluster-management-io/governance-policy-propagator
+// https://github.com/open-cluster-management-io/api
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is group version used to register the policy objects
+	GroupVersion = schema.GroupVersion{Group: "policy.open-cluster-management.io", Version: "v1"}
+	// PlacementGroupVersion is group version used to register the placement objects
+	PlacementGroupVersion = schema.GroupVersion{Group: "cluster.open-cluster-management.io", Version: "v1beta1"}
+	// ClusterSetBindingGroupVersion is group version used to register the managed cluster set binding objects
+	ClusterSetBindingGroupVersion = schema.GroupVersion{Group: "cluster.open-cluster-management.io", Version: "v1beta2"}
+)
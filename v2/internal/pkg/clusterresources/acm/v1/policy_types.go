@@ -0,0 +1,126 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// RemediationAction describes whether to enforce or inform a policy.
+type RemediationAction string
+
+const (
+	Enforce RemediationAction = "enforce"
+	Inform  RemediationAction = "inform"
+)
+
+// ComplianceType describes whether objects must or must not exist on the managed cluster.
+type ComplianceType string
+
+const (
+	MustHave     ComplianceType = "musthave"
+	MustOnlyHave ComplianceType = "mustonlyhave"
+	MustNotHave  ComplianceType = "mustnothave"
+)
+
+// PolicyTemplate is a template of a policy, propagated to the managed clusters.
+type PolicyTemplate struct {
+	ObjectDefinition runtime.RawExtension `json:"objectDefinition"`
+}
+
+// PolicySpec defines the desired state of Policy.
+type PolicySpec struct {
+	Disabled          bool              `json:"disabled"`
+	RemediationAction RemediationAction `json:"remediationAction,omitempty"`
+	PolicyTemplates   []*PolicyTemplate `json:"policy-templates"`
+}
+
+// Policy is the Schema for the policies API.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              PolicySpec `json:"spec"`
+}
+
+// ObjectTemplate describes an object that is checked on the managed cluster.
+type ObjectTemplate struct {
+	ComplianceType   ComplianceType       `json:"complianceType"`
+	ObjectDefinition runtime.RawExtension `json:"objectDefinition"`
+}
+
+// ConfigurationPolicySpec defines the desired state of ConfigurationPolicy.
+type ConfigurationPolicySpec struct {
+	Severity          string            `json:"severity,omitempty"`
+	RemediationAction RemediationAction `json:"remediationAction"`
+	ObjectTemplates   []*ObjectTemplate `json:"object-templates"`
+}
+
+// ConfigurationPolicy is the Schema for the configurationpolicies API.
+type ConfigurationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ConfigurationPolicySpec `json:"spec"`
+}
+
+// PlacementSubject is a policy bound to a placement.
+type PlacementSubject struct {
+	APIGroup string `json:"apiGroup"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+}
+
+// PlacementRef is the placement the policies are bound to.
+type PlacementRef struct {
+	APIGroup string `json:"apiGroup"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+}
+
+// PlacementBinding is the Schema for the placementbindings API.
+type PlacementBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	PlacementRef      PlacementRef       `json:"placementRef"`
+	Subjects          []PlacementSubject `json:"subjects"`
+}
+
+// PlacementSpec defines the desired state of Placement.
+type PlacementSpec struct {
+	// ClusterSets are the cluster sets the clusters are selected from,
+	// which must be bound to the namespace of the placement.
+	ClusterSets []string `json:"clusterSets,omitempty"`
+	// Predicates select the clusters of the cluster sets.
+	Predicates []ClusterPredicate `json:"predicates,omitempty"`
+}
+
+// ClusterPredicate selects the clusters matching a cluster selector.
+type ClusterPredicate struct {
+	RequiredClusterSelector ClusterSelector `json:"requiredClusterSelector"`
+}
+
+// ClusterSelector selects the clusters by their labels.
+type ClusterSelector struct {
+	LabelSelector metav1.LabelSelector `json:"labelSelector"`
+}
+
+// Placement is the Schema for the placements API.
+type Placement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              PlacementSpec `json:"spec"`
+}
+
+// ManagedClusterSetBindingSpec defines the cluster set bound to the namespace.
+type ManagedClusterSetBindingSpec struct {
+	ClusterSet string `json:"clusterSet"`
+}
+
+// ManagedClusterSetBinding is the Schema for the managedclustersetbindings API.
+// It makes a cluster set available to the placements of its namespace,
+// and must be named after the cluster set.
+type ManagedClusterSetBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ManagedClusterSetBindingSpec `json:"spec"`
+}
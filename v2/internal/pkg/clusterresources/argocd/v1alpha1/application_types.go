@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// ApplicationSource contains the git repository and path of the application manifests.
+type ApplicationSource struct {
+	RepoURL        string `json:"repoURL"`
+	Path           string `json:"path,omitempty"`
+	TargetRevision string `json:"targetRevision,omitempty"`
+}
+
+// ApplicationDestination holds the cluster the application is deployed to.
+type ApplicationDestination struct {
+	Server string `json:"server,omitempty"`
+}
+
+// SyncPolicyAutomated controls the automatic sync of the application.
+type SyncPolicyAutomated struct {
+	Prune    bool `json:"prune,omitempty"`
+	SelfHeal bool `json:"selfHeal,omitempty"`
+}
+
+// SyncPolicy controls when a sync will be performed.
+type SyncPolicy struct {
+	Automated *SyncPolicyAutomated `json:"automated,omitempty"`
+}
+
+// ApplicationSpec represents the desired state of the application.
+type ApplicationSpec struct {
+	Project     string                 `json:"project"`
+	Source      ApplicationSource      `json:"source"`
+	Destination ApplicationDestination `json:"destination"`
+	SyncPolicy  *SyncPolicy            `json:"syncPolicy,omitempty"`
+}
+
+// Application is a definition of an Argo CD Application resource.
+type Application struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ApplicationSpec `json:"spec"`
+}
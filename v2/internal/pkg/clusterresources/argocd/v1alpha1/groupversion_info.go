@@ -0,0 +1,12 @@
+// Subset of the Argo CD Application API
+// https://github.com/argoproj/argo-cd/tree/master/pkg/apis/application/v1alpha1
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "argoproj.io", Version: "v1alpha1"}
+)
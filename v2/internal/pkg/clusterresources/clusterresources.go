@@ -6,13 +6,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"unicode"
 
+	"github.com/BurntSushi/toml"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
 	confv1 "github.com/openshift/api/config/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	cm "github.com/openshift/oc-mirror/v2/internal/pkg/api/kubernetes/core"
 	ofv1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1"
 	ofv1alpha1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1alpha1"
@@ -45,6 +51,8 @@ type ClusterResourcesGenerator struct {
 	WorkingDir       string
 	Config           v2alpha1.ImageSetConfiguration
 	LocalStorageFQDN string
+	// cumulatedImages are all the images mirrored so far to the destination, set by UpdateMirrorSetState
+	cumulatedImages []v2alpha1.CopyImageSchema
 }
 
 type imageMirrorsGeneratorMode int
@@ -62,17 +70,34 @@ const (
 	releaseCategory = iota
 	operatorCategory
 	genericCategory
+	sampleCategory
 
-	idmsFileName = "idms-oc-mirror.yaml"
-	itmsFileName = "itms-oc-mirror.yaml"
+	idmsFileName        = "idms-oc-mirror.yaml"
+	itmsFileName        = "itms-oc-mirror.yaml"
+	imageStreamFileName = "imagestreams-oc-mirror.yaml"
+	icspFileName        = "icsp-oc-mirror.yaml"
 )
 
 func (o *ClusterResourcesGenerator) IDMS_ITMSGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
-	if len(allRelatedImages) == 0 {
+	// the delta files only describe the images mirrored during this run
+	if len(allRelatedImages) > 0 {
+		if err := o.writeIDMS_ITMS(allRelatedImages, forceRepositoryScope, deltaDir); err != nil {
+			return err
+		}
+	}
+
+	// the IDMS and ITMS files describe all the images mirrored so far
+	cumulatedImages := o.cumulativeImages(allRelatedImages)
+	if len(cumulatedImages) == 0 {
 		o.Log.Info(emoji.PageFacingUp + " Nothing mirrored. Skipping IDMS and ITMS files generation.")
 		return nil
 	}
+	return o.writeIDMS_ITMS(cumulatedImages, forceRepositoryScope, "")
+}
 
+// writeIDMS_ITMS generates the IDMS and ITMS files for allRelatedImages, in the subDir of the cluster-resources.
+// The custom resources of the delta files are prefixed, so that applying them does not overwrite the cumulative ones.
+func (o *ClusterResourcesGenerator) writeIDMS_ITMS(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool, subDir string) error {
 	// byDigestMirrors
 	byDigestMirrors, err := o.generateImageMirrors(allRelatedImages, DigestsOnlyMode, forceRepositoryScope)
 	if err != nil {
@@ -91,8 +116,13 @@ func (o *ClusterResourcesGenerator) IDMS_ITMSGenerator(allRelatedImages []v2alph
 		if err != nil {
 			return err
 		}
+		if subDir == deltaDir {
+			for i := range idmsList {
+				idmsList[i].Name = deltaDir + "-" + idmsList[i].Name
+			}
+		}
 
-		err = writeMirrorSet(idmsList, o.WorkingDir, idmsFileName, o.Log)
+		err = writeMirrorSet(idmsList, o.WorkingDir, filepath.Join(subDir, idmsFileName), o.Log)
 		if err != nil {
 			return err
 		}
@@ -106,7 +136,12 @@ func (o *ClusterResourcesGenerator) IDMS_ITMSGenerator(allRelatedImages []v2alph
 		if err != nil {
 			return err
 		}
-		err = writeMirrorSet(itmsList, o.WorkingDir, itmsFileName, o.Log)
+		if subDir == deltaDir {
+			for i := range itmsList {
+				itmsList[i].Name = deltaDir + "-" + itmsList[i].Name
+			}
+		}
+		err = writeMirrorSet(itmsList, o.WorkingDir, filepath.Join(subDir, itmsFileName), o.Log)
 		if err != nil {
 			return err
 		}
@@ -116,6 +151,129 @@ func (o *ClusterResourcesGenerator) IDMS_ITMSGenerator(allRelatedImages []v2alph
 	return nil
 }
 
+// RegistriesConfGenerator generates, for all the images mirrored so far, a registries.conf drop-in
+// configuring the same mirrors as the IDMS and ITMS files, for podman, CRI-O, MicroShift or bastion hosts.
+func (o *ClusterResourcesGenerator) RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
+	cumulatedImages := o.cumulativeImages(allRelatedImages)
+	byDigestMirrors, err := o.generateImageMirrors(cumulatedImages, DigestsOnlyMode, forceRepositoryScope)
+	if err != nil {
+		return err
+	}
+	byTagMirrors, err := o.generateImageMirrors(cumulatedImages, TagsOnlyMode, forceRepositoryScope)
+	if err != nil {
+		return err
+	}
+	if len(byDigestMirrors) == 0 && len(byTagMirrors) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " Nothing mirrored. Skipping registries.conf file generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp + " Generating registries.conf file...")
+	registriesConf, err := generateRegistriesConf(byDigestMirrors, byTagMirrors)
+	if err != nil {
+		return err
+	}
+	registriesConfPath := filepath.Join(o.WorkingDir, clusterResourcesDir, registriesConfFileName)
+	if err := os.MkdirAll(filepath.Dir(registriesConfPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(registriesConfPath, registriesConf, 0644); err != nil {
+		return err
+	}
+	o.Log.Info("%s file created", registriesConfPath)
+	return nil
+}
+
+// generateRegistriesConf merges the mirrors by digest and by tag of all categories
+// in one [[registry]] entry per source. A mirror used both by digest and by tag can be pulled from in all cases.
+func generateRegistriesConf(byDigestMirrors, byTagMirrors []categorizedMirrors) ([]byte, error) {
+	pullFromMirrors := map[string]map[string]string{}
+	addMirrors := func(mirrorsByCategory []categorizedMirrors, pullFromMirror string) {
+		for _, catMirrors := range mirrorsByCategory {
+			for source, mirrors := range catMirrors.mirrors {
+				if _, ok := pullFromMirrors[source]; !ok {
+					pullFromMirrors[source] = map[string]string{}
+				}
+				for _, mirror := range mirrors {
+					if previous, ok := pullFromMirrors[source][string(mirror)]; ok && previous != pullFromMirror {
+						pullFromMirrors[source][string(mirror)] = sysregistriesv2.MirrorAll
+						continue
+					}
+					pullFromMirrors[source][string(mirror)] = pullFromMirror
+				}
+			}
+		}
+	}
+	addMirrors(byDigestMirrors, sysregistriesv2.MirrorByDigestOnly)
+	addMirrors(byTagMirrors, sysregistriesv2.MirrorByTagOnly)
+
+	registriesConf := struct {
+		Registries []sysregistriesv2.Registry `toml:"registry"`
+	}{}
+	for _, source := range slices.Sorted(maps.Keys(pullFromMirrors)) {
+		registry := sysregistriesv2.Registry{
+			Prefix:   source,
+			Endpoint: sysregistriesv2.Endpoint{Location: source},
+		}
+		for _, mirror := range slices.Sorted(maps.Keys(pullFromMirrors[source])) {
+			registry.Mirrors = append(registry.Mirrors, sysregistriesv2.Endpoint{
+				Location:       mirror,
+				PullFromMirror: pullFromMirrors[source][mirror],
+			})
+		}
+		registriesConf.Registries = append(registriesConf.Registries, registry)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(registriesConfHeader)
+	if err := toml.NewEncoder(&buf).Encode(registriesConf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ICSPGenerator generates, for all the images mirrored so far, the ImageContentSourcePolicy file
+// needed by clusters older than 4.13, which do not support IDMS and ITMS.
+// ImageContentSourcePolicy only applies to pulls by digest.
+func (o *ClusterResourcesGenerator) ICSPGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error {
+	cumulatedImages := o.cumulativeImages(allRelatedImages)
+	byDigestMirrors, err := o.generateImageMirrors(cumulatedImages, DigestsOnlyMode, forceRepositoryScope)
+	if err != nil {
+		return err
+	}
+	if len(byDigestMirrors) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No images by digests were mirrored. Skipping ICSP generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp + " Generating ICSP file...")
+	icspList := make([]operatorv1alpha1.ImageContentSourcePolicy, len(byDigestMirrors))
+	for index, catMirrors := range byDigestMirrors {
+		icspList[index] = operatorv1alpha1.ImageContentSourcePolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: operatorv1alpha1.GroupVersion.String(),
+				Kind:       "ImageContentSourcePolicy",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "icsp-" + catMirrors.category.toString() + "-0",
+			},
+			Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
+				RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{},
+			},
+		}
+		for _, source := range slices.Sorted(maps.Keys(catMirrors.mirrors)) {
+			rdm := operatorv1alpha1.RepositoryDigestMirrors{
+				Source: source,
+			}
+			for _, mirror := range catMirrors.mirrors[source] {
+				rdm.Mirrors = append(rdm.Mirrors, string(mirror))
+			}
+			icspList[index].Spec.RepositoryDigestMirrors = append(icspList[index].Spec.RepositoryDigestMirrors, rdm)
+		}
+	}
+	return writeMirrorSet(icspList, o.WorkingDir, icspFileName, o.Log)
+}
+
 func (o *ClusterResourcesGenerator) generateITMS(mirrorsByCategory []categorizedMirrors) ([]confv1.ImageTagMirrorSet, error) {
 	// fill itmsList content
 	itmsList := make([]confv1.ImageTagMirrorSet, len(mirrorsByCategory))
@@ -132,10 +290,10 @@ func (o *ClusterResourcesGenerator) generateITMS(mirrorsByCategory []categorized
 				ImageTagMirrors: []confv1.ImageTagMirrors{},
 			},
 		}
-		for source, imgMirrors := range catMirrors.mirrors {
+		for _, source := range slices.Sorted(maps.Keys(catMirrors.mirrors)) {
 			itm := confv1.ImageTagMirrors{
 				Source:  source,
-				Mirrors: imgMirrors,
+				Mirrors: catMirrors.mirrors[source],
 			}
 			itmsList[index].Spec.ImageTagMirrors = append(itmsList[index].Spec.ImageTagMirrors, itm)
 		}
@@ -144,7 +302,7 @@ func (o *ClusterResourcesGenerator) generateITMS(mirrorsByCategory []categorized
 	return itmsList, nil
 }
 
-func writeMirrorSet[T confv1.ImageDigestMirrorSet | confv1.ImageTagMirrorSet](mirrorSetsList []T, workingDir, fileName string, log clog.PluggableLoggerInterface) error {
+func writeMirrorSet[T confv1.ImageDigestMirrorSet | confv1.ImageTagMirrorSet | operatorv1alpha1.ImageContentSourcePolicy | imagev1.ImageStream](mirrorSetsList []T, workingDir, fileName string, log clog.PluggableLoggerInterface) error {
 	msFilePath := filepath.Join(workingDir, clusterResourcesDir, fileName)
 	msAggregation := []byte{}
 	var err error
@@ -156,6 +314,10 @@ func writeMirrorSet[T confv1.ImageDigestMirrorSet | confv1.ImageTagMirrorSet](mi
 			return fmt.Errorf("error while sanitizing the catalogSource object prior to marshalling: %v", err)
 		}
 		delete(unstructuredObj.Object["metadata"].(map[string]interface{}), "creationTimestamp")
+		// the status of an ImageStream is populated by the cluster
+		if _, ok := any(ms).(imagev1.ImageStream); ok {
+			delete(unstructuredObj.Object, "status")
+		}
 
 		msBytes, err := yaml.Marshal(unstructuredObj.Object)
 		if err != nil {
@@ -186,7 +348,10 @@ func writeMirrorSet[T confv1.ImageDigestMirrorSet | confv1.ImageTagMirrorSet](mi
 }
 
 func (o *ClusterResourcesGenerator) CatalogSourceGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
-	if len(o.Config.Mirror.Operators) == 0 {
+	// catalogs mirrored during previous runs are still served from the destination registry,
+	// until the delete workflow removes them
+	allRelatedImages = o.cumulativeImages(allRelatedImages)
+	if !slices.ContainsFunc(allRelatedImages, func(img v2alpha1.CopyImageSchema) bool { return img.Type == v2alpha1.TypeOperatorCatalog }) {
 		o.Log.Info(emoji.PageFacingUp + " No catalogs mirrored. Skipping CatalogSource file generation.")
 		return nil
 	}
@@ -213,7 +378,10 @@ func (o *ClusterResourcesGenerator) CatalogSourceGenerator(allRelatedImages []v2
 }
 
 func (o *ClusterResourcesGenerator) ClusterCatalogGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
-	if len(o.Config.Mirror.Operators) == 0 {
+	// catalogs mirrored during previous runs are still served from the destination registry,
+	// until the delete workflow removes them
+	allRelatedImages = o.cumulativeImages(allRelatedImages)
+	if !slices.ContainsFunc(allRelatedImages, func(img v2alpha1.CopyImageSchema) bool { return img.Type == v2alpha1.TypeOperatorCatalog }) {
 		o.Log.Info(emoji.PageFacingUp + " No catalogs mirrored. Skipping ClusterCatalog file generation.")
 		return nil
 	}
@@ -238,12 +406,80 @@ func (o *ClusterResourcesGenerator) ClusterCatalogGenerator(allRelatedImages []v
 	return nil
 }
 
+// ImageStreamGenerator generates the sample ImageStreams, with their DockerImage
+// tags pointing to the mirrored images. Tags for which no image was mirrored are dropped.
+func (o *ClusterResourcesGenerator) ImageStreamGenerator(imageStreams []imagev1.ImageStream, allRelatedImages []v2alpha1.CopyImageSchema) error {
+	if len(imageStreams) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No sample images mirrored. Skipping ImageStream file generation.")
+		return nil
+	}
+	o.Log.Info(emoji.PageFacingUp + " Generating ImageStream file...")
+
+	mirroredRefs := map[string]string{}
+	for _, copyImage := range allRelatedImages {
+		if copyImage.Type != v2alpha1.TypeSampleImage || strings.Contains(copyImage.Destination, o.LocalStorageFQDN) {
+			continue
+		}
+		mirroredRefs[strings.TrimPrefix(copyImage.Origin, dockerProtocol)] = strings.TrimPrefix(copyImage.Destination, dockerProtocol)
+	}
+
+	isList := []imagev1.ImageStream{}
+	for _, is := range imageStreams {
+		mirrored := *is.DeepCopy()
+		mirrored.Namespace = samplesNamespace
+		mirrored.Status = imagev1.ImageStreamStatus{}
+		tags := mirrored.Spec.Tags
+		mirrored.Spec.Tags = []imagev1.TagReference{}
+		for _, tag := range tags {
+			if tag.From == nil || tag.From.Kind != dockerImageKind {
+				continue
+			}
+			mirroredRef, ok := mirroredRefs[strings.TrimPrefix(tag.From.Name, dockerProtocol)]
+			if !ok {
+				o.Log.Warn("[ImageStreamGenerator] image %s of imagestream %s:%s was not mirrored : SKIPPING", tag.From.Name, is.Name, tag.Name)
+				continue
+			}
+			tag.From.Name = mirroredRef
+			mirrored.Spec.Tags = append(mirrored.Spec.Tags, tag)
+		}
+		// aliases are kept only when the tag they point to is kept
+		for _, tag := range tags {
+			if tag.From == nil || tag.From.Kind != imageStreamTagKind {
+				continue
+			}
+			target := strings.TrimPrefix(tag.From.Name, is.Name+":")
+			if slices.ContainsFunc(mirrored.Spec.Tags, func(t imagev1.TagReference) bool { return t.Name == target }) {
+				mirrored.Spec.Tags = append(mirrored.Spec.Tags, tag)
+			}
+		}
+		if len(mirrored.Spec.Tags) == 0 {
+			continue
+		}
+		if mirrored.Kind == "" {
+			mirrored.TypeMeta = metav1.TypeMeta{APIVersion: imagev1.GroupVersion.String(), Kind: imageStreamKind}
+		}
+		isList = append(isList, mirrored)
+	}
+
+	if len(isList) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No sample images mirrored. Skipping ImageStream file generation.")
+		return nil
+	}
+	return writeMirrorSet(isList, o.WorkingDir, imageStreamFileName, o.Log)
+}
+
 func (o *ClusterResourcesGenerator) getCSTemplate(catalogRef string) string {
 	for _, op := range o.Config.ImageSetConfigurationSpec.Mirror.Operators {
 		if strings.Contains(catalogRef, op.Catalog) {
 			return op.TargetCatalogSourceTemplate
 		}
 	}
+	// composite catalogs are built from a directory named after their targetCatalog
+	for _, composite := range o.Config.ImageSetConfigurationSpec.Mirror.CompositeCatalogs {
+		if strings.HasPrefix(catalogRef, dirProtocol) && strings.HasSuffix(catalogRef, "/"+composite.TargetCatalog) {
+			return composite.TargetCatalogSourceTemplate
+		}
+	}
 	return ""
 }
 
@@ -499,10 +735,10 @@ func (o *ClusterResourcesGenerator) generateIDMS(mirrorsByCategory []categorized
 				ImageDigestMirrors: []confv1.ImageDigestMirrors{},
 			},
 		}
-		for source, imgMirrors := range catMirrors.mirrors {
+		for _, source := range slices.Sorted(maps.Keys(catMirrors.mirrors)) {
 			idm := confv1.ImageDigestMirrors{
 				Source:  source,
-				Mirrors: imgMirrors,
+				Mirrors: catMirrors.mirrors[source],
 			}
 			idmsList[index].Spec.ImageDigestMirrors = append(idmsList[index].Spec.ImageDigestMirrors, idm)
 		}
@@ -586,8 +822,8 @@ func (o *ClusterResourcesGenerator) generateImageMirrors(allRelatedImages []v2al
 		}
 	}
 	categorizedMirrorsList := make([]categorizedMirrors, 0, len(mirrorsByCategory))
-	for _, mirrors := range mirrorsByCategory {
-		categorizedMirrorsList = append(categorizedMirrorsList, mirrors)
+	for _, category := range slices.Sorted(maps.Keys(mirrorsByCategory)) {
+		categorizedMirrorsList = append(categorizedMirrorsList, mirrorsByCategory[category])
 	}
 	return categorizedMirrorsList, nil
 }
@@ -684,6 +920,8 @@ func (m mirrorCategory) toString() string {
 		return "operator"
 	case genericCategory:
 		return "generic"
+	case sampleCategory:
+		return "sample"
 	default:
 		return "generic"
 	}
@@ -705,6 +943,8 @@ func imageTypeToCategory(imageType v2alpha1.ImageType) mirrorCategory {
 		return operatorCategory
 	case v2alpha1.TypeOperatorRelatedImage:
 		return operatorCategory
+	case v2alpha1.TypeSampleImage:
+		return sampleCategory
 	case v2alpha1.TypeInvalid:
 		return genericCategory
 	default:
@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
 	confv1 "github.com/openshift/api/config/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	cm "github.com/openshift/oc-mirror/v2/internal/pkg/api/kubernetes/core"
 	ofv1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1"
 	ofv1alpha1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1alpha1"
@@ -19,6 +24,7 @@ import (
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/otiai10/copy"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -254,14 +260,23 @@ func TestIDMS_ITMSGenerator(t *testing.T) {
 				t.Fatalf("output folder should exist")
 			}
 
-			msFiles, err := os.ReadDir(filepath.Join(workingDir, clusterResourcesDir))
+			entries, err := os.ReadDir(filepath.Join(workingDir, clusterResourcesDir))
 			if err != nil {
 				t.Fatalf("ls output folder should not fail")
 			}
+			msFiles := slices.DeleteFunc(entries, func(e os.DirEntry) bool { return e.IsDir() })
 
 			if len(msFiles) != testCase.expectedNumberFilesGenerated {
 				t.Fatalf("output folder should contain %d files, but found %d", testCase.expectedNumberFilesGenerated, len(msFiles))
 			}
+
+			deltaFiles, err := os.ReadDir(filepath.Join(workingDir, clusterResourcesDir, deltaDir))
+			if err != nil {
+				t.Fatalf("ls delta folder should not fail")
+			}
+			if len(deltaFiles) != testCase.expectedNumberFilesGenerated {
+				t.Fatalf("delta folder should contain %d files, but found %d", testCase.expectedNumberFilesGenerated, len(deltaFiles))
+			}
 			isIdmsFound := false
 			isItmsFound := false
 			for _, file := range msFiles {
@@ -565,6 +580,142 @@ func TestGenerateITMS(t *testing.T) {
 	}
 }
 
+func TestRegistriesConfGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	type testCase struct {
+		caseName           string
+		imgList            []v2alpha1.CopyImageSchema
+		expectedRegistries []sysregistriesv2.Registry
+	}
+	testCases := []testCase{
+		{
+			caseName: "Testing RegistriesConfGenerator - release use case : should pass",
+			imgList:  imageListRelease,
+			expectedRegistries: []sysregistriesv2.Registry{
+				{
+					Prefix:   "quay.io/openshift-release-dev/ocp-release",
+					Endpoint: sysregistriesv2.Endpoint{Location: "quay.io/openshift-release-dev/ocp-release"},
+					Mirrors: []sysregistriesv2.Endpoint{
+						{Location: "myregistry/mynamespace/openshift/release-images", PullFromMirror: sysregistriesv2.MirrorAll},
+					},
+				},
+				{
+					Prefix:   "quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+					Endpoint: sysregistriesv2.Endpoint{Location: "quay.io/openshift-release-dev/ocp-v4.0-art-dev"},
+					Mirrors: []sysregistriesv2.Endpoint{
+						{Location: "myregistry/mynamespace/openshift/release", PullFromMirror: sysregistriesv2.MirrorByDigestOnly},
+					},
+				},
+			},
+		},
+		{
+			caseName: "Testing RegistriesConfGenerator - same repository by tag and by digest : should pull all from mirror",
+			imgList: []v2alpha1.CopyImageSchema{
+				{
+					Source:      "docker://localhost:55000/ubi8/ubi:latest",
+					Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+					Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+					Type:        v2alpha1.TypeGeneric,
+				},
+				{
+					Source:      "docker://localhost:55000/ubi8/ubi-minimal@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+					Destination: "docker://myregistry/mynamespace/ubi8/ubi-minimal@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+					Origin:      "docker://registry.redhat.io/ubi8/ubi-minimal@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+					Type:        v2alpha1.TypeGeneric,
+				},
+			},
+			expectedRegistries: []sysregistriesv2.Registry{
+				{
+					Prefix:   "registry.redhat.io/ubi8",
+					Endpoint: sysregistriesv2.Endpoint{Location: "registry.redhat.io/ubi8"},
+					Mirrors: []sysregistriesv2.Endpoint{
+						{Location: "myregistry/mynamespace/ubi8", PullFromMirror: sysregistriesv2.MirrorAll},
+					},
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			workingDir := t.TempDir() + "/working-dir"
+			cr := &ClusterResourcesGenerator{
+				Log:              log,
+				WorkingDir:       workingDir,
+				LocalStorageFQDN: "localhost:55000",
+			}
+			err := cr.RegistriesConfGenerator(testCase.imgList, false)
+			if err != nil {
+				t.Fatalf("should not fail")
+			}
+			content, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, registriesConfFileName))
+			if err != nil {
+				t.Fatalf("registries.conf file should exist")
+			}
+			registriesConf := struct {
+				Registries []sysregistriesv2.Registry `toml:"registry"`
+			}{}
+			_, err = toml.Decode(string(content), &registriesConf)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedRegistries, registriesConf.Registries)
+		})
+	}
+}
+
+func TestICSPGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	t.Run("Testing ICSPGenerator - tags and digests : should only contain mirrors by digest", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.ICSPGenerator(imageListMixed, false)
+		if err != nil {
+			t.Fatalf("should not fail")
+		}
+		content, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, icspFileName))
+		if err != nil {
+			t.Fatalf("ICSP file should exist")
+		}
+		objs, err := readResources(filepath.Join(workingDir, clusterResourcesDir, icspFileName))
+		assert.NoError(t, err)
+		assert.Len(t, objs, 2)
+		icsp := operatorv1alpha1.ImageContentSourcePolicy{}
+		assert.NoError(t, yaml.Unmarshal(objs[1], &icsp))
+		assert.Equal(t, "icsp-operator-0", icsp.Name)
+		assert.Equal(t, []operatorv1alpha1.RepositoryDigestMirrors{
+			{Source: "quay.io/openshift-community-operators", Mirrors: []string{"myregistry/mynamespace/openshift-community-operators"}},
+			{Source: "registry.redhat.io", Mirrors: []string{"myregistry/mynamespace"}},
+		}, icsp.Spec.RepositoryDigestMirrors)
+		assert.NotContains(t, string(content), "registry.redhat.io/ubi8")
+	})
+
+	t.Run("Testing ICSPGenerator - tags only : should not generate ICSP", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.ICSPGenerator([]v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://localhost:55000/ubi8/ubi:latest",
+				Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+				Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+				Type:        v2alpha1.TypeGeneric,
+			},
+		}, false)
+		if err != nil {
+			t.Fatalf("should not fail")
+		}
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, icspFileName))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
 func TestCatalogSourceGenerator(t *testing.T) {
 	log := clog.New("trace")
 
@@ -1351,6 +1502,73 @@ func TestUpdateServiceGenerator(t *testing.T) {
 	})
 }
 
+func TestImageStreamGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	imageStreams := []imagev1.ImageStream{
+		{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "image.openshift.io/v1", Kind: "ImageStream"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ruby"},
+			Spec: imagev1.ImageStreamSpec{
+				Tags: []imagev1.TagReference{
+					{Name: "3.3-ubi9", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.access.redhat.com/ubi9/ruby-33:latest"}},
+					{Name: "3.1-ubi8", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.access.redhat.com/ubi8/ruby-31:latest"}},
+					{Name: "latest", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "ruby:3.3-ubi9"}},
+					{Name: "old", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "ruby:3.1-ubi8"}},
+				},
+			},
+		},
+	}
+	imageList := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:55000/ubi9/ruby-33:latest",
+			Destination: "docker://myregistry/mynamespace/ubi9/ruby-33:latest",
+			Origin:      "registry.access.redhat.com/ubi9/ruby-33:latest",
+			Type:        v2alpha1.TypeSampleImage,
+		},
+	}
+
+	t.Run("Testing ImageStreamGenerator - Disk to Mirror : should pass", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		workingDir := filepath.Join(tmpDir, "working-dir")
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.ImageStreamGenerator(imageStreams, imageList)
+		assert.NoError(t, err)
+
+		fileContents, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, imageStreamFileName))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(fileContents), "status")
+
+		actualIS := imagev1.ImageStream{}
+		err = yaml.Unmarshal(fileContents, &actualIS)
+		assert.NoError(t, err)
+		assert.Equal(t, "openshift", actualIS.Namespace)
+		assert.Equal(t, []imagev1.TagReference{
+			{Name: "3.3-ubi9", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "myregistry/mynamespace/ubi9/ruby-33:latest"}},
+			{Name: "latest", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "ruby:3.3-ubi9"}},
+		}, actualIS.Spec.Tags)
+		// the input imagestreams should not be modified
+		assert.Equal(t, "registry.access.redhat.com/ubi9/ruby-33:latest", imageStreams[0].Spec.Tags[0].From.Name)
+	})
+
+	t.Run("Testing ImageStreamGenerator - no imagestreams : should not generate file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		workingDir := filepath.Join(tmpDir, "working-dir")
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.ImageStreamGenerator([]imagev1.ImageStream{}, imageList)
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, imageStreamFileName))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
 func TestGenerateSignatureConfigMap(t *testing.T) {
 
 	t.Run("Testing configmap both yaml&json should pass", func(t *testing.T) {
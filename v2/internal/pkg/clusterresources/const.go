@@ -2,6 +2,8 @@ package clusterresources
 
 const (
 	clusterResourcesDir            string = "cluster-resources"
+	deltaDir                       string = "delta"
+	mirrorSetStateFile             string = "mirror-set-state.json"
 	updateServiceFilename          string = "updateService.yaml"
 	updateServiceResourceName      string = "update-service-oc-mirror"
 	updateServiceResourceKind      string = "UpdateService"
@@ -13,4 +15,40 @@ const (
 	signatureLabel                        = "release.openshift.io/verification-signatures"
 	signatureConfigMapMsg                 = "[GenerateSignatureConfigMap] %v"
 	signatureDir                          = "signatures"
+	samplesNamespace                      = "openshift"
+	dockerProtocol                        = "docker://"
+	dirProtocol                           = "dir://"
+	dockerImageKind                       = "DockerImage"
+	imageStreamTagKind                    = "ImageStreamTag"
+	imageStreamKind                       = "ImageStream"
+	kustomizationFileName                 = "kustomization.yaml"
+	kustomizeApiVersion                   = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind                     = "Kustomization"
+	gitOpsResourceName                    = "oc-mirror"
+	acmDir                                = "acm"
+	acmPolicyFileName                     = "policy-oc-mirror.yaml"
+	acmDefaultNamespace                   = "open-cluster-management-global-set"
+	acmPolicyName                         = "policy-oc-mirror"
+	acmPlacementName                      = "placement-oc-mirror"
+	acmPlacementBindingName               = "binding-policy-oc-mirror"
+	acmDefaultClusterSet                  = "global"
+	acmVendorLabel                        = "vendor"
+	acmOpenShiftVendor                    = "OpenShift"
+	acmPolicySeverity                     = "low"
+	policyKind                            = "Policy"
+	configurationPolicyKind               = "ConfigurationPolicy"
+	placementKind                         = "Placement"
+	placementBindingKind                  = "PlacementBinding"
+	managedClusterSetBindingKind          = "ManagedClusterSetBinding"
+	argoCDDir                             = "argocd"
+	argoCDApplicationFileName             = "application-oc-mirror.yaml"
+	argoCDDefaultNamespace                = "openshift-gitops"
+	argoCDDefaultProject                  = "default"
+	argoCDTargetRevision                  = "HEAD"
+	argoCDInClusterServer                 = "https://kubernetes.default.svc"
+	applicationKind                       = "Application"
+	registriesConfFileName                = "registries-oc-mirror.conf"
+	registriesConfHeader                  = "# Drop-in for /etc/containers/registries.conf.d, generated by oc-mirror\n\n"
+	installConfigSnippetFileName          = "install-config-snippet.yaml"
+	additionalTrustBundlePolicy           = "Always"
 )
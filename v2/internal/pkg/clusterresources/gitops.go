@@ -0,0 +1,335 @@
+package clusterresources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	acmv1 "github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources/acm/v1"
+	argocdv1alpha1 "github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources/argocd/v1alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// GitOpsKustomize generates a Kustomize base listing all the cluster resources
+	GitOpsKustomize = "kustomize"
+	// GitOpsACM generates the Kustomize base, and an ACM Policy wrapping the cluster resources
+	GitOpsACM = "acm"
+	// GitOpsArgoCD generates the Kustomize base, and an Argo CD Application syncing it
+	GitOpsArgoCD = "argocd"
+)
+
+// GitOpsFormats lists the supported values of GitOpsOptions.Format
+var GitOpsFormats = []string{GitOpsKustomize, GitOpsACM, GitOpsArgoCD}
+
+// GitOpsOptions describes the GitOps output generated next to the cluster resources.
+type GitOpsOptions struct {
+	Format     string // one of GitOpsFormats, no GitOps output when empty
+	Namespace  string // namespace of the ACM Policy or of the Argo CD Application
+	ClusterSet string // cluster set the ACM Placement selects the clusters from (acm only)
+	RepoURL    string // git repository the cluster resources are pushed to (argocd only)
+	RepoPath   string // path of the cluster resources in the git repository (argocd only)
+}
+
+type kustomization struct {
+	metav1.TypeMeta `json:",inline"`
+	Resources       []string `json:"resources"`
+}
+
+// GitOpsGenerator generates, from the cluster resources of the current run,
+// a Kustomize base that can be committed as is to a git repository synced by fleet tooling,
+// optionally wrapped in an ACM Policy or referenced by an Argo CD Application.
+func (o *ClusterResourcesGenerator) GitOpsGenerator(gitOps GitOpsOptions) error {
+	if gitOps.Format == "" {
+		return nil
+	}
+
+	crDir := filepath.Join(o.WorkingDir, clusterResourcesDir)
+	entries, err := os.ReadDir(crDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	resources := []string{}
+	for _, entry := range entries {
+		// the delta resources are a subset of the cumulative ones,
+		// the ICSP duplicates the IDMS for clusters that cannot use it,
+		// and the install-config snippet is not a cluster resource
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") || slices.Contains([]string{kustomizationFileName, icspFileName, installConfigSnippetFileName}, entry.Name()) {
+			continue
+		}
+		resources = append(resources, entry.Name())
+	}
+	if len(resources) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No cluster resources generated. Skipping GitOps output generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp+" Generating GitOps output (%s)...", gitOps.Format)
+	base := kustomization{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kustomizeApiVersion,
+			Kind:       kustomizationKind,
+		},
+		Resources: resources,
+	}
+	if err := writeResources(filepath.Join(crDir, kustomizationFileName), &base); err != nil {
+		return err
+	}
+
+	switch gitOps.Format {
+	case GitOpsKustomize:
+		return nil
+	case GitOpsACM:
+		return o.generateACMPolicy(crDir, resources, gitOps)
+	case GitOpsArgoCD:
+		return o.generateArgoCDApplication(crDir, gitOps)
+	default:
+		return fmt.Errorf("unsupported GitOps output %q: must be one of %s", gitOps.Format, strings.Join(GitOpsFormats, ", "))
+	}
+}
+
+func (o *ClusterResourcesGenerator) generateACMPolicy(crDir string, resources []string, gitOps GitOpsOptions) error {
+	namespace := gitOps.Namespace
+	if namespace == "" {
+		namespace = acmDefaultNamespace
+	}
+	clusterSet := gitOps.ClusterSet
+	if clusterSet == "" {
+		clusterSet = acmDefaultClusterSet
+	}
+
+	objectTemplates := []*acmv1.ObjectTemplate{}
+	for _, resource := range resources {
+		objs, err := readResources(filepath.Join(crDir, resource))
+		if err != nil {
+			return fmt.Errorf("unable to read cluster resource %s: %w", resource, err)
+		}
+		for _, obj := range objs {
+			objectTemplates = append(objectTemplates, &acmv1.ObjectTemplate{
+				ComplianceType:   acmv1.MustHave,
+				ObjectDefinition: runtime.RawExtension{Raw: obj},
+			})
+		}
+	}
+
+	configPolicy := acmv1.ConfigurationPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: acmv1.GroupVersion.String(),
+			Kind:       configurationPolicyKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: gitOpsResourceName,
+		},
+		Spec: acmv1.ConfigurationPolicySpec{
+			Severity:          acmPolicySeverity,
+			RemediationAction: acmv1.Enforce,
+			ObjectTemplates:   objectTemplates,
+		},
+	}
+	configPolicyObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&configPolicy)
+	if err != nil {
+		return fmt.Errorf("error while sanitizing the object prior to marshalling: %v", err)
+	}
+	delete(configPolicyObj["metadata"].(map[string]interface{}), "creationTimestamp")
+	configPolicyBytes, err := json.Marshal(configPolicyObj)
+	if err != nil {
+		return err
+	}
+
+	policy := acmv1.Policy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: acmv1.GroupVersion.String(),
+			Kind:       policyKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      acmPolicyName,
+			Namespace: namespace,
+		},
+		Spec: acmv1.PolicySpec{
+			RemediationAction: acmv1.Enforce,
+			PolicyTemplates: []*acmv1.PolicyTemplate{
+				{ObjectDefinition: runtime.RawExtension{Raw: configPolicyBytes}},
+			},
+		},
+	}
+	// the cluster set is bound to the namespace, so that the placement can select its clusters
+	clusterSetBinding := acmv1.ManagedClusterSetBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: acmv1.ClusterSetBindingGroupVersion.String(),
+			Kind:       managedClusterSetBindingKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterSet,
+			Namespace: namespace,
+		},
+		Spec: acmv1.ManagedClusterSetBindingSpec{ClusterSet: clusterSet},
+	}
+	// the mirror sets only apply to the OpenShift clusters
+	placement := acmv1.Placement{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: acmv1.PlacementGroupVersion.String(),
+			Kind:       placementKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      acmPlacementName,
+			Namespace: namespace,
+		},
+		Spec: acmv1.PlacementSpec{
+			ClusterSets: []string{clusterSet},
+			Predicates: []acmv1.ClusterPredicate{
+				{
+					RequiredClusterSelector: acmv1.ClusterSelector{
+						LabelSelector: metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: acmVendorLabel, Operator: metav1.LabelSelectorOpIn, Values: []string{acmOpenShiftVendor}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	binding := acmv1.PlacementBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: acmv1.GroupVersion.String(),
+			Kind:       placementBindingKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      acmPlacementBindingName,
+			Namespace: namespace,
+		},
+		PlacementRef: acmv1.PlacementRef{
+			APIGroup: acmv1.PlacementGroupVersion.Group,
+			Kind:     placementKind,
+			Name:     acmPlacementName,
+		},
+		Subjects: []acmv1.PlacementSubject{
+			{
+				APIGroup: acmv1.GroupVersion.Group,
+				Kind:     policyKind,
+				Name:     acmPolicyName,
+			},
+		},
+	}
+
+	policyPath := filepath.Join(crDir, acmDir, acmPolicyFileName)
+	if err := writeResources(policyPath, &policy, &clusterSetBinding, &placement, &binding); err != nil {
+		return err
+	}
+	o.Log.Info("%s file created", policyPath)
+	return nil
+}
+
+func (o *ClusterResourcesGenerator) generateArgoCDApplication(crDir string, gitOps GitOpsOptions) error {
+	if gitOps.RepoURL == "" {
+		return fmt.Errorf("the git repository URL is required to generate an Argo CD Application")
+	}
+	namespace := gitOps.Namespace
+	if namespace == "" {
+		namespace = argoCDDefaultNamespace
+	}
+	repoPath := gitOps.RepoPath
+	if repoPath == "" {
+		repoPath = clusterResourcesDir
+	}
+
+	application := argocdv1alpha1.Application{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: argocdv1alpha1.GroupVersion.String(),
+			Kind:       applicationKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gitOpsResourceName,
+			Namespace: namespace,
+		},
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Project: argoCDDefaultProject,
+			Source: argocdv1alpha1.ApplicationSource{
+				RepoURL:        gitOps.RepoURL,
+				Path:           repoPath,
+				TargetRevision: argoCDTargetRevision,
+			},
+			Destination: argocdv1alpha1.ApplicationDestination{
+				Server: argoCDInClusterServer,
+			},
+			SyncPolicy: &argocdv1alpha1.SyncPolicy{
+				// resources of previous runs are kept on the cluster, as the images they reference may still be in use
+				Automated: &argocdv1alpha1.SyncPolicyAutomated{SelfHeal: true},
+			},
+		},
+	}
+
+	applicationPath := filepath.Join(crDir, argoCDDir, argoCDApplicationFileName)
+	if err := writeResources(applicationPath, &application); err != nil {
+		return err
+	}
+	o.Log.Info("%s file created", applicationPath)
+	return nil
+}
+
+// readResources returns, as JSON, each of the objects found in a (multi-document) YAML file
+func readResources(path string) ([][]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objs := [][]byte{}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), len(content))
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objBytes, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, objBytes)
+	}
+	return objs, nil
+}
+
+// writeResources writes objs (pointers to API objects) to path as a multi-document YAML file
+func writeResources(path string, objs ...interface{}) error {
+	content := []byte{}
+	for i, obj := range objs {
+		unstructuredObj := unstructured.Unstructured{}
+		var err error
+		unstructuredObj.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("error while sanitizing the object prior to marshalling: %v", err)
+		}
+		if metadata, ok := unstructuredObj.Object["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "creationTimestamp")
+		}
+		objBytes, err := yaml.Marshal(unstructuredObj.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			content = append(content, []byte("---\n")...)
+		}
+		content = append(content, objBytes...)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
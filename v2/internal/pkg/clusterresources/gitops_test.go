@@ -0,0 +1,153 @@
+package clusterresources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	acmv1 "github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources/acm/v1"
+	argocdv1alpha1 "github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources/argocd/v1alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGitOpsGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	imageList := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Destination: "docker://myregistry/mynamespace/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Type:        v2alpha1.TypeOCPReleaseContent,
+		},
+		{
+			Source:      "docker://localhost:55000/ubi8/ubi:latest",
+			Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+
+	setup := func(t *testing.T) (*ClusterResourcesGenerator, string) {
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.IDMS_ITMSGenerator(imageList, false)
+		assert.NoError(t, err)
+		return cr, filepath.Join(workingDir, clusterResourcesDir)
+	}
+
+	t.Run("Testing GitOpsGenerator - no output : should not generate a kustomization", func(t *testing.T) {
+		cr, crDir := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{})
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(crDir, kustomizationFileName))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Testing GitOpsGenerator - kustomize : should list all cluster resources", func(t *testing.T) {
+		cr, crDir := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsKustomize})
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(crDir, kustomizationFileName))
+		assert.NoError(t, err)
+		base := kustomization{}
+		assert.NoError(t, yaml.Unmarshal(content, &base))
+		assert.Equal(t, kustomizationKind, base.Kind)
+		assert.Equal(t, []string{idmsFileName, itmsFileName}, base.Resources)
+
+		// running again should not list the kustomization itself
+		err = cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsKustomize})
+		assert.NoError(t, err)
+		content2, err := os.ReadFile(filepath.Join(crDir, kustomizationFileName))
+		assert.NoError(t, err)
+		assert.Equal(t, content, content2)
+	})
+
+	t.Run("Testing GitOpsGenerator - acm : should wrap all cluster resources in a policy", func(t *testing.T) {
+		cr, crDir := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsACM})
+		assert.NoError(t, err)
+
+		objs, err := readResources(filepath.Join(crDir, acmDir, acmPolicyFileName))
+		assert.NoError(t, err)
+		assert.Len(t, objs, 4)
+
+		policy := acmv1.Policy{}
+		assert.NoError(t, yaml.Unmarshal(objs[0], &policy))
+		assert.Equal(t, acmPolicyName, policy.Name)
+		assert.Equal(t, acmDefaultNamespace, policy.Namespace)
+		assert.Len(t, policy.Spec.PolicyTemplates, 1)
+		configPolicy := acmv1.ConfigurationPolicy{}
+		assert.NoError(t, yaml.Unmarshal(policy.Spec.PolicyTemplates[0].ObjectDefinition.Raw, &configPolicy))
+		assert.Equal(t, configurationPolicyKind, configPolicy.Kind)
+		assert.Len(t, configPolicy.Spec.ObjectTemplates, 2)
+		assert.Contains(t, string(configPolicy.Spec.ObjectTemplates[0].ObjectDefinition.Raw), `"kind":"ImageDigestMirrorSet"`)
+		assert.Contains(t, string(configPolicy.Spec.ObjectTemplates[1].ObjectDefinition.Raw), `"kind":"ImageTagMirrorSet"`)
+
+		clusterSetBinding := acmv1.ManagedClusterSetBinding{}
+		assert.NoError(t, yaml.Unmarshal(objs[1], &clusterSetBinding))
+		assert.Equal(t, managedClusterSetBindingKind, clusterSetBinding.Kind)
+		assert.Equal(t, acmDefaultClusterSet, clusterSetBinding.Name)
+		assert.Equal(t, acmDefaultNamespace, clusterSetBinding.Namespace)
+		assert.Equal(t, acmDefaultClusterSet, clusterSetBinding.Spec.ClusterSet)
+
+		placement := acmv1.Placement{}
+		assert.NoError(t, yaml.Unmarshal(objs[2], &placement))
+		assert.Equal(t, []string{acmDefaultClusterSet}, placement.Spec.ClusterSets)
+		assert.Len(t, placement.Spec.Predicates, 1)
+		assert.Equal(t, []string{acmOpenShiftVendor}, placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].Values)
+
+		binding := acmv1.PlacementBinding{}
+		assert.NoError(t, yaml.Unmarshal(objs[3], &binding))
+		assert.Equal(t, acmPlacementName, binding.PlacementRef.Name)
+		assert.Equal(t, acmPolicyName, binding.Subjects[0].Name)
+	})
+
+	t.Run("Testing GitOpsGenerator - acm : should bind the cluster set to the namespace", func(t *testing.T) {
+		cr, crDir := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsACM, Namespace: "mirror-policies", ClusterSet: "disconnected"})
+		assert.NoError(t, err)
+
+		objs, err := readResources(filepath.Join(crDir, acmDir, acmPolicyFileName))
+		assert.NoError(t, err)
+		clusterSetBinding := acmv1.ManagedClusterSetBinding{}
+		assert.NoError(t, yaml.Unmarshal(objs[1], &clusterSetBinding))
+		assert.Equal(t, "disconnected", clusterSetBinding.Name)
+		assert.Equal(t, "mirror-policies", clusterSetBinding.Namespace)
+		assert.Equal(t, "disconnected", clusterSetBinding.Spec.ClusterSet)
+		placement := acmv1.Placement{}
+		assert.NoError(t, yaml.Unmarshal(objs[2], &placement))
+		assert.Equal(t, "mirror-policies", placement.Namespace)
+		assert.Equal(t, []string{"disconnected"}, placement.Spec.ClusterSets)
+	})
+
+	t.Run("Testing GitOpsGenerator - argocd : should generate an application", func(t *testing.T) {
+		cr, crDir := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsArgoCD, RepoURL: "https://git.example.com/fleet/mirror.git", RepoPath: "hub/mirror"})
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(crDir, argoCDDir, argoCDApplicationFileName))
+		assert.NoError(t, err)
+		application := argocdv1alpha1.Application{}
+		assert.NoError(t, yaml.Unmarshal(content, &application))
+		assert.Equal(t, argoCDDefaultNamespace, application.Namespace)
+		assert.Equal(t, "https://git.example.com/fleet/mirror.git", application.Spec.Source.RepoURL)
+		assert.Equal(t, "hub/mirror", application.Spec.Source.Path)
+		_, err = os.Stat(filepath.Join(crDir, kustomizationFileName))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Testing GitOpsGenerator - argocd without repository : should fail", func(t *testing.T) {
+		cr, _ := setup(t)
+		err := cr.GitOpsGenerator(GitOpsOptions{Format: GitOpsArgoCD})
+		assert.EqualError(t, err, "the git repository URL is required to generate an Argo CD Application")
+	})
+}
@@ -0,0 +1,159 @@
+package clusterresources
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	"sigs.k8s.io/yaml"
+)
+
+// InstallConfigOptions describes the mirrored content referenced by the install-config snippet.
+type InstallConfigOptions struct {
+	ReleaseImage          string // mirrored release image
+	GraphImage            string // mirrored graph data image, if any
+	AdditionalTrustBundle string // path to the PEM encoded CA bundle of the mirror registry, if any
+	ForceRepositoryScope  bool
+}
+
+// imageDigestSource is the install-config counterpart of an ImageDigestMirrors entry.
+type imageDigestSource struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors"`
+}
+
+type installConfigSnippet struct {
+	AdditionalTrustBundlePolicy string              `json:"additionalTrustBundlePolicy,omitempty"`
+	AdditionalTrustBundle       string              `json:"additionalTrustBundle,omitempty"`
+	ImageDigestSources          []imageDigestSource `json:"imageDigestSources"`
+}
+
+// InstallConfigGenerator generates the install-config-snippet.yaml file, holding the
+// install-config.yaml fields needed for a disconnected install from the mirrored releases.
+// The mirrored release image, and the day-2 update service and catalog references are
+// added as comments, as install-config.yaml does not have fields for them.
+func (o *ClusterResourcesGenerator) InstallConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema, installConfig InstallConfigOptions) error {
+	cumulatedImages := o.cumulativeImages(allRelatedImages)
+	// the graph data image is built by oc-mirror, it is not pulled by the installer
+	releaseImages := slices.DeleteFunc(slices.Clone(cumulatedImages), func(img v2alpha1.CopyImageSchema) bool {
+		return imageTypeToCategory(img.Type) != releaseCategory || img.Type == v2alpha1.TypeCincinnatiGraph
+	})
+	if len(releaseImages) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No release mirrored. Skipping install-config snippet generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp + " Generating install-config snippet file...")
+	snippet := installConfigSnippet{}
+	// the installer pulls the release payload by digest, whether the release was mirrored by tag or by digest
+	mirrors := map[string][]string{}
+	for _, mode := range []imageMirrorsGeneratorMode{DigestsOnlyMode, TagsOnlyMode} {
+		mirrorsByCategory, err := o.generateImageMirrors(releaseImages, mode, installConfig.ForceRepositoryScope)
+		if err != nil {
+			return err
+		}
+		for _, catMirrors := range mirrorsByCategory {
+			for source, sourceMirrors := range catMirrors.mirrors {
+				for _, mirror := range sourceMirrors {
+					if !slices.Contains(mirrors[source], string(mirror)) {
+						mirrors[source] = append(mirrors[source], string(mirror))
+					}
+				}
+			}
+		}
+	}
+	for _, source := range slices.Sorted(maps.Keys(mirrors)) {
+		snippet.ImageDigestSources = append(snippet.ImageDigestSources, imageDigestSource{Source: source, Mirrors: mirrors[source]})
+	}
+
+	if installConfig.AdditionalTrustBundle != "" {
+		trustBundle, err := os.ReadFile(installConfig.AdditionalTrustBundle)
+		if err != nil {
+			return fmt.Errorf("unable to read the additional trust bundle: %w", err)
+		}
+		if !strings.Contains(string(trustBundle), "-----BEGIN CERTIFICATE-----") {
+			return fmt.Errorf("the additional trust bundle %s does not contain any PEM encoded certificate", installConfig.AdditionalTrustBundle)
+		}
+		// the mirror registry is also pulled from by the cluster workloads (update service, catalogs)
+		snippet.AdditionalTrustBundlePolicy = additionalTrustBundlePolicy
+		snippet.AdditionalTrustBundle = string(trustBundle)
+	}
+
+	snippetBytes, err := yaml.Marshal(snippet)
+	if err != nil {
+		return err
+	}
+	header, err := installConfigHeader(cumulatedImages, installConfig)
+	if err != nil {
+		return err
+	}
+
+	snippetPath := filepath.Join(o.WorkingDir, clusterResourcesDir, installConfigSnippetFileName)
+	if err := os.MkdirAll(filepath.Dir(snippetPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(snippetPath, append([]byte(header), snippetBytes...), 0644); err != nil {
+		return err
+	}
+	o.Log.Info("%s file created", snippetPath)
+	return nil
+}
+
+// installConfigHeader lists, as YAML comments, the mirrored references that have no install-config.yaml field
+func installConfigHeader(cumulatedImages []v2alpha1.CopyImageSchema, installConfig InstallConfigOptions) (string, error) {
+	var header strings.Builder
+	header.WriteString("# install-config.yaml fields for a disconnected install, generated by oc-mirror\n")
+	if installConfig.ReleaseImage != "" {
+		releaseImage, err := image.ParseRef(installConfig.ReleaseImage)
+		if err != nil {
+			return "", err
+		}
+		header.WriteString("#\n# Mirrored release image (OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE):\n")
+		header.WriteString("#   " + releaseImage.Reference + "\n")
+	}
+
+	day2 := []string{}
+	if installConfig.GraphImage != "" && installConfig.ReleaseImage != "" {
+		graphImage, err := image.ParseRef(installConfig.GraphImage)
+		if err != nil {
+			return "", err
+		}
+		releaseImage, err := image.ParseRef(installConfig.ReleaseImage)
+		if err != nil {
+			return "", err
+		}
+		day2 = append(day2,
+			"UpdateService ("+updateServiceFilename+"):",
+			"  releases: "+releaseImage.Name,
+			"  graphDataImage: "+graphImage.Reference,
+		)
+	}
+	catalogs := []string{}
+	for _, img := range cumulatedImages {
+		if img.Type != v2alpha1.TypeOperatorCatalog {
+			continue
+		}
+		catalog, err := image.ParseRef(img.Destination)
+		if err != nil {
+			return "", err
+		}
+		catalogs = append(catalogs, "  "+catalog.Reference)
+	}
+	if len(catalogs) > 0 {
+		day2 = append(day2, "Catalogs (CatalogSource and ClusterCatalog files):")
+		day2 = append(day2, catalogs...)
+	}
+	if len(day2) > 0 {
+		header.WriteString("#\n# Day-2 references, applied from the other cluster resources once the cluster is installed:\n")
+		for _, line := range day2 {
+			header.WriteString("#   " + line + "\n")
+		}
+	}
+	return header.String(), nil
+}
@@ -0,0 +1,82 @@
+package clusterresources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestInstallConfigGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	caBundle := "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUYWJj\n-----END CERTIFICATE-----\n"
+	catalog := v2alpha1.CopyImageSchema{
+		Source:      "docker://localhost:55000/redhat/redhat-operator-index:v4.14",
+		Destination: "docker://myregistry/mynamespace/redhat/redhat-operator-index:v4.14",
+		Origin:      "docker://registry.redhat.io/redhat/redhat-operator-index:v4.14",
+		Type:        v2alpha1.TypeOperatorCatalog,
+	}
+
+	t.Run("Testing InstallConfigGenerator - release, graph and catalog : should pass", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		workingDir := filepath.Join(tmpDir, "working-dir")
+		caPath := filepath.Join(tmpDir, "ca.crt")
+		assert.NoError(t, os.WriteFile(caPath, []byte(caBundle), 0644))
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.InstallConfigGenerator(append(imageListRelease, catalog), InstallConfigOptions{
+			ReleaseImage:          "docker://myregistry/mynamespace/openshift/release-images:4.14.38-x86_64",
+			GraphImage:            "docker://myregistry/mynamespace/openshift/graph-image:latest",
+			AdditionalTrustBundle: caPath,
+		})
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, installConfigSnippetFileName))
+		assert.NoError(t, err)
+		snippet := installConfigSnippet{}
+		assert.NoError(t, yaml.Unmarshal(content, &snippet))
+		assert.Equal(t, []imageDigestSource{
+			{Source: "quay.io/openshift-release-dev/ocp-release", Mirrors: []string{"myregistry/mynamespace/openshift/release-images"}},
+			{Source: "quay.io/openshift-release-dev/ocp-v4.0-art-dev", Mirrors: []string{"myregistry/mynamespace/openshift/release"}},
+		}, snippet.ImageDigestSources)
+		assert.Equal(t, caBundle, snippet.AdditionalTrustBundle)
+		assert.Equal(t, additionalTrustBundlePolicy, snippet.AdditionalTrustBundlePolicy)
+		assert.Contains(t, string(content), "#   myregistry/mynamespace/openshift/release-images:4.14.38-x86_64\n")
+		assert.Contains(t, string(content), "#     graphDataImage: myregistry/mynamespace/openshift/graph-image:latest\n")
+		assert.Contains(t, string(content), "#     myregistry/mynamespace/redhat/redhat-operator-index:v4.14\n")
+	})
+
+	t.Run("Testing InstallConfigGenerator - invalid trust bundle : should fail", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		caPath := filepath.Join(tmpDir, "ca.crt")
+		assert.NoError(t, os.WriteFile(caPath, []byte("not a certificate"), 0644))
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       filepath.Join(tmpDir, "working-dir"),
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.InstallConfigGenerator(imageListRelease, InstallConfigOptions{AdditionalTrustBundle: caPath})
+		assert.ErrorContains(t, err, "does not contain any PEM encoded certificate")
+	})
+
+	t.Run("Testing InstallConfigGenerator - no release : should not generate the snippet", func(t *testing.T) {
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+		}
+		err := cr.InstallConfigGenerator([]v2alpha1.CopyImageSchema{catalog}, InstallConfigOptions{})
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, installConfigSnippetFileName))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
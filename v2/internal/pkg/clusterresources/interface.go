@@ -1,13 +1,20 @@
 package clusterresources
 
 import (
+	imagev1 "github.com/openshift/api/image/v1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 )
 
 type GeneratorInterface interface {
+	UpdateMirrorSetState(destination string, allRelatedImages []v2alpha1.CopyImageSchema) error
 	IDMS_ITMSGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error
+	RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error
+	ICSPGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) error
 	UpdateServiceGenerator(graphImage, releaseImage string) error
+	InstallConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema, installConfig InstallConfigOptions) error
 	CatalogSourceGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
 	GenerateSignatureConfigMap(allRelatedImages []v2alpha1.CopyImageSchema) error
 	ClusterCatalogGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
+	ImageStreamGenerator(imageStreams []imagev1.ImageStream, allRelatedImages []v2alpha1.CopyImageSchema) error
+	GitOpsGenerator(gitOps GitOpsOptions) error
 }
@@ -0,0 +1,148 @@
+package clusterresources
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// mirroredImage is an image mirrored to the destination registry,
+// as recorded in the mirror-set state.
+type mirroredImage struct {
+	Origin      string             `json:"origin"`
+	Destination string             `json:"destination"`
+	Type        v2alpha1.ImageType `json:"type"`
+}
+
+// mirrorSetState records, by destination, all the images mirrored across runs,
+// so that the mirror sets generated for a destination describe all of them,
+// and not only the ones mirrored during the current run.
+type mirrorSetState struct {
+	Destinations map[string][]mirroredImage `json:"destinations"`
+}
+
+func loadMirrorSetState(workingDir string) (mirrorSetState, error) {
+	state := mirrorSetState{Destinations: map[string][]mirroredImage{}}
+	content, err := os.ReadFile(filepath.Join(workingDir, mirrorSetStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return state, err
+	}
+	if state.Destinations == nil {
+		state.Destinations = map[string][]mirroredImage{}
+	}
+	return state, nil
+}
+
+func saveMirrorSetState(workingDir string, state mirrorSetState) error {
+	// sorted, so that the generated resources do not depend on the order of the runs
+	for _, images := range state.Destinations {
+		slices.SortFunc(images, func(a, b mirroredImage) int {
+			if c := strings.Compare(a.Destination, b.Destination); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Origin, b.Origin)
+		})
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(workingDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(workingDir, mirrorSetStateFile), content, 0644)
+}
+
+// stateKey returns the key of a destination in the mirror-set state: docker://myregistry/ns/
+// and myregistry/ns are the same destination
+func stateKey(destination string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(destination, dockerProtocol), "/")
+}
+
+// UpdateMirrorSetState adds the images mirrored to destination during the current run
+// to the mirror-set state, once per run, and keeps all the images mirrored so far to
+// destination for the IDMS, ITMS, ICSP, registries.conf and install-config generators.
+func (o *ClusterResourcesGenerator) UpdateMirrorSetState(destination string, allRelatedImages []v2alpha1.CopyImageSchema) error {
+	state, err := loadMirrorSetState(o.WorkingDir)
+	if err != nil {
+		return err
+	}
+	key := stateKey(destination)
+	known := map[mirroredImage]struct{}{}
+	for _, img := range state.Destinations[key] {
+		known[img] = struct{}{}
+	}
+	for _, img := range allRelatedImages {
+		// images copied to the local cache (mirrorToMirror) are not on the destination registry
+		if img.Origin == "" || (o.LocalStorageFQDN != "" && strings.Contains(img.Destination, o.LocalStorageFQDN)) {
+			continue
+		}
+		imgType := img.Type
+		if imgType == v2alpha1.TypeInvalid {
+			imgType = v2alpha1.TypeGeneric
+		}
+		mirrored := mirroredImage{Origin: img.Origin, Destination: img.Destination, Type: imgType}
+		if _, ok := known[mirrored]; !ok {
+			known[mirrored] = struct{}{}
+			state.Destinations[key] = append(state.Destinations[key], mirrored)
+		}
+	}
+	if err := saveMirrorSetState(o.WorkingDir, state); err != nil {
+		return err
+	}
+
+	o.cumulatedImages = make([]v2alpha1.CopyImageSchema, 0, len(state.Destinations[key]))
+	for _, img := range state.Destinations[key] {
+		o.cumulatedImages = append(o.cumulatedImages, v2alpha1.CopyImageSchema{
+			Source:      img.Destination,
+			Destination: img.Destination,
+			Origin:      img.Origin,
+			Type:        img.Type,
+		})
+	}
+	return nil
+}
+
+// cumulativeImages returns all the images mirrored so far to the destination of the run,
+// or allRelatedImages when the mirror-set state was not updated
+func (o *ClusterResourcesGenerator) cumulativeImages(allRelatedImages []v2alpha1.CopyImageSchema) []v2alpha1.CopyImageSchema {
+	if o.cumulatedImages == nil {
+		return allRelatedImages
+	}
+	return o.cumulatedImages
+}
+
+// RemoveFromMirrorSetState removes the images deleted from the destination registry
+// from the mirror-set state found in workingDir, so that the next cluster resources
+// generated do not reference them anymore.
+func RemoveFromMirrorSetState(workingDir string, deletedImages []v2alpha1.CopyImageSchema) error {
+	if _, err := os.Stat(filepath.Join(workingDir, mirrorSetStateFile)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	state, err := loadMirrorSetState(workingDir)
+	if err != nil {
+		return err
+	}
+	deleted := map[string]struct{}{}
+	for _, img := range deletedImages {
+		deleted[img.Destination] = struct{}{}
+	}
+	for key, images := range state.Destinations {
+		state.Destinations[key] = slices.DeleteFunc(images, func(img mirroredImage) bool {
+			_, ok := deleted[img.Destination]
+			return ok
+		})
+	}
+	return saveMirrorSetState(workingDir, state)
+}
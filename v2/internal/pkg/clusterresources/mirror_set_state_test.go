@@ -0,0 +1,171 @@
+package clusterresources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMirrorSetState(t *testing.T) {
+	log := clog.New("trace")
+	workingDir := filepath.Join(t.TempDir(), "working-dir")
+
+	cr := &ClusterResourcesGenerator{
+		Log:              log,
+		WorkingDir:       workingDir,
+		LocalStorageFQDN: "localhost:55000",
+	}
+
+	firstRun := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Destination: "docker://myregistry/mynamespace/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Type:        v2alpha1.TypeOCPReleaseContent,
+		},
+		{
+			Source:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Destination: "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+			Type:        v2alpha1.TypeOCPReleaseContent,
+		},
+	}
+	secondRun := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:55000/ubi8/ubi:latest",
+			Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+
+	const destination = "docker://myregistry/mynamespace"
+	err := cr.UpdateMirrorSetState(destination, firstRun)
+	assert.NoError(t, err)
+	err = cr.IDMS_ITMSGenerator(firstRun, false)
+	assert.NoError(t, err)
+	err = os.RemoveAll(filepath.Join(workingDir, clusterResourcesDir))
+	assert.NoError(t, err)
+	err = cr.UpdateMirrorSetState(destination, secondRun)
+	assert.NoError(t, err)
+	err = cr.IDMS_ITMSGenerator(secondRun, false)
+	assert.NoError(t, err)
+
+	t.Run("cumulative resources describe all runs", func(t *testing.T) {
+		_, err := os.Stat(filepath.Join(workingDir, clusterResourcesDir, "idms-oc-mirror.yaml"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, "itms-oc-mirror.yaml"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("delta resources describe the current run only", func(t *testing.T) {
+		_, err := os.Stat(filepath.Join(workingDir, clusterResourcesDir, deltaDir, "idms-oc-mirror.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		content, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, deltaDir, "itms-oc-mirror.yaml"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "name: delta-itms-generic-0")
+	})
+
+	t.Run("state is sorted and excludes the local cache", func(t *testing.T) {
+		state, err := loadMirrorSetState(workingDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []mirroredImage{
+			{
+				Origin:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+				Destination: "docker://myregistry/mynamespace/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+				Type:        v2alpha1.TypeOCPReleaseContent,
+			},
+			{
+				Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+				Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+				Type:        v2alpha1.TypeGeneric,
+			},
+		}, state.Destinations["myregistry/mynamespace"])
+	})
+
+	t.Run("resources of another destination do not describe the images of the first one", func(t *testing.T) {
+		otherWorkingDir := filepath.Join(t.TempDir(), "working-dir")
+		other := &ClusterResourcesGenerator{Log: log, WorkingDir: otherWorkingDir, LocalStorageFQDN: "localhost:55000"}
+		assert.NoError(t, other.UpdateMirrorSetState(destination, firstRun))
+		otherRun := []v2alpha1.CopyImageSchema{{
+			Source:      "docker://localhost:55000/ubi8/ubi:latest",
+			Destination: "docker://otherregistry/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		}}
+		assert.NoError(t, other.UpdateMirrorSetState("docker://otherregistry", otherRun))
+		assert.NoError(t, other.IDMS_ITMSGenerator(otherRun, false))
+		_, err := os.Stat(filepath.Join(otherWorkingDir, clusterResourcesDir, "idms-oc-mirror.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		content, err := os.ReadFile(filepath.Join(otherWorkingDir, clusterResourcesDir, "itms-oc-mirror.yaml"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "- otherregistry/ubi8\n")
+		assert.NotContains(t, string(content), "myregistry")
+	})
+
+	t.Run("deleted images are removed from the state", func(t *testing.T) {
+		err := RemoveFromMirrorSetState(workingDir, secondRun)
+		assert.NoError(t, err)
+		err = os.RemoveAll(filepath.Join(workingDir, clusterResourcesDir))
+		assert.NoError(t, err)
+		err = cr.UpdateMirrorSetState(destination, []v2alpha1.CopyImageSchema{})
+		assert.NoError(t, err)
+		err = cr.IDMS_ITMSGenerator([]v2alpha1.CopyImageSchema{}, false)
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, "itms-oc-mirror.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		content, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, "idms-oc-mirror.yaml"))
+		assert.NoError(t, err)
+		idms := map[string]interface{}{}
+		assert.NoError(t, yaml.Unmarshal(content, &idms))
+	})
+}
+
+func TestCumulativeCatalogSources(t *testing.T) {
+	log := clog.New("trace")
+	workingDir := filepath.Join(t.TempDir(), "working-dir")
+	cr := &ClusterResourcesGenerator{Log: log, WorkingDir: workingDir, LocalStorageFQDN: "localhost:55000"}
+	const destination = "docker://myregistry/mynamespace"
+	catalog := v2alpha1.CopyImageSchema{
+		Source:      "docker://localhost:55000/redhat/redhat-operator-index:v4.16",
+		Destination: "docker://myregistry/mynamespace/redhat/redhat-operator-index:v4.16",
+		Origin:      "docker://registry.redhat.io/redhat/redhat-operator-index:v4.16",
+		Type:        v2alpha1.TypeOperatorCatalog,
+	}
+	catalogSource := filepath.Join(workingDir, clusterResourcesDir, "cs-redhat-operator-index-v4-16.yaml")
+	clusterCatalog := filepath.Join(workingDir, clusterResourcesDir, "cc-redhat-operator-index-v4-16.yaml")
+	generate := func(t *testing.T, run []v2alpha1.CopyImageSchema) {
+		assert.NoError(t, os.RemoveAll(filepath.Join(workingDir, clusterResourcesDir)))
+		assert.NoError(t, cr.UpdateMirrorSetState(destination, run))
+		assert.NoError(t, cr.CatalogSourceGenerator(run))
+		assert.NoError(t, cr.ClusterCatalogGenerator(run))
+	}
+
+	t.Run("Testing CatalogSourceGenerator - should keep the catalogs of previous runs", func(t *testing.T) {
+		generate(t, []v2alpha1.CopyImageSchema{catalog})
+		assert.FileExists(t, catalogSource)
+		assert.FileExists(t, clusterCatalog)
+
+		// an incremental run without any catalog
+		generate(t, []v2alpha1.CopyImageSchema{{
+			Source:      "docker://localhost:55000/ubi8/ubi:latest",
+			Destination: "docker://myregistry/mynamespace/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		}})
+		assert.FileExists(t, catalogSource)
+		assert.FileExists(t, clusterCatalog)
+	})
+
+	t.Run("Testing CatalogSourceGenerator - should not generate the catalogs deleted", func(t *testing.T) {
+		assert.NoError(t, RemoveFromMirrorSetState(workingDir, []v2alpha1.CopyImageSchema{catalog}))
+		generate(t, []v2alpha1.CopyImageSchema{})
+		assert.NoFileExists(t, catalogSource)
+		assert.NoFileExists(t, clusterCatalog)
+	})
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// envVarRegexp matches the ${VAR} references expanded in the catalogs and the release versions
+var envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// configFile is one of the files of a composed configuration
+type configFile struct {
+	path string
+	data []byte
+}
+
+// readConfigFiles reads the comma-separated list of configuration files,
+// along with the files they include, in the order they are merged:
+// the includes of a file come before it, and a file included twice is read once.
+func readConfigFiles(configPath string) ([]configFile, error) {
+	var files []configFile
+	read := map[string]bool{}
+
+	var walk func(path string, including []string) error
+	walk = func(path string, including []string) error {
+		abs, err := filepath.Abs(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		if slices.Contains(including, abs) {
+			return fmt.Errorf("configuration %s includes itself: %s", path, strings.Join(append(including, abs), " -> "))
+		}
+		if read[abs] {
+			return nil
+		}
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return err
+		}
+		var spec struct {
+			Includes []string `json:"includes"`
+		}
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for _, include := range spec.Includes {
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			if err := walk(include, append(slices.Clone(including), abs)); err != nil {
+				return err
+			}
+		}
+		read[abs] = true
+		files = append(files, configFile{path: path, data: data})
+		return nil
+	}
+
+	for _, path := range strings.Split(configPath, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if err := walk(path, nil); err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no configuration file in %q", configPath)
+	}
+	return files, nil
+}
+
+// Digest returns the sha256 digest of a configuration as composed by Compose: the files merged,
+// and their environment variables expanded, so that it changes along with the variables.
+// It is the digest of the file itself for a single file without environment variables.
+func Digest(configPath string) (string, error) {
+	data, _, err := Compose(configPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+// Compose returns the configuration of configPath as a single document, with the files of
+// a composed configuration merged and their environment variables expanded, for the commands
+// checking the configuration without loading it. The document is the file itself when the
+// configuration is a single file without environment variables, so that the positions in the
+// document are the ones in the file: composed is false then.
+func Compose(configPath string) (data []byte, composed bool, err error) {
+	files, err := readConfigFiles(configPath)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(files) == 1 && !envVarRegexp.Match(files[0].data) {
+		return files[0].data, false, nil
+	}
+
+	typeMeta, err := getTypeMeta(files[0].data)
+	if err != nil {
+		return nil, false, err
+	}
+	var cfg interface{}
+	switch gvk := typeMeta.GroupVersionKind(); gvk {
+	case v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind):
+		isc, err := composeConfig(files, v2alpha1.ImageSetConfigurationKind, expandImageSetConfiguration, mergeImageSetConfiguration)
+		if err != nil {
+			return nil, false, err
+		}
+		isc.Includes = nil
+		isc.SetGroupVersionKind(gvk)
+		cfg = isc
+	case v2alpha1.GroupVersion.WithKind(v2alpha1.DeleteImageSetConfigurationKind):
+		disc, err := composeConfig(files, v2alpha1.DeleteImageSetConfigurationKind, expandDeleteImageSetConfiguration, mergeDeleteImageSetConfiguration)
+		if err != nil {
+			return nil, false, err
+		}
+		disc.Includes = nil
+		disc.SetGroupVersionKind(gvk)
+		cfg = disc
+	default:
+		if len(files) > 1 {
+			return nil, false, fmt.Errorf("config GVK %s can not be merged", gvk)
+		}
+		return files[0].data, false, nil
+	}
+	if data, err = yaml.Marshal(cfg); err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// composeConfig loads the files of a configuration, expands their environment variables,
+// and merges each of them into the configuration loaded from the previous ones
+func composeConfig[T any](files []configFile, kind string, expand func(*T) []error, merge func(dst *T, src T)) (T, error) {
+	var cfg T
+	fileErr := func(file configFile, err error) error {
+		if len(files) == 1 {
+			return err
+		}
+		return fmt.Errorf("%s: %v", file.path, err)
+	}
+
+	var first string
+	for i, file := range files {
+		typeMeta, err := getTypeMeta(file.data)
+		if err != nil {
+			return cfg, fileErr(file, err)
+		}
+		if i == 0 {
+			first = typeMeta.GroupVersionKind().String()
+		} else if gvk := typeMeta.GroupVersionKind().String(); gvk != first {
+			return cfg, fileErr(file, fmt.Errorf("config GVK %s can not be merged with %s", gvk, first))
+		}
+
+		fragment, err := LoadConfig[T](file.data, kind)
+		if err != nil {
+			return cfg, fileErr(file, err)
+		}
+		if errs := expand(&fragment); len(errs) > 0 {
+			return cfg, fileErr(file, fmt.Errorf("invalid configuration: %v", utilerrors.NewAggregate(errs)))
+		}
+		if i == 0 {
+			cfg = fragment
+		} else {
+			merge(&cfg, fragment)
+		}
+	}
+	return cfg, nil
+}
+
+// expandEnv replaces the ${VAR} references of the values by the environment variables
+func expandEnv(values ...*string) []error {
+	var errs []error
+	for _, value := range values {
+		*value = envVarRegexp.ReplaceAllStringFunc(*value, func(ref string) string {
+			name := envVarRegexp.FindStringSubmatch(ref)[1]
+			env, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("environment variable %s of %q is not set", name, *value))
+				return ref
+			}
+			return env
+		})
+	}
+	return errs
+}
+
+// expandPlatform expands the environment variables of the release channels and versions
+func expandPlatform(p *v2alpha1.Platform) []error {
+	var errs []error
+	for i := range p.Channels {
+		errs = append(errs, expandEnv(&p.Channels[i].Name, &p.Channels[i].MinVersion, &p.Channels[i].MaxVersion)...)
+	}
+	for i := range p.Releases {
+		errs = append(errs, expandEnv(&p.Releases[i].Version)...)
+	}
+	return errs
+}
+
+// expandOperators expands the environment variables of the catalogs and of their tags
+func expandOperators(operators []v2alpha1.Operator) []error {
+	var errs []error
+	for i := range operators {
+		errs = append(errs, expandEnv(&operators[i].Catalog, &operators[i].TargetTag)...)
+	}
+	return errs
+}
+
+// expandImages expands the environment variables of the names of the additional images
+func expandImages(images []v2alpha1.Image) []error {
+	var errs []error
+	for i := range images {
+		errs = append(errs, expandEnv(&images[i].Name)...)
+	}
+	return errs
+}
+
+func expandImageSetConfiguration(cfg *v2alpha1.ImageSetConfiguration) []error {
+	errs := expandPlatform(&cfg.Mirror.Platform)
+	errs = append(errs, expandOperators(cfg.Mirror.Operators)...)
+	for i := range cfg.Mirror.CompositeCatalogs {
+		errs = append(errs, expandEnv(&cfg.Mirror.CompositeCatalogs[i].TargetTag)...)
+		errs = append(errs, expandOperators(cfg.Mirror.CompositeCatalogs[i].Catalogs)...)
+	}
+	return append(errs, expandImages(cfg.Mirror.AdditionalImages)...)
+}
+
+func expandDeleteImageSetConfiguration(cfg *v2alpha1.DeleteImageSetConfiguration) []error {
+	errs := append(expandPlatform(&cfg.Delete.Platform), expandOperators(cfg.Delete.Operators)...)
+	return append(errs, expandImages(cfg.Delete.AdditionalImages)...)
+}
+
+// mergeImageSetConfiguration merges the fragment src into dst.
+// The lists are merged by the key of their elements: the elements of src are either merged into
+// the element of dst with the same key, or appended to dst. When merging two elements, the lists
+// are merged the same way, the flags are set when set in any of them, and the other values of src
+// override the ones of dst when set. The keys are:
+//   - the name of the release channels, of the packages, of their channels, of the helm
+//     repositories and charts, of the samples, and of the additional and blocked images
+//   - the catalog, targetCatalog and targetTag of the operators
+//   - the targetCatalog and targetTag of the composite catalogs
+func mergeImageSetConfiguration(dst *v2alpha1.ImageSetConfiguration, src v2alpha1.ImageSetConfiguration) {
+	mergePlatform(&dst.Mirror.Platform, src.Mirror.Platform)
+	dst.Mirror.Operators = mergeOperators(dst.Mirror.Operators, src.Mirror.Operators)
+	dst.Mirror.AdditionalImages = mergeBy(dst.Mirror.AdditionalImages, src.Mirror.AdditionalImages, imageName, nil)
+	mergeHelm(&dst.Mirror.Helm, src.Mirror.Helm)
+	dst.Mirror.BlockedImages = mergeBy(dst.Mirror.BlockedImages, src.Mirror.BlockedImages, imageName, nil)
+	dst.Mirror.Samples = mergeBy(dst.Mirror.Samples, src.Mirror.Samples, sampleName, mergeSamples)
+	dst.Mirror.CompositeCatalogs = mergeBy(dst.Mirror.CompositeCatalogs, src.Mirror.CompositeCatalogs, compositeCatalogKey, mergeCompositeCatalog)
+	override(&dst.ArchiveSize, src.ArchiveSize)
+}
+
+// mergeDeleteImageSetConfiguration merges the fragment src into dst, as mergeImageSetConfiguration
+func mergeDeleteImageSetConfiguration(dst *v2alpha1.DeleteImageSetConfiguration, src v2alpha1.DeleteImageSetConfiguration) {
+	mergePlatform(&dst.Delete.Platform, src.Delete.Platform)
+	dst.Delete.Operators = mergeOperators(dst.Delete.Operators, src.Delete.Operators)
+	dst.Delete.AdditionalImages = mergeBy(dst.Delete.AdditionalImages, src.Delete.AdditionalImages, imageName, nil)
+	mergeHelm(&dst.Delete.Helm, src.Delete.Helm)
+	dst.Delete.Samples = mergeBy(dst.Delete.Samples, src.Delete.Samples, sampleName, mergeSamples)
+}
+
+func mergePlatform(dst *v2alpha1.Platform, src v2alpha1.Platform) {
+	dst.Graph = dst.Graph || src.Graph
+	dst.KubeVirtContainer = dst.KubeVirtContainer || src.KubeVirtContainer
+	dst.TrimGraph = dst.TrimGraph || src.TrimGraph
+	override(&dst.Release, src.Release)
+	override(&dst.GraphBaseImage, src.GraphBaseImage)
+	override(&dst.GraphCopyHelper, src.GraphCopyHelper)
+	override(&dst.GraphLocalChannel, src.GraphLocalChannel)
+	dst.Channels = mergeBy(dst.Channels, src.Channels, func(ch v2alpha1.ReleaseChannel) string { return ch.Name }, mergeReleaseChannel)
+	dst.Architectures = mergeBy(dst.Architectures, src.Architectures, identity, nil)
+	dst.Releases = mergeBy(dst.Releases, src.Releases, func(r v2alpha1.ReleasePayload) string {
+		return r.Version + "|" + r.Image + "|" + r.Architecture
+	}, nil)
+	if src.BootImages != nil {
+		if dst.BootImages == nil {
+			dst.BootImages = src.BootImages.DeepCopy()
+		} else {
+			dst.BootImages.Architectures = mergeBy(dst.BootImages.Architectures, src.BootImages.Architectures, identity, nil)
+			dst.BootImages.Platforms = mergeBy(dst.BootImages.Platforms, src.BootImages.Platforms, identity, nil)
+			dst.BootImages.Formats = mergeBy(dst.BootImages.Formats, src.BootImages.Formats, identity, nil)
+			dst.BootImages.OCIArtifact = dst.BootImages.OCIArtifact || src.BootImages.OCIArtifact
+			override(&dst.BootImages.HTTPDir, src.BootImages.HTTPDir)
+		}
+	}
+}
+
+func mergeReleaseChannel(dst *v2alpha1.ReleaseChannel, src v2alpha1.ReleaseChannel) {
+	override(&dst.Type, src.Type)
+	override(&dst.MinVersion, src.MinVersion)
+	override(&dst.MaxVersion, src.MaxVersion)
+	dst.ShortestPath = dst.ShortestPath || src.ShortestPath
+	dst.EUSPath = dst.EUSPath || src.EUSPath
+	dst.Full = dst.Full || src.Full
+}
+
+func mergeOperators(dst, src []v2alpha1.Operator) []v2alpha1.Operator {
+	return mergeBy(dst, src, func(op v2alpha1.Operator) string {
+		return op.Catalog + "|" + op.TargetCatalog + "|" + op.TargetTag
+	}, mergeOperator)
+}
+
+func mergeOperator(dst *v2alpha1.Operator, src v2alpha1.Operator) {
+	dst.Full = dst.Full || src.Full
+	dst.SkipDependencies = dst.SkipDependencies || src.SkipDependencies
+	override(&dst.TargetCatalogSourceTemplate, src.TargetCatalogSourceTemplate)
+	override(&dst.CatalogBaseImage, src.CatalogBaseImage)
+	dst.Packages = mergeBy(dst.Packages, src.Packages, func(pkg v2alpha1.IncludePackage) string { return pkg.Name }, mergePackage)
+}
+
+func mergePackage(dst *v2alpha1.IncludePackage, src v2alpha1.IncludePackage) {
+	override(&dst.DefaultChannel, src.DefaultChannel)
+	mergeBundle(&dst.IncludeBundle, src.IncludeBundle)
+	dst.SelectionPolicy = dst.SelectionPolicy.Merge(src.SelectionPolicy)
+	dst.Channels = mergeBy(dst.Channels, src.Channels, func(ch v2alpha1.IncludeChannel) string { return ch.Name }, func(dst *v2alpha1.IncludeChannel, src v2alpha1.IncludeChannel) {
+		mergeBundle(&dst.IncludeBundle, src.IncludeBundle)
+		dst.SelectionPolicy = dst.SelectionPolicy.Merge(src.SelectionPolicy)
+	})
+}
+
+func mergeBundle(dst *v2alpha1.IncludeBundle, src v2alpha1.IncludeBundle) {
+	override(&dst.MinVersion, src.MinVersion)
+	override(&dst.MaxVersion, src.MaxVersion)
+}
+
+func mergeHelm(dst *v2alpha1.Helm, src v2alpha1.Helm) {
+	dst.Repositories = mergeBy(dst.Repositories, src.Repositories, func(repo v2alpha1.Repository) string { return repo.Name }, func(dst *v2alpha1.Repository, src v2alpha1.Repository) {
+		override(&dst.URL, src.URL)
+		dst.Charts = mergeBy(dst.Charts, src.Charts, chartName, mergeChart)
+	})
+	dst.Local = mergeBy(dst.Local, src.Local, chartName, mergeChart)
+}
+
+func mergeChart(dst *v2alpha1.Chart, src v2alpha1.Chart) {
+	override(&dst.Version, src.Version)
+	override(&dst.Path, src.Path)
+	dst.ImagePaths = mergeBy(dst.ImagePaths, src.ImagePaths, identity, nil)
+}
+
+func mergeSamples(dst *v2alpha1.SampleImages, src v2alpha1.SampleImages) {
+	dst.Tags = mergeBy(dst.Tags, src.Tags, identity, nil)
+}
+
+func mergeCompositeCatalog(dst *v2alpha1.CompositeCatalog, src v2alpha1.CompositeCatalog) {
+	override(&dst.CatalogBaseImage, src.CatalogBaseImage)
+	override(&dst.TargetCatalogSourceTemplate, src.TargetCatalogSourceTemplate)
+	dst.Catalogs = mergeOperators(dst.Catalogs, src.Catalogs)
+}
+
+func imageName(img v2alpha1.Image) string { return img.Name }
+
+func sampleName(sample v2alpha1.SampleImages) string { return sample.Name }
+
+func chartName(chart v2alpha1.Chart) string { return chart.Name }
+
+func compositeCatalogKey(c v2alpha1.CompositeCatalog) string {
+	return c.TargetCatalog + "|" + c.TargetTag
+}
+
+func identity(s string) string { return s }
+
+// mergeBy merges each element of src into the element of dst with the same key, or appends it to dst.
+// Without merge function, the element of dst is kept as is.
+// The duplicates within src are appended, so that they are still reported by the validation.
+func mergeBy[E any](dst, src []E, key func(E) string, merge func(dst *E, src E)) []E {
+	index := map[string]int{}
+	for i, e := range dst {
+		if _, ok := index[key(e)]; !ok {
+			index[key(e)] = i
+		}
+	}
+	for _, e := range src {
+		i, ok := index[key(e)]
+		if !ok {
+			dst = append(dst, e)
+			continue
+		}
+		if merge != nil {
+			merge(&dst[i], e)
+		}
+	}
+	return dst
+}
+
+// override sets dst to src, when src is set
+func override[T comparable](dst *T, src T) {
+	var zero T
+	if src != zero {
+		*dst = src
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+const (
+	composeHeader = "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\n"
+	platformISC   = composeHeader + `mirror:
+  platform:
+    channels:
+    - name: stable-${OCP_VERSION}
+      minVersion: ${OCP_VERSION}.1
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v${OCP_VERSION}
+    packages:
+    - name: aws-load-balancer-operator
+      channels:
+      - name: stable-v1
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest
+`
+	dataServicesISC = composeHeader + `mirror:
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: aws-load-balancer-operator
+      defaultChannel: stable-v0
+      channels:
+      - name: stable-v1
+        minVersion: 1.1.0
+      - name: stable-v0
+    - name: odf-operator
+  - catalog: registry.redhat.io/redhat/certified-operator-index:v4.16
+    packages:
+    - name: mongodb-enterprise
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest
+  - name: registry.redhat.io/rhel9/postgresql-15:latest
+`
+)
+
+func writeConfigs(t *testing.T, configs map[string]string) string {
+	dir := t.TempDir()
+	for name, config := range configs {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(config), 0644))
+	}
+	return dir
+}
+
+func TestComposeConfig(t *testing.T) {
+	expectedOperators := []v2alpha1.Operator{
+		{
+			Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+				{
+					Name:           "aws-load-balancer-operator",
+					DefaultChannel: "stable-v0",
+					Channels: []v2alpha1.IncludeChannel{
+						{Name: "stable-v1", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "1.1.0"}},
+						{Name: "stable-v0"},
+					},
+				},
+				{Name: "odf-operator"},
+			}},
+		},
+		{
+			Catalog:       "registry.redhat.io/redhat/certified-operator-index:v4.16",
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{{Name: "mongodb-enterprise"}}},
+		},
+	}
+	expectedImages := []v2alpha1.Image{
+		{Name: "registry.redhat.io/ubi9/ubi:latest"},
+		{Name: "registry.redhat.io/rhel9/postgresql-15:latest"},
+	}
+
+	t.Run("Testing ReadConfig - list of files: should merge the files in order", func(t *testing.T) {
+		t.Setenv("OCP_VERSION", "4.16")
+		dir := writeConfigs(t, map[string]string{"platform.yaml": platformISC, "data-services.yaml": dataServicesISC})
+		res, err := ReadConfig(filepath.Join(dir, "platform.yaml")+","+filepath.Join(dir, "data-services.yaml"), v2alpha1.ImageSetConfigurationKind)
+		require.NoError(t, err)
+		cfg := res.(v2alpha1.ImageSetConfiguration)
+		assert.Equal(t, []v2alpha1.ReleaseChannel{{Name: "stable-4.16", Type: v2alpha1.TypeOCP, MinVersion: "4.16.1"}}, cfg.Mirror.Platform.Channels)
+		assert.Equal(t, expectedOperators, cfg.Mirror.Operators)
+		assert.Equal(t, expectedImages, cfg.Mirror.AdditionalImages)
+	})
+
+	t.Run("Testing ReadConfig - includes: should merge the included files before the file", func(t *testing.T) {
+		t.Setenv("OCP_VERSION", "4.16")
+		dir := writeConfigs(t, map[string]string{
+			"teams/platform.yaml":      platformISC,
+			"teams/data-services.yaml": dataServicesISC,
+			"isc.yaml": composeHeader + `includes:
+- teams/platform.yaml
+- teams/data-services.yaml
+archiveSize: 4
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.3
+`,
+		})
+		res, err := ReadConfig(filepath.Join(dir, "isc.yaml"), v2alpha1.ImageSetConfigurationKind)
+		require.NoError(t, err)
+		cfg := res.(v2alpha1.ImageSetConfiguration)
+		assert.Nil(t, cfg.Includes)
+		assert.Equal(t, int64(4), cfg.ArchiveSize)
+		assert.Equal(t, []v2alpha1.ReleaseChannel{{Name: "stable-4.16", Type: v2alpha1.TypeOCP, MinVersion: "4.16.3"}}, cfg.Mirror.Platform.Channels)
+		assert.Equal(t, expectedOperators, cfg.Mirror.Operators)
+		assert.Equal(t, expectedImages, cfg.Mirror.AdditionalImages)
+	})
+
+	t.Run("Testing ReadConfig - delete configuration: should merge the files", func(t *testing.T) {
+		dir := writeConfigs(t, map[string]string{
+			"base.yaml": "kind: DeleteImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\ndelete:\n  additionalImages:\n  - name: registry.redhat.io/ubi9/ubi:latest\n",
+			"delete.yaml": "kind: DeleteImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\nincludes:\n- base.yaml\n" +
+				"delete:\n  additionalImages:\n  - name: registry.redhat.io/ubi9/ubi-minimal:latest\n",
+		})
+		res, err := ReadConfig(filepath.Join(dir, "delete.yaml"), v2alpha1.DeleteImageSetConfigurationKind)
+		require.NoError(t, err)
+		cfg := res.(v2alpha1.DeleteImageSetConfiguration)
+		assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi9/ubi:latest"}, {Name: "registry.redhat.io/ubi9/ubi-minimal:latest"}}, cfg.Delete.AdditionalImages)
+	})
+
+	t.Run("Testing ReadConfig - additional images: should expand the environment variables", func(t *testing.T) {
+		t.Setenv("UBI_TAG", "9.4")
+		dir := writeConfigs(t, map[string]string{"isc.yaml": composeHeader + "mirror:\n  additionalImages:\n  - name: registry.redhat.io/ubi9/ubi:${UBI_TAG}\n"})
+		res, err := ReadConfig(filepath.Join(dir, "isc.yaml"), v2alpha1.ImageSetConfigurationKind)
+		require.NoError(t, err)
+		assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi9/ubi:9.4"}}, res.(v2alpha1.ImageSetConfiguration).Mirror.AdditionalImages)
+	})
+
+	t.Run("Testing Compose - single file: should be the file itself", func(t *testing.T) {
+		dir := writeConfigs(t, map[string]string{"isc.yaml": dataServicesISC})
+		data, composed, err := Compose(filepath.Join(dir, "isc.yaml"))
+		require.NoError(t, err)
+		assert.False(t, composed)
+		assert.Equal(t, dataServicesISC, string(data))
+	})
+
+	t.Run("Testing Compose - includes: should be the merged configuration", func(t *testing.T) {
+		t.Setenv("OCP_VERSION", "4.16")
+		dir := writeConfigs(t, map[string]string{
+			"platform.yaml": platformISC,
+			"isc.yaml":      composeHeader + "includes:\n- platform.yaml\nmirror: {}\n",
+		})
+		data, composed, err := Compose(filepath.Join(dir, "isc.yaml"))
+		require.NoError(t, err)
+		assert.True(t, composed)
+		cfg, err := LoadConfig[v2alpha1.ImageSetConfiguration](data, v2alpha1.ImageSetConfigurationKind)
+		require.NoError(t, err)
+		assert.Nil(t, cfg.Includes)
+		assert.Equal(t, v2alpha1.ImageSetConfigurationKind, cfg.Kind)
+		assert.Equal(t, "registry.redhat.io/redhat/redhat-operator-index:v4.16", cfg.Mirror.Operators[0].Catalog)
+		assert.Equal(t, "stable-4.16", cfg.Mirror.Platform.Channels[0].Name)
+	})
+
+	t.Run("Testing ReadConfig - duplicates within a file: should still be reported", func(t *testing.T) {
+		dir := writeConfigs(t, map[string]string{
+			"a.yaml": composeHeader + "mirror:\n  operators:\n  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16\n",
+			"b.yaml": composeHeader + "mirror:\n  operators:\n  - catalog: registry.redhat.io/redhat/certified-operator-index:v4.16\n  - catalog: registry.redhat.io/redhat/certified-operator-index:v4.16\n",
+		})
+		_, err := ReadConfig(filepath.Join(dir, "a.yaml")+","+filepath.Join(dir, "b.yaml"), v2alpha1.ImageSetConfigurationKind)
+		assert.ErrorContains(t, err, `catalog "registry.redhat.io/redhat/certified-operator-index:v4.16": duplicate found in configuration`)
+	})
+
+	type testCase struct {
+		caseName string
+		configs  map[string]string
+		expError string
+	}
+	testCases := []testCase{
+		{
+			caseName: "unset environment variable",
+			configs:  map[string]string{"isc.yaml": platformISC},
+			expError: `invalid configuration: [environment variable OCP_VERSION of "stable-${OCP_VERSION}" is not set, environment variable OCP_VERSION of "${OCP_VERSION}.1" is not set, environment variable OCP_VERSION of "registry.redhat.io/redhat/redhat-operator-index:v${OCP_VERSION}" is not set]`,
+		},
+		{
+			caseName: "include cycle",
+			configs: map[string]string{
+				"isc.yaml":   composeHeader + "includes:\n- other.yaml\nmirror: {}\n",
+				"other.yaml": composeHeader + "includes:\n- ./isc.yaml\nmirror: {}\n",
+			},
+			expError: "includes itself",
+		},
+		{
+			caseName: "missing include",
+			configs:  map[string]string{"isc.yaml": composeHeader + "includes:\n- missing.yaml\nmirror: {}\n"},
+			expError: "missing.yaml: no such file or directory",
+		},
+		{
+			caseName: "kinds mismatch",
+			configs: map[string]string{
+				"isc.yaml":   "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v1alpha2\nincludes:\n- other.yaml\nmirror: {}\n",
+				"other.yaml": composeHeader + "mirror: {}\n",
+			},
+			expError: "isc.yaml: config GVK mirror.openshift.io/v1alpha2, Kind=ImageSetConfiguration can not be merged with mirror.openshift.io/v2alpha1, Kind=ImageSetConfiguration",
+		},
+		{
+			caseName: "invalid fragment",
+			configs: map[string]string{
+				"isc.yaml":   composeHeader + "includes:\n- other.yaml\nmirror: {}\n",
+				"other.yaml": composeHeader + "mirror:\n  operator: []\n",
+			},
+			expError: `other.yaml: decode ImageSetConfiguration: json: unknown field "operator"`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("Testing ReadConfig - %s: should fail", testCase.caseName), func(t *testing.T) {
+			dir := writeConfigs(t, testCase.configs)
+			_, err := ReadConfig(filepath.Join(dir, "isc.yaml"), v2alpha1.ImageSetConfigurationKind)
+			assert.ErrorContains(t, err, testCase.expError)
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"platform.yaml":  platformISC,
+		"isc.yaml":       composeHeader + "includes:\n- platform.yaml\nmirror: {}\n",
+		"services.yaml":  dataServicesISC,
+		"composite.yaml": composeHeader + "includes:\n- services.yaml\nmirror: {}\n",
+	})
+
+	t.Run("Testing Digest - single file: should be the digest of the file", func(t *testing.T) {
+		digest, err := Digest(filepath.Join(dir, "services.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(dataServicesISC))), digest)
+	})
+
+	t.Run("Testing Digest - includes: should be the digest of the composed configuration", func(t *testing.T) {
+		t.Setenv("OCP_VERSION", "4.16")
+		digest, err := Digest(filepath.Join(dir, "isc.yaml"))
+		require.NoError(t, err)
+		composed, _, err := Compose(filepath.Join(dir, "isc.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256(composed)), digest)
+	})
+
+	t.Run("Testing Digest - environment variables: should change when only a variable changes", func(t *testing.T) {
+		for _, configFile := range []string{"platform.yaml", "isc.yaml"} {
+			t.Setenv("OCP_VERSION", "4.16")
+			before, err := Digest(filepath.Join(dir, configFile))
+			require.NoError(t, err)
+			t.Setenv("OCP_VERSION", "4.17")
+			after, err := Digest(filepath.Join(dir, configFile))
+			require.NoError(t, err)
+			assert.NotEqual(t, before, after, configFile)
+		}
+	})
+
+	t.Run("Testing Digest - includes without variables: should not change", func(t *testing.T) {
+		before, err := Digest(filepath.Join(dir, "composite.yaml"))
+		require.NoError(t, err)
+		after, err := Digest(filepath.Join(dir, "composite.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,21 +14,31 @@ import (
 
 // ReadConfig opens an imageset configuration file at the given path
 // and loads it into a v2alpha1.ImageSetConfiguration instance for processing and validation.
+//
+// The path may be a comma-separated list of files, and each file may include other files
+// with the includes field, relative to it. The files are merged in order, the includes of
+// a file before it, as described by mergeImageSetConfiguration: a file overlays the files
+// merged before it. The ${VAR} references of the catalogs, of their target tags, of the
+// release channels and versions, and of the additional images are replaced by the
+// environment variables.
 func ReadConfig(configPath string, kind string) (interface{}, error) {
 
 	result := interface{}(nil)
-	data, err := os.ReadFile(filepath.Clean(configPath))
+	files, err := readConfigFiles(configPath)
 	if err != nil {
 		return result, err
 	}
 
-	if strings.Contains(string(data), "mirror:") && kind == "DeleteImageSetConfiguration" {
-		return result, fmt.Errorf("mirror: is not allowed in DeleteImageSetConfigurationKind")
-	}
+	for _, file := range files {
+		if strings.Contains(string(file.data), "mirror:") && kind == "DeleteImageSetConfiguration" {
+			return result, fmt.Errorf("mirror: is not allowed in DeleteImageSetConfigurationKind")
+		}
 
-	if strings.Contains(string(data), "delete:") && kind == "ImageSetConfiguration" {
-		return result, fmt.Errorf("delete: is not allowed in ImageSetConfigurationKind")
+		if strings.Contains(string(file.data), "delete:") && kind == "ImageSetConfiguration" {
+			return result, fmt.Errorf("delete: is not allowed in ImageSetConfigurationKind")
+		}
 	}
+	data := files[0].data
 
 	typeMeta, err := getTypeMeta(data)
 	if err != nil {
@@ -39,10 +47,11 @@ func ReadConfig(configPath string, kind string) (interface{}, error) {
 
 	switch typeMeta.GroupVersionKind() {
 	case v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind):
-		if strings.Contains(string(data), "delete:") {
+		if containsAny(files, "delete:") {
 			return result, fmt.Errorf("delete: is not allowed in ImageSetConfiguration")
 		}
-		cfg, err := LoadConfig[v2alpha1.ImageSetConfiguration](data, v2alpha1.ImageSetConfigurationKind)
+		cfg, err := composeConfig(files, v2alpha1.ImageSetConfigurationKind, expandImageSetConfiguration, mergeImageSetConfiguration)
+		cfg.Includes = nil
 		gvk := v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind)
 		cfg.SetGroupVersionKind(gvk)
 		if err != nil {
@@ -55,10 +64,11 @@ func ReadConfig(configPath string, kind string) (interface{}, error) {
 		}
 		return cfg, nil
 	case v2alpha1.GroupVersion.WithKind(v2alpha1.DeleteImageSetConfigurationKind):
-		if strings.Contains(string(data), "mirror:") {
+		if containsAny(files, "mirror:") {
 			return result, fmt.Errorf("mirror: is not allowed in DeleteImageSetConfiguration")
 		}
-		cfg, err := LoadConfig[v2alpha1.DeleteImageSetConfiguration](data, v2alpha1.DeleteImageSetConfigurationKind)
+		cfg, err := composeConfig(files, v2alpha1.DeleteImageSetConfigurationKind, expandDeleteImageSetConfiguration, mergeDeleteImageSetConfiguration)
+		cfg.Includes = nil
 		gvk := v2alpha1.GroupVersion.WithKind(v2alpha1.DeleteImageSetConfigurationKind)
 		cfg.SetGroupVersionKind(gvk)
 		if err != nil {
@@ -70,12 +80,40 @@ func ReadConfig(configPath string, kind string) (interface{}, error) {
 			return result, err
 		}
 		return cfg, nil
+	case v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationLockKind):
+		if len(files) > 1 {
+			return result, fmt.Errorf("an ImageSetConfigurationLock can not be merged with other configurations")
+		}
+		lock, err := LoadConfig[v2alpha1.ImageSetConfigurationLock](data, v2alpha1.ImageSetConfigurationLockKind)
+		if err != nil {
+			return result, err
+		}
+		lock.SetGroupVersionKind(v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationLockKind))
+		// the locked configuration is validated as any imageset configuration
+		cfg := lock.GetImageSetConfiguration()
+		Complete(&cfg)
+		err = Validate(&cfg)
+		if err != nil {
+			return result, err
+		}
+		lock.ImageSetConfigurationSpec = cfg.ImageSetConfigurationSpec
+		return lock, nil
 
 	default:
 		return result, fmt.Errorf("config GVK not recognized: %s", typeMeta.GroupVersionKind())
 	}
 }
 
+// containsAny returns true when the data of one of the files contains s
+func containsAny(files []configFile, s string) bool {
+	for _, file := range files {
+		if strings.Contains(string(file.data), s) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadConfig loads data into a v2alpha1.ImageSetConfiguration or
 // v2alpha1.DeleteImageSetConfiguration instance
 func LoadConfig[T any](data []byte, kind string) (c T, err error) {
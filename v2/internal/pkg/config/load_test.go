@@ -362,3 +362,39 @@ func TestReadConfigDelete(t *testing.T) {
 		}
 	})
 }
+
+func TestReadConfigLock(t *testing.T) {
+	t.Run("Testing ReadConfigLock : should pass ", func(t *testing.T) {
+		lockFile := filepath.Join(t.TempDir(), "isc.lock.yaml")
+		lockData := `apiVersion: mirror.openshift.io/v2alpha1
+kind: ImageSetConfigurationLock
+configDigest: sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+mirror:
+  platform:
+    releases:
+    - image: quay.io/openshift-release-dev/ocp-release@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi:latest@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+images:
+- origin: registry.redhat.io/ubi9/ubi:latest
+  image: registry.redhat.io/ubi9/ubi@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+  type: generic
+`
+		require.NoError(t, os.WriteFile(lockFile, []byte(lockData), 0644))
+		res, err := ReadConfig(lockFile, v2alpha1.ImageSetConfigurationKind)
+		require.NoError(t, err)
+		conv := res.(v2alpha1.ImageSetConfigurationLock)
+		require.Equal(t, v2alpha1.ImageSetConfigurationLockKind, conv.Kind)
+		require.Len(t, conv.Mirror.Platform.Releases, 1)
+		require.Equal(t, []v2alpha1.LockedImage{{
+			Origin: "registry.redhat.io/ubi9/ubi:latest",
+			Image:  "registry.redhat.io/ubi9/ubi@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Type:   v2alpha1.TypeGeneric,
+		}}, conv.Images)
+
+		// should fail
+		require.NoError(t, os.WriteFile(lockFile, []byte(lockData+"unknown: field\n"), 0644))
+		_, err = ReadConfig(lockFile, v2alpha1.ImageSetConfigurationKind)
+		require.Error(t, err)
+	})
+}
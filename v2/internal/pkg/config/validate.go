@@ -2,19 +2,52 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 )
 
 type validationFunc func(cfg *v2alpha1.ImageSetConfiguration) []error
 type validationDeleteFunc func(cfg *v2alpha1.DeleteImageSetConfiguration) error
 
-var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels}
+var validationChecks = []validationFunc{validateOperatorOptions, validateCompositeCatalogs, validateSelectionPolicies, validateReleaseChannels, validateGraphOptions, validateReleasePayloads, validateBootImages}
 var validationDeleteChecks = []validationDeleteFunc{validateOperatorOptionsDelete, validateReleaseChannelsDelete}
 
+// graphChannelRegexp matches the names of the channels of the Cincinnati graph data, such as stable-4.16
+var graphChannelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// releasePayloadArchitectures are the architectures of the OCP release payloads
+var releasePayloadArchitectures = []string{"amd64", "arm64", "ppc64le", "s390x", "multi"}
+
+// bootImagesArchitectures are the architectures of the coreos stream metadata
+var bootImagesArchitectures = []string{"x86_64", "aarch64", "ppc64le", "s390x"}
+
+// FieldError is an input error of a field of the configuration. Path is the path
+// of the field, such as mirror.operators[0].packages[1].maxVersion
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldErrorf(path, format string, args ...any) error {
+	return &FieldError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
 // Validate will check an ImagesetConfiguration for input errors.
 func Validate(cfg *v2alpha1.ImageSetConfiguration) error {
 	var errs []error
@@ -26,22 +59,37 @@ func Validate(cfg *v2alpha1.ImageSetConfiguration) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// ValidationErrors returns all the input errors of an ImagesetConfiguration, one by error.
+func ValidationErrors(cfg *v2alpha1.ImageSetConfiguration) []error {
+	var errs []error
+	for _, check := range validationChecks {
+		errs = append(errs, check(cfg)...)
+	}
+	return errs
+}
+
 func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 	seen := map[string]bool{}
 	errs := []error{}
-	for _, ctlg := range cfg.Mirror.Operators {
+	for i, ctlg := range cfg.Mirror.Operators {
+		path := fmt.Sprintf("mirror.operators[%d]", i)
 		ctlgName, err := ctlg.GetUniqueName()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, &FieldError{Path: path, Err: err})
 		}
 		if seen[ctlgName] {
-			errs = append(errs, fmt.Errorf(
+			errs = append(errs, fieldErrorf(path,
 				"catalog %q: duplicate found in configuration", ctlgName,
 			))
 		}
-		if filterErrs := validateOperatorFiltering(ctlg); len(filterErrs) > 0 {
+		if filterErrs := validateOperatorFiltering(ctlg, path); len(filterErrs) > 0 {
 			errs = append(errs, filterErrs...)
 		}
+		if ctlg.CatalogBaseImage != "" && !ctlg.IsFBCDir() {
+			errs = append(errs, fieldErrorf(path+".catalogBaseImage",
+				"catalog %q: catalogBaseImage is only supported for file-based catalog directories (dir://)", ctlg.Catalog,
+			))
+		}
 
 		seen[ctlgName] = true
 	}
@@ -50,40 +98,80 @@ func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 	}
 	return nil
 }
-func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
+
+func validateCompositeCatalogs(cfg *v2alpha1.ImageSetConfiguration) []error {
+	seenTargets := map[string]bool{}
+	errs := []error{}
+	for i, composite := range cfg.Mirror.CompositeCatalogs {
+		path := fmt.Sprintf("mirror.compositeCatalogs[%d]", i)
+		switch {
+		case composite.TargetCatalog == "":
+			errs = append(errs, fieldErrorf(path, "composite catalog: targetCatalog is required"))
+		case !v2alpha1.IsValidPathComponent(composite.TargetCatalog):
+			errs = append(errs, fieldErrorf(path+".targetCatalog", "composite catalog %q: targetCatalog is not a valid path component", composite.TargetCatalog))
+		case seenTargets[composite.TargetCatalog]:
+			errs = append(errs, fieldErrorf(path+".targetCatalog", "composite catalog %q: duplicate found in configuration", composite.TargetCatalog))
+		}
+		seenTargets[composite.TargetCatalog] = true
+
+		if len(composite.Catalogs) == 0 {
+			errs = append(errs, fieldErrorf(path+".catalogs", "composite catalog %q: at least one source catalog is required", composite.TargetCatalog))
+		}
+		seen := map[string]bool{}
+		for j, ctlg := range composite.Catalogs {
+			ctlgPath := fmt.Sprintf("%s.catalogs[%d]", path, j)
+			if seen[ctlg.Catalog] {
+				errs = append(errs, fieldErrorf(ctlgPath, "composite catalog %q: catalog %q: duplicate found in configuration", composite.TargetCatalog, ctlg.Catalog))
+			}
+			seen[ctlg.Catalog] = true
+			if filterErrs := validateOperatorFiltering(ctlg, ctlgPath); len(filterErrs) > 0 {
+				errs = append(errs, filterErrs...)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateOperatorFiltering checks the filters of the catalog found at path in the configuration
+func validateOperatorFiltering(ctlg v2alpha1.Operator, path string) []error {
 	errs := []error{}
 	if len(ctlg.Packages) > 0 {
-		for _, pkg := range ctlg.Packages {
+		for i, pkg := range ctlg.Packages {
+			pkgPath := fmt.Sprintf("%s.packages[%d]", path, i)
 			if pkg.MaxVersion != "" || pkg.MinVersion != "" {
 				if pkg.MaxVersion != "" {
 					if _, err := semver.NewVersion(pkg.MaxVersion); err != nil {
-						errs = append(errs, fmt.Errorf("catalog %q: operator %q: maxVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, pkg.MaxVersion))
+						errs = append(errs, fieldErrorf(pkgPath+".maxVersion", "catalog %q: operator %q: maxVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, pkg.MaxVersion))
 					}
 				}
 				if pkg.MinVersion != "" {
 					if _, err := semver.NewVersion(pkg.MinVersion); err != nil {
-						errs = append(errs, fmt.Errorf("catalog %q: operator %q: minVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, pkg.MinVersion))
+						errs = append(errs, fieldErrorf(pkgPath+".minVersion", "catalog %q: operator %q: minVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, pkg.MinVersion))
 					}
 				}
 
 				if len(pkg.Channels) > 0 {
-					for _, chFilter := range pkg.Channels {
+					for j, chFilter := range pkg.Channels {
 						if chFilter.MaxVersion != "" || chFilter.MinVersion != "" {
-							errs = append(errs, fmt.Errorf("catalog %q: operator %q: mixing both filtering by minVersion/maxVersion and filtering by channel minVersion/maxVersion is not allowed", ctlg.Catalog, pkg.Name))
+							errs = append(errs, fieldErrorf(fmt.Sprintf("%s.channels[%d]", pkgPath, j), "catalog %q: operator %q: mixing both filtering by minVersion/maxVersion and filtering by channel minVersion/maxVersion is not allowed", ctlg.Catalog, pkg.Name))
 						}
 					}
 				}
 			}
 			if len(pkg.Channels) > 0 {
-				for _, chFilter := range pkg.Channels {
+				for j, chFilter := range pkg.Channels {
+					chPath := fmt.Sprintf("%s.channels[%d]", pkgPath, j)
 					if chFilter.MaxVersion != "" {
 						if _, err := semver.NewVersion(chFilter.MaxVersion); err != nil {
-							errs = append(errs, fmt.Errorf("catalog %q: operator %q: channel %q: maxVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.MaxVersion))
+							errs = append(errs, fieldErrorf(chPath+".maxVersion", "catalog %q: operator %q: channel %q: maxVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.MaxVersion))
 						}
 					}
 					if chFilter.MinVersion != "" {
 						if _, err := semver.NewVersion(chFilter.MinVersion); err != nil {
-							errs = append(errs, fmt.Errorf("catalog %q: operator %q: channel %q: minVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.MinVersion))
+							errs = append(errs, fieldErrorf(chPath+".minVersion", "catalog %q: operator %q: channel %q: minVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.MinVersion))
 						}
 					}
 				}
@@ -95,16 +183,193 @@ func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
 	}
 	return nil
 }
+
+// validateSelectionPolicies checks the selection policies of the packages and channels of the catalogs
+func validateSelectionPolicies(cfg *v2alpha1.ImageSetConfiguration) []error {
+	type pathCatalog struct {
+		path string
+		ctlg v2alpha1.Operator
+	}
+	catalogs := []pathCatalog{}
+	for i, ctlg := range cfg.Mirror.Operators {
+		catalogs = append(catalogs, pathCatalog{path: fmt.Sprintf("mirror.operators[%d]", i), ctlg: ctlg})
+	}
+	for i, composite := range cfg.Mirror.CompositeCatalogs {
+		for j, ctlg := range composite.Catalogs {
+			catalogs = append(catalogs, pathCatalog{path: fmt.Sprintf("mirror.compositeCatalogs[%d].catalogs[%d]", i, j), ctlg: ctlg})
+		}
+	}
+	errs := []error{}
+	check := func(ctlg v2alpha1.Operator, path, subject string, policy v2alpha1.SelectionPolicy) {
+		if policy.Latest < 0 {
+			errs = append(errs, fieldErrorf(path+".latest", "catalog %q: %s: latest must be a positive number of bundles", ctlg.Catalog, subject))
+		}
+		if policy.CompatibleWithPlatform && len(cfg.Mirror.Platform.Channels) == 0 {
+			errs = append(errs, fieldErrorf(path+".compatibleWithPlatform", "catalog %q: %s: compatibleWithPlatform requires platform channels", ctlg.Catalog, subject))
+		}
+	}
+	for _, c := range catalogs {
+		ctlg := c.ctlg
+		for i, pkg := range ctlg.Packages {
+			pkgPath := fmt.Sprintf("%s.packages[%d]", c.path, i)
+			check(ctlg, pkgPath, fmt.Sprintf("operator %q", pkg.Name), pkg.SelectionPolicy)
+			for j, ch := range pkg.Channels {
+				check(ctlg, fmt.Sprintf("%s.channels[%d]", pkgPath, j), fmt.Sprintf("operator %q: channel %q", pkg.Name, ch.Name), ch.SelectionPolicy)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func validateReleaseChannels(cfg *v2alpha1.ImageSetConfiguration) []error {
 	seen := map[string]bool{}
-	for _, channel := range cfg.Mirror.Platform.Channels {
+	for i, channel := range cfg.Mirror.Platform.Channels {
 		if seen[channel.Name] {
-			return []error{fmt.Errorf(
+			return []error{fieldErrorf(fmt.Sprintf("mirror.platform.channels[%d]", i),
 				"release channel %q: duplicate found in configuration", channel.Name,
 			)}
 		}
 		seen[channel.Name] = true
 	}
+	errs := []error{}
+	for i, channel := range cfg.Mirror.Platform.Channels {
+		if channel.EUSPath {
+			errs = append(errs, validateEUSPath(channel, fmt.Sprintf("mirror.platform.channels[%d]", i))...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateEUSPath checks that the min and max versions of an eusPath channel, found at path in the
+// configuration, are both in EUS (even) minors
+func validateEUSPath(channel v2alpha1.ReleaseChannel, path string) []error {
+	if channel.ShortestPath || channel.Full {
+		return []error{fieldErrorf(path+".eusPath", "release channel %q: eusPath is mutually exclusive with shortestPath and full", channel.Name)}
+	}
+	if channel.MinVersion == "" || channel.MaxVersion == "" {
+		return []error{fieldErrorf(path, "release channel %q: eusPath requires minVersion and maxVersion", channel.Name)}
+	}
+	minVersion, err := semver.StrictNewVersion(channel.MinVersion)
+	if err != nil {
+		return []error{fieldErrorf(path+".minVersion", "release channel %q: invalid minVersion %q: %v", channel.Name, channel.MinVersion, err)}
+	}
+	maxVersion, err := semver.StrictNewVersion(channel.MaxVersion)
+	if err != nil {
+		return []error{fieldErrorf(path+".maxVersion", "release channel %q: invalid maxVersion %q: %v", channel.Name, channel.MaxVersion, err)}
+	}
+	errs := []error{}
+	if minVersion.Major() != maxVersion.Major() {
+		errs = append(errs, fieldErrorf(path, "release channel %q: eusPath requires minVersion and maxVersion of the same major version", channel.Name))
+	}
+	if minVersion.Minor()%2 != 0 || maxVersion.Minor()%2 != 0 {
+		errs = append(errs, fieldErrorf(path, "release channel %q: eusPath requires minVersion and maxVersion in EUS (even) minor versions", channel.Name))
+	}
+	if maxVersion.Minor() <= minVersion.Minor() {
+		errs = append(errs, fieldErrorf(path+".maxVersion", "release channel %q: eusPath requires maxVersion in a minor version greater than minVersion", channel.Name))
+	}
+	return errs
+}
+
+func validateGraphOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
+	platform := cfg.Mirror.Platform
+	errs := []error{}
+	if (platform.GraphBaseImage != "" || platform.GraphCopyHelper != "") && !platform.Graph {
+		field := "mirror.platform.graphBaseImage"
+		if platform.GraphBaseImage == "" {
+			field = "mirror.platform.graphCopyHelper"
+		}
+		errs = append(errs, fieldErrorf(field, "graphBaseImage and graphCopyHelper are only supported when graph is true"))
+	}
+	isScratch := platform.GraphBaseImage == v2alpha1.ScratchGraphBaseImage
+	if isScratch && platform.GraphCopyHelper == "" {
+		errs = append(errs, fieldErrorf("mirror.platform.graphCopyHelper", "graphCopyHelper is required when graphBaseImage is %s", v2alpha1.ScratchGraphBaseImage))
+	}
+	if !isScratch && platform.GraphCopyHelper != "" {
+		errs = append(errs, fieldErrorf("mirror.platform.graphCopyHelper", "graphCopyHelper is only supported when graphBaseImage is %s", v2alpha1.ScratchGraphBaseImage))
+	}
+	if platform.TrimGraph && !platform.Graph {
+		errs = append(errs, fieldErrorf("mirror.platform.trimGraph", "trimGraph is only supported when graph is true"))
+	}
+	if platform.GraphLocalChannel != "" && !platform.TrimGraph {
+		errs = append(errs, fieldErrorf("mirror.platform.graphLocalChannel", "graphLocalChannel is only supported when trimGraph is true"))
+	}
+	if platform.GraphLocalChannel != "" && !graphChannelRegexp.MatchString(platform.GraphLocalChannel) {
+		errs = append(errs, fieldErrorf("mirror.platform.graphLocalChannel", "graphLocalChannel %q is not a valid channel name", platform.GraphLocalChannel))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateReleasePayloads(cfg *v2alpha1.ImageSetConfiguration) []error {
+	errs := []error{}
+	if cfg.Mirror.Platform.Release != "" && len(cfg.Mirror.Platform.Releases) > 0 {
+		errs = append(errs, fieldErrorf("mirror.platform.releases", "release and releases are mutually exclusive"))
+	}
+	for i, payload := range cfg.Mirror.Platform.Releases {
+		path := fmt.Sprintf("mirror.platform.releases[%d]", i)
+		switch {
+		case payload.Version == "" && payload.Image == "":
+			errs = append(errs, fieldErrorf(path, "releases[%d]: one of version or image is required", i))
+		case payload.Version != "" && payload.Image != "":
+			errs = append(errs, fieldErrorf(path, "releases[%d]: version and image are mutually exclusive", i))
+		case payload.Version != "":
+			if _, err := semver.StrictNewVersion(payload.Version); err != nil {
+				errs = append(errs, fieldErrorf(path+".version", "releases[%d]: invalid version %q: %v", i, payload.Version, err))
+			}
+			if !slices.Contains(releasePayloadArchitectures, payload.GetArchitecture()) {
+				errs = append(errs, fieldErrorf(path+".architecture", "releases[%d]: architecture %q must be one of %s", i, payload.Architecture, strings.Join(releasePayloadArchitectures, ", ")))
+			}
+		default:
+			imgSpec, err := image.ParseRef(payload.Image)
+			if err != nil {
+				errs = append(errs, fieldErrorf(path+".image", "releases[%d]: %v", i, err))
+				continue
+			}
+			if !imgSpec.IsImageByDigest() {
+				errs = append(errs, fieldErrorf(path+".image", "releases[%d]: image %s must be pinned by digest", i, payload.Image))
+			}
+			if payload.Architecture != "" {
+				errs = append(errs, fieldErrorf(path+".architecture", "releases[%d]: architecture is only supported with version", i))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateBootImages(cfg *v2alpha1.ImageSetConfiguration) []error {
+	platform := cfg.Mirror.Platform
+	if platform.BootImages == nil {
+		return nil
+	}
+	errs := []error{}
+	if len(platform.Channels) == 0 && platform.Release == "" && len(platform.Releases) == 0 {
+		errs = append(errs, fieldErrorf("mirror.platform.bootImages", "bootImages requires releases to be mirrored (channels, release or releases)"))
+	}
+	if len(platform.BootImages.Platforms) == 0 {
+		errs = append(errs, fieldErrorf("mirror.platform.bootImages.platforms", "bootImages: at least one platform is required"))
+	}
+	for i, arch := range platform.BootImages.Architectures {
+		if !slices.Contains(bootImagesArchitectures, arch) {
+			errs = append(errs, fieldErrorf(fmt.Sprintf("mirror.platform.bootImages.architectures[%d]", i), "bootImages: architecture %q must be one of %s", arch, strings.Join(bootImagesArchitectures, ", ")))
+		}
+	}
+	if platform.BootImages.HTTPDir != "" && !filepath.IsAbs(platform.BootImages.HTTPDir) {
+		errs = append(errs, fieldErrorf("mirror.platform.bootImages.httpDir", "bootImages: httpDir %q must be an absolute path", platform.BootImages.HTTPDir))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
@@ -119,15 +384,27 @@ func ValidateDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// DeleteValidationErrors returns all the input errors of a DeleteImagesetConfiguration.
+func DeleteValidationErrors(cfg *v2alpha1.DeleteImageSetConfiguration) []error {
+	var errs []error
+	for _, check := range validationDeleteChecks {
+		if err := check(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 func validateOperatorOptionsDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	seen := map[string]bool{}
-	for _, ctlg := range cfg.Delete.Operators {
+	for i, ctlg := range cfg.Delete.Operators {
+		path := fmt.Sprintf("delete.operators[%d]", i)
 		ctlgName, err := ctlg.GetUniqueName()
 		if err != nil {
-			return err
+			return &FieldError{Path: path, Err: err}
 		}
 		if seen[ctlgName] {
-			return fmt.Errorf(
+			return fieldErrorf(path,
 				"catalog %q: duplicate found in configuration", ctlgName,
 			)
 		}
@@ -138,9 +415,9 @@ func validateOperatorOptionsDelete(cfg *v2alpha1.DeleteImageSetConfiguration) er
 
 func validateReleaseChannelsDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	seen := map[string]bool{}
-	for _, channel := range cfg.Delete.Platform.Channels {
+	for i, channel := range cfg.Delete.Platform.Channels {
 		if seen[channel.Name] {
-			return fmt.Errorf(
+			return fieldErrorf(fmt.Sprintf("delete.platform.channels[%d]", i),
 				"release channel %q: duplicate found in configuration", channel.Name,
 			)
 		}
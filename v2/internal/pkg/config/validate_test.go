@@ -132,6 +132,270 @@ func TestValidate(t *testing.T) {
 			},
 			expError: "invalid configuration: catalog \"test:latest\": duplicate found in configuration",
 		},
+		{
+			name: "Invalid/CatalogBaseImageWithoutFBCDir",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:          "test-catalog:latest",
+								CatalogBaseImage: "quay.io/operator-framework/opm:v1.47.0",
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: catalog \"test-catalog:latest\": catalogBaseImage is only supported for file-based catalog directories (dir://)",
+		},
+		{
+			name: "Valid/CatalogBaseImageWithFBCDir",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:          "dir:///home/user/catalogs/internal-operators",
+								CatalogBaseImage: "quay.io/operator-framework/opm:v1.47.0",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Valid/CompositeCatalog",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						CompositeCatalogs: []v2alpha1.CompositeCatalog{
+							{
+								TargetCatalog: "approved/operator-index",
+								Catalogs: []v2alpha1.Operator{
+									{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+									{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.16"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/CompositeCatalogWithoutTargetCatalog",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						CompositeCatalogs: []v2alpha1.CompositeCatalog{
+							{
+								Catalogs: []v2alpha1.Operator{
+									{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: composite catalog: targetCatalog is required",
+		},
+		{
+			name: "Invalid/CompositeCatalogWithoutCatalogs",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						CompositeCatalogs: []v2alpha1.CompositeCatalog{
+							{TargetCatalog: "approved/operator-index"},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: composite catalog \"approved/operator-index\": at least one source catalog is required",
+		},
+		{
+			name: "Invalid/CompositeCatalogDuplicateSource",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						CompositeCatalogs: []v2alpha1.CompositeCatalog{
+							{
+								TargetCatalog: "approved/operator-index:v1",
+								Catalogs: []v2alpha1.Operator{
+									{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+									{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [composite catalog \"approved/operator-index:v1\": targetCatalog is not a valid path component, composite catalog \"approved/operator-index:v1\": catalog \"registry.redhat.io/redhat/redhat-operator-index:v4.16\": duplicate found in configuration]",
+		},
+		{
+			name: "Valid/GraphScratchBaseImage",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:           true,
+							GraphBaseImage:  v2alpha1.ScratchGraphBaseImage,
+							GraphCopyHelper: "/usr/local/bin/graph-data-copier",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/GraphScratchBaseImageWithoutHelper",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:          true,
+							GraphBaseImage: v2alpha1.ScratchGraphBaseImage,
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: graphCopyHelper is required when graphBaseImage is scratch",
+		},
+		{
+			name: "Invalid/GraphBaseImageWithoutGraph",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							GraphBaseImage: "oci:///home/user/ubi9",
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: graphBaseImage and graphCopyHelper are only supported when graph is true",
+		},
+		{
+			name: "Valid/TrimGraphWithLocalChannel",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:             true,
+							TrimGraph:         true,
+							GraphLocalChannel: "mirrored-4.16",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/GraphLocalChannel",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							TrimGraph:         true,
+							GraphLocalChannel: "Mirrored/4.16",
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [trimGraph is only supported when graph is true, graphLocalChannel \"Mirrored/4.16\" is not a valid channel name]",
+		},
+		{
+			name: "Valid/ReleasePayloads",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Releases: []v2alpha1.ReleasePayload{
+								{Version: "4.16.3"},
+								{Version: "4.17.0-ec.2", Architecture: "arm64"},
+								{Image: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/ReleasePayloads",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Releases: []v2alpha1.ReleasePayload{
+								{},
+								{Version: "4.16.3", Image: "quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64"},
+								{Version: "4.16", Architecture: "x86_64"},
+								{Image: "quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64", Architecture: "amd64"},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [releases[0]: one of version or image is required, releases[1]: version and image are mutually exclusive, releases[2]: invalid version \"4.16\": Invalid Semantic Version, releases[2]: architecture \"x86_64\" must be one of amd64, arm64, ppc64le, s390x, multi, releases[3]: image quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64 must be pinned by digest, releases[3]: architecture is only supported with version]",
+		},
+		{
+			name: "Valid/BootImages",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Channels: []v2alpha1.ReleaseChannel{{Name: "stable-4.16"}},
+							BootImages: &v2alpha1.BootImages{
+								Architectures: []string{"x86_64", "aarch64"},
+								Platforms:     []string{"metal"},
+								Formats:       []string{"iso", "pxe"},
+								HTTPDir:       "/var/www/html/rhcos",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/BootImages",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							BootImages: &v2alpha1.BootImages{
+								Architectures: []string{"amd64"},
+								HTTPDir:       "rhcos",
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [bootImages requires releases to be mirrored (channels, release or releases), bootImages: at least one platform is required, bootImages: architecture \"amd64\" must be one of x86_64, aarch64, ppc64le, s390x, bootImages: httpDir \"rhcos\" must be an absolute path]",
+		},
+		{
+			name: "Valid/EUSPath",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Channels: []v2alpha1.ReleaseChannel{{Name: "eus-4.16", MinVersion: "4.12.60", MaxVersion: "4.16.3", EUSPath: true}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/EUSPath",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Channels: []v2alpha1.ReleaseChannel{
+								{Name: "eus-4.14", MinVersion: "4.12.60", EUSPath: true},
+								{Name: "eus-4.16", MinVersion: "4.13.10", MaxVersion: "4.13.20", EUSPath: true},
+								{Name: "stable-4.16", MinVersion: "4.14.10", MaxVersion: "4.16.3", EUSPath: true, ShortestPath: true},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [release channel \"eus-4.14\": eusPath requires minVersion and maxVersion, release channel \"eus-4.16\": eusPath requires minVersion and maxVersion in EUS (even) minor versions, release channel \"eus-4.16\": eusPath requires maxVersion in a minor version greater than minVersion, release channel \"stable-4.16\": eusPath is mutually exclusive with shortestPath and full]",
+		},
 		{
 			name: "Invalid/CatalogWithTargetCatalogContainsTag",
 			config: &v2alpha1.ImageSetConfiguration{
@@ -195,6 +459,35 @@ func TestValidate(t *testing.T) {
 			},
 			expError: "invalid configuration: [catalog \"test-catalog1:latest\": operator \"operator1\": channel \"fast\": maxVersion \"abc\" must respect semantic versioning notation, catalog \"test-catalog1:latest\": operator \"operator1\": channel \"fast\": minVersion \"-+?\" must respect semantic versioning notation]",
 		},
+		{
+			name: "Invalid/CatalogSelectionPolicies",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog: "test-catalog1:latest",
+								IncludeConfig: v2alpha1.IncludeConfig{
+									Packages: []v2alpha1.IncludePackage{
+										{
+											Name:            "operator1",
+											SelectionPolicy: v2alpha1.SelectionPolicy{CompatibleWithPlatform: true},
+											Channels: []v2alpha1.IncludeChannel{
+												{
+													Name:            "fast",
+													SelectionPolicy: v2alpha1.SelectionPolicy{Latest: -1},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"test-catalog1:latest\": operator \"operator1\": compatibleWithPlatform requires platform channels, catalog \"test-catalog1:latest\": operator \"operator1\": channel \"fast\": latest must be a positive number of bundles]",
+		},
 		{
 			name: "Invalid/CatalogFilteringIncorrectVersions",
 			config: &v2alpha1.ImageSetConfiguration{
@@ -13,6 +13,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
@@ -183,18 +184,25 @@ func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageL
 			collectorSchema.TotalAdditionalImages += increment
 		case img.Type.IsHelmImage():
 			collectorSchema.TotalHelmImages += increment
+		case img.Type.IsSampleImage():
+			collectorSchema.TotalSampleImages += increment
 		}
 	}
 
 	o.Opts.Stdout = io.Discard
 	if !o.Opts.Global.DeleteGenerate && len(o.Opts.Global.DeleteDestination) > 0 {
-		if _, err := o.Batch.Worker(context.Background(), collectorSchema, o.Opts); err != nil {
+		deletedImages, err := o.Batch.Worker(context.Background(), collectorSchema, o.Opts)
+		if err != nil {
 			if _, ok := err.(batch.UnsafeError); ok {
 				return err
 			} else {
 				batchError = err
 			}
 		}
+		// the next cluster resources generated should not reference the deleted images
+		if err := clusterresources.RemoveFromMirrorSetState(o.Opts.Global.WorkingDir, deletedImages.AllImages); err != nil {
+			o.Log.Warn("unable to update the mirror-set state: %v", err)
+		}
 	}
 
 	if batchError != nil {
@@ -19,6 +19,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
@@ -360,6 +361,69 @@ func (b *ImageBuilder) SaveImageLayoutToDir(ctx context.Context, imgRef string,
 	return layout.Write(layoutDir, idx)
 }
 
+// ScratchImageLayout writes to layoutDir the OCI layout of an empty image for the given platform,
+// to which layers can then be added with BuildAndPush.
+func ScratchImageLayout(layoutDir string, platform v1.Platform) (layout.Path, error) {
+	img, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{
+		OS:           platform.OS,
+		Architecture: platform.Architecture,
+		RootFS:       v1.RootFS{Type: "layers"},
+	})
+	if err != nil {
+		return "", err
+	}
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), mutate.IndexAddendum{
+		Add:        img,
+		Descriptor: v1.Descriptor{Platform: &platform},
+	})
+	return layout.Write(layoutDir, idx)
+}
+
+// LayerFromFile builds a layer containing the file found at path as targetPath, owned by root.
+// The modification time of the file is the Unix epoch, so that the layer is reproducible.
+// The file is not loaded in memory: the layer is read from the file each time it is opened.
+func LayerFromFile(targetPath, path string, mode int64) (v1.Layer, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	opener := func() (io.ReadCloser, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			defer f.Close()
+			tw := tar.NewWriter(pw)
+			hdr := &tar.Header{
+				Name:     targetPath,
+				Typeflag: tar.TypeReg,
+				Mode:     mode,
+				Size:     info.Size(),
+				ModTime:  time.Unix(0, 0),
+				Format:   tar.FormatPAX,
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				pw.CloseWithError(fmt.Errorf("failed to write tar header: %w", err))
+				return
+			}
+			if _, err := io.Copy(tw, f); err != nil {
+				pw.CloseWithError(fmt.Errorf("failed to write file into the tar: %w", err))
+				return
+			}
+			pw.CloseWithError(tw.Close())
+		}()
+		return pr, nil
+	}
+	return tarball.LayerFromOpener(opener)
+}
+
+// LayerFromGzipByteArray builds a layer from the gzipped tar content, under contentPrefixDir,
+// owned by uid and gid. The modification times of the files are the Unix epoch, and the owner
+// names are dropped, so that the layer only depends on the content.
 func LayerFromGzipByteArray(content []byte, outputFile string, contentPrefixDir string, mod int, uid, gid int) (v1.Layer, error) {
 	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
 	if err != nil {
@@ -388,6 +452,11 @@ func LayerFromGzipByteArray(content []byte, outputFile string, contentPrefixDir
 		header.Name = filepath.Join(contentPrefixDir, header.Name)
 		header.Uid = uid
 		header.Gid = gid
+		header.Uname = ""
+		header.Gname = ""
+		header.ModTime = time.Unix(0, 0)
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
 
 		if err := tarWriter.WriteHeader(header); err != nil {
 			return nil, err
@@ -1,12 +1,17 @@
 package imagebuilder
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"io"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
@@ -242,3 +247,101 @@ func TestProcessImageIndex(t *testing.T) {
 
 	})
 }
+
+func TestLayerFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rhcos-live.x86_64.iso")
+	if err := os.WriteFile(path, []byte("boot artifact content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layer, err := LayerFromFile("/boot-images/rhcos-live.x86_64.iso", path, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := layer.Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	tr := tar.NewReader(reader)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "/boot-images/rhcos-live.x86_64.iso" || hdr.Mode != 0644 || hdr.Uid != 0 || !hdr.ModTime.Equal(time.Unix(0, 0)) {
+		t.Fatalf("unexpected header %+v", hdr)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "boot artifact content" {
+		t.Fatalf("unexpected content %q", content)
+	}
+
+	// the layer is read from the file each time it is opened, with the same digest
+	first, err := layer.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := LayerFromFile("/boot-images/rhcos-live.x86_64.iso", path, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := rebuilt.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatalf("layer %s should be reproducible, got %s", first, second)
+	}
+
+	if _, err := LayerFromFile("/boot-images/missing", filepath.Join(t.TempDir(), "missing"), 0644); err == nil {
+		t.Fatal("should fail when the file does not exist")
+	}
+}
+
+func TestLayerFromGzipByteArrayReproducible(t *testing.T) {
+	graphData := func(modTime time.Time, owner string) []byte {
+		var b bytes.Buffer
+		gw := gzip.NewWriter(&b)
+		tw := tar.NewWriter(gw)
+		content := []byte("version: 1")
+		hdr := &tar.Header{Name: "channels/stable-4.16.yaml", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content)), ModTime: modTime, Uname: owner, Gname: owner, Uid: 1000, Gid: 1000}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return b.Bytes()
+	}
+
+	tmpDir := t.TempDir()
+	first, err := LayerFromGzipByteArray(graphData(time.Now(), "builder"), filepath.Join(tmpDir, "first.tar"), graphDataDir, 0644, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LayerFromGzipByteArray(graphData(time.Now().Add(-time.Hour), "someone"), filepath.Join(tmpDir, "second.tar"), graphDataDir, 0644, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstDigest, err := first.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondDigest, err := second.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstDigest != secondDigest {
+		t.Fatalf("layers %s and %s of the same graph data should be identical", firstDigest, secondDigest)
+	}
+}
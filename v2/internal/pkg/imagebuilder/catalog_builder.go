@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/operator-framework/operator-registry/pkg/cache"
 	"github.com/otiai10/copy"
 )
 
@@ -32,6 +34,12 @@ const (
 	operatorCatalogFilteredImageDir = "filtered-catalog-image"
 	operatorCatalogImageDir         = "catalog-image"
 	operatorCatalogConfigDir        = "catalog-config"
+	operatorCatalogServeCacheDir    = "serve-cache"
+	// catalogCacheDir is where the catalog images expect the pre-computed
+	// opm serve cache (see opm generate dockerfile)
+	catalogCacheDir = "/tmp/cache"
+	// catalogUID is the user catalog images run as
+	catalogUID = 1001
 )
 
 type GCRCatalogBuilder struct {
@@ -70,6 +78,22 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 	}
 	layersToAdd = append(layersToAdd, configLayerToAdd)
 
+	// the serve cache of the original catalog does not match the filtered FBC anymore:
+	// it is computed again, so that the catalog does not need to rebuild it at startup
+	cachePath, err := os.MkdirTemp("", operatorCatalogServeCacheDir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cachePath)
+	if err := buildServeCache(ctx, c.CopyOpts.Global.OpmBinary, configPath, cachePath); err != nil {
+		return fmt.Errorf("error building the serve cache of catalog %s : %v", catalogCopyRef.Origin, err)
+	}
+	cacheLayerToAdd, err := LayerFromPathWithUidGid(catalogCacheDir, cachePath, catalogUID, 0)
+	if err != nil {
+		return fmt.Errorf("error creating add layer: %v", err)
+	}
+	layersToAdd = append(layersToAdd, cacheLayerToAdd)
+
 	// Since we are defining the FBC as index.json,
 	// remove anything that may currently exist
 	deletedConfigLayer, err := deleteLayer("/.wh.configs")
@@ -78,6 +102,12 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 	}
 	layersToDelete = append(layersToDelete, deletedConfigLayer)
 
+	deletedCacheLayer, err := deleteLayer("/tmp/.wh.cache")
+	if err != nil {
+		return fmt.Errorf("error preparing to delete old %s from catalog %s : %v", catalogCacheDir, catalogCopyRef.Origin, err)
+	}
+	layersToDelete = append(layersToDelete, deletedCacheLayer)
+
 	// Deleted layers must be added first in the slice
 	// so that the /configs and /tmp directories are deleted
 	// and then added back from the layers rebuilt from the new FBC.
@@ -96,7 +126,7 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 		return fmt.Errorf("error creating OCI layout: %v", err)
 	}
 
-	configCMD := []string{"serve", "/configs"}
+	configCMD := []string{"serve", "/configs", "--cache-dir=" + catalogCacheDir}
 
 	var srcCache string
 	filteredDir := filepath.Dir(configPath)
@@ -256,6 +286,27 @@ func LayerFromPathWithUidGid(targetPath, path string, uid int, gid int) (v1.Laye
 	return tarball.LayerFromOpener(opener)
 }
 
+// buildServeCache computes the opm serve cache of the FBC found in configPath into cachePath.
+// The catalog fails to start when its opm does not read the format of the cache: the cache is
+// built by opmBinary, matching the opm of the catalog image, when set, and otherwise by the
+// operator-registry oc-mirror is built with, the one of v2alpha1.DefaultCatalogBaseImage.
+func buildServeCache(ctx context.Context, opmBinary, configPath, cachePath string) error {
+	if opmBinary != "" {
+		// nolint: gosec
+		cmd := exec.CommandContext(ctx, opmBinary, "serve", configPath, "--cache-dir="+cachePath, "--cache-only")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s serve --cache-only failed: %v: %s", opmBinary, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	serveCache, err := cache.New(cachePath)
+	if err != nil {
+		return err
+	}
+	defer serveCache.Close()
+	return serveCache.Build(ctx, os.DirFS(configPath))
+}
+
 func deleteLayer(old string) (v1.Layer, error) {
 	deleteMap := map[string][]byte{}
 	deleteMap[old] = []byte{}
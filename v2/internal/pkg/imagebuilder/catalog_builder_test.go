@@ -0,0 +1,79 @@
+package imagebuilder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+const fbcOperator = `{"schema": "olm.package", "name": "op1", "defaultChannel": "stable"}
+{"schema": "olm.channel", "name": "stable", "package": "op1", "entries": [{"name": "op1.v1.0.0"}]}
+{"schema": "olm.bundle", "name": "op1.v1.0.0", "package": "op1", "image": "quay.io/example/op1-bundle:v1.0.0", "properties": [{"type": "olm.package", "value": {"packageName": "op1", "version": "1.0.0"}}]}
+`
+
+func TestBuildServeCache(t *testing.T) {
+	ctx := context.Background()
+	configPath := filepath.Join(t.TempDir(), operatorCatalogConfigDir)
+	if err := os.MkdirAll(filepath.Join(configPath, "op1"), 0755); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configPath, "op1", "catalog.json"), []byte(fbcOperator), 0644); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	cachePath := t.TempDir()
+
+	err := buildServeCache(ctx, "", configPath, cachePath)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+
+	// opm serve checks the integrity of the cache against the FBC before using it
+	serveCache, err := cache.New(cachePath)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	defer serveCache.Close()
+	assert.NoError(t, serveCache.CheckIntegrity(ctx, os.DirFS(configPath)))
+	assert.NoError(t, serveCache.Load(ctx))
+	bundle, err := serveCache.GetBundleForChannel(ctx, "op1", "stable")
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	assert.Equal(t, "op1.v1.0.0", bundle.CsvName)
+}
+
+func TestBuildServeCacheOpmBinary(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, operatorCatalogConfigDir)
+	cachePath := filepath.Join(tmpDir, operatorCatalogServeCacheDir)
+	argsPath := filepath.Join(tmpDir, "args")
+
+	t.Run("Testing buildServeCache - should run the opm binary when set", func(t *testing.T) {
+		opmBinary := filepath.Join(tmpDir, "opm")
+		script := "#!/bin/sh\necho \"$@\" > " + argsPath + "\n"
+		if err := os.WriteFile(opmBinary, []byte(script), 0755); err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		err := buildServeCache(ctx, opmBinary, configPath, cachePath)
+		assert.NoError(t, err)
+		args, err := os.ReadFile(argsPath)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Equal(t, "serve "+configPath+" --cache-dir="+cachePath+" --cache-only\n", string(args))
+	})
+
+	t.Run("Testing buildServeCache - should fail when the opm binary fails", func(t *testing.T) {
+		opmBinary := filepath.Join(tmpDir, "failing-opm")
+		if err := os.WriteFile(opmBinary, []byte("#!/bin/sh\necho unknown cache format\nexit 1\n"), 0755); err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		err := buildServeCache(ctx, opmBinary, configPath, cachePath)
+		assert.ErrorContains(t, err, "unknown cache format")
+	})
+}
@@ -0,0 +1,22 @@
+package isc
+
+import "github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+
+// JSON Schema types of the field values
+const (
+	kindObject  string = "object"
+	kindArray   string = "array"
+	kindString  string = "string"
+	kindBoolean string = "boolean"
+	kindInteger string = "integer"
+)
+
+// severities of the issues
+const (
+	SeverityError   string = "error"
+	SeverityWarning string = "warning"
+)
+
+const jsonSchemaDraft string = "http://json-schema.org/draft-07/schema#"
+
+var supportedKinds = []string{v2alpha1.ImageSetConfigurationKind, v2alpha1.DeleteImageSetConfigurationKind}
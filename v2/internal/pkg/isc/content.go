@@ -0,0 +1,313 @@
+package isc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+)
+
+// ContentLoader loads the content that an ImageSetConfiguration selects from
+type ContentLoader interface {
+	// DeclarativeConfig returns the declarative config of the catalog of an operator, unfiltered
+	DeclarativeConfig(ctx context.Context, op v2alpha1.Operator) (*declcfg.DeclarativeConfig, error)
+	// ChannelVersions returns the sorted releases of a channel of the update graph for an architecture,
+	// none when the channel does not exist
+	ChannelVersions(ctx context.Context, ch v2alpha1.ReleaseChannel, arch string) ([]semver.Version, error)
+}
+
+// releaseChannelPrefixes are the prefixes of the channels of the update graph, such as stable-4.16
+var releaseChannelPrefixes = []string{"stable", "fast", "candidate", "eus"}
+
+var releaseChannelVersionRegexp = regexp.MustCompile(`\d+\.\d+$`)
+
+// CheckContent validates an ImageSetConfiguration, and then checks it against the catalogs
+// and the update graph it selects from: the packages, channels and versions which do not exist,
+// the excluded default channels, and the version ranges which select nothing.
+func CheckContent(ctx context.Context, data []byte, loader ContentLoader) []Issue {
+	if issues := Validate(data); HasErrors(issues) {
+		return issues
+	}
+	root, _ := parse(data)
+	if kind := scalarValue(mappingValue(root, "kind")); kind != v2alpha1.ImageSetConfigurationKind {
+		return []Issue{nodeIssue(root, "kind", fmt.Sprintf("the content can only be checked for the kind %s", v2alpha1.ImageSetConfigurationKind))}
+	}
+	cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration](data, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+	config.Complete(&cfg)
+
+	l := &linter{root: root}
+	for i, ch := range cfg.Mirror.Platform.Channels {
+		path := fmt.Sprintf("mirror.platform.channels[%d]", i)
+		for _, arch := range cfg.Mirror.Platform.Architectures {
+			versions, err := loader.ChannelVersions(ctx, ch, arch)
+			if err != nil {
+				l.report(SeverityError, path, "unable to load the releases of channel %q: %v", ch.Name, err)
+				continue
+			}
+			l.checkReleaseChannel(path, ch, arch, versions)
+		}
+	}
+	checkCatalog := func(path string, op v2alpha1.Operator) {
+		dc, err := loader.DeclarativeConfig(ctx, op)
+		if err != nil {
+			l.report(SeverityError, path+".catalog", "unable to load catalog %s: %v", op.Catalog, err)
+			return
+		}
+		l.checkCatalog(path, op, dc)
+	}
+	for i, op := range cfg.Mirror.Operators {
+		checkCatalog(fmt.Sprintf("mirror.operators[%d]", i), op)
+	}
+	for i, composite := range cfg.Mirror.CompositeCatalogs {
+		for j, op := range composite.Catalogs {
+			checkCatalog(fmt.Sprintf("mirror.compositeCatalogs[%d].catalogs[%d]", i, j), op)
+		}
+	}
+	return l.issues
+}
+
+func (l *linter) checkReleaseChannel(path string, ch v2alpha1.ReleaseChannel, arch string, versions []semver.Version) {
+	if len(versions) == 0 {
+		l.report(SeverityError, path+".name", "channel %q has no release for %s%s", ch.Name, arch, didYouMean(ch.Name, releaseChannelCandidates(ch.Name)))
+		return
+	}
+	minVersion, maxVersion := versions[0], versions[len(versions)-1]
+	// the collector only mirrors the latest release of a channel without versions
+	if ch.IsHeadsOnly() && ch.MinVersion == "" && ch.MaxVersion == "" {
+		minVersion = maxVersion
+	}
+	valid := true
+	for _, bound := range []struct {
+		field   string
+		value   string
+		version *semver.Version
+	}{{"minVersion", ch.MinVersion, &minVersion}, {"maxVersion", ch.MaxVersion, &maxVersion}} {
+		if bound.value == "" {
+			continue
+		}
+		version, err := semver.Parse(bound.value)
+		if err != nil {
+			l.report(SeverityError, path+"."+bound.field, "%s %q is not a semantic version: %v", bound.field, bound.value, err)
+			valid = false
+			continue
+		}
+		*bound.version = version
+		if !slices.ContainsFunc(versions, version.EQ) {
+			l.warn(path+"."+bound.field, "channel %q has no release %s for %s%s", ch.Name, bound.value, arch, didYouMeanVersion(version, versions))
+		}
+	}
+	if !valid {
+		return
+	}
+	if ch.MinVersion != "" && ch.MaxVersion != "" && minVersion.GT(maxVersion) {
+		l.report(SeverityError, path, "minVersion %s of channel %q is greater than its maxVersion %s", minVersion, ch.Name, maxVersion)
+		return
+	}
+	if !slices.ContainsFunc(versions, func(v semver.Version) bool { return v.GTE(minVersion) && v.LTE(maxVersion) }) {
+		l.report(SeverityError, path, "channel %q has no release between %s and %s for %s: nothing is mirrored", ch.Name, minVersion, maxVersion, arch)
+	}
+}
+
+// releaseChannelCandidates returns the channels of the update graph for the version of a channel name
+func releaseChannelCandidates(name string) []string {
+	version := releaseChannelVersionRegexp.FindString(name)
+	if version == "" {
+		return nil
+	}
+	var candidates []string
+	for _, prefix := range releaseChannelPrefixes {
+		candidates = append(candidates, prefix+"-"+version)
+	}
+	return candidates
+}
+
+// catalogPackage is the content of a package of a catalog
+type catalogPackage struct {
+	defaultChannel string
+	// channels are the bundle versions of each channel
+	channels map[string][]semver.Version
+	versions []semver.Version
+}
+
+func newCatalogPackages(dc *declcfg.DeclarativeConfig) map[string]*catalogPackage {
+	packages := map[string]*catalogPackage{}
+	for _, pkg := range dc.Packages {
+		packages[pkg.Name] = &catalogPackage{defaultChannel: pkg.DefaultChannel, channels: map[string][]semver.Version{}}
+	}
+	bundleVersions := map[string]semver.Version{}
+	for _, bundle := range dc.Bundles {
+		pkg, ok := packages[bundle.Package]
+		if !ok {
+			continue
+		}
+		for _, prop := range bundle.Properties {
+			if prop.Type != property.TypePackage {
+				continue
+			}
+			var p property.Package
+			if err := json.Unmarshal(prop.Value, &p); err != nil {
+				continue
+			}
+			if version, err := semver.Parse(p.Version); err == nil {
+				bundleVersions[bundle.Package+"/"+bundle.Name] = version
+				pkg.versions = append(pkg.versions, version)
+			}
+		}
+	}
+	for _, ch := range dc.Channels {
+		pkg, ok := packages[ch.Package]
+		if !ok {
+			continue
+		}
+		versions := []semver.Version{}
+		for _, entry := range ch.Entries {
+			if version, ok := bundleVersions[ch.Package+"/"+entry.Name]; ok {
+				versions = append(versions, version)
+			}
+		}
+		semver.Sort(versions)
+		pkg.channels[ch.Name] = versions
+	}
+	for _, pkg := range packages {
+		semver.Sort(pkg.versions)
+	}
+	return packages
+}
+
+func (l *linter) checkCatalog(path string, op v2alpha1.Operator, dc *declcfg.DeclarativeConfig) {
+	packages := newCatalogPackages(dc)
+	packageNames := slices.Sorted(maps.Keys(packages))
+	for i, pkg := range op.Packages {
+		pkgPath := fmt.Sprintf("%s.packages[%d]", path, i)
+		content, ok := packages[pkg.Name]
+		if !ok {
+			l.report(SeverityError, pkgPath+".name", "package %q does not exist in catalog %s%s", pkg.Name, op.Catalog, didYouMean(pkg.Name, packageNames))
+			continue
+		}
+		l.checkVersions(pkgPath, fmt.Sprintf("package %q", pkg.Name), pkg.IncludeBundle, content.versions)
+
+		channelNames := slices.Sorted(maps.Keys(content.channels))
+		included := []string{}
+		for j, ch := range pkg.Channels {
+			chPath := fmt.Sprintf("%s.channels[%d]", pkgPath, j)
+			versions, ok := content.channels[ch.Name]
+			if !ok {
+				l.report(SeverityError, chPath+".name", "channel %q does not exist in package %q%s", ch.Name, pkg.Name, didYouMean(ch.Name, channelNames))
+				continue
+			}
+			included = append(included, ch.Name)
+			l.checkVersions(chPath, fmt.Sprintf("channel %q of package %q", ch.Name, pkg.Name), ch.IncludeBundle, versions)
+		}
+
+		switch {
+		case pkg.DefaultChannel != "" && !slices.Contains(channelNames, pkg.DefaultChannel):
+			l.report(SeverityError, pkgPath+".defaultChannel", "defaultChannel %q does not exist in package %q%s", pkg.DefaultChannel, pkg.Name, didYouMean(pkg.DefaultChannel, channelNames))
+		case len(included) == 0 || len(included) < len(pkg.Channels):
+			// no channel filter, or the unknown channels were already reported
+		case pkg.DefaultChannel != "" && !slices.Contains(included, pkg.DefaultChannel):
+			l.report(SeverityError, pkgPath+".defaultChannel", "defaultChannel %q of package %q is not one of the included channels %s", pkg.DefaultChannel, pkg.Name, strings.Join(included, ", "))
+		case pkg.DefaultChannel == "" && !slices.Contains(included, content.defaultChannel):
+			l.report(SeverityError, pkgPath+".channels", "the default channel %q of package %q is not included: add it to the channels, or set defaultChannel to one of %s", content.defaultChannel, pkg.Name, strings.Join(included, ", "))
+		}
+	}
+}
+
+// checkVersions checks the version range of a package or a channel against the versions of its bundles
+func (l *linter) checkVersions(path, subject string, bundle v2alpha1.IncludeBundle, versions []semver.Version) {
+	if bundle.MinVersion == "" && bundle.MaxVersion == "" {
+		return
+	}
+	var minVersion, maxVersion *semver.Version
+	for _, bound := range []struct {
+		field   string
+		value   string
+		version **semver.Version
+	}{{"minVersion", bundle.MinVersion, &minVersion}, {"maxVersion", bundle.MaxVersion, &maxVersion}} {
+		if bound.value == "" {
+			continue
+		}
+		version, err := semver.Parse(bound.value)
+		if err != nil {
+			// reported by the validation
+			return
+		}
+		*bound.version = &version
+		if !slices.ContainsFunc(versions, version.EQ) {
+			l.warn(path+"."+bound.field, "%s has no bundle version %s%s", subject, bound.value, didYouMeanVersion(version, versions))
+		}
+	}
+	if minVersion != nil && maxVersion != nil && minVersion.GT(*maxVersion) {
+		l.report(SeverityError, path, "minVersion %s of %s is greater than its maxVersion %s", minVersion, subject, maxVersion)
+		return
+	}
+	inRange := func(v semver.Version) bool {
+		return (minVersion == nil || v.GTE(*minVersion)) && (maxVersion == nil || v.LTE(*maxVersion))
+	}
+	if !slices.ContainsFunc(versions, inRange) {
+		var versionRange []string
+		if minVersion != nil {
+			versionRange = append(versionRange, ">="+minVersion.String())
+		}
+		if maxVersion != nil {
+			versionRange = append(versionRange, "<="+maxVersion.String())
+		}
+		l.report(SeverityError, path, "%s has no bundle in the range %s: nothing is mirrored", subject, strings.Join(versionRange, " "))
+	}
+}
+
+// didYouMean suggests the closest candidates to a misspelled name
+func didYouMean(name string, candidates []string) string {
+	type match struct {
+		candidate string
+		distance  int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if distance <= max(2, len(name)/3) || strings.Contains(candidate, name) || strings.Contains(name, candidate) {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	var suggestions []string
+	for _, m := range matches[:min(3, len(matches))] {
+		suggestions = append(suggestions, fmt.Sprintf("%q", m.candidate))
+	}
+	return ", did you mean " + strings.Join(suggestions, " or ") + "?"
+}
+
+// didYouMeanVersion suggests the existing versions closest to a version
+func didYouMeanVersion(version semver.Version, versions []semver.Version) string {
+	var suggestions []string
+	if i := slices.IndexFunc(versions, version.LT); i > 0 {
+		suggestions = append(suggestions, versions[i-1].String(), versions[i].String())
+	} else if i == 0 {
+		suggestions = append(suggestions, versions[0].String())
+	} else if len(versions) > 0 {
+		suggestions = append(suggestions, versions[len(versions)-1].String())
+	}
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ", did you mean " + strings.Join(suggestions, " or ") + "?"
+}
@@ -0,0 +1,201 @@
+package isc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+type mockContentLoader struct {
+	catalogs map[string]*declcfg.DeclarativeConfig
+	channels map[string][]semver.Version
+}
+
+func (o mockContentLoader) DeclarativeConfig(_ context.Context, op v2alpha1.Operator) (*declcfg.DeclarativeConfig, error) {
+	dc, ok := o.catalogs[op.Catalog]
+	if !ok {
+		return nil, fmt.Errorf("manifest unknown")
+	}
+	return dc, nil
+}
+
+func (o mockContentLoader) ChannelVersions(_ context.Context, ch v2alpha1.ReleaseChannel, arch string) ([]semver.Version, error) {
+	return o.channels[arch+"/"+ch.Name], nil
+}
+
+func bundle(pkg, version string) declcfg.Bundle {
+	return declcfg.Bundle{
+		Schema:     declcfg.SchemaBundle,
+		Package:    pkg,
+		Name:       pkg + ".v" + version,
+		Properties: []property.Property{property.MustBuildPackage(pkg, version)},
+	}
+}
+
+func channel(pkg, name string, versions ...string) declcfg.Channel {
+	ch := declcfg.Channel{Schema: declcfg.SchemaChannel, Package: pkg, Name: name}
+	for _, version := range versions {
+		ch.Entries = append(ch.Entries, declcfg.ChannelEntry{Name: pkg + ".v" + version})
+	}
+	return ch
+}
+
+func TestCheckContent(t *testing.T) {
+	const catalog = "registry.redhat.io/redhat/redhat-operator-index@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+	loader := mockContentLoader{
+		catalogs: map[string]*declcfg.DeclarativeConfig{
+			catalog: {
+				Packages: []declcfg.Package{
+					{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v1"},
+					{Schema: declcfg.SchemaPackage, Name: "3scale-operator", DefaultChannel: "threescale-2.14"},
+				},
+				Channels: []declcfg.Channel{
+					channel("aws-load-balancer-operator", "stable-v1", "1.0.0", "1.1.0", "1.2.0"),
+					channel("aws-load-balancer-operator", "stable-v0", "0.2.0"),
+					channel("3scale-operator", "threescale-2.13", "0.10.0"),
+					channel("3scale-operator", "threescale-2.14", "0.11.0", "0.11.1"),
+				},
+				Bundles: []declcfg.Bundle{
+					bundle("aws-load-balancer-operator", "0.2.0"),
+					bundle("aws-load-balancer-operator", "1.0.0"),
+					bundle("aws-load-balancer-operator", "1.1.0"),
+					bundle("aws-load-balancer-operator", "1.2.0"),
+					bundle("3scale-operator", "0.10.0"),
+					bundle("3scale-operator", "0.11.0"),
+					bundle("3scale-operator", "0.11.1"),
+				},
+			},
+		},
+		channels: map[string][]semver.Version{
+			"amd64/stable-4.16":    {semver.MustParse("4.16.1"), semver.MustParse("4.16.2"), semver.MustParse("4.16.4")},
+			"amd64/fast-4.16":      {semver.MustParse("4.16.1"), semver.MustParse("4.16.2"), semver.MustParse("4.16.4")},
+			"amd64/candidate-4.16": {semver.MustParse("4.16.1"), semver.MustParse("4.16.2"), semver.MustParse("4.16.4")},
+		},
+	}
+
+	type testCase struct {
+		caseName string
+		config   string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			caseName: "existing content",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.1
+      maxVersion: 4.16.4
+  operators:
+  - catalog: ` + catalog + `
+    packages:
+    - name: aws-load-balancer-operator
+      minVersion: 1.1.0
+    - name: 3scale-operator
+      channels:
+      - name: threescale-2.14
+`,
+		},
+		{
+			caseName: "release channels",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stabel-4.16
+    - name: stable-4.16
+      minVersion: 4.16.3
+      maxVersion: 4.16.5
+    - name: fast-4.16
+      minVersion: 4.16.4
+      maxVersion: 4.16.2
+    - name: candidate-4.16
+      minVersion: 4.16.5
+`,
+			expected: []string{
+				`6:13: error: mirror.platform.channels[0].name: channel "stabel-4.16" has no release for amd64, did you mean "stable-4.16"?`,
+				"8:19: warning: mirror.platform.channels[1].minVersion: channel \"stable-4.16\" has no release 4.16.3 for amd64, did you mean 4.16.2 or 4.16.4?",
+				"9:19: warning: mirror.platform.channels[1].maxVersion: channel \"stable-4.16\" has no release 4.16.5 for amd64, did you mean 4.16.4?",
+				`10:7: error: mirror.platform.channels[2]: minVersion 4.16.4 of channel "fast-4.16" is greater than its maxVersion 4.16.2`,
+				"14:19: warning: mirror.platform.channels[3].minVersion: channel \"candidate-4.16\" has no release 4.16.5 for amd64, did you mean 4.16.4?",
+				`13:7: error: mirror.platform.channels[3]: channel "candidate-4.16" has no release between 4.16.5 and 4.16.4 for amd64: nothing is mirrored`,
+			},
+		},
+		{
+			caseName: "operators",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  operators:
+  - catalog: ` + catalog + `
+    packages:
+    - name: aws-loadbalancer-operator
+    - name: aws-load-balancer-operator
+      minVersion: 1.3.0
+    - name: 3scale-operator
+      channels:
+      - name: threescale-2.15
+      - name: threescale-2.13
+        minVersion: 0.10.1
+        maxVersion: 0.10.0
+    - name: 3scale-operator
+      channels:
+      - name: threescale-2.13
+    - name: aws-load-balancer-operator
+      defaultChannel: stable
+    - name: aws-load-balancer-operator
+      defaultChannel: stable-v1
+      channels:
+      - name: stable-v0
+  - catalog: registry.redhat.io/redhat/certified-operator-index@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+`,
+			expected: []string{
+				`7:13: error: mirror.operators[0].packages[0].name: package "aws-loadbalancer-operator" does not exist in catalog ` + catalog + `, did you mean "aws-load-balancer-operator"?`,
+				`9:19: warning: mirror.operators[0].packages[1].minVersion: package "aws-load-balancer-operator" has no bundle version 1.3.0, did you mean 1.2.0?`,
+				`8:7: error: mirror.operators[0].packages[1]: package "aws-load-balancer-operator" has no bundle in the range >=1.3.0: nothing is mirrored`,
+				`12:15: error: mirror.operators[0].packages[2].channels[0].name: channel "threescale-2.15" does not exist in package "3scale-operator", did you mean "threescale-2.13" or "threescale-2.14"?`,
+				`14:21: warning: mirror.operators[0].packages[2].channels[1].minVersion: channel "threescale-2.13" of package "3scale-operator" has no bundle version 0.10.1, did you mean 0.10.0?`,
+				`13:9: error: mirror.operators[0].packages[2].channels[1]: minVersion 0.10.1 of channel "threescale-2.13" of package "3scale-operator" is greater than its maxVersion 0.10.0`,
+				`18:7: error: mirror.operators[0].packages[3].channels: the default channel "threescale-2.14" of package "3scale-operator" is not included: add it to the channels, or set defaultChannel to one of threescale-2.13`,
+				`20:23: error: mirror.operators[0].packages[4].defaultChannel: defaultChannel "stable" does not exist in package "aws-load-balancer-operator", did you mean "stable-v0" or "stable-v1"?`,
+				`22:23: error: mirror.operators[0].packages[5].defaultChannel: defaultChannel "stable-v1" of package "aws-load-balancer-operator" is not one of the included channels stable-v0`,
+				"25:14: error: mirror.operators[1].catalog: unable to load catalog registry.redhat.io/redhat/certified-operator-index@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea: manifest unknown",
+			},
+		},
+		{
+			caseName: "invalid configuration",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  operators:
+  - catalog: ` + catalog + `
+    packages:
+    - name: aws-load-balancer-operator
+      minversion: 1.1.0
+`,
+			expected: []string{
+				`8:7: error: mirror.operators[0].packages[0]: unknown field "minversion" in IncludePackage, did you mean "minVersion"? field names are case-sensitive`,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			var issues []string
+			for _, issue := range CheckContent(context.Background(), []byte(testCase.config), loader) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, testCase.expected, issues)
+		})
+	}
+}
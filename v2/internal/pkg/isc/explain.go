@@ -0,0 +1,72 @@
+package isc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// Explain writes the documentation of a field of the configuration, given by
+// its dotted path such as mirror.operators.packages, with the list of its fields.
+// When recursive, the fields of the fields are listed as well.
+func Explain(w io.Writer, kind, path string, recursive bool) error {
+	root, err := NewSchema(kind)
+	if err != nil {
+		return err
+	}
+	schema, err := root.Lookup(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "KIND:     %s\n", kind)
+	fmt.Fprintf(w, "VERSION:  %s\n\n", v2alpha1.GroupVersion.String())
+	if path != "" {
+		fmt.Fprintf(w, "FIELD:    %s <%s>\n\n", schema.Name, schema.TypeName)
+	}
+	fmt.Fprintf(w, "DESCRIPTION:\n%s\n", indent(description(schema), 1))
+	if enum := schema.object().Enum; len(enum) > 0 {
+		fmt.Fprintf(w, "\nVALUES:\n%s\n", indent(strings.Join(enum, "\n"), 1))
+	}
+
+	fields := schema.object().Fields
+	if len(fields) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nFIELDS:\n")
+	writeFields(w, fields, 1, recursive)
+	return nil
+}
+
+func writeFields(w io.Writer, fields []*Schema, depth int, recursive bool) {
+	for _, field := range fields {
+		required := ""
+		if field.Required {
+			required = " -required-"
+		}
+		fmt.Fprintf(w, "%s%s\t<%s>%s\n", strings.Repeat("  ", depth), field.Name, field.TypeName, required)
+		if recursive {
+			writeFields(w, field.object().Fields, depth+1, recursive)
+			continue
+		}
+		fmt.Fprintf(w, "%s\n\n", indent(description(field), depth+1))
+	}
+}
+
+func description(s *Schema) string {
+	if s.Description == "" {
+		return "<empty>"
+	}
+	return s.Description
+}
+
+func indent(text string, depth int) string {
+	prefix := strings.Repeat("  ", depth)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
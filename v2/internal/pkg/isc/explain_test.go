@@ -0,0 +1,81 @@
+package isc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func TestExplain(t *testing.T) {
+	t.Run("field", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Explain(&out, v2alpha1.ImageSetConfigurationKind, "mirror.operators.packages", false)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "KIND:     ImageSetConfiguration\nVERSION:  mirror.openshift.io/v2alpha1\n\nFIELD:    packages <[]IncludePackage>\n")
+		assert.Contains(t, out.String(), "  name\t<string> -required-\n    Name of package.\n")
+		assert.Contains(t, out.String(), "  minVersion\t<string>\n    MinVersion to include")
+	})
+	t.Run("recursive", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Explain(&out, v2alpha1.ImageSetConfigurationKind, "mirror.platform", true)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "  channels\t<[]ReleaseChannel>\n    name\t<string> -required-\n    type\t<PlatformType>\n")
+	})
+	t.Run("enum", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Explain(&out, v2alpha1.ImageSetConfigurationKind, "mirror.platform.channels.type", false)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "VALUES:\n  ocp\n  okd\n")
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		err := Explain(&bytes.Buffer{}, v2alpha1.DeleteImageSetConfigurationKind, "delete.operators.bundles", false)
+		assert.EqualError(t, err, `field "bundles" does not exist in []Operator`)
+	})
+	t.Run("unknown kind", func(t *testing.T) {
+		err := Explain(&bytes.Buffer{}, "ImageSet", "", false)
+		assert.EqualError(t, err, "kind must be one of ImageSetConfiguration, DeleteImageSetConfiguration")
+	})
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema(v2alpha1.ImageSetConfigurationKind)
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema               string   `json:"$schema"`
+		Title                string   `json:"title"`
+		Required             []string `json:"required"`
+		AdditionalProperties bool     `json:"additionalProperties"`
+		Properties           map[string]struct {
+			Properties map[string]struct {
+				Properties map[string]struct {
+					Items struct {
+						Properties map[string]struct {
+							Type        string   `json:"type"`
+							Description string   `json:"description"`
+							Enum        []string `json:"enum"`
+						} `json:"properties"`
+						Required []string `json:"required"`
+					} `json:"items"`
+				} `json:"properties"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema.Schema)
+	assert.Equal(t, v2alpha1.ImageSetConfigurationKind, schema.Title)
+	assert.Equal(t, []string{"kind", "apiVersion"}, schema.Required)
+	assert.False(t, schema.AdditionalProperties)
+
+	channel := schema.Properties["mirror"].Properties["platform"].Properties["channels"].Items
+	assert.Equal(t, []string{"name"}, channel.Required)
+	assert.Equal(t, "string", channel.Properties["minVersion"].Type)
+	assert.Contains(t, channel.Properties["minVersion"].Description, "MinVersion")
+	assert.Equal(t, "boolean", channel.Properties["full"].Type)
+	assert.Equal(t, []string{"ocp", "okd"}, channel.Properties["type"].Enum)
+}
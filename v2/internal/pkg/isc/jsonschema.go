@@ -0,0 +1,45 @@
+package isc
+
+import "encoding/json"
+
+// JSONSchema returns the JSON Schema of an imageset configuration kind, for the editors
+// validating and completing the configuration as it is written
+func JSONSchema(kind string) ([]byte, error) {
+	root, err := NewSchema(kind)
+	if err != nil {
+		return nil, err
+	}
+	doc := jsonSchema(root)
+	doc["$schema"] = jsonSchemaDraft
+	doc["title"] = kind
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func jsonSchema(s *Schema) map[string]any {
+	doc := map[string]any{"type": s.Kind}
+	if s.Description != "" {
+		doc["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		doc["enum"] = s.Enum
+	}
+	switch s.Kind {
+	case kindObject:
+		properties := map[string]any{}
+		var required []string
+		for _, field := range s.Fields {
+			properties[field.Name] = jsonSchema(field)
+			if field.Required {
+				required = append(required, field.Name)
+			}
+		}
+		doc["properties"] = properties
+		doc["additionalProperties"] = false
+		if len(required) > 0 {
+			doc["required"] = required
+		}
+	case kindArray:
+		doc["items"] = jsonSchema(s.Items)
+	}
+	return doc
+}
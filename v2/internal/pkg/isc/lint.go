@@ -0,0 +1,124 @@
+package isc
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// Lint returns the issues of an ImageSetConfiguration which mirrors other content than usually expected:
+//   - heads-only release channels and packages, which only mirror the latest release or bundle
+//   - catalogs not pinned by digest
+//   - packages and channels listed more than once
+//   - full: true combined with version filters
+//
+// The configuration must be valid, its validation issues are returned otherwise.
+func Lint(data []byte) []Issue {
+	if issues := Validate(data); HasErrors(issues) {
+		return issues
+	}
+	root, _ := parse(data)
+	if kind := scalarValue(mappingValue(root, "kind")); kind != v2alpha1.ImageSetConfigurationKind {
+		return []Issue{nodeIssue(root, "kind", fmt.Sprintf("lint only supports the kind %s", v2alpha1.ImageSetConfigurationKind))}
+	}
+	cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration](data, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	l := &linter{root: root}
+	l.lintReleaseChannels(cfg.Mirror.Platform.Channels)
+	for i, op := range cfg.Mirror.Operators {
+		l.lintOperator(fmt.Sprintf("mirror.operators[%d]", i), op)
+	}
+	for i, composite := range cfg.Mirror.CompositeCatalogs {
+		for j, op := range composite.Catalogs {
+			l.lintOperator(fmt.Sprintf("mirror.compositeCatalogs[%d].catalogs[%d]", i, j), op)
+		}
+	}
+	return l.issues
+}
+
+type linter struct {
+	root   *yaml.Node
+	issues []Issue
+}
+
+func (l *linter) warn(path, format string, args ...any) {
+	l.report(SeverityWarning, path, format, args...)
+}
+
+func (l *linter) report(severity, path, format string, args ...any) {
+	issue := Issue{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)}
+	node := pathNode(l.root, path)
+	issue.Line, issue.Column = node.Line, node.Column
+	l.issues = append(l.issues, issue)
+}
+
+func (l *linter) lintReleaseChannels(channels []v2alpha1.ReleaseChannel) {
+	for i, ch := range channels {
+		path := fmt.Sprintf("mirror.platform.channels[%d]", i)
+		switch {
+		case ch.MinVersion == "" && ch.MaxVersion == "" && !ch.Full:
+			l.warn(path, "only the latest release of channel %q is mirrored: set minVersion, maxVersion or full: true to mirror older releases", ch.Name)
+		case ch.MinVersion == "" && ch.MaxVersion != "":
+			l.warn(path, "all the releases of channel %q up to maxVersion %s are mirrored, from the first release of the channel: set minVersion to limit them", ch.Name, ch.MaxVersion)
+		case ch.MinVersion != "" && ch.MaxVersion != "" && ch.Full:
+			l.warn(path, "full: true has no effect on channel %q, only the releases between minVersion and maxVersion are mirrored", ch.Name)
+		}
+	}
+}
+
+func (l *linter) lintOperator(path string, op v2alpha1.Operator) {
+	if !op.IsFBCOCI() && !op.IsFBCDir() {
+		if imgSpec, err := image.ParseRef(op.Catalog); err == nil && !imgSpec.IsImageByDigest() {
+			l.warn(path+".catalog", "catalog %s is not pinned by digest: the mirrored content changes with the catalog tag, pin it with @sha256:<digest> for reproducible mirrorings", op.Catalog)
+		}
+	}
+	if len(op.Packages) == 0 {
+		if !op.Full {
+			l.warn(path, "only the channel heads of all the packages of catalog %s are mirrored: list packages or set full: true", op.Catalog)
+		}
+		return
+	}
+
+	seenPackages := map[string]int{}
+	for i, pkg := range op.Packages {
+		pkgPath := fmt.Sprintf("%s.packages[%d]", path, i)
+		if first, ok := seenPackages[pkg.Name]; ok {
+			l.warn(pkgPath, "package %q is already listed at packages[%d]: its channels are merged, and its version filters are the ones of the last entry only", pkg.Name, first)
+		} else {
+			seenPackages[pkg.Name] = i
+		}
+
+		pkgFiltered := pkg.MinVersion != "" || pkg.MaxVersion != ""
+		channelsFiltered := false
+		seenChannels := map[string]int{}
+		for j, ch := range pkg.Channels {
+			if first, ok := seenChannels[ch.Name]; ok {
+				l.warn(fmt.Sprintf("%s.channels[%d]", pkgPath, j), "channel %q of package %q is already listed at channels[%d]: only the last entry is used", ch.Name, pkg.Name, first)
+			} else {
+				seenChannels[ch.Name] = j
+			}
+			channelsFiltered = channelsFiltered || ch.MinVersion != "" || ch.MaxVersion != ""
+		}
+
+		switch {
+		case op.Full && (pkgFiltered || channelsFiltered):
+			l.report(SeverityError, pkgPath, "full: true can not be mixed with the minVersion and maxVersion of package %q: filtering the catalog fails", pkg.Name)
+		case op.Full || pkgFiltered:
+		case len(pkg.Channels) == 0:
+			l.warn(pkgPath, "only the head of each channel of package %q is mirrored: set minVersion, maxVersion or full: true to mirror older bundles", pkg.Name)
+		default:
+			for j, ch := range pkg.Channels {
+				if ch.MinVersion == "" && ch.MaxVersion == "" {
+					l.warn(fmt.Sprintf("%s.channels[%d]", pkgPath, j), "only the head of channel %q of package %q is mirrored: set minVersion or maxVersion to mirror older bundles", ch.Name, pkg.Name)
+				}
+			}
+		}
+	}
+}
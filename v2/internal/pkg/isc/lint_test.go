@@ -0,0 +1,126 @@
+package isc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	type testCase struct {
+		caseName string
+		config   string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			caseName: "no surprise",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.1
+      maxVersion: 4.16.10
+  operators:
+  - catalog: oci:///home/user/catalogs/redhat-operator-index
+    packages:
+    - name: aws-load-balancer-operator
+      minVersion: 1.1.0
+`,
+		},
+		{
+			caseName: "release channels",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.15
+    - name: stable-4.16
+      maxVersion: 4.16.10
+    - name: stable-4.17
+      minVersion: 4.17.1
+      maxVersion: 4.17.5
+      full: true
+`,
+			expected: []string{
+				`6:7: warning: mirror.platform.channels[0]: only the latest release of channel "stable-4.15" is mirrored: set minVersion, maxVersion or full: true to mirror older releases`,
+				`7:7: warning: mirror.platform.channels[1]: all the releases of channel "stable-4.16" up to maxVersion 4.16.10 are mirrored, from the first release of the channel: set minVersion to limit them`,
+				`9:7: warning: mirror.platform.channels[2]: full: true has no effect on channel "stable-4.17", only the releases between minVersion and maxVersion are mirrored`,
+			},
+		},
+		{
+			caseName: "operators",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+  - catalog: registry.redhat.io/redhat/certified-operator-index@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea
+    packages:
+    - name: aws-load-balancer-operator
+    - name: 3scale-operator
+      channels:
+      - name: threescale-2.13
+      - name: threescale-2.14
+        minVersion: 0.11.0
+      - name: threescale-2.13
+    - name: aws-load-balancer-operator
+      minVersion: 1.1.0
+  - catalog: dir:///home/user/catalogs/community-operator-index
+    full: true
+    packages:
+    - name: cert-manager
+      maxVersion: 1.14.0
+`,
+			expected: []string{
+				"5:14: warning: mirror.operators[0].catalog: catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 is not pinned by digest: the mirrored content changes with the catalog tag, pin it with @sha256:<digest> for reproducible mirrorings",
+				"5:5: warning: mirror.operators[0]: only the channel heads of all the packages of catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 are mirrored: list packages or set full: true",
+				`8:7: warning: mirror.operators[1].packages[0]: only the head of each channel of package "aws-load-balancer-operator" is mirrored: set minVersion, maxVersion or full: true to mirror older bundles`,
+				`14:9: warning: mirror.operators[1].packages[1].channels[2]: channel "threescale-2.13" of package "3scale-operator" is already listed at channels[0]: only the last entry is used`,
+				`11:9: warning: mirror.operators[1].packages[1].channels[0]: only the head of channel "threescale-2.13" of package "3scale-operator" is mirrored: set minVersion or maxVersion to mirror older bundles`,
+				`14:9: warning: mirror.operators[1].packages[1].channels[2]: only the head of channel "threescale-2.13" of package "3scale-operator" is mirrored: set minVersion or maxVersion to mirror older bundles`,
+				`15:7: warning: mirror.operators[1].packages[2]: package "aws-load-balancer-operator" is already listed at packages[0]: its channels are merged, and its version filters are the ones of the last entry only`,
+				`20:7: error: mirror.operators[2].packages[0]: full: true can not be mixed with the minVersion and maxVersion of package "cert-manager": filtering the catalog fails`,
+			},
+		},
+		{
+			caseName: "invalid configuration",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minversion: 4.16.1
+`,
+			expected: []string{
+				`7:7: error: mirror.platform.channels[0]: unknown field "minversion" in ReleaseChannel, did you mean "minVersion"? field names are case-sensitive`,
+			},
+		},
+		{
+			caseName: "delete configuration",
+			config: `kind: DeleteImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+delete:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.1
+      maxVersion: 4.16.10
+`,
+			expected: []string{"1:7: error: kind: lint only supports the kind ImageSetConfiguration"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			var issues []string
+			for _, issue := range Lint([]byte(testCase.config)) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, testCase.expected, issues)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package isc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// Schema describes a field of an imageset configuration, and the values it accepts.
+// It is built from the v2alpha1 types and their doc comments.
+type Schema struct {
+	// Name is the name of the field in the configuration, empty for the root
+	Name string
+	// TypeName is the Go type of the field, such as []IncludePackage
+	TypeName string
+	// Kind is the JSON Schema type of the field values
+	Kind        string
+	Description string
+	Required    bool
+	// Enum lists the accepted values, when restricted
+	Enum []string
+	// Fields of an object
+	Fields []*Schema
+	// Items of an array
+	Items *Schema
+}
+
+// Field returns the field of an object by name, or nil
+func (s *Schema) Field(name string) *Schema {
+	for _, field := range s.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// object returns the schema holding the fields: the schema itself, or the items of an array
+func (s *Schema) object() *Schema {
+	if s.Kind == kindArray {
+		return s.Items.object()
+	}
+	return s
+}
+
+// Lookup returns the schema of a dotted path of fields, such as mirror.operators.packages
+func (s *Schema) Lookup(path string) (*Schema, error) {
+	current := s
+	if path == "" {
+		return current, nil
+	}
+	for _, name := range strings.Split(path, ".") {
+		field := current.object().Field(name)
+		if field == nil {
+			return nil, fmt.Errorf("field %q does not exist in %s", name, current.TypeName)
+		}
+		current = field
+	}
+	return current, nil
+}
+
+var platformTypeType = reflect.TypeOf(v2alpha1.PlatformType(0))
+
+// NewSchema returns the schema of an imageset configuration kind:
+// ImageSetConfiguration or DeleteImageSetConfiguration
+func NewSchema(kind string) (*Schema, error) {
+	var configType reflect.Type
+	switch kind {
+	case v2alpha1.ImageSetConfigurationKind:
+		configType = reflect.TypeOf(v2alpha1.ImageSetConfiguration{})
+	case v2alpha1.DeleteImageSetConfigurationKind:
+		configType = reflect.TypeOf(v2alpha1.DeleteImageSetConfiguration{})
+	default:
+		return nil, fmt.Errorf("kind must be one of %s", strings.Join(supportedKinds, ", "))
+	}
+	docs := v2alpha1.ConfigDocs()
+	root := newTypeSchema(configType, docs)
+	root.Description = docs.Types[configType.Name()]
+	for _, field := range root.Fields {
+		switch field.Name {
+		case "apiVersion":
+			field.Description = "APIVersion of the configuration."
+			field.Enum = []string{v2alpha1.GroupVersion.String()}
+			field.Required = true
+		case "kind":
+			field.Description = "Kind of the configuration."
+			field.Enum = []string{kind}
+			field.Required = true
+		}
+	}
+	return root, nil
+}
+
+func newTypeSchema(t reflect.Type, docs v2alpha1.TypeDocs) *Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := &Schema{TypeName: typeName(t)}
+	switch {
+	case t == platformTypeType:
+		s.Kind = kindString
+		s.Enum = []string{v2alpha1.TypeOCP.String(), v2alpha1.TypeOKD.String()}
+	case t.Kind() == reflect.Struct:
+		s.Kind = kindObject
+		s.Fields = structFields(t, docs)
+	case t.Kind() == reflect.Slice:
+		s.Kind = kindArray
+		s.Items = newTypeSchema(t.Elem(), docs)
+	case t.Kind() == reflect.Bool:
+		s.Kind = kindBoolean
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int64:
+		s.Kind = kindInteger
+	default:
+		s.Kind = kindString
+	}
+	return s
+}
+
+// structFields returns the fields of a struct, with the fields of the inlined structs
+func structFields(t reflect.Type, docs v2alpha1.TypeDocs) []*Schema {
+	var fields []*Schema
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && (field.Anonymous || strings.Contains(options, "inline")) {
+			fields = append(fields, structFields(field.Type, docs)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s := newTypeSchema(field.Type, docs)
+		s.Name = name
+		s.Description = docs.Fields[t.Name()+"."+field.Name]
+		// the strings without default value are required
+		s.Required = s.Kind == kindString && s.Enum == nil && !strings.Contains(options, "omitempty")
+		fields = append(fields, s)
+	}
+	return fields
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	}
+	return t.Name()
+}
@@ -0,0 +1,316 @@
+package isc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+)
+
+// Issue is a problem found in an imageset configuration
+type Issue struct {
+	// Line and Column of the issue in the configuration, 0 when unknown
+	Line     int
+	Column   int
+	Severity string
+	// Path of the field, such as mirror.operators[0].packages[1]
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "%d:%d: ", i.Line, i.Column)
+	}
+	b.WriteString(i.Severity + ": ")
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// HasErrors determines whether some issues are errors
+func HasErrors(issues []Issue) bool {
+	return slices.ContainsFunc(issues, func(i Issue) bool { return i.Severity == SeverityError })
+}
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// Validate checks an imageset configuration strictly, and returns all its issues:
+// the unknown fields, the values of the wrong type, the missing required fields,
+// and then the input errors found by the mirroring
+func Validate(data []byte) []Issue {
+	root, issues := parse(data)
+	if root == nil {
+		return issues
+	}
+	kind := scalarValue(mappingValue(root, "kind"))
+	schema, err := NewSchema(kind)
+	if err != nil {
+		return []Issue{nodeIssue(root, "kind", err.Error())}
+	}
+
+	v := &validator{}
+	v.walk(root, schema, "")
+	if len(v.issues) > 0 {
+		return v.issues
+	}
+
+	// the structure is valid: the configuration is decoded as by the mirroring
+	var errs []error
+	switch kind {
+	case v2alpha1.ImageSetConfigurationKind:
+		cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration](data, kind)
+		if err != nil {
+			return []Issue{{Severity: SeverityError, Message: err.Error()}}
+		}
+		config.Complete(&cfg)
+		errs = config.ValidationErrors(&cfg)
+	case v2alpha1.DeleteImageSetConfigurationKind:
+		cfg, err := config.LoadConfig[v2alpha1.DeleteImageSetConfiguration](data, kind)
+		if err != nil {
+			return []Issue{{Severity: SeverityError, Message: err.Error()}}
+		}
+		config.CompleteDelete(&cfg)
+		errs = config.DeleteValidationErrors(&cfg)
+	}
+	if agg := utilerrors.NewAggregate(errs); agg != nil {
+		for _, err := range utilerrors.Flatten(agg).Errors() {
+			issues = append(issues, semanticIssue(root, err))
+		}
+	}
+	return issues
+}
+
+// parse returns the root mapping of the configuration
+func parse(data []byte) (*yaml.Node, []Issue) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		issue := Issue{Severity: SeverityError, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if match := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+			issue.Line, _ = strconv.Atoi(match[1])
+			issue.Column = 1
+		}
+		return nil, []Issue{issue}
+	}
+	if len(doc.Content) == 0 {
+		return nil, []Issue{{Severity: SeverityError, Message: "the configuration is empty"}}
+	}
+	root := resolve(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, []Issue{{Line: root.Line, Column: root.Column, Severity: SeverityError, Message: "the configuration must be a mapping"}}
+	}
+	return root, nil
+}
+
+// semanticIssue reports an error of the mirroring at the position of its field, when known
+func semanticIssue(root *yaml.Node, err error) Issue {
+	issue := Issue{Severity: SeverityError, Message: err.Error()}
+	var fieldErr *config.FieldError
+	if errors.As(err, &fieldErr) {
+		node := pathNode(root, fieldErr.Path)
+		issue.Line, issue.Column = node.Line, node.Column
+	}
+	return issue
+}
+
+// pathNode returns the node of a field path, such as mirror.operators[0].packages[1],
+// or of its closest parent found in the configuration
+func pathNode(root *yaml.Node, path string) *yaml.Node {
+	node := root
+	for _, segment := range strings.Split(path, ".") {
+		name, indexes, _ := strings.Cut(segment, "[")
+		value := mappingValue(node, name)
+		if value == nil {
+			return node
+		}
+		node = value
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			if err != nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+				return node
+			}
+			node = resolve(node.Content[i])
+		}
+	}
+	return node
+}
+
+type validator struct {
+	issues []Issue
+}
+
+func (v *validator) add(node *yaml.Node, path, format string, args ...any) {
+	v.issues = append(v.issues, Issue{Line: node.Line, Column: node.Column, Severity: SeverityError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) walk(node *yaml.Node, schema *Schema, path string) {
+	node = resolve(node)
+	if node.Tag == "!!null" {
+		if schema.Required {
+			v.add(node, path, "a value is required")
+		}
+		return
+	}
+	switch schema.Kind {
+	case kindObject:
+		if node.Kind != yaml.MappingNode {
+			v.add(node, path, "must be a mapping of %s fields", schema.TypeName)
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := joinPath(path, key.Value)
+			if seen[key.Value] {
+				v.add(key, fieldPath, "duplicate field %q", key.Value)
+				continue
+			}
+			seen[key.Value] = true
+			field := schema.Field(key.Value)
+			if field == nil {
+				v.add(key, path, "%s", unknownField(key.Value, schema))
+				continue
+			}
+			v.walk(value, field, fieldPath)
+		}
+		for _, field := range schema.Fields {
+			if field.Required && !seen[field.Name] {
+				v.add(node, path, "missing required field %q", field.Name)
+			}
+		}
+	case kindArray:
+		if node.Kind != yaml.SequenceNode {
+			v.add(node, path, "must be a list of %s", schema.Items.TypeName)
+			return
+		}
+		for i, item := range node.Content {
+			v.walk(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.add(node, path, "must be a %s", schema.Kind)
+			return
+		}
+		v.checkScalar(node, schema, path)
+	}
+}
+
+func (v *validator) checkScalar(node *yaml.Node, schema *Schema, path string) {
+	switch schema.Kind {
+	case kindBoolean:
+		if node.Tag != "!!bool" {
+			v.add(node, path, "must be true or false, got %q", node.Value)
+			return
+		}
+	case kindInteger:
+		if node.Tag != "!!int" {
+			v.add(node, path, "must be an integer, got %q", node.Value)
+			return
+		}
+	case kindString:
+		// 4.10 would be read as the number 4.1
+		if node.Tag != "!!str" {
+			v.add(node, path, "must be a string, got %s %s: quote it to keep it as is", strings.TrimPrefix(node.Tag, "!!"), node.Value)
+			return
+		}
+	}
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, node.Value) {
+		v.add(node, path, "must be one of %s, got %q", strings.Join(schema.Enum, ", "), node.Value)
+	}
+}
+
+// unknownField suggests the closest field of the object, since
+// the field names are case-sensitive in the configuration
+func unknownField(name string, schema *Schema) string {
+	message := fmt.Sprintf("unknown field %q in %s", name, schema.TypeName)
+	best, bestDistance := "", 3
+	for _, field := range schema.Fields {
+		if strings.EqualFold(field.Name, name) {
+			return message + fmt.Sprintf(", did you mean %q? field names are case-sensitive", field.Name)
+		}
+		if distance := levenshtein(strings.ToLower(name), strings.ToLower(field.Name)); distance < bestDistance {
+			best, bestDistance = field.Name, distance
+		}
+	}
+	if best != "" {
+		return message + fmt.Sprintf(", did you mean %q?", best)
+	}
+	return message
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolve(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// nodeIssue reports an error on the value of a key of the mapping, or on the mapping when missing
+func nodeIssue(mapping *yaml.Node, key, message string) Issue {
+	node := mappingValue(mapping, key)
+	if node == nil {
+		node = mapping
+	}
+	return Issue{Line: node.Line, Column: node.Column, Severity: SeverityError, Path: key, Message: message}
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
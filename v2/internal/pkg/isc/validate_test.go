@@ -0,0 +1,147 @@
+package isc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		caseName string
+		config   string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			caseName: "valid configuration",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minVersion: 4.16.1
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: aws-load-balancer-operator
+`,
+		},
+		{
+			caseName: "all the structural errors are reported with their lines",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      minversion: 4.16.1
+      full: yes
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: aws-load-balancer-operator
+      maxVersion: 1.10
+    - name: 3scale-operator
+      chanels:
+      - name: stable
+  - packages:
+    - name: node-observability-operator
+`,
+			expected: []string{
+				`7:7: error: mirror.platform.channels[0]: unknown field "minversion" in ReleaseChannel, did you mean "minVersion"? field names are case-sensitive`,
+				`8:13: error: mirror.platform.channels[0].full: must be true or false, got "yes"`,
+				`13:19: error: mirror.operators[0].packages[0].maxVersion: must be a string, got float 1.10: quote it to keep it as is`,
+				`15:7: error: mirror.operators[0].packages[1]: unknown field "chanels" in IncludePackage, did you mean "channels"?`,
+				`17:5: error: mirror.operators[1]: missing required field "catalog"`,
+			},
+		},
+		{
+			caseName: "unknown kind",
+			config: `kind: ImageSetConfig
+apiVersion: mirror.openshift.io/v2alpha1
+`,
+			expected: []string{"1:7: error: kind: kind must be one of ImageSetConfiguration, DeleteImageSetConfiguration"},
+		},
+		{
+			caseName: "wrong apiVersion",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v1alpha2
+`,
+			expected: []string{`2:13: error: apiVersion: must be one of mirror.openshift.io/v2alpha1, got "mirror.openshift.io/v1alpha2"`},
+		},
+		{
+			caseName: "duplicate field",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.redhat.io/ubi8/ubi:latest
+    name: registry.redhat.io/ubi9/ubi:latest
+`,
+			expected: []string{`6:5: error: mirror.additionalImages[0].name: duplicate field "name"`},
+		},
+		{
+			caseName: "syntax error",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  additionalImages:
+  - name: registry.redhat.io/ubi9/ubi: latest
+`,
+			expected: []string{"5:1: error: line 5: mapping values are not allowed in this context"},
+		},
+		{
+			caseName: "errors of the mirroring",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+      eusPath: true
+    releases:
+    - version: 4.16.1
+      image: quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64
+`,
+			expected: []string{
+				`6:7: error: release channel "stable-4.16": eusPath requires minVersion and maxVersion`,
+				"9:7: error: releases[0]: version and image are mutually exclusive",
+			},
+		},
+		{
+			caseName: "errors of the mirroring are reported on their fields",
+			config: `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: aws-load-balancer-operator
+      channels:
+      - name: stable-v1
+        minVersion: not-a-version
+`,
+			expected: []string{
+				`10:21: error: catalog "registry.redhat.io/redhat/redhat-operator-index:v4.16": operator "aws-load-balancer-operator": channel "stable-v1": minVersion "not-a-version" must respect semantic versioning notation`,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			var issues []string
+			for _, issue := range Validate([]byte(testCase.config)) {
+				issues = append(issues, issue.String())
+			}
+			assert.Equal(t, testCase.expected, issues)
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("channels", "channels"))
+	assert.Equal(t, 1, levenshtein("chanels", "channels"))
+	assert.Equal(t, 2, levenshtein("packges", "packages2"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
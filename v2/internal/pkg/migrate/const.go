@@ -0,0 +1,12 @@
+package migrate
+
+const (
+	dockerProtocol string = "docker://"
+	fileProtocol   string = "file://"
+	s3Protocol     string = "s3://"
+	// metadataBasePath is the path of the v1 metadata, in the local backend
+	// directory and in the image of the registry backend
+	metadataBasePath string = "publish/.metadata.json"
+	// metadataDefaultTag is the tag of the v1 registry backend image when its imageURL has none
+	metadataDefaultTag string = "latest"
+)
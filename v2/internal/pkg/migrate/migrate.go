@@ -0,0 +1,165 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// Unsupported is a field of the v1 ImageSetConfiguration
+// which could not be translated as is to v2alpha1
+type Unsupported struct {
+	Field  string
+	Reason string
+}
+
+func (u Unsupported) String() string {
+	return u.Field + ": " + u.Reason
+}
+
+// ReadMetadata reads the v1 metadata through the storage backend of the v1 ImageSetConfiguration,
+// or through the local backend of the v1 workspace when set
+func ReadMetadata(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, v1Workspace string) (v1alpha2.Metadata, error) {
+	var meta v1alpha2.Metadata
+	storageConfig := cfg.StorageConfig
+	if v1Workspace != "" {
+		storageConfig = v1alpha2.StorageConfig{Local: &v1alpha2.LocalConfig{Path: v1Workspace}}
+	}
+	if !storageConfig.IsSet() {
+		return meta, fmt.Errorf("the v1 ImageSetConfiguration has no storageConfig: use --v1-workspace to read the metadata of an oc-mirror-workspace")
+	}
+
+	var data []byte
+	var err error
+	if storageConfig.Registry != nil {
+		data, err = readRegistryMetadata(ctx, *storageConfig.Registry)
+	} else {
+		data, err = readLocalMetadata(storageConfig.Local.Path)
+	}
+	if errors.Is(err, errMetadataNotExist) {
+		return meta, fmt.Errorf("no v1 metadata found in %s", describeBackend(storageConfig))
+	}
+	if err != nil {
+		return meta, fmt.Errorf("unable to read the v1 metadata from %s: %v", describeBackend(storageConfig), err)
+	}
+	meta, err = loadMetadata(data)
+	if err != nil {
+		return meta, fmt.Errorf("unable to read the v1 metadata from %s: %v", describeBackend(storageConfig), err)
+	}
+	return meta, nil
+}
+
+// HistoryBlobs returns the digests of the manifests and blobs of the images of the v1 metadata,
+// the same digests as the ones in the v2 history of an archive
+func HistoryBlobs(meta v1alpha2.Metadata) map[string]string {
+	blobs := make(map[string]string)
+	add := func(d string) {
+		if _, err := digest.Parse(d); err == nil {
+			blobs[d] = ""
+		}
+	}
+	associations := append(append([]v1alpha2.Association{}, meta.PastAssociations...), meta.PastMirror.Associations...)
+	for _, association := range associations {
+		add(association.ID)
+		for _, d := range association.ManifestDigests {
+			add(d)
+		}
+		for _, d := range association.LayerDigests {
+			add(d)
+		}
+	}
+	return blobs
+}
+
+// ConvertConfig translates a v1alpha2 ImageSetConfiguration into v2alpha1.
+// The fields without v2alpha1 equivalent are dropped, or converted to their closest
+// equivalent, and returned as Unsupported.
+func ConvertConfig(cfg v1alpha2.ImageSetConfiguration) (v2alpha1.ImageSetConfiguration, []Unsupported, error) {
+	var unsupported []Unsupported
+	isc := v2alpha1.ImageSetConfiguration{}
+	isc.SetGroupVersionKind(v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind))
+	isc.ArchiveSize = cfg.ArchiveSize
+
+	if cfg.StorageConfig.IsSet() {
+		unsupported = append(unsupported, Unsupported{
+			Field:  "storageConfig",
+			Reason: "v2 keeps its state in the working-dir, " + stateBackend(cfg.StorageConfig) + " to share it between runners",
+		})
+	}
+
+	// the v1 operators are copied, so that the dropped fields are not removed from the v1 configuration
+	v1Mirror := cfg.Mirror
+	v1Mirror.Operators = make([]v1alpha2.Operator, len(cfg.Mirror.Operators))
+	for i, op := range cfg.Mirror.Operators {
+		field := fmt.Sprintf("mirror.operators[%d]", i)
+		if op.TargetName != "" {
+			if op.TargetCatalog == "" {
+				op.TargetCatalog = op.TargetName
+				unsupported = append(unsupported, Unsupported{Field: field + ".targetName", Reason: "replaced by targetCatalog: " + op.TargetName})
+			} else {
+				unsupported = append(unsupported, Unsupported{Field: field + ".targetName", Reason: "dropped, targetCatalog is set"})
+			}
+			op.TargetName = ""
+		}
+		if op.OriginalRef != "" {
+			unsupported = append(unsupported, Unsupported{Field: field + ".originalRef", Reason: "dropped, the catalog is used as is"})
+			op.OriginalRef = ""
+		}
+		op.Packages = slices.Clone(op.Packages)
+		for j := range op.Packages {
+			pkg := &op.Packages[j]
+			pkgField := fmt.Sprintf("%s.packages[%d]", field, j)
+			if pkg.MinBundle != "" {
+				unsupported = append(unsupported, Unsupported{Field: pkgField + ".minBundle", Reason: "dropped, use minVersion instead"})
+				pkg.MinBundle = ""
+			}
+			pkg.Channels = slices.Clone(pkg.Channels)
+			for k := range pkg.Channels {
+				if pkg.Channels[k].MinBundle != "" {
+					unsupported = append(unsupported, Unsupported{Field: fmt.Sprintf("%s.channels[%d].minBundle", pkgField, k), Reason: "dropped, use minVersion instead"})
+					pkg.Channels[k].MinBundle = ""
+				}
+			}
+		}
+		v1Mirror.Operators[i] = op
+	}
+
+	// the remaining fields of v1alpha2 have the same json representation in v2alpha1:
+	// any field left would fail the decoding
+	data, err := json.Marshal(v1Mirror)
+	if err != nil {
+		return isc, unsupported, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&isc.Mirror); err != nil {
+		return isc, unsupported, fmt.Errorf("unable to translate the v1 ImageSetConfiguration: %v", err)
+	}
+	return isc, unsupported, nil
+}
+
+// stateBackend suggests the v2 state backend replacing the v1 storage config.
+// The image of the v1 registry backend keeps the v1 metadata and has no v2 equivalent:
+// a shared directory or an S3 bucket is suggested instead.
+func stateBackend(storageConfig v1alpha2.StorageConfig) string {
+	if storageConfig.Registry != nil {
+		return "the v1 registry backend " + storageConfig.Registry.ImageURL + " has no v2 equivalent, use --state-backend " +
+			fileProtocol + "<shared directory> or " + s3Protocol + "<bucket>/<prefix>"
+	}
+	return "use --state-backend " + fileProtocol + storageConfig.Local.Path
+}
+
+func describeBackend(storageConfig v1alpha2.StorageConfig) string {
+	if storageConfig.Registry != nil {
+		return "registry " + storageConfig.Registry.ImageURL
+	}
+	return "directory " + storageConfig.Local.Path
+}
@@ -0,0 +1,237 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+const (
+	manifestDigest = "sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"
+	childDigest    = "sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d"
+	layerDigest    = "sha256:1ff4ea896d6b958aa060e04eb090f70c563ad0650e6b362c1a1d67582acb3b8e"
+	configDigest   = "sha256:25d123725cf91c20b497ca9dae8e0a6e8dedd8fe64f83757f3b41f6ac447eac0"
+)
+
+func v1Metadata() v1alpha2.Metadata {
+	meta := v1alpha2.NewMetadata()
+	meta.PastMirror = v1alpha2.PastMirror{
+		Sequence: 3,
+		Associations: []v1alpha2.Association{
+			{Name: "quay.io/openshift-release-dev/ocp-release:4.14.1-x86_64", ID: manifestDigest, Type: v1alpha2.TypeOCPRelease, ManifestDigests: []string{childDigest}},
+			{Name: "quay.io/openshift-release-dev/ocp-release@" + childDigest, ID: childDigest, Type: v1alpha2.TypeOCPRelease, LayerDigests: []string{layerDigest, configDigest}},
+			// the v1 tags are not digests
+			{Name: "registry.redhat.io/ubi9/ubi:latest", ID: "latest", Type: v1alpha2.TypeGeneric, LayerDigests: []string{layerDigest}},
+		},
+	}
+	meta.PastAssociations = []v1alpha2.Association{
+		{Name: "quay.io/openshift-release-dev/ocp-release:4.14.0-x86_64", ID: manifestDigest, Type: v1alpha2.TypeOCPRelease},
+	}
+	return meta
+}
+
+func TestHistoryBlobs(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		manifestDigest: "",
+		childDigest:    "",
+		layerDigest:    "",
+		configDigest:   "",
+	}, HistoryBlobs(v1Metadata()))
+	assert.Empty(t, HistoryBlobs(v1alpha2.NewMetadata()))
+}
+
+func TestConvertConfig(t *testing.T) {
+	v1Config := v1alpha2.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+			ArchiveSize:   4,
+			StorageConfig: v1alpha2.StorageConfig{Registry: &v1alpha2.RegistryConfig{ImageURL: "registry.example.com/oc-mirror/metadata:latest"}},
+			Mirror: v1alpha2.Mirror{
+				Platform: v1alpha2.Platform{
+					Graph:         true,
+					Architectures: []string{"amd64"},
+					Channels:      []v1alpha2.ReleaseChannel{{Name: "stable-4.14", Type: v1alpha2.TypeOKD, MinVersion: "4.14.1", ShortestPath: true}},
+				},
+				Operators: []v1alpha2.Operator{
+					{
+						Catalog:    "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+						TargetName: "my-index",
+						IncludeConfig: v1alpha2.IncludeConfig{Packages: []v1alpha2.IncludePackage{
+							{Name: "aws-load-balancer-operator", IncludeBundle: v1alpha2.IncludeBundle{MinBundle: "aws-load-balancer-operator.v1.0.0"}},
+							{Name: "node-observability-operator", Channels: []v1alpha2.IncludeChannel{
+								{Name: "alpha", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.1.0", MinBundle: "node-observability-operator.v0.1.0"}},
+							}},
+						}},
+					},
+					{Catalog: "oci:///home/user/catalog", TargetName: "ignored", TargetCatalog: "ns/catalog", OriginalRef: "registry.example.com/ns/catalog:v1", Full: true},
+				},
+				AdditionalImages: []v1alpha2.Image{{Name: "registry.redhat.io/ubi9/ubi:latest"}},
+				Helm:             v1alpha2.Helm{Repositories: []v1alpha2.Repository{{Name: "podinfo", URL: "https://stefanprodan.github.io/podinfo", Charts: []v1alpha2.Chart{{Name: "podinfo", Version: "5.0.0"}}}}},
+				BlockedImages:    []v1alpha2.Image{{Name: "registry.redhat.io/ubi9/ubi-micro"}},
+				Samples:          []v1alpha2.SampleImages{{Image: v1alpha2.Image{Name: "ruby"}}},
+			},
+		},
+	}
+
+	isc, unsupported, err := ConvertConfig(v1Config)
+	assert.NoError(t, err)
+	assert.Equal(t, v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind), isc.GroupVersionKind())
+	assert.Equal(t, int64(4), isc.ArchiveSize)
+	assert.Equal(t, v2alpha1.Platform{
+		Graph:         true,
+		Architectures: []string{"amd64"},
+		Channels:      []v2alpha1.ReleaseChannel{{Name: "stable-4.14", Type: v2alpha1.TypeOKD, MinVersion: "4.14.1", ShortestPath: true}},
+	}, isc.Mirror.Platform)
+	assert.Equal(t, []v2alpha1.Operator{
+		{
+			Catalog:       "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+			TargetCatalog: "my-index",
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+				{Name: "aws-load-balancer-operator"},
+				{Name: "node-observability-operator", Channels: []v2alpha1.IncludeChannel{
+					{Name: "alpha", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "0.1.0"}},
+				}},
+			}},
+		},
+		{Catalog: "oci:///home/user/catalog", TargetCatalog: "ns/catalog", Full: true},
+	}, isc.Mirror.Operators)
+	assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi9/ubi:latest"}}, isc.Mirror.AdditionalImages)
+	assert.Equal(t, "podinfo", isc.Mirror.Helm.Repositories[0].Charts[0].Name)
+	assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi9/ubi-micro"}}, isc.Mirror.BlockedImages)
+	assert.Equal(t, []v2alpha1.SampleImages{{Image: v2alpha1.Image{Name: "ruby"}}}, isc.Mirror.Samples)
+
+	assert.Equal(t, []Unsupported{
+		{Field: "storageConfig", Reason: "v2 keeps its state in the working-dir, the v1 registry backend registry.example.com/oc-mirror/metadata:latest has no v2 equivalent, use --state-backend file://<shared directory> or s3://<bucket>/<prefix> to share it between runners"},
+		{Field: "mirror.operators[0].targetName", Reason: "replaced by targetCatalog: my-index"},
+		{Field: "mirror.operators[0].packages[0].minBundle", Reason: "dropped, use minVersion instead"},
+		{Field: "mirror.operators[0].packages[1].channels[0].minBundle", Reason: "dropped, use minVersion instead"},
+		{Field: "mirror.operators[1].targetName", Reason: "dropped, targetCatalog is set"},
+		{Field: "mirror.operators[1].originalRef", Reason: "dropped, the catalog is used as is"},
+	}, unsupported)
+
+	// the v1 configuration is left untouched
+	assert.Equal(t, "my-index", v1Config.Mirror.Operators[0].TargetName)
+	assert.Equal(t, "aws-load-balancer-operator.v1.0.0", v1Config.Mirror.Operators[0].Packages[0].MinBundle)
+	assert.Equal(t, "node-observability-operator.v0.1.0", v1Config.Mirror.Operators[0].Packages[1].Channels[0].MinBundle)
+}
+
+func TestReadMetadata(t *testing.T) {
+	metadataFile := func(t *testing.T) []byte {
+		meta := v1Metadata()
+		data, err := json.Marshal(&meta)
+		assert.NoError(t, err)
+		return data
+	}
+	writeMetadata := func(t *testing.T, dir string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "publish"), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, metadataBasePath), metadataFile(t), 0644))
+	}
+
+	t.Run("Testing ReadMetadata - should read the metadata of the local storageConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMetadata(t, dir)
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.StorageConfig.Local = &v1alpha2.LocalConfig{Path: dir}
+		meta, err := ReadMetadata(context.Background(), cfg, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, meta.PastMirror.Sequence)
+		assert.Len(t, meta.PastMirror.Associations, 3)
+	})
+
+	t.Run("Testing ReadMetadata - should read the metadata of a v1 workspace", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMetadata(t, dir)
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.StorageConfig.Local = &v1alpha2.LocalConfig{Path: t.TempDir()}
+		meta, err := ReadMetadata(context.Background(), cfg, dir)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, meta.PastMirror.Sequence)
+	})
+
+	t.Run("Testing ReadMetadata - should read the metadata image of the registry storageConfig", func(t *testing.T) {
+		server := httptest.NewServer(registry.New())
+		defer server.Close()
+		imageURL := strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/metadata"
+		img, err := crane.Image(map[string][]byte{metadataBasePath: metadataFile(t)})
+		assert.NoError(t, err)
+		assert.NoError(t, crane.Push(img, imageURL+":latest"))
+
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.StorageConfig.Registry = &v1alpha2.RegistryConfig{ImageURL: imageURL}
+		meta, err := ReadMetadata(context.Background(), cfg, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, meta.PastMirror.Sequence)
+		assert.Len(t, meta.PastMirror.Associations, 3)
+
+		cfg.StorageConfig.Registry.ImageURL = imageURL + ":missing"
+		_, err = ReadMetadata(context.Background(), cfg, "")
+		assert.EqualError(t, err, "no v1 metadata found in registry "+imageURL+":missing")
+	})
+
+	t.Run("Testing ReadMetadata - should fail without metadata", func(t *testing.T) {
+		_, err := ReadMetadata(context.Background(), v1alpha2.ImageSetConfiguration{}, "")
+		assert.EqualError(t, err, "the v1 ImageSetConfiguration has no storageConfig: use --v1-workspace to read the metadata of an oc-mirror-workspace")
+
+		dir := t.TempDir()
+		_, err = ReadMetadata(context.Background(), v1alpha2.ImageSetConfiguration{}, dir)
+		assert.EqualError(t, err, "no v1 metadata found in directory "+dir)
+	})
+}
+
+func TestReadConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		configPath := filepath.Join(t.TempDir(), "isc.yaml")
+		assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+		return configPath
+	}
+
+	t.Run("Testing ReadConfig - should read a v1alpha2 configuration", func(t *testing.T) {
+		cfg, err := ReadConfig(writeConfig(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v1alpha2
+storageConfig:
+  registry:
+    imageURL: registry.example.com/oc-mirror/metadata
+mirror:
+  platform:
+    channels:
+    - name: stable-4.14
+      type: okd
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.14
+    targetName: my-index
+    packages:
+    - name: aws-load-balancer-operator
+      minBundle: aws-load-balancer-operator.v1.0.0
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, "registry.example.com/oc-mirror/metadata", cfg.StorageConfig.Registry.ImageURL)
+		assert.Equal(t, []v1alpha2.ReleaseChannel{{Name: "stable-4.14", Type: v1alpha2.TypeOKD}}, cfg.Mirror.Platform.Channels)
+		assert.Equal(t, []string{v1alpha2.DefaultPlatformArchitecture}, cfg.Mirror.Platform.Architectures)
+		assert.Equal(t, "my-index", cfg.Mirror.Operators[0].TargetName)
+		assert.Equal(t, "aws-load-balancer-operator.v1.0.0", cfg.Mirror.Operators[0].Packages[0].MinBundle)
+	})
+
+	t.Run("Testing ReadConfig - should fail on other kinds and on unknown fields", func(t *testing.T) {
+		_, err := ReadConfig(writeConfig(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+`))
+		assert.EqualError(t, err, "config GVK not recognized: mirror.openshift.io/v2alpha1, Kind=ImageSetConfiguration")
+
+		_, err = ReadConfig(writeConfig(t, `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v1alpha2
+mirror:
+  unknown: true
+`))
+		assert.ErrorContains(t, err, `json: unknown field "unknown"`)
+	})
+}
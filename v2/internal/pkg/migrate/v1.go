@@ -0,0 +1,125 @@
+package migrate
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v1alpha2"
+)
+
+var errMetadataNotExist = errors.New("v1 metadata does not exist")
+
+// ReadConfig reads a v1alpha2 ImageSetConfiguration, rejecting the fields unknown to v1
+// as oc-mirror v1 does, and sets the default release architecture of v1
+func ReadConfig(configPath string) (v1alpha2.ImageSetConfiguration, error) {
+	var cfg v1alpha2.ImageSetConfiguration
+	data, err := os.ReadFile(filepath.Clean(configPath))
+	if err != nil {
+		return cfg, err
+	}
+	gvk := v1alpha2.GroupVersion.WithKind(v1alpha2.ImageSetConfigurationKind)
+	if err := decodeStrict(data, gvk, &cfg); err != nil {
+		return cfg, err
+	}
+	if len(cfg.Mirror.Platform.Channels) != 0 && len(cfg.Mirror.Platform.Architectures) == 0 {
+		cfg.Mirror.Platform.Architectures = []string{v1alpha2.DefaultPlatformArchitecture}
+	}
+	return cfg, nil
+}
+
+// loadMetadata decodes the v1 metadata file
+func loadMetadata(data []byte) (v1alpha2.Metadata, error) {
+	var meta v1alpha2.Metadata
+	err := decodeStrict(data, v1alpha2.GroupVersion.WithKind(v1alpha2.MetadataKind), &meta)
+	return meta, err
+}
+
+func decodeStrict(data []byte, gvk schema.GroupVersionKind, obj interface{}) error {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return fmt.Errorf("get type meta: %v", err)
+	}
+	if typeMeta.GroupVersionKind() != gvk {
+		return fmt.Errorf("config GVK not recognized: %s", typeMeta.GroupVersionKind())
+	}
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("yaml to json %s: %v", gvk, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		return fmt.Errorf("decode %s: %v", gvk, err)
+	}
+	return nil
+}
+
+// readLocalMetadata reads the metadata file of the v1 local backend
+func readLocalMetadata(dir string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metadataBasePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errMetadataNotExist
+	}
+	return data, err
+}
+
+// readRegistryMetadata reads the metadata file from the image of the v1 registry backend
+func readRegistryMetadata(ctx context.Context, registry v1alpha2.RegistryConfig) ([]byte, error) {
+	nameOpts := []name.Option{name.WithDefaultTag(metadataDefaultTag)}
+	remoteOpts := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx)}
+	if registry.SkipTLS {
+		nameOpts = append(nameOpts, name.Insecure)
+		transport := remote.DefaultTransport.(*http.Transport).Clone()
+		// nolint: gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+		remoteOpts = append(remoteOpts, remote.WithTransport(transport))
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(registry.ImageURL, dockerProtocol), nameOpts...)
+	if err != nil {
+		return nil, err
+	}
+	img, err := remote.Image(ref, remoteOpts...)
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return nil, errMetadataNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the metadata file is the only file of the image
+	reader := mutate.Extract(img)
+	defer reader.Close()
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("metadata %s does not contain %s", ref, metadataBasePath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Clean(header.Name) == metadataBasePath {
+			return io.ReadAll(tr)
+		}
+	}
+}
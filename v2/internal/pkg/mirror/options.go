@@ -43,6 +43,7 @@ type GlobalOptions struct {
 	From               string        // local storage for diskToMirror workflow
 	Port               uint16        // HTTP port used by oc-mirror's local storage instance
 	ConfigPath         string        // Path to use for imagesetconfig
+	LockFile           string        // Path to the imageset configuration lockfile, mirrored instead of the imagesetconfig
 	Quiet              bool          // Suppress output information when copying images
 	Force              bool          // Force the copy/mirror even if there is nothing to update
 	V2                 bool          // Redirect the flow to oc-mirror v2 - PLEASE DO NOT USE that. V2 is still under development and it is not ready to be used.
@@ -56,6 +57,16 @@ type GlobalOptions struct {
 	DeleteID           string        // This flag is used to append to the artifacts created by the delete functionality
 	DeleteYaml         string        // This flag will use the contents of the indicated yaml as basis to delete the local cache and remote registry
 	CacheDir           string        // Path to the cache directory
+	TrustBundlePath    string        // Path to the PEM encoded CA bundle of the destination registry, added to the install-config snippet
+	InstallRelease     string        // Release installed by the install-config snippet, when several releases are mirrored
+	GitOpsOutput       string        // GitOps output generated next to the cluster resources: kustomize, acm or argocd
+	GitOpsNamespace    string        // Namespace of the generated ACM Policy or Argo CD Application
+	GitOpsClusterSet   string        // ACM cluster set bound to the namespace of the Policy, whose clusters are selected by its Placement
+	GitOpsRepoURL      string        // Git repository the cluster resources are pushed to, referenced by the Argo CD Application
+	GitOpsRepoPath     string        // Path of the cluster resources in the git repository, referenced by the Argo CD Application
+	StateBackend       string        // Location of the working-dir state: file://, docker:// or s3://, pulled before and pushed after mirroring
+	StateTLSVerify     bool          // Verify the TLS certificates of the registry or S3 state backend
+	OpmBinary          string        // Path to the opm binary building the serve cache of the rebuilt catalogs
 }
 
 type CopyOptions struct {
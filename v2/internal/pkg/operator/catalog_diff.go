@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+const (
+	PackageAdded   = "added"
+	PackageRemoved = "removed"
+	PackageChanged = "changed"
+)
+
+// CatalogDiff is the delta between two snapshots of a catalog, by package
+type CatalogDiff struct {
+	// Old and New are the references of the compared catalogs
+	Old      string        `json:"old"`
+	New      string        `json:"new"`
+	Packages []PackageDiff `json:"packages"`
+}
+
+// PackageDiff is the delta of a package between two snapshots of a catalog
+type PackageDiff struct {
+	Name string `json:"name"`
+	// Status is added, removed or changed
+	Status               string            `json:"status"`
+	AddedBundles         []string          `json:"addedBundles,omitempty"`
+	RemovedBundles       []string          `json:"removedBundles,omitempty"`
+	ChannelHeads         []ChannelHeadDiff `json:"channelHeads,omitempty"`
+	DefaultChannel       *ValueDiff        `json:"defaultChannel,omitempty"`
+	NewDeprecations      []DeprecationDiff `json:"newDeprecations,omitempty"`
+	AddedRelatedImages   []string          `json:"addedRelatedImages,omitempty"`
+	RemovedRelatedImages []string          `json:"removedRelatedImages,omitempty"`
+}
+
+// ChannelHeadDiff is the change of the head of a channel.
+// Old is empty for a new channel, and New for a removed channel.
+type ChannelHeadDiff struct {
+	Channel string `json:"channel"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// ValueDiff is the change of a value
+type ValueDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// DeprecationDiff is a deprecation of the new catalog, missing in the old one
+type DeprecationDiff struct {
+	// Schema is the schema of the deprecated object: olm.package, olm.channel or olm.bundle
+	Schema  string `json:"schema"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// packageContent is the content of a package of a catalog, compared between the snapshots
+type packageContent struct {
+	defaultChannel string
+	bundles        map[string]bool
+	heads          map[string]string
+	deprecations   map[string]DeprecationDiff
+	relatedImages  map[string]bool
+}
+
+// DiffCatalogs compares two snapshots of a catalog, and returns for each package which differs
+// the bundles, channel heads, default channel, deprecations and related images that changed
+func DiffCatalogs(oldRef string, oldDC *declcfg.DeclarativeConfig, newRef string, newDC *declcfg.DeclarativeConfig) CatalogDiff {
+	diff := CatalogDiff{Old: oldRef, New: newRef, Packages: []PackageDiff{}}
+	oldPkgs := packageContents(oldDC)
+	newPkgs := packageContents(newDC)
+
+	names := slices.Collect(maps.Keys(oldPkgs))
+	for name := range newPkgs {
+		if _, ok := oldPkgs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		oldPkg, inOld := oldPkgs[name]
+		newPkg, inNew := newPkgs[name]
+		pkgDiff := PackageDiff{Name: name, Status: PackageChanged}
+		switch {
+		case !inOld:
+			pkgDiff.Status = PackageAdded
+			oldPkg = &packageContent{}
+		case !inNew:
+			pkgDiff.Status = PackageRemoved
+			newPkg = &packageContent{}
+		}
+
+		pkgDiff.AddedBundles = missingKeys(newPkg.bundles, oldPkg.bundles)
+		pkgDiff.RemovedBundles = missingKeys(oldPkg.bundles, newPkg.bundles)
+		pkgDiff.AddedRelatedImages = missingKeys(newPkg.relatedImages, oldPkg.relatedImages)
+		pkgDiff.RemovedRelatedImages = missingKeys(oldPkg.relatedImages, newPkg.relatedImages)
+		channels := slices.Collect(maps.Keys(oldPkg.heads))
+		for ch := range newPkg.heads {
+			if _, ok := oldPkg.heads[ch]; !ok {
+				channels = append(channels, ch)
+			}
+		}
+		slices.Sort(channels)
+		for _, ch := range channels {
+			if oldPkg.heads[ch] != newPkg.heads[ch] {
+				pkgDiff.ChannelHeads = append(pkgDiff.ChannelHeads, ChannelHeadDiff{Channel: ch, Old: oldPkg.heads[ch], New: newPkg.heads[ch]})
+			}
+		}
+		if inOld && inNew && oldPkg.defaultChannel != newPkg.defaultChannel {
+			pkgDiff.DefaultChannel = &ValueDiff{Old: oldPkg.defaultChannel, New: newPkg.defaultChannel}
+		}
+		for _, key := range slices.Sorted(maps.Keys(newPkg.deprecations)) {
+			if _, ok := oldPkg.deprecations[key]; !ok {
+				pkgDiff.NewDeprecations = append(pkgDiff.NewDeprecations, newPkg.deprecations[key])
+			}
+		}
+
+		if pkgDiff.Status != PackageChanged || pkgDiff.changed() {
+			diff.Packages = append(diff.Packages, pkgDiff)
+		}
+	}
+	return diff
+}
+
+func (d PackageDiff) changed() bool {
+	return len(d.AddedBundles) > 0 || len(d.RemovedBundles) > 0 || len(d.ChannelHeads) > 0 || d.DefaultChannel != nil ||
+		len(d.NewDeprecations) > 0 || len(d.AddedRelatedImages) > 0 || len(d.RemovedRelatedImages) > 0
+}
+
+// Rows returns the changes of the diff, one by row of package, change and details
+func (d CatalogDiff) Rows() [][]string {
+	var rows [][]string
+	for _, pkg := range d.Packages {
+		add := func(change string, details string) {
+			rows = append(rows, []string{pkg.Name, change, details})
+		}
+		if pkg.Status != PackageChanged {
+			add("package "+pkg.Status, "")
+		}
+		if pkg.DefaultChannel != nil {
+			add("default channel", pkg.DefaultChannel.Old+" -> "+pkg.DefaultChannel.New)
+		}
+		for _, head := range pkg.ChannelHeads {
+			switch {
+			case head.Old == "":
+				add("channel added", head.Channel+": "+head.New)
+			case head.New == "":
+				add("channel removed", head.Channel+": "+head.Old)
+			default:
+				add("head changed", head.Channel+": "+head.Old+" -> "+head.New)
+			}
+		}
+		for _, bundle := range pkg.AddedBundles {
+			add("bundle added", bundle)
+		}
+		for _, bundle := range pkg.RemovedBundles {
+			add("bundle removed", bundle)
+		}
+		for _, deprecation := range pkg.NewDeprecations {
+			details := strings.TrimPrefix(deprecation.Schema, "olm.")
+			if deprecation.Name != "" {
+				details += " " + deprecation.Name
+			}
+			add("deprecated", details+": "+deprecation.Message)
+		}
+		for _, img := range pkg.AddedRelatedImages {
+			add("image added", img)
+		}
+		for _, img := range pkg.RemovedRelatedImages {
+			add("image removed", img)
+		}
+	}
+	return rows
+}
+
+// packageContents indexes the content of the packages of a declarative config
+func packageContents(dc *declcfg.DeclarativeConfig) map[string]*packageContent {
+	pkgs := map[string]*packageContent{}
+	get := func(name string) *packageContent {
+		if _, ok := pkgs[name]; !ok {
+			pkgs[name] = &packageContent{
+				bundles:       map[string]bool{},
+				heads:         map[string]string{},
+				deprecations:  map[string]DeprecationDiff{},
+				relatedImages: map[string]bool{},
+			}
+		}
+		return pkgs[name]
+	}
+	for _, pkg := range dc.Packages {
+		get(pkg.Name).defaultChannel = pkg.DefaultChannel
+	}
+	for _, ch := range dc.Channels {
+		get(ch.Package).heads[ch.Name] = channelHead(ch)
+	}
+	for _, bundle := range dc.Bundles {
+		pkg := get(bundle.Package)
+		pkg.bundles[bundle.Name] = true
+		for _, img := range bundle.RelatedImages {
+			if img.Image != "" && img.Image != bundle.Image {
+				pkg.relatedImages[img.Image] = true
+			}
+		}
+	}
+	for _, deprecation := range dc.Deprecations {
+		pkg := get(deprecation.Package)
+		for _, entry := range deprecation.Entries {
+			d := DeprecationDiff{Schema: entry.Reference.Schema, Name: entry.Reference.Name, Message: strings.TrimSpace(entry.Message)}
+			pkg.deprecations[fmt.Sprintf("%s/%s", d.Schema, d.Name)] = d
+		}
+	}
+	return pkgs
+}
+
+// channelHead returns the entries of a channel that no other entry replaces or skips.
+// A valid channel has a single head.
+func channelHead(ch declcfg.Channel) string {
+	replaced := map[string]bool{}
+	for _, entry := range ch.Entries {
+		replaced[entry.Replaces] = true
+		for _, skip := range entry.Skips {
+			replaced[skip] = true
+		}
+	}
+	var heads []string
+	for _, entry := range ch.Entries {
+		if !replaced[entry.Name] {
+			heads = append(heads, entry.Name)
+		}
+	}
+	slices.Sort(heads)
+	return strings.Join(heads, ",")
+}
+
+// missingKeys returns the sorted keys of from missing in in
+func missingKeys(from, in map[string]bool) []string {
+	var missing []string
+	for key := range from {
+		if !in[key] {
+			missing = append(missing, key)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	bundle := func(pkg, name string, relatedImages ...string) declcfg.Bundle {
+		b := declcfg.Bundle{Schema: declcfg.SchemaBundle, Package: pkg, Name: name, Image: "registry.redhat.io/" + name}
+		b.RelatedImages = append(b.RelatedImages, declcfg.RelatedImage{Name: "bundle", Image: b.Image})
+		for _, img := range relatedImages {
+			b.RelatedImages = append(b.RelatedImages, declcfg.RelatedImage{Image: img})
+		}
+		return b
+	}
+	oldDC := &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v0"},
+			{Schema: declcfg.SchemaPackage, Name: "3scale-operator", DefaultChannel: "threescale-2.14"},
+			{Schema: declcfg.SchemaPackage, Name: "odf-operator", DefaultChannel: "stable-4.15"},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v0", Entries: []declcfg.ChannelEntry{{Name: "albo.v0.2.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v1", Entries: []declcfg.ChannelEntry{
+				{Name: "albo.v1.0.0"},
+				{Name: "albo.v1.1.0", Replaces: "albo.v1.0.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Package: "3scale-operator", Name: "threescale-2.14", Entries: []declcfg.ChannelEntry{{Name: "3scale.v0.11.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "odf-operator", Name: "stable-4.15", Entries: []declcfg.ChannelEntry{{Name: "odf.v4.15.0"}}},
+		},
+		Bundles: []declcfg.Bundle{
+			bundle("aws-load-balancer-operator", "albo.v0.2.0", "registry.redhat.io/albo/operator@sha256:0"),
+			bundle("aws-load-balancer-operator", "albo.v1.0.0", "registry.redhat.io/albo/operator@sha256:1"),
+			bundle("aws-load-balancer-operator", "albo.v1.1.0", "registry.redhat.io/albo/operator@sha256:1"),
+			bundle("3scale-operator", "3scale.v0.11.0", "registry.redhat.io/3scale/operator@sha256:1"),
+			bundle("odf-operator", "odf.v4.15.0"),
+		},
+	}
+	newDC := &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v1"},
+			{Schema: declcfg.SchemaPackage, Name: "3scale-operator", DefaultChannel: "threescale-2.14"},
+			{Schema: declcfg.SchemaPackage, Name: "lvms-operator", DefaultChannel: "stable-4.16"},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v1", Entries: []declcfg.ChannelEntry{
+				{Name: "albo.v1.0.0"},
+				{Name: "albo.v1.1.0", Replaces: "albo.v1.0.0"},
+				{Name: "albo.v1.2.0", Replaces: "albo.v1.1.0", Skips: []string{"albo.v1.0.0"}},
+			}},
+			{Schema: declcfg.SchemaChannel, Package: "3scale-operator", Name: "threescale-2.14", Entries: []declcfg.ChannelEntry{{Name: "3scale.v0.11.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "lvms-operator", Name: "stable-4.16", Entries: []declcfg.ChannelEntry{{Name: "lvms.v4.16.0"}}},
+		},
+		Bundles: []declcfg.Bundle{
+			bundle("aws-load-balancer-operator", "albo.v1.0.0", "registry.redhat.io/albo/operator@sha256:1"),
+			bundle("aws-load-balancer-operator", "albo.v1.1.0", "registry.redhat.io/albo/operator@sha256:1"),
+			bundle("aws-load-balancer-operator", "albo.v1.2.0", "registry.redhat.io/albo/operator@sha256:2"),
+			bundle("3scale-operator", "3scale.v0.11.0", "registry.redhat.io/3scale/operator@sha256:1"),
+			bundle("lvms-operator", "lvms.v4.16.0"),
+		},
+		Deprecations: []declcfg.Deprecation{{
+			Schema:  declcfg.SchemaDeprecation,
+			Package: "aws-load-balancer-operator",
+			Entries: []declcfg.DeprecationEntry{
+				{Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaChannel, Name: "stable-v0"}, Message: "stable-v0 is no longer supported\n"},
+			},
+		}},
+	}
+
+	t.Run("Testing DiffCatalogs - should report the changes by package", func(t *testing.T) {
+		diff := DiffCatalogs("old", oldDC, "new", newDC)
+		assert.Equal(t, CatalogDiff{Old: "old", New: "new", Packages: []PackageDiff{
+			{
+				Name:           "aws-load-balancer-operator",
+				Status:         PackageChanged,
+				AddedBundles:   []string{"albo.v1.2.0"},
+				RemovedBundles: []string{"albo.v0.2.0"},
+				ChannelHeads: []ChannelHeadDiff{
+					{Channel: "stable-v0", Old: "albo.v0.2.0"},
+					{Channel: "stable-v1", Old: "albo.v1.1.0", New: "albo.v1.2.0"},
+				},
+				DefaultChannel:       &ValueDiff{Old: "stable-v0", New: "stable-v1"},
+				NewDeprecations:      []DeprecationDiff{{Schema: declcfg.SchemaChannel, Name: "stable-v0", Message: "stable-v0 is no longer supported"}},
+				AddedRelatedImages:   []string{"registry.redhat.io/albo/operator@sha256:2"},
+				RemovedRelatedImages: []string{"registry.redhat.io/albo/operator@sha256:0"},
+			},
+			{
+				Name:         "lvms-operator",
+				Status:       PackageAdded,
+				AddedBundles: []string{"lvms.v4.16.0"},
+				ChannelHeads: []ChannelHeadDiff{{Channel: "stable-4.16", New: "lvms.v4.16.0"}},
+			},
+			{
+				Name:           "odf-operator",
+				Status:         PackageRemoved,
+				RemovedBundles: []string{"odf.v4.15.0"},
+				ChannelHeads:   []ChannelHeadDiff{{Channel: "stable-4.15", Old: "odf.v4.15.0"}},
+			},
+		}}, diff)
+
+		assert.Equal(t, [][]string{
+			{"aws-load-balancer-operator", "default channel", "stable-v0 -> stable-v1"},
+			{"aws-load-balancer-operator", "channel removed", "stable-v0: albo.v0.2.0"},
+			{"aws-load-balancer-operator", "head changed", "stable-v1: albo.v1.1.0 -> albo.v1.2.0"},
+			{"aws-load-balancer-operator", "bundle added", "albo.v1.2.0"},
+			{"aws-load-balancer-operator", "bundle removed", "albo.v0.2.0"},
+			{"aws-load-balancer-operator", "deprecated", "channel stable-v0: stable-v0 is no longer supported"},
+			{"aws-load-balancer-operator", "image added", "registry.redhat.io/albo/operator@sha256:2"},
+			{"aws-load-balancer-operator", "image removed", "registry.redhat.io/albo/operator@sha256:0"},
+			{"lvms-operator", "package added", ""},
+			{"lvms-operator", "channel added", "stable-4.16: lvms.v4.16.0"},
+			{"lvms-operator", "bundle added", "lvms.v4.16.0"},
+			{"odf-operator", "package removed", ""},
+			{"odf-operator", "channel removed", "stable-4.15: odf.v4.15.0"},
+			{"odf-operator", "bundle removed", "odf.v4.15.0"},
+		}, diff.Rows())
+	})
+
+	t.Run("Testing DiffCatalogs - should not report identical catalogs", func(t *testing.T) {
+		diff := DiffCatalogs("old", oldDC, "new", oldDC)
+		assert.Empty(t, diff.Packages)
+		assert.Empty(t, diff.Rows())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -48,6 +49,13 @@ func setInternalLog(log clog.PluggableLoggerInterface) {
 
 func (o catalogHandler) getDeclarativeConfig(filePath string) (*declcfg.DeclarativeConfig, error) {
 	setInternalLog(o.Log)
+	isFile, err := isFBCFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if isFile {
+		return declcfg.LoadFile(os.DirFS(filepath.Dir(filePath)), filepath.Base(filePath))
+	}
 	return declcfg.LoadFS(context.Background(), os.DirFS(filePath))
 }
 
@@ -55,6 +63,36 @@ func saveDeclarativeConfig(fbc declcfg.DeclarativeConfig, path string) error {
 	return declcfg.WriteFS(fbc, path, declcfg.WriteJSON, ".json")
 }
 
+// mergeDeclarativeConfigs merges the declarative configs of several catalogs into a single one.
+// catalogs[i] is the name of the catalog dcs[i] comes from. A package can only come from one catalog:
+// packages found in more than one catalog are reported as an error.
+func mergeDeclarativeConfigs(catalogs []string, dcs []declcfg.DeclarativeConfig) (*declcfg.DeclarativeConfig, error) {
+	merged := &declcfg.DeclarativeConfig{}
+	pkgCatalogs := map[string][]string{}
+	for i, dc := range dcs {
+		for _, pkg := range dc.Packages {
+			pkgCatalogs[pkg.Name] = append(pkgCatalogs[pkg.Name], catalogs[i])
+		}
+		merged.Packages = append(merged.Packages, dc.Packages...)
+		merged.Channels = append(merged.Channels, dc.Channels...)
+		merged.Bundles = append(merged.Bundles, dc.Bundles...)
+		merged.Deprecations = append(merged.Deprecations, dc.Deprecations...)
+		merged.Others = append(merged.Others, dc.Others...)
+	}
+
+	conflicts := []string{}
+	for pkgName, pkgCtlgs := range pkgCatalogs {
+		if len(pkgCtlgs) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", pkgName, strings.Join(pkgCtlgs, ", ")))
+		}
+	}
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		return nil, fmt.Errorf("packages found in more than one catalog: %s", strings.Join(conflicts, "; "))
+	}
+	return merged, nil
+}
+
 func filterFromImageSetConfig(iscCatalogFilter v2alpha1.Operator) (filter.FilterConfiguration, error) {
 	catFilter := filter.FilterConfiguration{
 		TypeMeta: v1.TypeMeta{
@@ -77,6 +115,10 @@ func filterFromImageSetConfig(iscCatalogFilter v2alpha1.Operator) (filter.Filter
 			if op.MaxVersion != "" {
 				p.VersionRange += " <=" + op.MaxVersion
 			}
+			// the selection policies pick among all the bundles of the heads-only channels
+			if len(op.Channels) == 0 && selectionPolicy(iscCatalogFilter, op, "").headsOnly && op.SelectionPolicy.IsSet() {
+				p.VersionRange = anyVersionRange
+			}
 			if len(op.Channels) > 0 {
 				p.Channels = []filter.Channel{}
 				for _, ch := range op.Channels {
@@ -90,6 +132,9 @@ func filterFromImageSetConfig(iscCatalogFilter v2alpha1.Operator) (filter.Filter
 					if ch.MaxVersion != "" {
 						filterChan.VersionRange += " <=" + ch.MaxVersion
 					}
+					if policy := selectionPolicy(iscCatalogFilter, op, ch.Name); policy.headsOnly && policy.IsSet() {
+						filterChan.VersionRange = anyVersionRange
+					}
 					p.Channels = append(p.Channels, filterChan)
 				}
 			}
@@ -125,36 +170,7 @@ func (o catalogHandler) getCatalog(filePath string) (OperatorCatalog, error) {
 		return operatorCatalog, nil
 	}
 
-	for _, p := range cfg.Packages {
-		operatorCatalog.Packages[p.Name] = p
-	}
-
-	for _, c := range cfg.Channels {
-		operatorCatalog.Channels[c.Package] = append(operatorCatalog.Channels[c.Package], c)
-		for _, e := range c.Entries {
-			if _, ok := operatorCatalog.ChannelEntries[c.Package]; !ok {
-				operatorCatalog.ChannelEntries[c.Package] = make(map[string]map[string]declcfg.ChannelEntry)
-			}
-			if _, ok := operatorCatalog.ChannelEntries[c.Package][c.Name]; !ok {
-				operatorCatalog.ChannelEntries[c.Package][c.Name] = make(map[string]declcfg.ChannelEntry)
-			}
-
-			operatorCatalog.ChannelEntries[c.Package][c.Name][e.Name] = e
-		}
-
-	}
-
-	for _, b := range cfg.Bundles {
-		if _, ok := operatorCatalog.BundlesByPkgAndName[b.Package]; !ok {
-			operatorCatalog.BundlesByPkgAndName[b.Package] = make(map[string]declcfg.Bundle)
-		}
-
-		if _, ok := operatorCatalog.BundlesByPkgAndName[b.Package][b.Name]; !ok {
-			operatorCatalog.BundlesByPkgAndName[b.Package][b.Name] = b
-		}
-	}
-
-	return operatorCatalog, err
+	return NewOperatorCatalog(cfg), nil
 }
 
 func (o catalogHandler) filterRelatedImagesFromCatalog(operatorCatalog OperatorCatalog, ctlgInIsc v2alpha1.Operator, copyImageSchemaMap *v2alpha1.CopyImageSchemaMap) (map[string][]v2alpha1.RelatedImage, error) {
@@ -227,6 +243,42 @@ func newOperatorCatalog() OperatorCatalog {
 	return operatorConfig
 }
 
+// NewOperatorCatalog indexes the packages, channels, channel entries and bundles of a declarative config
+func NewOperatorCatalog(cfg *declcfg.DeclarativeConfig) OperatorCatalog {
+	operatorCatalog := newOperatorCatalog()
+
+	for _, p := range cfg.Packages {
+		operatorCatalog.Packages[p.Name] = p
+	}
+
+	for _, c := range cfg.Channels {
+		operatorCatalog.Channels[c.Package] = append(operatorCatalog.Channels[c.Package], c)
+		for _, e := range c.Entries {
+			if _, ok := operatorCatalog.ChannelEntries[c.Package]; !ok {
+				operatorCatalog.ChannelEntries[c.Package] = make(map[string]map[string]declcfg.ChannelEntry)
+			}
+			if _, ok := operatorCatalog.ChannelEntries[c.Package][c.Name]; !ok {
+				operatorCatalog.ChannelEntries[c.Package][c.Name] = make(map[string]declcfg.ChannelEntry)
+			}
+
+			operatorCatalog.ChannelEntries[c.Package][c.Name][e.Name] = e
+		}
+
+	}
+
+	for _, b := range cfg.Bundles {
+		if _, ok := operatorCatalog.BundlesByPkgAndName[b.Package]; !ok {
+			operatorCatalog.BundlesByPkgAndName[b.Package] = make(map[string]declcfg.Bundle)
+		}
+
+		if _, ok := operatorCatalog.BundlesByPkgAndName[b.Package][b.Name]; !ok {
+			operatorCatalog.BundlesByPkgAndName[b.Package][b.Name] = b
+		}
+	}
+
+	return operatorCatalog
+}
+
 func parseOperatorCatalogByOperator(operatorName string, operatorCatalog OperatorCatalog) OperatorCatalog {
 	operatorConfig := newOperatorCatalog()
 	operatorConfig.Packages[operatorName] = operatorCatalog.Packages[operatorName]
@@ -1158,3 +1158,34 @@ func TestFilterCatalog(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeDeclarativeConfigs(t *testing.T) {
+	redhatDC := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Name: "aws-load-balancer-operator"}},
+		Channels: []declcfg.Channel{{Name: "stable-v1", Package: "aws-load-balancer-operator"}},
+		Bundles:  []declcfg.Bundle{{Name: "aws-load-balancer-operator.v1.1.1", Package: "aws-load-balancer-operator"}},
+	}
+	certifiedDC := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Name: "nginx-ingress-operator"}},
+		Channels: []declcfg.Channel{{Name: "alpha", Package: "nginx-ingress-operator"}},
+		Bundles:  []declcfg.Bundle{{Name: "nginx-ingress-operator.v3.0.0", Package: "nginx-ingress-operator"}},
+	}
+	internalDC := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Name: "aws-load-balancer-operator"}, {Name: "internal-operator"}},
+	}
+
+	t.Run("Testing mergeDeclarativeConfigs - distinct packages: should pass", func(t *testing.T) {
+		merged, err := mergeDeclarativeConfigs([]string{"redhat-operator-index", "certified-operator-index"}, []declcfg.DeclarativeConfig{redhatDC, certifiedDC})
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Len(t, merged.Packages, 2)
+		assert.Len(t, merged.Channels, 2)
+		assert.Len(t, merged.Bundles, 2)
+	})
+
+	t.Run("Testing mergeDeclarativeConfigs - duplicate packages: should fail", func(t *testing.T) {
+		_, err := mergeDeclarativeConfigs([]string{"redhat-operator-index", "certified-operator-index", "internal-index"}, []declcfg.DeclarativeConfig{redhatDC, certifiedDC, internalDC})
+		assert.EqualError(t, err, "packages found in more than one catalog: aws-load-balancer-operator (redhat-operator-index, internal-index)")
+	})
+}
@@ -0,0 +1,177 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// PackageInfo describes a package of a catalog
+type PackageInfo struct {
+	Name           string        `json:"name"`
+	DefaultChannel string        `json:"defaultChannel"`
+	Channels       []ChannelInfo `json:"channels,omitempty"`
+}
+
+// ChannelInfo describes a channel of a package, with its bundles ordered by version
+type ChannelInfo struct {
+	Name    string       `json:"name"`
+	Head    string       `json:"head"`
+	Bundles []BundleInfo `json:"bundles,omitempty"`
+}
+
+// BundleInfo describes a bundle of a package
+type BundleInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Image   string `json:"image,omitempty"`
+	// RelatedImages is only set when a single bundle is inspected
+	RelatedImages []declcfg.RelatedImage `json:"relatedImages,omitempty"`
+}
+
+// PackageInfos returns the packages of the catalog ordered by name, with their channels and bundles
+func (o OperatorCatalog) PackageInfos() []PackageInfo {
+	names := make([]string, 0, len(o.Packages))
+	for name := range o.Packages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	pkgs := make([]PackageInfo, 0, len(names))
+	for _, name := range names {
+		pkg, _ := o.PackageInfo(name)
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs
+}
+
+// PackageInfo returns a package of the catalog, with its channels ordered by name
+func (o OperatorCatalog) PackageInfo(name string) (PackageInfo, error) {
+	pkg, ok := o.Packages[name]
+	if !ok {
+		return PackageInfo{}, fmt.Errorf("package %s not found in catalog", name)
+	}
+	info := PackageInfo{Name: name, DefaultChannel: pkg.DefaultChannel}
+	channels := slices.Clone(o.Channels[name])
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	for _, ch := range channels {
+		chInfo := ChannelInfo{Name: ch.Name, Head: channelHead(ch)}
+		for _, entry := range ch.Entries {
+			bundle := BundleInfo{Name: entry.Name}
+			if b, ok := o.BundlesByPkgAndName[name][entry.Name]; ok {
+				bundle.Version = bundleVersion(b)
+				bundle.Image = b.Image
+			}
+			chInfo.Bundles = append(chInfo.Bundles, bundle)
+		}
+		sortBundles(chInfo.Bundles)
+		info.Channels = append(info.Channels, chInfo)
+	}
+	return info, nil
+}
+
+// BundleInfo returns a bundle of a package of the catalog, with its related images
+func (o OperatorCatalog) BundleInfo(pkgName, name string) (BundleInfo, error) {
+	if _, ok := o.Packages[pkgName]; !ok {
+		return BundleInfo{}, fmt.Errorf("package %s not found in catalog", pkgName)
+	}
+	b, ok := o.BundlesByPkgAndName[pkgName][name]
+	if !ok {
+		return BundleInfo{}, fmt.Errorf("bundle %s not found in package %s", name, pkgName)
+	}
+	return BundleInfo{Name: b.Name, Version: bundleVersion(b), Image: b.Image, RelatedImages: b.RelatedImages}, nil
+}
+
+// IncludePackage returns the selection of a package for an ImageSetConfiguration.
+// The selection is the whole package when channels is empty, otherwise these channels.
+// minVersion and maxVersion, when set, must be versions of the selected bundles.
+// When the default channel of the package is not selected, the first channel becomes the
+// default channel of the selection, as the filtered catalog must have one.
+func (o OperatorCatalog) IncludePackage(pkgName string, channels []string, minVersion, maxVersion string) (v2alpha1.IncludePackage, error) {
+	info, err := o.PackageInfo(pkgName)
+	if err != nil {
+		return v2alpha1.IncludePackage{}, err
+	}
+	include := v2alpha1.IncludePackage{Name: pkgName}
+	bundles := bundleVersions{}
+	for _, ch := range info.Channels {
+		if len(channels) == 0 || slices.Contains(channels, ch.Name) {
+			bundles.add(ch.Bundles)
+		}
+	}
+	for _, name := range channels {
+		if !slices.ContainsFunc(info.Channels, func(ch ChannelInfo) bool { return ch.Name == name }) {
+			return v2alpha1.IncludePackage{}, fmt.Errorf("channel %s not found in package %s", name, pkgName)
+		}
+	}
+	for _, version := range []string{minVersion, maxVersion} {
+		if version != "" && !bundles[version] {
+			return v2alpha1.IncludePackage{}, fmt.Errorf("version %s not found in the selected channels of package %s", version, pkgName)
+		}
+	}
+
+	versions := v2alpha1.IncludeBundle{MinVersion: minVersion, MaxVersion: maxVersion}
+	if len(channels) == 0 {
+		include.IncludeBundle = versions
+		return include, nil
+	}
+	for _, name := range channels {
+		include.Channels = append(include.Channels, v2alpha1.IncludeChannel{Name: name, IncludeBundle: versions})
+	}
+	if !slices.Contains(channels, info.DefaultChannel) {
+		include.DefaultChannel = channels[0]
+	}
+	return include, nil
+}
+
+// bundleVersions is the set of the versions of the selected bundles
+type bundleVersions map[string]bool
+
+func (v bundleVersions) add(bundles []BundleInfo) {
+	for _, b := range bundles {
+		if b.Version != "" {
+			v[b.Version] = true
+		}
+	}
+}
+
+// bundleVersion returns the version of the olm.package property of a bundle,
+// or the version in its name when it has none
+func bundleVersion(b declcfg.Bundle) string {
+	for _, prop := range b.Properties {
+		if prop.Type != property.TypePackage {
+			continue
+		}
+		var p property.Package
+		if err := json.Unmarshal(prop.Value, &p); err == nil && p.Version != "" {
+			return p.Version
+		}
+	}
+	if version, err := getChannelEntrySemVer(b.Name); err == nil {
+		return version.String()
+	}
+	return ""
+}
+
+// sortBundles orders bundles by version, the bundles without a semantic version last by name
+func sortBundles(bundles []BundleInfo) {
+	sort.SliceStable(bundles, func(i, j int) bool {
+		vi, errI := semver.Parse(bundles[i].Version)
+		vj, errJ := semver.Parse(bundles[j].Version)
+		switch {
+		case errI == nil && errJ == nil:
+			return vi.LT(vj)
+		case errI == nil || errJ == nil:
+			return errI == nil
+		default:
+			return bundles[i].Name < bundles[j].Name
+		}
+	})
+}
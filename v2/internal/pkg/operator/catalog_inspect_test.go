@@ -0,0 +1,93 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func TestOperatorCatalogInspection(t *testing.T) {
+	bundle := func(name, version string, relatedImages ...declcfg.RelatedImage) declcfg.Bundle {
+		b := declcfg.Bundle{Schema: declcfg.SchemaBundle, Package: "aws-load-balancer-operator", Name: name, Image: "registry.redhat.io/albo/bundle:" + name, RelatedImages: relatedImages}
+		if version != "" {
+			b.Properties = []property.Property{property.MustBuildPackage("aws-load-balancer-operator", version)}
+		}
+		return b
+	}
+	ctlg := NewOperatorCatalog(&declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "aws-load-balancer-operator", DefaultChannel: "stable-v1"},
+			{Schema: declcfg.SchemaPackage, Name: "3scale-operator", DefaultChannel: "threescale-2.14"},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v1", Entries: []declcfg.ChannelEntry{
+				{Name: "albo.v1.10.0", Replaces: "albo.v1.2.0"},
+				{Name: "albo.v1.2.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Package: "aws-load-balancer-operator", Name: "stable-v0", Entries: []declcfg.ChannelEntry{{Name: "albo.v0.2.0"}}},
+			{Schema: declcfg.SchemaChannel, Package: "3scale-operator", Name: "threescale-2.14", Entries: []declcfg.ChannelEntry{{Name: "3scale.v0.11.0"}}},
+		},
+		Bundles: []declcfg.Bundle{
+			bundle("albo.v0.2.0", ""),
+			bundle("albo.v1.2.0", "1.2.0"),
+			bundle("albo.v1.10.0", "1.10.0", declcfg.RelatedImage{Name: "controller", Image: "registry.redhat.io/albo/operator@sha256:1"}),
+			{Schema: declcfg.SchemaBundle, Package: "3scale-operator", Name: "3scale.v0.11.0"},
+		},
+	})
+
+	t.Run("Testing PackageInfos - should list the packages by name, their channels by name and bundles by version", func(t *testing.T) {
+		pkgs := ctlg.PackageInfos()
+		assert.Equal(t, []string{"3scale-operator", "aws-load-balancer-operator"}, []string{pkgs[0].Name, pkgs[1].Name})
+		assert.Equal(t, PackageInfo{
+			Name:           "aws-load-balancer-operator",
+			DefaultChannel: "stable-v1",
+			Channels: []ChannelInfo{
+				{Name: "stable-v0", Head: "albo.v0.2.0", Bundles: []BundleInfo{{Name: "albo.v0.2.0", Version: "0.2.0", Image: "registry.redhat.io/albo/bundle:albo.v0.2.0"}}},
+				{Name: "stable-v1", Head: "albo.v1.10.0", Bundles: []BundleInfo{
+					{Name: "albo.v1.2.0", Version: "1.2.0", Image: "registry.redhat.io/albo/bundle:albo.v1.2.0"},
+					{Name: "albo.v1.10.0", Version: "1.10.0", Image: "registry.redhat.io/albo/bundle:albo.v1.10.0"},
+				}},
+			},
+		}, pkgs[1])
+	})
+
+	t.Run("Testing BundleInfo - should report the related images of a bundle", func(t *testing.T) {
+		b, err := ctlg.BundleInfo("aws-load-balancer-operator", "albo.v1.10.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []declcfg.RelatedImage{{Name: "controller", Image: "registry.redhat.io/albo/operator@sha256:1"}}, b.RelatedImages)
+
+		_, err = ctlg.BundleInfo("aws-load-balancer-operator", "albo.v9.0.0")
+		assert.EqualError(t, err, "bundle albo.v9.0.0 not found in package aws-load-balancer-operator")
+		_, err = ctlg.BundleInfo("odf-operator", "odf.v4.16.0")
+		assert.EqualError(t, err, "package odf-operator not found in catalog")
+	})
+
+	t.Run("Testing IncludePackage - should select the package, its channels and versions", func(t *testing.T) {
+		include, err := ctlg.IncludePackage("aws-load-balancer-operator", nil, "1.2.0", "")
+		assert.NoError(t, err)
+		assert.Equal(t, v2alpha1.IncludePackage{Name: "aws-load-balancer-operator", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "1.2.0"}}, include)
+
+		include, err = ctlg.IncludePackage("aws-load-balancer-operator", []string{"stable-v1"}, "", "1.10.0")
+		assert.NoError(t, err)
+		assert.Equal(t, v2alpha1.IncludePackage{Name: "aws-load-balancer-operator", Channels: []v2alpha1.IncludeChannel{
+			{Name: "stable-v1", IncludeBundle: v2alpha1.IncludeBundle{MaxVersion: "1.10.0"}},
+		}}, include)
+
+		include, err = ctlg.IncludePackage("aws-load-balancer-operator", []string{"stable-v0"}, "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, v2alpha1.IncludePackage{Name: "aws-load-balancer-operator", DefaultChannel: "stable-v0", Channels: []v2alpha1.IncludeChannel{{Name: "stable-v0"}}}, include)
+	})
+
+	t.Run("Testing IncludePackage - should fail", func(t *testing.T) {
+		_, err := ctlg.IncludePackage("aws-load-balancer-operator", []string{"stable-v2"}, "", "")
+		assert.EqualError(t, err, "channel stable-v2 not found in package aws-load-balancer-operator")
+		_, err = ctlg.IncludePackage("aws-load-balancer-operator", []string{"stable-v0"}, "1.2.0", "")
+		assert.EqualError(t, err, "version 1.2.0 not found in the selected channels of package aws-load-balancer-operator")
+		_, err = ctlg.IncludePackage("odf-operator", nil, "", "")
+		assert.EqualError(t, err, "package odf-operator not found in catalog")
+	})
+}
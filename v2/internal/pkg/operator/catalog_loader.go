@@ -0,0 +1,73 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// CatalogLoader loads the declarative config of the catalogs of an ImageSetConfiguration,
+// unfiltered. The catalog images are cached in the working-dir as by the FilterCollector,
+// so that a mirroring which follows does not copy them again.
+type CatalogLoader struct {
+	OperatorCollector
+}
+
+func NewCatalogLoader(log clog.PluggableLoggerInterface,
+	opts mirror.CopyOptions,
+	mirror mirror.MirrorInterface,
+	manifest manifest.ManifestInterface,
+) *CatalogLoader {
+	return &CatalogLoader{OperatorCollector{Log: log, Opts: opts, Mirror: mirror, Manifest: manifest, ctlgHandler: catalogHandler{Log: log}}}
+}
+
+// DeclarativeConfig returns the declarative config of the catalog of an operator,
+// as found by the FilterCollector before filtering it
+func (o *CatalogLoader) DeclarativeConfig(ctx context.Context, op v2alpha1.Operator) (*declcfg.DeclarativeConfig, error) {
+	imgSpec, err := image.ParseRef(op.Catalog)
+	if err != nil {
+		return nil, err
+	}
+	// the declarative config of a file-based catalog directory is read in place
+	if imgSpec.Transport == dirProtocol {
+		return o.ctlgHandler.getDeclarativeConfig(o.fbcDir(imgSpec))
+	}
+
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	catalogDigest, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find catalog %s: %v", op.Catalog, err)
+	}
+
+	imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), catalogDigest)
+	configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
+	catalogImageDir := filepath.Join(imageIndexDir, operatorCatalogImageDir)
+	if err := createFolders([]string{configsDir, catalogImageDir}); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+		if err := o.copyCatalogImage(ctx, op, imgSpec, catalogImageDir); err != nil {
+			return nil, fmt.Errorf("unable to copy catalog %s: %v", op.Catalog, err)
+		}
+	}
+
+	label, _, err := o.extractCatalogConfig(op, imgSpec, catalogImageDir, configsDir)
+	if err != nil {
+		return nil, err
+	}
+	return o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+}
@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestCatalogLoader(t *testing.T) {
+	log := clog.New("trace")
+	ctx := context.Background()
+
+	newOpts := func(t *testing.T) mirror.CopyOptions {
+		global := &mirror.GlobalOptions{WorkingDir: filepath.Join(t.TempDir(), "working-dir")}
+		_, sharedOpts := mirror.SharedImageFlags()
+		_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+		_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+		return mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.MirrorToDisk}
+	}
+
+	t.Run("Testing DeclarativeConfig - FBC directory: should read the directory in place", func(t *testing.T) {
+		fbcDir := filepath.Join(t.TempDir(), "internal-operators")
+		require.NoError(t, os.MkdirAll(filepath.Join(fbcDir, "op1"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(fbcDir, "op1", "catalog.yaml"), []byte("schema: olm.package\nname: op1\ndefaultChannel: stable\n"), 0644))
+
+		opts := newOpts(t)
+		loader := NewCatalogLoader(log, opts, &MockMirror{Fail: true}, &MockManifest{Log: log})
+		dc, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: "dir://" + fbcDir})
+		require.NoError(t, err)
+		require.Len(t, dc.Packages, 1)
+		assert.Equal(t, "op1", dc.Packages[0].Name)
+		assert.NoDirExists(t, filepath.Join(opts.Global.WorkingDir, operatorCatalogsDir))
+	})
+
+	t.Run("Testing DeclarativeConfig - FBC file: should read the file in place", func(t *testing.T) {
+		fbcFile := filepath.Join(t.TempDir(), "internal-operators.yaml")
+		require.NoError(t, os.WriteFile(fbcFile, []byte("schema: olm.package\nname: op1\ndefaultChannel: stable\n"), 0644))
+
+		loader := NewCatalogLoader(log, newOpts(t), &MockMirror{Fail: true}, &MockManifest{Log: log})
+		dc, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: "dir://" + fbcFile})
+		require.NoError(t, err)
+		require.Len(t, dc.Packages, 1)
+		assert.Equal(t, "op1", dc.Packages[0].Name)
+	})
+
+	t.Run("Testing DeclarativeConfig - FBC file: should fail for a file which is not a declarative config", func(t *testing.T) {
+		fbcFile := filepath.Join(t.TempDir(), "internal-operators.tar")
+		require.NoError(t, os.WriteFile(fbcFile, []byte("not a catalog"), 0644))
+
+		loader := NewCatalogLoader(log, newOpts(t), &MockMirror{Fail: true}, &MockManifest{Log: log})
+		_, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: "dir://" + fbcFile})
+		assert.EqualError(t, err, "file-based catalog "+fbcFile+" must be a directory, or a .json or .yaml file")
+	})
+
+	t.Run("Testing DeclarativeConfig - catalog image: should cache the catalog in the working-dir", func(t *testing.T) {
+		opts := newOpts(t)
+		loader := NewCatalogLoader(log, opts, &MockMirror{}, &MockManifest{Log: log})
+		loader.ctlgHandler = &MockHandler{Log: log}
+		dc, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"})
+		require.NoError(t, err)
+		require.Len(t, dc.Packages, 1)
+		assert.Equal(t, "op1", dc.Packages[0].Name)
+		assert.DirExists(t, filepath.Join(opts.Global.WorkingDir, operatorCatalogsDir, "redhat-operator-index", "f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", operatorCatalogImageDir))
+	})
+
+	t.Run("Testing DeclarativeConfig - catalog image: should fail when the catalog can not be copied", func(t *testing.T) {
+		loader := NewCatalogLoader(log, newOpts(t), &MockMirror{Fail: true}, &MockManifest{Log: log})
+		_, err := loader.DeclarativeConfig(ctx, v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"})
+		assert.EqualError(t, err, "unable to copy catalog registry.redhat.io/redhat/redhat-operator-index:v4.16: forced mirror run fail")
+	})
+}
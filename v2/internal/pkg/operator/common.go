@@ -2,17 +2,24 @@ package operator
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"os"
 	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/otiai10/copy"
 )
 
 type OperatorCollector struct {
@@ -53,6 +60,91 @@ func isMultiManifestIndex(oci v2alpha1.OCISchema) bool {
 	return len(oci.Manifests) > 1
 }
 
+// copyCatalogImage copies the catalog image of op to the oci layout catalogImageDir:
+// the directory of an oci:// catalog is copied as is, the other catalogs are copied by the mirror
+func (o OperatorCollector) copyCatalogImage(ctx context.Context, op v2alpha1.Operator, imgSpec image.ImageSpec, catalogImageDir string) error {
+	if imgSpec.Transport == ociProtocol {
+		return copy.Copy(imgSpec.PathComponent, catalogImageDir)
+	}
+	optsCopy := o.Opts
+	optsCopy.Stdout = io.Discard
+	return o.Mirror.Run(ctx, dockerProtocol+op.Catalog, ociProtocolTrimmed+catalogImageDir, "copy", &optsCopy)
+}
+
+// extractCatalogConfig extracts the declarative config of the catalog image in the oci layout
+// catalogImageDir to configsDir, and returns the label of the catalog image: the directory of
+// the declarative config in configsDir. The multi-manifest index of an oci:// catalog is
+// converted to a single manifest first, which converted reports.
+func (o OperatorCollector) extractCatalogConfig(op v2alpha1.Operator, imgSpec image.ImageSpec, catalogImageDir, configsDir string) (label string, converted bool, err error) {
+	// it's in oci format so we can go directly to the index.json file
+	oci, err := o.Manifest.GetImageIndex(catalogImageDir)
+	if err != nil {
+		return "", false, err
+	}
+
+	if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
+		if err := o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci); err != nil {
+			return "", false, err
+		}
+		if oci, err = o.Manifest.GetImageIndex(catalogImageDir); err != nil {
+			return "", false, err
+		}
+		converted = true
+	}
+
+	if len(oci.Manifests) == 0 {
+		return "", converted, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
+	}
+
+	validDigest, err := digest.Parse(oci.Manifests[0].Digest)
+	if err != nil {
+		return "", converted, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+	}
+
+	o.Log.Debug(collectorPrefix+"manifest %s", validDigest.Encoded())
+	// read the operator image manifest
+	oci, err = o.Manifest.GetImageManifest(filepath.Join(catalogImageDir, blobsDir, validDigest.Encoded()))
+	if err != nil {
+		return "", converted, err
+	}
+
+	// we need to check if oci returns multi manifests
+	// (from manifest list) also oci.Config will be nil
+	// we are only interested in the first manifest as all
+	// architecture "configs" will be exactly the same
+	if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
+		subDigest, err := digest.Parse(oci.Manifests[0].Digest)
+		if err != nil {
+			return "", converted, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+		}
+		oci, err = o.Manifest.GetImageManifest(filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded()))
+		if err != nil {
+			return "", converted, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+		}
+	}
+
+	// read the config digest to get the detailed manifest
+	// looking for the lable to search for a specific folder
+	configDigest, err := digest.Parse(oci.Config.Digest)
+	if err != nil {
+		return "", converted, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+	}
+	ocs, err := o.Manifest.GetOperatorConfig(filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded()))
+	if err != nil {
+		return "", converted, err
+	}
+
+	label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
+	o.Log.Debug(collectorPrefix+"label %s", label)
+
+	// untar all the blobs for the operator
+	// if the layer with "label (from previous step) is found to a specific folder"
+	if err := o.Manifest.ExtractLayersOCI(filepath.Join(catalogImageDir, blobsDir), configsDir, label, oci); err != nil {
+		return "", converted, err
+	}
+	return label, converted, nil
+}
+
 // cachedCatalog returns the reference to the filtered catalog in the local oc-mirror cache
 // The filtered cached catalog reference is computed from:
 // * `catalog` (`v2alpha1.Operator`): the reference to the catalog in the imageSetConfig along with targetCatalog and targetTag if set
@@ -68,8 +160,8 @@ func (o OperatorCollector) cachedCatalog(catalog v2alpha1.Operator, filteredTag
 	switch {
 	case len(catalog.TargetCatalog) > 0:
 		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, catalog.TargetCatalog}, "/")
-	case srcImgSpec.Transport == ociProtocol:
-		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, path.Base(srcImgSpec.Reference)}, "/")
+	case srcImgSpec.Transport == ociProtocol || srcImgSpec.Transport == dirProtocol:
+		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, catalogRepositoryName(srcImgSpec)}, "/")
 	default:
 		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, srcImgSpec.PathComponent}, "/")
 	}
@@ -79,6 +171,55 @@ func (o OperatorCollector) cachedCatalog(catalog v2alpha1.Operator, filteredTag
 	return src, nil
 }
 
+// isWorkingDirCatalog returns true when the file-based catalog directory is in the working-dir,
+// as the one of a composite catalog: its path is then relative to the working-dir, so that the
+// references saved in the working-dir do not depend on where the working-dir is.
+func isWorkingDirCatalog(imgSpec image.ImageSpec) bool {
+	return strings.HasPrefix(imgSpec.PathComponent, filepath.Join(operatorCatalogsDir, compositeCatalogsDir)+"/")
+}
+
+// fbcDir returns the path of a file-based catalog directory, or file, resolving the ones relative to the working-dir
+func (o OperatorCollector) fbcDir(imgSpec image.ImageSpec) string {
+	if isWorkingDirCatalog(imgSpec) {
+		return filepath.Join(o.Opts.Global.WorkingDir, imgSpec.PathComponent)
+	}
+	return imgSpec.PathComponent
+}
+
+// isFBCFile returns true when a file-based catalog is a single declarative config file
+// instead of a directory, and fails for the files which are not declarative configs
+func isFBCFile(fbcPath string) (bool, error) {
+	info, err := os.Stat(fbcPath)
+	if err != nil {
+		return false, err
+	}
+	if info.IsDir() {
+		return false, nil
+	}
+	if !slices.Contains(fbcFileExtensions, strings.ToLower(filepath.Ext(fbcPath))) {
+		return false, fmt.Errorf("file-based catalog %s must be a directory, or a .json or .yaml file", fbcPath)
+	}
+	return true, nil
+}
+
+// catalogRepositoryName returns the repository name of a catalog from a local path:
+// the name of the OCI layout or of the file-based catalog, without the file extension
+func catalogRepositoryName(imgSpec image.ImageSpec) string {
+	name := path.Base(imgSpec.Reference)
+	if imgSpec.Transport == dirProtocol && slices.Contains(fbcFileExtensions, strings.ToLower(path.Ext(name))) {
+		return strings.TrimSuffix(name, path.Ext(name))
+	}
+	return name
+}
+
+// fbcDigestFile returns the file of the working-dir where the digest of a file-based catalog
+// is saved. It is keyed by the catalog reference as written in the ImageSetConfiguration, so that
+// diskToMirror finds it wherever it runs, and different catalogs with the same name do not share it.
+func (o OperatorCollector) fbcDigestFile(imgSpec image.ImageSpec) (string, error) {
+	pathHash := fmt.Sprintf("%x", sha256.Sum256([]byte(imgSpec.ReferenceWithTransport)))
+	return filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), operatorCatalogFBCDigest, pathHash), nil
+}
+
 // catalogDigest: method used during diskToMirror in order to discover the catalog's digest from a reference by tag.
 // It queries the cache registry instead of the registry set in the `catalog` reference
 func (o OperatorCollector) catalogDigest(ctx context.Context, catalog v2alpha1.Operator) (string, error) {
@@ -89,12 +230,26 @@ func (o OperatorCollector) catalogDigest(ctx context.Context, catalog v2alpha1.O
 		return "", fmt.Errorf("unable to determine cached reference for catalog %s: %v", catalog.Catalog, err)
 	}
 
+	// the catalog built from a file-based catalog directory is identified by the digest
+	// of the directory, saved in the working-dir during mirrorToDisk
+	if srcImgSpec.Transport == dirProtocol {
+		digestFile, err := o.fbcDigestFile(srcImgSpec)
+		if err != nil {
+			return "", err
+		}
+		fbcDigest, err := os.ReadFile(digestFile)
+		if err != nil {
+			return "", fmt.Errorf("unable to find the digest of catalog %s: %v", catalog.Catalog, err)
+		}
+		return string(fbcDigest), nil
+	}
+
 	// prepare the src and dest references
 	switch {
 	case len(catalog.TargetCatalog) > 0:
 		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, catalog.TargetCatalog}, "/")
 	case srcImgSpec.Transport == ociProtocol:
-		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, path.Base(srcImgSpec.Reference)}, "/")
+		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, catalogRepositoryName(srcImgSpec)}, "/")
 	default:
 		src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, srcImgSpec.PathComponent}, "/")
 	}
@@ -157,7 +312,7 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 			case img.Type == v2alpha1.TypeOperatorCatalog && len(img.TargetCatalog) > 0:
 				src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, img.TargetCatalog}, "/")
 				dest = strings.Join([]string{o.Opts.Destination, img.TargetCatalog}, "/")
-			case imgSpec.Transport == ociProtocol:
+			case imgSpec.Transport == ociProtocol || imgSpec.Transport == dirProtocol:
 				src = dockerProtocol + strings.Join([]string{o.LocalStorageFQDN, img.Name}, "/")
 				dest = strings.Join([]string{o.Opts.Destination, img.Name}, "/")
 			default:
@@ -211,7 +366,7 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 
 			o.Log.Debug("source %s", src)
 			o.Log.Debug("destination %s", dest)
-			if img.Type == v2alpha1.TypeOperatorCatalog && o.Opts.Function == "delete" {
+			if img.Type == v2alpha1.TypeOperatorCatalog && o.Opts.Function == "delete" && !o.deletesFullCatalog(img.Image) {
 				o.Log.Debug("delete mode, catalog index %s : SKIPPED", img.Image)
 			} else {
 				if _, found := alreadyIncluded[img.Image]; !found {
@@ -224,6 +379,15 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 	return result, nil
 }
 
+// deletesFullCatalog returns true when the delete configuration deletes all the operators of the catalog:
+// the catalog image is deleted as well, and its CatalogSource is not generated anymore.
+// Otherwise the catalog image is kept, for the operators left.
+func (o OperatorCollector) deletesFullCatalog(catalogImage string) bool {
+	return slices.ContainsFunc(o.Config.Mirror.Operators, func(op v2alpha1.Operator) bool {
+		return op.Catalog == catalogImage && isFullCatalog(op)
+	})
+}
+
 func (o OperatorCollector) prepareM2DCopyBatch(images map[string][]v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSchema, error) {
 	var result []v2alpha1.CopyImageSchema
 	var alreadyIncluded map[string]struct{} = make(map[string]struct{})
@@ -246,7 +410,7 @@ func (o OperatorCollector) prepareM2DCopyBatch(images map[string][]v2alpha1.Rela
 			// applies only to catalogs
 			case img.Type == v2alpha1.TypeOperatorCatalog && len(img.TargetCatalog) > 0:
 				dest = dockerProtocol + strings.Join([]string{o.destinationRegistry(), img.TargetCatalog}, "/")
-			case img.Type == v2alpha1.TypeOperatorCatalog && imgSpec.Transport == ociProtocol:
+			case img.Type == v2alpha1.TypeOperatorCatalog && (imgSpec.Transport == ociProtocol || imgSpec.Transport == dirProtocol):
 				dest = dockerProtocol + strings.Join([]string{o.destinationRegistry(), img.Name}, "/")
 			default:
 				dest = dockerProtocol + strings.Join([]string{o.destinationRegistry(), imgSpec.PathComponent}, "/")
@@ -270,6 +434,15 @@ func (o OperatorCollector) prepareM2DCopyBatch(images map[string][]v2alpha1.Rela
 				dest = dest + ":" + imgSpec.Tag
 			}
 
+			// the catalog built from a file-based catalog directory is already in the cache
+			if imgSpec.Transport == dirProtocol {
+				destSpec, err := image.ParseRef(dest)
+				if err != nil {
+					return result, err
+				}
+				src = destSpec.SetTag(img.RebuiltTag).ReferenceWithTransport
+			}
+
 			o.Log.Debug("source %s", src)
 			o.Log.Debug("destination %s", dest)
 
@@ -387,8 +560,13 @@ func (d CatalogImageDispatcher) dispatch(img v2alpha1.RelatedImage) ([]v2alpha1.
 	toCacheImage = saveCtlgToCacheRef(imgSpec, img, d.cacheRegistry)
 	fromRebuiltImage = rebuiltCtlgRef(imgSpec, img, d.cacheRegistry)
 	toDestImage = destCtlgRef(imgSpec, img, d.destinationRegistry)
+	toCacheSource := imgSpec.ReferenceWithTransport
+	if imgSpec.Transport == dirProtocol {
+		// the catalog built from a file-based catalog directory is already in the cache
+		toCacheSource = fromRebuiltImage
+	}
 	cacheCopy := v2alpha1.CopyImageSchema{
-		Source:      imgSpec.ReferenceWithTransport,
+		Source:      toCacheSource,
 		Destination: toCacheImage,
 		Origin:      imgSpec.ReferenceWithTransport,
 		RebuiltTag:  img.RebuiltTag,
@@ -411,7 +589,7 @@ func saveCtlgToCacheRef(spec image.ImageSpec, img v2alpha1.RelatedImage, cacheRe
 	switch {
 	case len(img.TargetCatalog) > 0:
 		saveCtlgDest = strings.Join([]string{cacheRegistry, img.TargetCatalog}, "/")
-	case spec.Transport == ociProtocol:
+	case spec.Transport == ociProtocol || spec.Transport == dirProtocol:
 		saveCtlgDest = strings.Join([]string{cacheRegistry, img.Name}, "/")
 	default:
 		saveCtlgDest = strings.Join([]string{cacheRegistry, spec.PathComponent}, "/")
@@ -440,7 +618,7 @@ func rebuiltCtlgRef(spec image.ImageSpec, img v2alpha1.RelatedImage, cacheRegist
 	// applies only to catalogs
 	case len(img.TargetCatalog) > 0:
 		rebuiltCtlgSrc = strings.Join([]string{cacheRegistry, img.TargetCatalog}, "/")
-	case spec.Transport == ociProtocol:
+	case spec.Transport == ociProtocol || spec.Transport == dirProtocol:
 		rebuiltCtlgSrc = strings.Join([]string{cacheRegistry, img.Name}, "/")
 	default:
 		rebuiltCtlgSrc = strings.Join([]string{cacheRegistry, spec.PathComponent}, "/")
@@ -474,7 +652,7 @@ func destCtlgRef(spec image.ImageSpec, img v2alpha1.RelatedImage, destinationReg
 	// applies only to catalogs
 	case len(img.TargetCatalog) > 0:
 		dest = strings.Join([]string{destinationRegistry, img.TargetCatalog}, "/")
-	case spec.Transport == ociProtocol:
+	case spec.Transport == ociProtocol || spec.Transport == dirProtocol:
 		dest = strings.Join([]string{destinationRegistry, img.Name}, "/")
 	default:
 		dest = strings.Join([]string{destinationRegistry, spec.PathComponent}, "/")
@@ -2,10 +2,13 @@ package operator
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/stretchr/testify/assert"
@@ -76,6 +79,61 @@ func TestPrepareDeleteForV1(t *testing.T) {
 		})
 	}
 }
+func TestPrepareD2MCopyBatchDelete(t *testing.T) {
+	log := clog.New("trace")
+	tempDir := t.TempDir()
+	relatedImages := map[string][]v2alpha1.RelatedImage{
+		"redhat-operator-index": {
+			{
+				Name:  "redhat-operator-index",
+				Image: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				Type:  v2alpha1.TypeOperatorCatalog,
+			},
+		},
+		"operatorA": {
+			{
+				Name:  "testA",
+				Image: "registry.redhat.io/openshift4/ose-kube-rbac-proxy@sha256:7efeeb8b29872a6f0271f651d7ae02c91daea16d853c50e374c310f044d8c76c",
+				Type:  v2alpha1.TypeOperatorBundle,
+			},
+		},
+	}
+	type testCase struct {
+		caseName        string
+		operator        v2alpha1.Operator
+		expectedCatalog bool
+	}
+	testCases := []testCase{
+		{
+			caseName: "Testing prepareD2MCopyBatch - delete of packages: should keep the catalog",
+			operator: v2alpha1.Operator{
+				Catalog:       "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{{Name: "aws-load-balancer-operator"}}},
+			},
+			expectedCatalog: false,
+		},
+		{
+			caseName:        "Testing prepareD2MCopyBatch - delete of the full catalog: should delete the catalog",
+			operator:        v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", Full: true},
+			expectedCatalog: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{})
+			ex.Opts.Mode = string(mirror.DeleteMode)
+			ex.Opts.Function = string(mirror.DeleteMode)
+			ex.Opts.Destination = "docker://localhost:5000/test"
+			ex.Config.Mirror.Operators = []v2alpha1.Operator{testCase.operator}
+			res, err := ex.prepareD2MCopyBatch(relatedImages)
+			assert.NoError(t, err)
+			hasCatalog := slices.ContainsFunc(res, func(img v2alpha1.CopyImageSchema) bool { return img.Type == v2alpha1.TypeOperatorCatalog })
+			assert.Equal(t, testCase.expectedCatalog, hasCatalog)
+			assert.True(t, slices.ContainsFunc(res, func(img v2alpha1.CopyImageSchema) bool { return img.Type == v2alpha1.TypeOperatorBundle }))
+		})
+	}
+}
+
 func TestPrepareM2MCopyBatch(t *testing.T) {
 
 	log := clog.New("trace")
@@ -447,3 +505,46 @@ func TestPrepareM2MCopyBatch(t *testing.T) {
 	}
 
 }
+
+func TestFBCDigestFile(t *testing.T) {
+	t.Run("Testing fbcDigestFile - should not share the digest of directories with the same name", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		first, err := image.ParseRef("dir:///teams/a/internal-operators")
+		assert.NoError(t, err)
+		second, err := image.ParseRef("dir:///teams/b/internal-operators")
+		assert.NoError(t, err)
+		firstFile, err := ex.fbcDigestFile(first)
+		assert.NoError(t, err)
+		secondFile, err := ex.fbcDigestFile(second)
+		assert.NoError(t, err)
+		assert.NotEqual(t, firstFile, secondFile)
+	})
+
+	t.Run("Testing fbcDigestFile - should be keyed by the catalog reference, not by the current directory", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		imgSpec, err := image.ParseRef("dir://catalogs/internal-operators")
+		assert.NoError(t, err)
+		firstFile, err := ex.fbcDigestFile(imgSpec)
+		assert.NoError(t, err)
+		cwd, err := os.Getwd()
+		assert.NoError(t, err)
+		assert.NoError(t, os.Chdir(t.TempDir()))
+		defer func() { assert.NoError(t, os.Chdir(cwd)) }()
+		secondFile, err := ex.fbcDigestFile(imgSpec)
+		assert.NoError(t, err)
+		assert.Equal(t, firstFile, secondFile)
+	})
+
+	t.Run("Testing fbcDigestFile - should not depend on the location of the working-dir for composite catalogs", func(t *testing.T) {
+		first := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		second := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		imgSpec, err := image.ParseRef("dir://" + filepath.Join(operatorCatalogsDir, compositeCatalogsDir, "approved"))
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(first.Opts.Global.WorkingDir, operatorCatalogsDir, compositeCatalogsDir, "approved"), first.fbcDir(imgSpec))
+		firstFile, err := first.fbcDigestFile(imgSpec)
+		assert.NoError(t, err)
+		secondFile, err := second.fbcDigestFile(imgSpec)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Base(firstFile), filepath.Base(secondFile))
+	})
+}
@@ -5,11 +5,14 @@ const (
 	dockerProtocol                    = "docker://"
 	ociProtocol                       = "oci://"
 	ociProtocolTrimmed                = "oci:"
+	dirProtocol                       = "dir://"
 	operatorImageDir                  = "operator-images" //TODO ALEX REMOVE ME when filtered_collector.go is the default
 	operatorCatalogsDir        string = "operator-catalogs"
 	operatorCatalogConfigDir   string = "catalog-config"
 	operatorCatalogImageDir    string = "catalog-image"
 	operatorCatalogFilteredDir string = "filtered-catalogs"
+	operatorCatalogFBCDigest   string = "fbc-digest"
+	compositeCatalogsDir       string = "composite-catalogs"
 	blobsDir                          = "blobs/sha256"
 	collectorPrefix                   = "[OperatorImageCollector] "
 	errMsg                            = collectorPrefix + "%s"
@@ -18,3 +21,6 @@ const (
 	filteredCatalogDir                = "filtered-operator"
 	digestIncorrectMessage     string = "the digests seem to be incorrect for %s: %s "
 )
+
+// fbcFileExtensions are the extensions of the declarative config files a file-based catalog can be made of
+var fbcFileExtensions = []string{".json", ".yaml", ".yml"}
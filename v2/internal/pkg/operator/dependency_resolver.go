@@ -0,0 +1,466 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+const dependenciesReportFile = "dependencies-report.yaml"
+
+// DependencyReport explains the bundles added to the filtered catalogs to satisfy the
+// dependencies of the selected bundles, and the dependencies which could not be satisfied
+type DependencyReport struct {
+	Dependencies []Dependency           `json:"dependencies,omitempty"`
+	Unresolved   []UnresolvedDependency `json:"unresolved,omitempty"`
+}
+
+// Dependency is a bundle added to the filtered catalog of Catalog, as RequiredBy requires it
+type Dependency struct {
+	Catalog     string `json:"catalog"`
+	Package     string `json:"package"`
+	Bundle      string `json:"bundle"`
+	RequiredBy  string `json:"requiredBy"`
+	Requirement string `json:"requirement"`
+}
+
+// UnresolvedDependency is a requirement of a selected bundle that no catalog of the
+// ImageSetConfiguration satisfies
+type UnresolvedDependency struct {
+	Catalog     string `json:"catalog"`
+	RequiredBy  string `json:"requiredBy"`
+	Requirement string `json:"requirement"`
+	Reason      string `json:"reason"`
+}
+
+// requirement is an olm.package.required or olm.gvk.required constraint of a bundle
+type requirement struct {
+	pkg          string
+	versionRange string
+	gvk          *property.GVKRequired
+}
+
+func (r requirement) String() string {
+	if r.gvk != nil {
+		return fmt.Sprintf("%s %s/%s %s", property.TypeGVKRequired, r.gvk.Group, r.gvk.Version, r.gvk.Kind)
+	}
+	if r.versionRange == "" {
+		return fmt.Sprintf("%s %s", property.TypePackageRequired, r.pkg)
+	}
+	return fmt.Sprintf("%s %s %s", property.TypePackageRequired, r.pkg, r.versionRange)
+}
+
+// dependencyCatalog is a catalog of the ImageSetConfiguration, unfiltered, with the bundles
+// selected by its filter and those added as dependencies
+type dependencyCatalog struct {
+	op v2alpha1.Operator
+	dc *declcfg.DeclarativeConfig
+	// consumer is true when the dependencies of the selected bundles must be resolved
+	consumer bool
+	// selected and added are the bundle names by package
+	selected map[string]map[string]bool
+	added    map[string][]string
+	// channeled and defaults are the bundles in a channel and in the default channel, by package/name
+	channeled map[string]bool
+	defaults  map[string]bool
+}
+
+func newDependencyCatalog(op v2alpha1.Operator, dc, filteredDC *declcfg.DeclarativeConfig) *dependencyCatalog {
+	c := &dependencyCatalog{
+		op:        op,
+		dc:        dc,
+		consumer:  len(op.Packages) > 0 && !op.SkipDependencies,
+		selected:  map[string]map[string]bool{},
+		added:     map[string][]string{},
+		channeled: map[string]bool{},
+		defaults:  map[string]bool{},
+	}
+	for _, b := range filteredDC.Bundles {
+		c.selectBundle(b.Package, b.Name)
+	}
+	defaultChannels := map[string]string{}
+	for _, pkg := range dc.Packages {
+		defaultChannels[pkg.Name] = pkg.DefaultChannel
+	}
+	for _, ch := range dc.Channels {
+		for _, entry := range ch.Entries {
+			c.channeled[ch.Package+"/"+entry.Name] = true
+			if ch.Name == defaultChannels[ch.Package] {
+				c.defaults[ch.Package+"/"+entry.Name] = true
+			}
+		}
+	}
+	return c
+}
+
+func (c *dependencyCatalog) selectBundle(pkg, name string) {
+	if _, ok := c.selected[pkg]; !ok {
+		c.selected[pkg] = map[string]bool{}
+	}
+	c.selected[pkg][name] = true
+}
+
+// dependencyResolver computes the transitive closure of the dependencies of the selected
+// bundles of the catalogs, across the catalogs of the ImageSetConfiguration
+type dependencyResolver struct {
+	catalogs []*dependencyCatalog
+	report   DependencyReport
+}
+
+// resolve adds to the catalogs the bundles required by their selected bundles.
+// A requirement already satisfied by a selected bundle of any catalog is not resolved again, as
+// OLM resolves across catalog sources. Otherwise the bundle is looked up in the catalog of the
+// requiring bundle first, then in the other catalogs in their order in the ImageSetConfiguration.
+// Among the candidates of a catalog, the bundles of default channels are preferred, then the
+// highest versions.
+func (r *dependencyResolver) resolve() {
+	type pending struct {
+		catalog *dependencyCatalog
+		bundle  declcfg.Bundle
+	}
+	queue := []pending{}
+	for _, c := range r.catalogs {
+		if !c.consumer {
+			continue
+		}
+		for _, b := range c.dc.Bundles {
+			if c.selected[b.Package][b.Name] {
+				queue = append(queue, pending{c, b})
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		reqs, unsupported := bundleRequirements(p.bundle)
+		for _, constraint := range unsupported {
+			r.report.Unresolved = append(r.report.Unresolved, UnresolvedDependency{
+				Catalog: p.catalog.op.Catalog, RequiredBy: p.bundle.Name, Requirement: constraint, Reason: "only the package and gvk constraints are resolved",
+			})
+		}
+		for _, req := range reqs {
+			satisfied, err := r.satisfied(p.catalog, req)
+			if err != nil {
+				r.report.Unresolved = append(r.report.Unresolved, UnresolvedDependency{
+					Catalog: p.catalog.op.Catalog, RequiredBy: p.bundle.Name, Requirement: req.String(), Reason: err.Error(),
+				})
+				continue
+			}
+			if satisfied {
+				continue
+			}
+			provider, b := r.provider(p.catalog, req)
+			if provider == nil {
+				r.report.Unresolved = append(r.report.Unresolved, UnresolvedDependency{
+					Catalog: p.catalog.op.Catalog, RequiredBy: p.bundle.Name, Requirement: req.String(), Reason: "no bundle of the catalogs of the imageset configuration satisfies it",
+				})
+				continue
+			}
+			provider.selectBundle(b.Package, b.Name)
+			provider.added[b.Package] = append(provider.added[b.Package], b.Name)
+			r.report.Dependencies = append(r.report.Dependencies, Dependency{
+				Catalog: provider.op.Catalog, Package: b.Package, Bundle: b.Name, RequiredBy: p.bundle.Name, Requirement: req.String(),
+			})
+			queue = append(queue, pending{provider, b})
+		}
+	}
+}
+
+// ordered returns the catalogs, the catalog first
+func (r *dependencyResolver) ordered(catalog *dependencyCatalog) []*dependencyCatalog {
+	catalogs := []*dependencyCatalog{catalog}
+	for _, c := range r.catalogs {
+		if c != catalog {
+			catalogs = append(catalogs, c)
+		}
+	}
+	return catalogs
+}
+
+func (r *dependencyResolver) satisfied(catalog *dependencyCatalog, req requirement) (bool, error) {
+	for _, c := range r.ordered(catalog) {
+		for _, b := range c.dc.Bundles {
+			if !c.selected[b.Package][b.Name] {
+				continue
+			}
+			ok, err := satisfies(b, req)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *dependencyResolver) provider(catalog *dependencyCatalog, req requirement) (*dependencyCatalog, declcfg.Bundle) {
+	for _, c := range r.ordered(catalog) {
+		candidates := []declcfg.Bundle{}
+		for _, b := range c.dc.Bundles {
+			if ok, _ := satisfies(b, req); ok && c.channeled[b.Package+"/"+b.Name] {
+				candidates = append(candidates, b)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			bi, bj := candidates[i], candidates[j]
+			if di, dj := c.defaults[bi.Package+"/"+bi.Name], c.defaults[bj.Package+"/"+bj.Name]; di != dj {
+				return di
+			}
+			infos := []BundleInfo{{Name: bi.Name, Version: bundleVersion(bi)}, {Name: bj.Name, Version: bundleVersion(bj)}}
+			sortBundles(infos)
+			if infos[0].Name != infos[1].Name {
+				return infos[1].Name == bi.Name
+			}
+			return bi.Package < bj.Package
+		})
+		return c, candidates[0]
+	}
+	return nil, declcfg.Bundle{}
+}
+
+// bundleRequirements returns the package and gvk requirements of a bundle, and its
+// olm.constraint properties that are neither
+func bundleRequirements(b declcfg.Bundle) ([]requirement, []string) {
+	reqs := []requirement{}
+	unsupported := []string{}
+	for _, prop := range b.Properties {
+		switch prop.Type {
+		case property.TypePackageRequired:
+			var p property.PackageRequired
+			if err := json.Unmarshal(prop.Value, &p); err == nil {
+				reqs = append(reqs, requirement{pkg: p.PackageName, versionRange: p.VersionRange})
+			}
+		case property.TypeGVKRequired:
+			var gvk property.GVKRequired
+			if err := json.Unmarshal(prop.Value, &gvk); err == nil {
+				reqs = append(reqs, requirement{gvk: &gvk})
+			}
+		case property.TypeConstraint:
+			var constraint struct {
+				Package *property.PackageRequired `json:"package,omitempty"`
+				GVK     *property.GVKRequired     `json:"gvk,omitempty"`
+			}
+			switch err := json.Unmarshal(prop.Value, &constraint); {
+			case err == nil && constraint.Package != nil:
+				reqs = append(reqs, requirement{pkg: constraint.Package.PackageName, versionRange: constraint.Package.VersionRange})
+			case err == nil && constraint.GVK != nil:
+				reqs = append(reqs, requirement{gvk: constraint.GVK})
+			default:
+				unsupported = append(unsupported, fmt.Sprintf("%s %s", property.TypeConstraint, string(prop.Value)))
+			}
+		}
+	}
+	return reqs, unsupported
+}
+
+// satisfies returns true when a bundle satisfies a requirement
+func satisfies(b declcfg.Bundle, req requirement) (bool, error) {
+	if req.gvk != nil {
+		return slices.ContainsFunc(b.Properties, func(prop property.Property) bool {
+			var gvk property.GVK
+			return prop.Type == property.TypeGVK && json.Unmarshal(prop.Value, &gvk) == nil &&
+				gvk.Group == req.gvk.Group && gvk.Version == req.gvk.Version && gvk.Kind == req.gvk.Kind
+		}), nil
+	}
+	if b.Package != req.pkg {
+		return false, nil
+	}
+	if req.versionRange == "" {
+		return true, nil
+	}
+	versionRange, err := semver.ParseRange(req.versionRange)
+	if err != nil {
+		return false, fmt.Errorf("invalid version range %q: %v", req.versionRange, err)
+	}
+	version, err := semver.Parse(bundleVersion(b))
+	return err == nil && versionRange(version), nil
+}
+
+// dependencyKey identifies a catalog of the ImageSetConfiguration
+func dependencyKey(op v2alpha1.Operator) string {
+	return op.Catalog + "|" + op.TargetCatalog + "|" + op.TargetTag
+}
+
+// resolvedCatalog is a catalog loaded and filtered while resolving the dependencies,
+// reused when the catalog is collected
+type resolvedCatalog struct {
+	original *declcfg.DeclarativeConfig
+	filtered *declcfg.DeclarativeConfig
+}
+
+// resolveDependencies resolves the dependencies of the bundles selected in the catalogs of the
+// ImageSetConfiguration, keeps the catalogs and the bundles to add to each filtered catalog,
+// and writes the report of the dependencies to the working-dir
+func (o *FilterCollector) resolveDependencies(ctx context.Context) error {
+	reportPath := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, dependenciesReportFile)
+	// the report of a previous run does not describe this one
+	if err := os.Remove(reportPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	ops := slices.Clone(o.Config.Mirror.Operators)
+	for _, composite := range o.Config.Mirror.CompositeCatalogs {
+		ops = append(ops, composite.Catalogs...)
+	}
+	if !slices.ContainsFunc(ops, func(op v2alpha1.Operator) bool { return len(op.Packages) > 0 && !op.SkipDependencies }) {
+		return nil
+	}
+
+	loader := &CatalogLoader{o.OperatorCollector}
+	resolver := &dependencyResolver{}
+	o.resolved = map[string]resolvedCatalog{}
+	for _, op := range ops {
+		dc, err := loader.DeclarativeConfig(ctx, op)
+		if err != nil {
+			return err
+		}
+		filteredDC := dc
+		if len(op.Packages) > 0 {
+			if filteredDC, err = filterCatalog(ctx, *dc, op); err != nil {
+				return err
+			}
+			platformVersion, err := o.platformVersion(op)
+			if err == nil {
+				err = applySelectionPolicies(filteredDC, op, platformVersion)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		o.resolved[dependencyKey(op)] = resolvedCatalog{original: dc, filtered: filteredDC}
+		resolver.catalogs = append(resolver.catalogs, newDependencyCatalog(op, dc, filteredDC))
+	}
+	resolver.resolve()
+
+	o.dependencies = map[string]map[string][]string{}
+	for _, c := range resolver.catalogs {
+		for pkg, bundles := range c.added {
+			key := dependencyKey(c.op)
+			if _, ok := o.dependencies[key]; !ok {
+				o.dependencies[key] = map[string][]string{}
+			}
+			o.dependencies[key][pkg] = append(o.dependencies[key][pkg], bundles...)
+		}
+	}
+	for _, d := range resolver.report.Dependencies {
+		o.Log.Info(collectorPrefix+"adding bundle %s of package %s from %s: required by %s (%s)", d.Bundle, d.Package, d.Catalog, d.RequiredBy, d.Requirement)
+	}
+	for _, u := range resolver.report.Unresolved {
+		o.Log.Warn(collectorPrefix+"dependency %s of %s from %s not mirrored: %s", u.Requirement, u.RequiredBy, u.Catalog, u.Reason)
+	}
+	if len(resolver.report.Dependencies) == 0 && len(resolver.report.Unresolved) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(resolver.report)
+	if err != nil {
+		return err
+	}
+	if err := createFolders([]string{filepath.Dir(reportPath)}); err != nil {
+		return err
+	}
+	return os.WriteFile(reportPath, data, 0644)
+}
+
+// addDependencies adds to a filtered declarative config the bundles of the original one
+// resolved as dependencies, by package, with their package, channels and deprecations.
+// The channels of the added bundles are rewired as by the selection policies.
+func addDependencies(filteredDC, originalDC *declcfg.DeclarativeConfig, added map[string][]string) {
+	pkgNames := make([]string, 0, len(added))
+	for pkg := range added {
+		pkgNames = append(pkgNames, pkg)
+	}
+	slices.Sort(pkgNames)
+
+	for _, pkgName := range pkgNames {
+		adding := map[string]bool{}
+		for _, name := range added[pkgName] {
+			adding[name] = true
+		}
+		inFiltered := map[string]bool{}
+		for _, b := range filteredDC.Bundles {
+			if b.Package == pkgName {
+				inFiltered[b.Name] = true
+			}
+		}
+		bundles := map[string]declcfg.Bundle{}
+		for _, b := range originalDC.Bundles {
+			if b.Package == pkgName {
+				bundles[b.Name] = b
+			}
+		}
+
+		channels := []string{}
+		for _, ch := range originalDC.Channels {
+			if ch.Package != pkgName || !slices.ContainsFunc(ch.Entries, func(e declcfg.ChannelEntry) bool { return adding[e.Name] }) {
+				continue
+			}
+			channels = append(channels, ch.Name)
+			filteredIndex := slices.IndexFunc(filteredDC.Channels, func(f declcfg.Channel) bool { return f.Package == pkgName && f.Name == ch.Name })
+			keep := maps.Clone(adding)
+			if filteredIndex >= 0 {
+				for _, entry := range filteredDC.Channels[filteredIndex].Entries {
+					keep[entry.Name] = true
+				}
+			}
+			selected := []BundleInfo{}
+			for _, entry := range ch.Entries {
+				if keep[entry.Name] {
+					selected = append(selected, BundleInfo{Name: entry.Name, Version: bundleVersion(bundles[entry.Name])})
+				}
+			}
+			sortBundles(selected)
+			entries := rewireChannel(ch.Entries, selected)
+			if filteredIndex >= 0 {
+				filteredDC.Channels[filteredIndex].Entries = entries
+			} else {
+				ch.Entries = entries
+				filteredDC.Channels = append(filteredDC.Channels, ch)
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(adding)) {
+			if b, ok := bundles[name]; ok && !inFiltered[name] {
+				filteredDC.Bundles = append(filteredDC.Bundles, b)
+			}
+		}
+
+		if slices.ContainsFunc(filteredDC.Packages, func(p declcfg.Package) bool { return p.Name == pkgName }) {
+			continue
+		}
+		for _, pkg := range originalDC.Packages {
+			if pkg.Name != pkgName {
+				continue
+			}
+			if !slices.Contains(channels, pkg.DefaultChannel) && len(channels) > 0 {
+				slices.Sort(channels)
+				pkg.DefaultChannel = channels[0]
+			}
+			filteredDC.Packages = append(filteredDC.Packages, pkg)
+		}
+		for _, deprecation := range originalDC.Deprecations {
+			if deprecation.Package != pkgName {
+				continue
+			}
+			deprecation.Entries = slices.DeleteFunc(slices.Clone(deprecation.Entries), func(e declcfg.DeprecationEntry) bool {
+				return e.Reference.Schema == declcfg.SchemaBundle && !adding[e.Reference.Name] ||
+					e.Reference.Schema == declcfg.SchemaChannel && !slices.Contains(channels, e.Reference.Name)
+			})
+			filteredDC.Deprecations = append(filteredDC.Deprecations, deprecation)
+		}
+	}
+}
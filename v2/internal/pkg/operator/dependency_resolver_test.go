@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestDependencyResolver(t *testing.T) {
+	const (
+		redhatCatalog    = "registry.redhat.io/redhat/redhat-operator-index:v4.16"
+		certifiedCatalog = "registry.redhat.io/redhat/certified-operator-index:v4.16"
+	)
+	bundle := func(pkg, version string, props ...property.Property) declcfg.Bundle {
+		return declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Package:    pkg,
+			Name:       pkg + ".v" + version,
+			Image:      "registry.redhat.io/" + pkg + "/bundle:v" + version,
+			Properties: append([]property.Property{property.MustBuildPackage(pkg, version)}, props...),
+		}
+	}
+	gvkRequired := property.MustBuildGVKRequired("logging.openshift.io", "v1", "ClusterLogging")
+	constraint := func(value string) property.Property {
+		return property.Property{Type: property.TypeConstraint, Value: json.RawMessage(value)}
+	}
+	// loki requires the logging api, provided by logging 5.8 and 5.9, and elasticsearch 5.x
+	// logging requires nothing, elasticsearch is in the certified catalog
+	newRedhatDC := func() *declcfg.DeclarativeConfig {
+		return &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{
+				{Schema: declcfg.SchemaPackage, Name: "loki-operator", DefaultChannel: "stable"},
+				{Schema: declcfg.SchemaPackage, Name: "cluster-logging", DefaultChannel: "stable-5.9"},
+			},
+			Channels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Package: "loki-operator", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "loki-operator.v5.9.0"}}},
+				{Schema: declcfg.SchemaChannel, Package: "cluster-logging", Name: "stable-5.8", Entries: []declcfg.ChannelEntry{{Name: "cluster-logging.v5.8.0"}}},
+				{Schema: declcfg.SchemaChannel, Package: "cluster-logging", Name: "stable-5.9", Entries: []declcfg.ChannelEntry{
+					{Name: "cluster-logging.v5.9.0"},
+					{Name: "cluster-logging.v5.9.1", Replaces: "cluster-logging.v5.9.0"},
+				}},
+			},
+			Bundles: []declcfg.Bundle{
+				bundle("loki-operator", "5.9.0", gvkRequired, property.MustBuildPackageRequired("elasticsearch-operator", ">=5.0.0 <6.0.0"),
+					constraint(`{"failureMessage":"requires a cel expression","cel":{"rule":"properties.exists(p, p.type == 'certified')"}}`)),
+				bundle("cluster-logging", "5.8.0", property.MustBuildGVK("logging.openshift.io", "v1", "ClusterLogging")),
+				bundle("cluster-logging", "5.9.0", property.MustBuildGVK("logging.openshift.io", "v1", "ClusterLogging")),
+				bundle("cluster-logging", "5.9.1", property.MustBuildGVK("logging.openshift.io", "v1", "ClusterLogging")),
+			},
+		}
+	}
+	newCertifiedDC := func() *declcfg.DeclarativeConfig {
+		return &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "elasticsearch-operator", DefaultChannel: "stable-6"}},
+			Channels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Package: "elasticsearch-operator", Name: "stable-5", Entries: []declcfg.ChannelEntry{
+					{Name: "elasticsearch-operator.v5.7.0"},
+					{Name: "elasticsearch-operator.v5.8.0", Replaces: "elasticsearch-operator.v5.7.0"},
+				}},
+				{Schema: declcfg.SchemaChannel, Package: "elasticsearch-operator", Name: "stable-6", Entries: []declcfg.ChannelEntry{{Name: "elasticsearch-operator.v6.0.0"}}},
+			},
+			Bundles: []declcfg.Bundle{
+				bundle("elasticsearch-operator", "5.7.0"),
+				bundle("elasticsearch-operator", "5.8.0"),
+				bundle("elasticsearch-operator", "6.0.0"),
+			},
+		}
+	}
+	lokiOperator := v2alpha1.Operator{Catalog: redhatCatalog, IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{{Name: "loki-operator"}}}}
+	lokiDC := &declcfg.DeclarativeConfig{Bundles: []declcfg.Bundle{bundle("loki-operator", "5.9.0")}}
+	newResolver := func(certified v2alpha1.Operator, certifiedDC *declcfg.DeclarativeConfig) (*dependencyResolver, *declcfg.DeclarativeConfig, *declcfg.DeclarativeConfig) {
+		redhatDC, originalCertifiedDC := newRedhatDC(), newCertifiedDC()
+		if certifiedDC == nil {
+			certifiedDC = originalCertifiedDC
+		}
+		return &dependencyResolver{catalogs: []*dependencyCatalog{
+			newDependencyCatalog(lokiOperator, redhatDC, lokiDC),
+			newDependencyCatalog(certified, originalCertifiedDC, certifiedDC),
+		}}, redhatDC, originalCertifiedDC
+	}
+
+	t.Run("Testing resolve - should add the dependencies from the catalogs of the imageset configuration", func(t *testing.T) {
+		certified := v2alpha1.Operator{Catalog: certifiedCatalog, IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{{Name: "elasticsearch-operator"}}}}
+		resolver, _, _ := newResolver(certified, &declcfg.DeclarativeConfig{Bundles: []declcfg.Bundle{bundle("elasticsearch-operator", "6.0.0")}})
+		resolver.resolve()
+		assert.Equal(t, []Dependency{
+			{Catalog: redhatCatalog, Package: "cluster-logging", Bundle: "cluster-logging.v5.9.1", RequiredBy: "loki-operator.v5.9.0", Requirement: "olm.gvk.required logging.openshift.io/v1 ClusterLogging"},
+			{Catalog: certifiedCatalog, Package: "elasticsearch-operator", Bundle: "elasticsearch-operator.v5.8.0", RequiredBy: "loki-operator.v5.9.0", Requirement: "olm.package.required elasticsearch-operator >=5.0.0 <6.0.0"},
+		}, resolver.report.Dependencies)
+		require.Len(t, resolver.report.Unresolved, 1)
+		assert.Equal(t, "only the package and gvk constraints are resolved", resolver.report.Unresolved[0].Reason)
+		assert.Equal(t, map[string][]string{"cluster-logging": {"cluster-logging.v5.9.1"}}, resolver.catalogs[0].added)
+		assert.Equal(t, map[string][]string{"elasticsearch-operator": {"elasticsearch-operator.v5.8.0"}}, resolver.catalogs[1].added)
+	})
+
+	t.Run("Testing resolve - should not add the dependencies satisfied by a full catalog", func(t *testing.T) {
+		resolver, _, _ := newResolver(v2alpha1.Operator{Catalog: certifiedCatalog}, nil)
+		resolver.resolve()
+		require.Len(t, resolver.report.Dependencies, 1)
+		assert.Equal(t, "cluster-logging.v5.9.1", resolver.report.Dependencies[0].Bundle)
+		assert.Empty(t, resolver.catalogs[1].added)
+	})
+
+	t.Run("Testing resolve - should report the dependencies no catalog satisfies", func(t *testing.T) {
+		resolver := &dependencyResolver{catalogs: []*dependencyCatalog{newDependencyCatalog(lokiOperator, newRedhatDC(), lokiDC)}}
+		resolver.resolve()
+		require.Len(t, resolver.report.Unresolved, 2)
+		assert.Equal(t, UnresolvedDependency{
+			Catalog: redhatCatalog, RequiredBy: "loki-operator.v5.9.0", Requirement: "olm.package.required elasticsearch-operator >=5.0.0 <6.0.0",
+			Reason: "no bundle of the catalogs of the imageset configuration satisfies it",
+		}, resolver.report.Unresolved[1])
+	})
+
+	t.Run("Testing resolve - should skip the catalogs with skipDependencies", func(t *testing.T) {
+		op := lokiOperator
+		op.SkipDependencies = true
+		resolver := &dependencyResolver{catalogs: []*dependencyCatalog{newDependencyCatalog(op, newRedhatDC(), lokiDC)}}
+		resolver.resolve()
+		assert.Empty(t, resolver.report)
+	})
+
+	t.Run("Testing addDependencies - should keep the filtered catalog valid", func(t *testing.T) {
+		originalDC := newCertifiedDC()
+		filteredDC := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "elasticsearch-operator", DefaultChannel: "stable-6"}},
+			Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "elasticsearch-operator", Name: "stable-5", Entries: []declcfg.ChannelEntry{{Name: "elasticsearch-operator.v5.8.0"}}}},
+			Bundles:  []declcfg.Bundle{bundle("elasticsearch-operator", "5.8.0")},
+		}
+		// the default channel of the filtered package is fixed by the filter
+		filteredDC.Channels = append(filteredDC.Channels, originalDC.Channels[1])
+		filteredDC.Bundles = append(filteredDC.Bundles, originalDC.Bundles[2])
+		addDependencies(filteredDC, originalDC, map[string][]string{"elasticsearch-operator": {"elasticsearch-operator.v5.7.0"}})
+		m, err := declcfg.ConvertToModel(*filteredDC)
+		require.NoError(t, err)
+		require.NoError(t, m.Validate())
+		assert.Equal(t, originalDC.Channels[0].Entries, filteredDC.Channels[0].Entries)
+		assert.Len(t, filteredDC.Bundles, 3)
+
+		redhatDC := newRedhatDC()
+		filteredDC = &declcfg.DeclarativeConfig{
+			Packages: redhatDC.Packages[:1],
+			Channels: redhatDC.Channels[:1],
+			Bundles:  redhatDC.Bundles[:1],
+		}
+		addDependencies(filteredDC, redhatDC, map[string][]string{"cluster-logging": {"cluster-logging.v5.8.0"}})
+		m, err = declcfg.ConvertToModel(*filteredDC)
+		require.NoError(t, err)
+		require.NoError(t, m.Validate())
+		assert.Equal(t, "stable-5.8", filteredDC.Packages[1].DefaultChannel)
+	})
+}
+
+func TestResolveDependenciesReport(t *testing.T) {
+	t.Run("Testing resolveDependencies - should remove the report of a previous run", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		ex = ex.withConfig(v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					Operators: []v2alpha1.Operator{{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16"}},
+				},
+			},
+		})
+		reportPath := filepath.Join(ex.Opts.Global.WorkingDir, operatorCatalogsDir, dependenciesReportFile)
+		require.NoError(t, os.MkdirAll(filepath.Dir(reportPath), 0755))
+		require.NoError(t, os.WriteFile(reportPath, []byte("previous: run\n"), 0644))
+
+		require.NoError(t, ex.resolveDependencies(context.Background()))
+		assert.NoFileExists(t, reportPath)
+	})
+}
@@ -3,18 +3,18 @@ package operator
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/containers/image/v5/types"
-	"github.com/opencontainers/go-digest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
@@ -28,6 +28,11 @@ import (
 
 type FilterCollector struct {
 	OperatorCollector
+	// dependencies are the bundles to add to the filtered catalogs, by catalog and package,
+	// resolved as dependencies of the selected bundles
+	dependencies map[string]map[string][]string
+	// resolved are the catalogs loaded and filtered while resolving the dependencies, by catalog
+	resolved map[string]resolvedCatalog
 }
 
 // OperatorImageCollector - this looks into the operator index image
@@ -36,468 +41,578 @@ type FilterCollector struct {
 // once unmarshalled, the links to manifests are inspected
 func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.CollectorSchema, error) {
 
-	var (
-		allImages       []v2alpha1.CopyImageSchema
-		label           string
-		catalogImageDir string
-		catalogName     string
-		rebuiltTag      string
-	)
+	var allImages []v2alpha1.CopyImageSchema
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting operator images", o.Opts.MultiArch)
 
 	relatedImages := make(map[string][]v2alpha1.RelatedImage)
 	collectorSchema := v2alpha1.CollectorSchema{}
 	copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
 
+	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+		if err := o.resolveDependencies(ctx); err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+	}
+
 	for _, op := range o.Config.Mirror.Operators {
-		var catalogImage string
-		// download the operator index image
-		o.Log.Debug(collectorPrefix+"copying operator image %s", op.Catalog)
-
-		// prepare spinner
-		p := mpb.New()
-		spinner := p.AddSpinner(
-			1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
-			mpb.BarWidth(3),
-			mpb.PrependDecorators(
-				decor.OnComplete(spinners.EmptyDecorator(), emoji.SpinnerCheckMark),
-				decor.OnAbort(spinners.EmptyDecorator(), emoji.SpinnerCrossMark),
-			),
-			mpb.AppendDecorators(
-				decor.Name("("),
-				decor.Elapsed(decor.ET_STYLE_GO),
-				decor.Name(") Collecting catalog "+op.Catalog+" "),
-			),
-			mpb.BarFillerClearOnComplete(),
-			spinners.BarFillerClearOnAbort(),
-		)
-		// CLID-47 double check that targetCatalog is valid
-		if op.TargetCatalog != "" && !v2alpha1.IsValidPathComponent(op.TargetCatalog) {
-			o.Log.Error(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+		if _, err := o.collectCatalog(ctx, op, &collectorSchema, relatedImages, copyImageSchemaMap); err != nil {
+			return v2alpha1.CollectorSchema{}, err
+		}
+	}
+
+	for _, composite := range o.Config.Mirror.CompositeCatalogs {
+		if err := o.collectCompositeCatalog(ctx, composite, &collectorSchema, relatedImages, copyImageSchemaMap); err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+	}
+
+	o.Log.Debug(collectorPrefix+"related images length %d ", len(relatedImages))
+	var count = 0
+	if o.Opts.Global.LogLevel == "debug" {
+		for _, v := range relatedImages {
+			count = count + len(v)
+		}
+	}
+	o.Log.Debug(collectorPrefix+"images to copy (before duplicates) %d ", count)
+	var err error
+	// check the mode
+	switch {
+	case o.Opts.IsMirrorToDisk():
+		allImages, err = o.prepareM2DCopyBatch(relatedImages)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+	case o.Opts.IsMirrorToMirror():
+		allImages, err = o.dispatchImagesForM2M(relatedImages)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+	case o.Opts.IsDiskToMirror() || o.Opts.Mode == string(mirror.DeleteMode):
+		allImages, err = o.prepareD2MCopyBatch(relatedImages)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+
+	}
+
+	collectorSchema.AllImages = allImages
+	collectorSchema.CopyImageSchemaMap = *copyImageSchemaMap
+
+	return collectorSchema, nil
+}
+
+// collectCatalog downloads (or reuses from the cache) the catalog, filters it according to
+// the ImageSetConfiguration, and adds the catalog and its related images to relatedImages.
+// It returns the filtered declarative config of the catalog, or nil if the catalog was skipped.
+func (o *FilterCollector) collectCatalog(ctx context.Context, op v2alpha1.Operator, collectorSchema *v2alpha1.CollectorSchema, relatedImages map[string][]v2alpha1.RelatedImage, copyImageSchemaMap *v2alpha1.CopyImageSchemaMap) (*declcfg.DeclarativeConfig, error) {
+	var (
+		label           string
+		catalogImageDir string
+		catalogName     string
+		rebuiltTag      string
+	)
+	var catalogImage string
+	// download the operator index image
+	o.Log.Debug(collectorPrefix+"copying operator image %s", op.Catalog)
+
+	// prepare spinner
+	p := mpb.New()
+	spinner := p.AddSpinner(
+		1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
+		mpb.BarWidth(3),
+		mpb.PrependDecorators(
+			decor.OnComplete(spinners.EmptyDecorator(), emoji.SpinnerCheckMark),
+			decor.OnAbort(spinners.EmptyDecorator(), emoji.SpinnerCrossMark),
+		),
+		mpb.AppendDecorators(
+			decor.Name("("),
+			decor.Elapsed(decor.ET_STYLE_GO),
+			decor.Name(") Collecting catalog "+op.Catalog+" "),
+		),
+		mpb.BarFillerClearOnComplete(),
+		spinners.BarFillerClearOnAbort(),
+	)
+	// CLID-47 double check that targetCatalog is valid
+	if op.TargetCatalog != "" && !v2alpha1.IsValidPathComponent(op.TargetCatalog) {
+		o.Log.Error(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, fmt.Errorf(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+	}
+	// CLID-27 ensure we pick up oci:// (on disk) catalogs
+	imgSpec, err := image.ParseRef(op.Catalog)
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
+	//OCPBUGS-36214: For diskToMirror (and delete), access to the source registry is not guaranteed
+	catalogDigest := ""
+	if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
+		d, err := o.catalogDigest(ctx, op)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+			return nil, err
 		}
-		// CLID-27 ensure we pick up oci:// (on disk) catalogs
-		imgSpec, err := image.ParseRef(op.Catalog)
+		catalogDigest = d
+	} else if imgSpec.Transport == dirProtocol {
+		// there is no catalog image yet: the digest is computed from the contents of the directory
+		d, err := o.fbcDirDigest(imgSpec)
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
 		}
-		//OCPBUGS-36214: For diskToMirror (and delete), access to the source registry is not guaranteed
-		catalogDigest := ""
-		if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
-			d, err := o.catalogDigest(ctx, op)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
-			catalogDigest = d
-		} else {
-			sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
-			d, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
-			// OCPBUGS-36548 (manifest unknown)
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				o.Log.Warn(collectorPrefix+"catalog %s : SKIPPING", err.Error())
-				continue
-			}
-			catalogDigest = d
+		catalogDigest = d
+	} else {
+		sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+		if err != nil {
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
 		}
+		d, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+		// OCPBUGS-36548 (manifest unknown)
+		if err != nil {
+			spinner.Abort(true)
+			spinner.Wait()
+			o.Log.Warn(collectorPrefix+"catalog %s : SKIPPING", err.Error())
+			return nil, nil
+		}
+		catalogDigest = d
+	}
 
-		imageIndex := filepath.Join(imgSpec.ComponentName(), catalogDigest)
-		imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imageIndex)
-		configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
-		catalogImageDir = filepath.Join(imageIndexDir, operatorCatalogImageDir)
-		filteredCatalogsDir := filepath.Join(imageIndexDir, operatorCatalogFilteredDir)
+	imageIndex := filepath.Join(imgSpec.ComponentName(), catalogDigest)
+	imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imageIndex)
+	configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
+	catalogImageDir = filepath.Join(imageIndexDir, operatorCatalogImageDir)
+	filteredCatalogsDir := filepath.Join(imageIndexDir, operatorCatalogFilteredDir)
 
-		err = createFolders([]string{configsDir, catalogImageDir, filteredCatalogsDir})
+	err = createFolders([]string{configsDir, catalogImageDir, filteredCatalogsDir})
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
+
+	var filteredDC *declcfg.DeclarativeConfig
+	var isAlreadyFiltered bool
+
+	filterDigest, err := o.filterDigest(op)
+	if err != nil {
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
+	rebuiltTag = filterDigest
+	var srcFilteredCatalog string
+	filterPath := filepath.Join(filteredCatalogsDir, filterDigest, "digest")
+	filteredImageDigest, err := os.ReadFile(filterPath)
+	if err == nil && len(filterDigest) > 0 {
+		srcFilteredCatalog, err = o.cachedCatalog(op, filterDigest)
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
 		}
+		// the dependencies added to a catalog depend on the other catalogs: it is filtered again
+		isAlreadyFiltered = o.isAlreadyFiltered(ctx, srcFilteredCatalog, string(filteredImageDigest)) && len(o.dependencies[dependencyKey(op)]) == 0
+	}
 
-		var filteredDC *declcfg.DeclarativeConfig
-		var isAlreadyFiltered bool
-
-		filterDigest, err := digestOfFilter(op)
+	if isAlreadyFiltered {
+		filterConfigDir := filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
+		filteredDC, err = o.ctlgHandler.getDeclarativeConfig(filterConfigDir)
 		if err != nil {
+			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
+		}
+		if len(op.TargetCatalog) > 0 {
+			catalogName = op.TargetCatalog
+		} else {
+			catalogName = catalogRepositoryName(imgSpec)
 		}
-		rebuiltTag = filterDigest
-		var srcFilteredCatalog string
-		filterPath := filepath.Join(filteredCatalogsDir, filterDigest, "digest")
-		filteredImageDigest, err := os.ReadFile(filterPath)
-		if err == nil && len(filterDigest) > 0 {
-			srcFilteredCatalog, err = o.cachedCatalog(op, filterDigest)
+		if imgSpec.Transport == ociProtocol {
+			// ensure correct oci format and directory lookup
+			sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
-			isAlreadyFiltered = o.isAlreadyFiltered(ctx, srcFilteredCatalog, string(filteredImageDigest))
+			catalogImage = ociProtocol + sourceOCIDir
+		} else {
+			catalogImage = op.Catalog
+		}
+		catalogDigest = string(filteredImageDigest)
+		if collectorSchema.CatalogToFBCMap == nil {
+			collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
 		}
+		result := v2alpha1.CatalogFilterResult{
+			OperatorFilter:     op,
+			FilteredConfigPath: filterConfigDir,
+			ToRebuild:          false,
+		}
+		collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 
-		if isAlreadyFiltered {
-			filterConfigDir := filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
-			filteredDC, err = o.ctlgHandler.getDeclarativeConfig(filterConfigDir)
+	} else {
+		toRebuild := true
+		var originalDC *declcfg.DeclarativeConfig
+		if imgSpec.Transport == dirProtocol {
+			if o.Opts.IsDiskToMirror() || o.Opts.IsDelete() {
+				spinner.Abort(true)
+				spinner.Wait()
+				return nil, fmt.Errorf(collectorPrefix+"catalog %s not found in the cache: it is built during mirrorToDisk", op.Catalog)
+			}
+			originalDC, err = o.prepareFBCDir(ctx, op, imgSpec, catalogImageDir, configsDir)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
 			if len(op.TargetCatalog) > 0 {
 				catalogName = op.TargetCatalog
 			} else {
-				catalogName = path.Base(imgSpec.Reference)
+				catalogName = catalogRepositoryName(imgSpec)
 			}
-			if imgSpec.Transport == ociProtocol {
-				// ensure correct oci format and directory lookup
-				sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
-				if err != nil {
+			catalogImage = op.Catalog
+		} else if imgSpec.Transport == ociProtocol {
+			if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+				// delete the existing directory and untarred cache contents
+				os.RemoveAll(catalogImageDir)
+				os.RemoveAll(configsDir)
+				// copy all contents to the working dir
+				if err := o.copyCatalogImage(ctx, op, imgSpec, catalogImageDir); err != nil {
 					o.Log.Error(errMsg, err.Error())
-					return v2alpha1.CollectorSchema{}, err
+					spinner.Abort(true)
+					spinner.Wait()
+					return nil, err
 				}
-				catalogImage = ociProtocol + sourceOCIDir
-			} else {
-				catalogImage = op.Catalog
-			}
-			catalogDigest = string(filteredImageDigest)
-			if collectorSchema.CatalogToFBCMap == nil {
-				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
 			}
-			result := v2alpha1.CatalogFilterResult{
-				OperatorFilter:     op,
-				FilteredConfigPath: filterConfigDir,
-				ToRebuild:          false,
-			}
-			collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 
-		} else {
-			toRebuild := true
-			if imgSpec.Transport == ociProtocol {
-				if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
-					// delete the existing directory and untarred cache contents
-					os.RemoveAll(catalogImageDir)
-					os.RemoveAll(configsDir)
-					// copy all contents to the working dir
-					err := copy.Copy(imgSpec.PathComponent, catalogImageDir)
-					if err != nil {
-						o.Log.Error(errMsg, err.Error())
-						spinner.Abort(true)
-						spinner.Wait()
-						return v2alpha1.CollectorSchema{}, err
-					}
-				}
-
-				if len(op.TargetCatalog) > 0 {
-					catalogName = op.TargetCatalog
-				} else {
-					catalogName = path.Base(imgSpec.Reference)
-				}
+			if len(op.TargetCatalog) > 0 {
+				catalogName = op.TargetCatalog
 			} else {
-				src := dockerProtocol + op.Catalog
-				dest := ociProtocolTrimmed + catalogImageDir
-
-				optsCopy := o.Opts
-				optsCopy.Stdout = io.Discard
-
-				err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
-
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-				}
+				catalogName = catalogRepositoryName(imgSpec)
 			}
+		} else if err := o.copyCatalogImage(ctx, op, imgSpec, catalogImageDir); err != nil {
+			o.Log.Error(errMsg, err.Error())
+		}
 
-			// it's in oci format so we can go directly to the index.json file
-			oci, err := o.Manifest.GetImageIndex(catalogImageDir)
+		// the declarative config of a file-based catalog directory is already prepared
+		if imgSpec.Transport != dirProtocol {
+			var converted bool
+			label, converted, err = o.extractCatalogConfig(op, imgSpec, catalogImageDir, configsDir)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
-
-			if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
-				err = o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
-				oci, err = o.Manifest.GetImageIndex(catalogImageDir)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
+			if converted {
 				sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
 				if err != nil {
 					o.Log.Error(errMsg, err.Error())
-					return v2alpha1.CollectorSchema{}, err
+					return nil, err
 				}
 				catalogImage = ociProtocol + sourceOCIDir
 			} else {
 				catalogImage = op.Catalog
 			}
 
-			if len(oci.Manifests) == 0 {
-				o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
+			if resolved, ok := o.resolved[dependencyKey(op)]; ok {
+				originalDC = resolved.original
+			} else {
+				originalDC, err = o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+				if err != nil {
+					spinner.Abort(true)
+					spinner.Wait()
+					return nil, err
+				}
 			}
+		}
 
-			validDigest, err := digest.Parse(oci.Manifests[0].Digest)
-			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
-			}
+		// catalogs from a file-based catalog directory always need to be built
+		if !isFullCatalog(op) || imgSpec.Transport == dirProtocol {
 
-			manifest := validDigest.Encoded()
-			o.Log.Debug(collectorPrefix+"manifest %s", manifest)
-			// read the operator image manifest
-			manifestDir := filepath.Join(catalogImageDir, blobsDir, manifest)
-			oci, err = o.Manifest.GetImageManifest(manifestDir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
+			var filteredDigestPath string
+			var filterDigest string
 
-			// we need to check if oci returns multi manifests
-			// (from manifest list) also oci.Config will be nil
-			// we are only interested in the first manifest as all
-			// architecture "configs" will be exactly the same
-			if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
-				subDigest, err := digest.Parse(oci.Manifests[0].Digest)
+			resolved, isResolved := o.resolved[dependencyKey(op)]
+			switch {
+			case isFullCatalog(op):
+				filteredDC = originalDC
+			case isResolved:
+				filteredDC = resolved.filtered
+			default:
+				filteredDC, err = filterCatalog(ctx, *originalDC, op)
 				if err != nil {
-					o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+					return nil, err
+				}
+				platformVersion, err := o.platformVersion(op)
+				if err == nil {
+					err = applySelectionPolicies(filteredDC, op, platformVersion)
 				}
-				manifestDir := filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded())
-				oci, err = o.Manifest.GetImageManifest(manifestDir)
 				if err != nil {
-					o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+					return nil, err
 				}
 			}
-
-			// read the config digest to get the detailed manifest
-			// looking for the lable to search for a specific folder
-			configDigest, err := digest.Parse(oci.Config.Digest)
-			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+			if added := o.dependencies[dependencyKey(op)]; len(added) > 0 {
+				addDependencies(filteredDC, originalDC, added)
 			}
-			catalogDir := filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded())
-			ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
+
+			filterDigest, err = o.filterDigest(op)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
 
-			label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
-			o.Log.Debug(collectorPrefix+"label %s", label)
+			if filterDigest != "" {
+				filteredDigestPath = filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
 
-			// untar all the blobs for the operator
-			// if the layer with "label (from previous step) is found to a specific folder"
-			fromDir := strings.Join([]string{catalogImageDir, blobsDir}, "/")
-			err = o.Manifest.ExtractLayersOCI(fromDir, configsDir, label, oci)
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				err = createFolders([]string{filteredDigestPath})
+				if err != nil {
+					o.Log.Error(errMsg, err.Error())
+					spinner.Abort(true)
+					spinner.Wait()
+					return nil, err
+				}
 			}
 
-			originalDC, err := o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+			err = saveDeclarativeConfig(*filteredDC, filteredDigestPath)
 			if err != nil {
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
 
-			if !isFullCatalog(op) {
+			if collectorSchema.CatalogToFBCMap == nil {
+				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
+			}
+			result := v2alpha1.CatalogFilterResult{
+				OperatorFilter:     op,
+				FilteredConfigPath: filteredDigestPath,
+				ToRebuild:          toRebuild,
+			}
+			collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 
-				var filteredDigestPath string
-				var filterDigest string
+		} else {
+			rebuiltTag = ""
+			toRebuild = false
+			filteredDC = originalDC
+			if collectorSchema.CatalogToFBCMap == nil {
+				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
+			}
+			result := v2alpha1.CatalogFilterResult{
+				OperatorFilter:     op,
+				FilteredConfigPath: "", // this value is not relevant: no rebuilding required
+				ToRebuild:          toRebuild,
+			}
+			collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
+		}
+	}
 
-				filteredDC, err = filterCatalog(ctx, *originalDC, op)
-				if err != nil {
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
+	ri, err := o.ctlgHandler.getRelatedImagesFromCatalog(filteredDC, copyImageSchemaMap)
+	if err != nil {
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
 
-				filterDigest, err = digestOfFilter(op)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
+	//OCPBUGS-45059
+	//TODO remove me when the migration from oc-mirror v1 to v2 ends
+	if imgSpec.Transport == ociProtocol && o.isDeleteOfV1CatalogFromDisk() {
+		addOriginFromOperatorCatalogOnDisk(&ri)
+	}
 
-				if filterDigest != "" {
-					filteredDigestPath = filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
+	maps.Copy(relatedImages, ri)
+
+	var targetTag string
+	var targetCatalog string
+	if len(op.TargetTag) > 0 {
+		targetTag = op.TargetTag
+	} else if imgSpec.Transport == ociProtocol || imgSpec.Transport == dirProtocol {
+		// for this case only, img.ParseRef(in its current state)
+		// will not be able to determine the digest.
+		// this leaves the oci imgSpec with no tag nor digest as it
+		// goes to prepareM2DCopyBatch/prepareD2MCopyBath. This is
+		// why we set the digest read from manifest in targetTag
+		targetTag = "latest"
+	}
 
-					err = createFolders([]string{filteredDigestPath})
-					if err != nil {
-						o.Log.Error(errMsg, err.Error())
-						spinner.Abort(true)
-						spinner.Wait()
-						return v2alpha1.CollectorSchema{}, err
-					}
-				}
+	if len(op.TargetCatalog) > 0 {
+		targetCatalog = op.TargetCatalog
 
-				err = saveDeclarativeConfig(*filteredDC, filteredDigestPath)
-				if err != nil {
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
+	}
 
-				if collectorSchema.CatalogToFBCMap == nil {
-					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
-				}
-				result := v2alpha1.CatalogFilterResult{
-					OperatorFilter:     op,
-					FilteredConfigPath: filteredDigestPath,
-					ToRebuild:          toRebuild,
-				}
-				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
+	componentName := imgSpec.ComponentName() + "." + catalogDigest
+
+	relatedImages[componentName] = []v2alpha1.RelatedImage{
+		{
+			Name:          catalogName,
+			Image:         catalogImage,
+			Type:          v2alpha1.TypeOperatorCatalog,
+			TargetTag:     targetTag,
+			TargetCatalog: targetCatalog,
+			RebuiltTag:    rebuiltTag,
+		},
+	}
+	spinner.Increment()
+	p.Wait()
 
-			} else {
-				rebuiltTag = ""
-				toRebuild = false
-				filteredDC = originalDC
-				if collectorSchema.CatalogToFBCMap == nil {
-					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
-				}
-				result := v2alpha1.CatalogFilterResult{
-					OperatorFilter:     op,
-					FilteredConfigPath: "", // this value is not relevant: no rebuilding required
-					ToRebuild:          toRebuild,
-				}
-				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
+	return filteredDC, nil
+}
+
+// collectCompositeCatalog filters each source catalog of the composite catalog and merges the results
+// in a single declarative config, saved in the working-dir. The composite catalog is then collected
+// as a file-based catalog directory, so that it is built once, on top of the catalog base image.
+// During diskToMirror (and delete), the source catalogs are not accessible: the composite catalog
+// is found in the cache.
+func (o *FilterCollector) collectCompositeCatalog(ctx context.Context, composite v2alpha1.CompositeCatalog, collectorSchema *v2alpha1.CollectorSchema, relatedImages map[string][]v2alpha1.RelatedImage, copyImageSchemaMap *v2alpha1.CopyImageSchemaMap) error {
+	// the composite catalog is referenced relative to the working-dir, which may be moved between runs
+	compositeOp := v2alpha1.Operator{
+		Catalog:                     dirProtocol + filepath.Join(operatorCatalogsDir, compositeCatalogsDir, composite.TargetCatalog),
+		TargetCatalog:               composite.TargetCatalog,
+		TargetTag:                   composite.TargetTag,
+		Full:                        true,
+		CatalogBaseImage:            composite.CatalogBaseImage,
+		TargetCatalogSourceTemplate: composite.TargetCatalogSourceTemplate,
+	}
+
+	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+		compositeDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, compositeCatalogsDir, composite.TargetCatalog)
+		catalogs := []string{}
+		dcs := []declcfg.DeclarativeConfig{}
+		for _, src := range composite.Catalogs {
+			// the source catalogs are not mirrored: only the merged catalog and its related images are
+			srcCopyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
+			dc, err := o.collectCatalog(ctx, src, &v2alpha1.CollectorSchema{}, make(map[string][]v2alpha1.RelatedImage), srcCopyImageSchemaMap)
+			if err != nil {
+				return err
 			}
+			if dc == nil {
+				return fmt.Errorf("composite catalog %s: unable to collect catalog %s", composite.TargetCatalog, src.Catalog)
+			}
+			catalogs = append(catalogs, src.Catalog)
+			dcs = append(dcs, *dc)
 		}
 
-		ri, err := o.ctlgHandler.getRelatedImagesFromCatalog(filteredDC, copyImageSchemaMap)
+		mergedDC, err := mergeDeclarativeConfigs(catalogs, dcs)
 		if err != nil {
-			spinner.Abort(true)
-			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return fmt.Errorf("composite catalog %s: %v", composite.TargetCatalog, err)
 		}
-
-		//OCPBUGS-45059
-		//TODO remove me when the migration from oc-mirror v1 to v2 ends
-		if imgSpec.Transport == ociProtocol && o.isDeleteOfV1CatalogFromDisk() {
-			addOriginFromOperatorCatalogOnDisk(&ri)
+		// packages removed from the source catalogs should not remain from previous runs
+		if err := os.RemoveAll(compositeDir); err != nil {
+			return err
 		}
-
-		maps.Copy(relatedImages, ri)
-
-		var targetTag string
-		var targetCatalog string
-		if len(op.TargetTag) > 0 {
-			targetTag = op.TargetTag
-		} else if imgSpec.Transport == ociProtocol {
-			// for this case only, img.ParseRef(in its current state)
-			// will not be able to determine the digest.
-			// this leaves the oci imgSpec with no tag nor digest as it
-			// goes to prepareM2DCopyBatch/prepareD2MCopyBath. This is
-			// why we set the digest read from manifest in targetTag
-			targetTag = "latest"
+		if err := createFolders([]string{compositeDir}); err != nil {
+			return err
 		}
-
-		if len(op.TargetCatalog) > 0 {
-			targetCatalog = op.TargetCatalog
-
+		if err := saveDeclarativeConfig(*mergedDC, compositeDir); err != nil {
+			return err
 		}
+	}
 
-		componentName := imgSpec.ComponentName() + "." + catalogDigest
+	_, err := o.collectCatalog(ctx, compositeOp, collectorSchema, relatedImages, copyImageSchemaMap)
+	return err
+}
 
-		relatedImages[componentName] = []v2alpha1.RelatedImage{
-			{
-				Name:          catalogName,
-				Image:         catalogImage,
-				Type:          v2alpha1.TypeOperatorCatalog,
-				TargetTag:     targetTag,
-				TargetCatalog: targetCatalog,
-				RebuiltTag:    rebuiltTag,
-			},
+// prepareFBCDir prepares the working-dir for a catalog coming from a file-based catalog
+// directory, or file: the opm base image is copied to catalogImageDir, so that the catalog
+// image can be built on top of it, and the declarative config is copied to configsDir.
+func (o *FilterCollector) prepareFBCDir(ctx context.Context, op v2alpha1.Operator, imgSpec image.ImageSpec, catalogImageDir, configsDir string) (*declcfg.DeclarativeConfig, error) {
+	if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+		baseImgSpec, err := image.ParseRef(op.GetCatalogBaseImage())
+		if err != nil {
+			return nil, err
+		}
+		o.Log.Debug(collectorPrefix+"copying catalog base image %s", baseImgSpec.Reference)
+		optsCopy := o.Opts
+		optsCopy.Stdout = io.Discard
+		if err := o.Mirror.Run(ctx, baseImgSpec.ReferenceWithTransport, ociProtocolTrimmed+catalogImageDir, "copy", &optsCopy); err != nil {
+			return nil, fmt.Errorf("unable to copy catalog base image %s: %v", baseImgSpec.Reference, err)
 		}
-		spinner.Increment()
-		p.Wait()
 	}
 
-	o.Log.Debug(collectorPrefix+"related images length %d ", len(relatedImages))
-	var count = 0
-	if o.Opts.Global.LogLevel == "debug" {
-		for _, v := range relatedImages {
-			count = count + len(v)
-		}
+	// the declarative config is always copied again, as the directory is not versioned
+	if err := os.RemoveAll(configsDir); err != nil {
+		return nil, err
 	}
-	o.Log.Debug(collectorPrefix+"images to copy (before duplicates) %d ", count)
-	var err error
-	// check the mode
-	switch {
-	case o.Opts.IsMirrorToDisk():
-		allImages, err = o.prepareM2DCopyBatch(relatedImages)
+	fbcPath := o.fbcDir(imgSpec)
+	isFile, err := isFBCFile(fbcPath)
+	if err != nil {
+		return nil, err
+	}
+	target := configsDir
+	if isFile {
+		target = filepath.Join(configsDir, filepath.Base(fbcPath))
+	}
+	if err := copy.Copy(fbcPath, target); err != nil {
+		return nil, err
+	}
+	return o.ctlgHandler.getDeclarativeConfig(configsDir)
+}
+
+// fbcDirDigest computes the digest of a file-based catalog directory, or file, from its contents.
+// The digest is saved in the working-dir, so that it can be found during diskToMirror,
+// when the directory is not available.
+func (o *FilterCollector) fbcDirDigest(imgSpec image.ImageSpec) (string, error) {
+	hasher := sha256.New()
+	fbcDir := o.fbcDir(imgSpec)
+	err := filepath.WalkDir(fbcDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
+			return err
 		}
-	case o.Opts.IsMirrorToMirror():
-		allImages, err = o.dispatchImagesForM2M(relatedImages)
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(fbcDir, path)
 		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
+			return err
 		}
-	case o.Opts.IsDiskToMirror() || o.Opts.Mode == string(mirror.DeleteMode):
-		allImages, err = o.prepareD2MCopyBatch(relatedImages)
+		f, err := os.Open(path)
 		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
+			return err
 		}
-
+		defer f.Close()
+		hasher.Write([]byte(rel))
+		_, err = io.Copy(hasher, f)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to read file-based catalog %s: %v", fbcDir, err)
 	}
+	fbcDigest := fmt.Sprintf("%x", hasher.Sum(nil))
 
-	collectorSchema.AllImages = allImages
-	collectorSchema.CopyImageSchemaMap = *copyImageSchemaMap
-
-	return collectorSchema, nil
+	digestFile, err := o.fbcDigestFile(imgSpec)
+	if err != nil {
+		return "", err
+	}
+	if err := createFolders([]string{filepath.Dir(digestFile)}); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(digestFile, []byte(fbcDigest), 0644); err != nil {
+		return "", err
+	}
+	return fbcDigest, nil
 }
 
 func isFullCatalog(catalog v2alpha1.Operator) bool {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/otiai10/copy"
@@ -591,7 +592,7 @@ func setupFilterCollector_DiskToMirror(tempDir string, log clog.PluggableLoggerI
 	}
 
 	ex := &FilterCollector{
-		OperatorCollector{Log: log,
+		OperatorCollector: OperatorCollector{Log: log,
 			Mirror:           &MockMirror{Fail: false},
 			Config:           nominalConfigD2M,
 			Manifest:         manifest,
@@ -630,7 +631,7 @@ func setupFilterCollector_MirrorToDisk(tempDir string, log clog.PluggableLoggerI
 	}
 
 	ex := &FilterCollector{
-		OperatorCollector{Log: log,
+		OperatorCollector: OperatorCollector{Log: log,
 			Mirror:           &MockMirror{Fail: false},
 			Config:           nominalConfigM2D,
 			Manifest:         manifest,
@@ -646,3 +647,300 @@ func (ex *FilterCollector) withConfig(cfg v2alpha1.ImageSetConfiguration) *Filte
 	ex.Config = cfg
 	return ex
 }
+
+func TestFilterCollectorFBCDir(t *testing.T) {
+	log := clog.New("trace")
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	fbcDir := filepath.Join(tempDir, "internal-operators")
+	err := os.MkdirAll(filepath.Join(fbcDir, "op1"), 0755)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(fbcDir, "op1", "catalog.yaml"), []byte("schema: olm.package\nname: op1\n"), 0644)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+
+	fbcConfig := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Operators: []v2alpha1.Operator{
+					{
+						Catalog: "dir://" + fbcDir,
+						IncludeConfig: v2alpha1.IncludeConfig{
+							Packages: []v2alpha1.IncludePackage{
+								{Name: "op1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	filterDigest, err := digestOfFilter(fbcConfig.Mirror.Operators[0])
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	relatedImages := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://sometestimage-a@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Destination: "docker://localhost:9999/sometestimage-a:sha256-f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Origin:      "docker://sometestimage-a@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Type:        v2alpha1.TypeInvalid,
+		},
+		{
+			Source:      "docker://sometestimage-b@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Destination: "docker://localhost:9999/sometestimage-b:sha256-f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Origin:      "docker://sometestimage-b@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea",
+			Type:        v2alpha1.TypeInvalid,
+		},
+		{
+			Source:      "docker://gcr.io/kubebuilder/kube-rbac-proxy@sha256:d4883d7c622683b3319b5e6b3a7edfbf2594c18060131a8bf64504805f875522",
+			Destination: "docker://localhost:9999/kubebuilder/kube-rbac-proxy:v0.13.1",
+			Origin:      "docker://gcr.io/kubebuilder/kube-rbac-proxy:v0.13.1@sha256:d4883d7c622683b3319b5e6b3a7edfbf2594c18060131a8bf64504805f875522",
+			Type:        v2alpha1.TypeInvalid,
+		},
+	}
+
+	t.Run("Testing OperatorImageCollector - FBC directory - Mirror to disk: should pass", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+		ex = ex.withConfig(fbcConfig)
+		res, err := ex.OperatorImageCollector(ctx)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		expected := append([]v2alpha1.CopyImageSchema{}, relatedImages...)
+		expected = append(expected, v2alpha1.CopyImageSchema{
+			Source:      "docker://localhost:9999/internal-operators:" + filterDigest,
+			Destination: "docker://localhost:9999/internal-operators:latest",
+			Origin:      "dir://" + fbcDir,
+			Type:        v2alpha1.TypeOperatorCatalog,
+			RebuiltTag:  filterDigest,
+		})
+		assert.ElementsMatch(t, expected, res.AllImages)
+
+		filterResult, ok := res.CatalogToFBCMap["dir://"+fbcDir]
+		assert.True(t, ok)
+		assert.True(t, filterResult.ToRebuild)
+
+		// the declarative config of the directory is copied to the working-dir
+		fbcSpec, err := image.ParseRef("dir://" + fbcDir)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		digestFile, err := ex.fbcDigestFile(fbcSpec)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		fbcDigest, err := os.ReadFile(digestFile)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		_, err = os.Stat(filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "internal-operators", string(fbcDigest), operatorCatalogConfigDir, "op1", "catalog.yaml"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Testing OperatorImageCollector - FBC directory - Mirror to mirror: should pass", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+		ex = ex.withConfig(fbcConfig)
+		ex.Opts.Mode = mirror.MirrorToMirror
+		ex.Opts.Destination = "docker://localhost:5000/test"
+		res, err := ex.OperatorImageCollector(ctx)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		catalogCopies := []v2alpha1.CopyImageSchema{}
+		for _, img := range res.AllImages {
+			if img.Type == v2alpha1.TypeOperatorCatalog {
+				catalogCopies = append(catalogCopies, img)
+			}
+		}
+		expected := []v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://localhost:9999/internal-operators:" + filterDigest,
+				Destination: "docker://localhost:9999/internal-operators:latest",
+				Origin:      "dir://" + fbcDir,
+				Type:        v2alpha1.TypeOperatorCatalog,
+				RebuiltTag:  filterDigest,
+			},
+			{
+				Source:      "docker://localhost:9999/internal-operators:" + filterDigest,
+				Destination: "docker://localhost:5000/test/internal-operators:latest",
+				Origin:      "dir://" + fbcDir,
+				Type:        v2alpha1.TypeOperatorCatalog,
+				RebuiltTag:  filterDigest,
+			},
+		}
+		assert.ElementsMatch(t, expected, catalogCopies)
+	})
+
+	t.Run("Testing OperatorImageCollector - FBC directory - Disk to mirror: should pass", func(t *testing.T) {
+		ex := setupFilterCollector_DiskToMirror(tempDir, log)
+		ex = ex.withConfig(fbcConfig)
+		// the rebuilt catalog is found in the cache, with the digest saved during mirrorToDisk
+		fbcSpec, err := image.ParseRef("dir://" + fbcDir)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		digestFile, err := ex.fbcDigestFile(fbcSpec)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		fbcDigest, err := os.ReadFile(digestFile)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		filteredDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "internal-operators", string(fbcDigest), operatorCatalogFilteredDir, filterDigest)
+		err = os.WriteFile(filepath.Join(filteredDir, "digest"), []byte("f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"), 0644)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+
+		res, err := ex.OperatorImageCollector(ctx)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Contains(t, res.AllImages, v2alpha1.CopyImageSchema{
+			Source:      "docker://localhost:9999/internal-operators:" + filterDigest,
+			Destination: "docker://localhost:5000/test/internal-operators:latest",
+			Origin:      "dir://" + fbcDir,
+			Type:        v2alpha1.TypeOperatorCatalog,
+			RebuiltTag:  filterDigest,
+		})
+	})
+
+	t.Run("Testing OperatorImageCollector - FBC file - Mirror to disk: should pass", func(t *testing.T) {
+		fbcFile := filepath.Join(t.TempDir(), "file-operators.yaml")
+		err := os.WriteFile(fbcFile, []byte("schema: olm.package\nname: op1\n"), 0644)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		fileConfig := fbcConfig
+		fileConfig.Mirror.Operators = []v2alpha1.Operator{fbcConfig.Mirror.Operators[0]}
+		fileConfig.Mirror.Operators[0].Catalog = "dir://" + fbcFile
+		fileFilterDigest, err := digestOfFilter(fileConfig.Mirror.Operators[0])
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+		ex = ex.withConfig(fileConfig)
+		res, err := ex.OperatorImageCollector(ctx)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Contains(t, res.AllImages, v2alpha1.CopyImageSchema{
+			Source:      "docker://localhost:9999/file-operators:" + fileFilterDigest,
+			Destination: "docker://localhost:9999/file-operators:latest",
+			Origin:      "dir://" + fbcFile,
+			Type:        v2alpha1.TypeOperatorCatalog,
+			RebuiltTag:  fileFilterDigest,
+		})
+	})
+
+	t.Run("Testing OperatorImageCollector - FBC directory - Disk to mirror without mirror to disk: should fail", func(t *testing.T) {
+		ex := setupFilterCollector_DiskToMirror(t.TempDir(), log)
+		ex = ex.withConfig(fbcConfig)
+		_, err := ex.OperatorImageCollector(ctx)
+		assert.ErrorContains(t, err, "unable to find the digest of catalog")
+	})
+}
+
+func TestFilterCollectorCompositeCatalog(t *testing.T) {
+	log := clog.New("trace")
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	fbcDir := filepath.Join(tempDir, "internal-operators")
+	err := os.MkdirAll(filepath.Join(fbcDir, "op1"), 0755)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(fbcDir, "op1", "catalog.yaml"), []byte("schema: olm.package\nname: op1\n"), 0644)
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+
+	compositeConfig := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				CompositeCatalogs: []v2alpha1.CompositeCatalog{
+					{
+						TargetCatalog: "approved/operator-index",
+						Catalogs: []v2alpha1.Operator{
+							{
+								Catalog: "dir://" + fbcDir,
+								IncludeConfig: v2alpha1.IncludeConfig{
+									Packages: []v2alpha1.IncludePackage{
+										{Name: "op1"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	compositeDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, compositeCatalogsDir, "approved", "operator-index")
+	// the composite catalog is referenced relative to the working-dir
+	compositeRef := "dir://" + filepath.Join(operatorCatalogsDir, compositeCatalogsDir, "approved", "operator-index")
+	filterDigest, err := digestOfFilter(v2alpha1.Operator{Catalog: compositeRef, TargetCatalog: "approved/operator-index", Full: true})
+	if err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+
+	t.Run("Testing OperatorImageCollector - composite catalog - Mirror to disk: should pass", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+		ex = ex.withConfig(compositeConfig)
+		res, err := ex.OperatorImageCollector(ctx)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		catalogCopies := []v2alpha1.CopyImageSchema{}
+		for _, img := range res.AllImages {
+			if img.Type == v2alpha1.TypeOperatorCatalog {
+				catalogCopies = append(catalogCopies, img)
+			}
+		}
+		// only the composite catalog is mirrored, not its source catalogs
+		expected := []v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://localhost:9999/approved/operator-index:" + filterDigest,
+				Destination: "docker://localhost:9999/approved/operator-index:latest",
+				Origin:      compositeRef,
+				Type:        v2alpha1.TypeOperatorCatalog,
+				RebuiltTag:  filterDigest,
+			},
+		}
+		assert.ElementsMatch(t, expected, catalogCopies)
+
+		filterResult, ok := res.CatalogToFBCMap[compositeRef]
+		assert.True(t, ok)
+		assert.True(t, filterResult.ToRebuild)
+		assert.Len(t, res.CatalogToFBCMap, 1)
+
+		// the merged declarative config is saved in the working-dir
+		_, err = os.Stat(filepath.Join(compositeDir, "op1", "catalog.json"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Testing OperatorImageCollector - composite catalog with duplicate packages: should fail", func(t *testing.T) {
+		cfg := compositeConfig
+		cfg.Mirror.CompositeCatalogs = []v2alpha1.CompositeCatalog{
+			{
+				TargetCatalog: "approved/operator-index",
+				Catalogs: []v2alpha1.Operator{
+					compositeConfig.Mirror.CompositeCatalogs[0].Catalogs[0],
+					{Catalog: "dir://" + fbcDir, TargetCatalog: "internal"},
+				},
+			},
+		}
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+		ex = ex.withConfig(cfg)
+		_, err := ex.OperatorImageCollector(ctx)
+		assert.ErrorContains(t, err, "packages found in more than one catalog: op1")
+	})
+}
@@ -4,18 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"maps"
 	"os"
-	"path"
 	"path/filepath"
-	"strings"
 
-	"github.com/opencontainers/go-digest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
-	"github.com/otiai10/copy"
 )
 
 type LocalStorageCollector struct {
@@ -88,8 +83,7 @@ func (o *LocalStorageCollector) OperatorImageCollector(ctx context.Context) (v2a
 				os.RemoveAll(dir)
 				os.RemoveAll(cacheDir)
 				// copy all contents to the working dir
-				err := copy.Copy(imgSpec.PathComponent, dir)
-				if err != nil {
+				if err := o.copyCatalogImage(ctx, op, imgSpec, dir); err != nil {
 					o.Log.Error(errMsg, err.Error())
 					return v2alpha1.CollectorSchema{}, err
 				}
@@ -98,7 +92,7 @@ func (o *LocalStorageCollector) OperatorImageCollector(ctx context.Context) (v2a
 			if len(op.TargetCatalog) > 0 {
 				catalogName = op.TargetCatalog
 			} else {
-				catalogName = path.Base(imgSpec.Reference)
+				catalogName = catalogRepositoryName(imgSpec)
 			}
 		} else {
 			if _, err := os.Stat(cacheDir); errors.Is(err, os.ErrNotExist) {
@@ -107,110 +101,25 @@ func (o *LocalStorageCollector) OperatorImageCollector(ctx context.Context) (v2a
 					o.Log.Error(errMsg, err.Error())
 					return v2alpha1.CollectorSchema{}, err
 				}
-				src := dockerProtocol + op.Catalog
-				dest := ociProtocolTrimmed + dir
-
-				optsCopy := o.Opts
-				optsCopy.Stdout = io.Discard
-
-				err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
-
-				if err != nil {
+				if err := o.copyCatalogImage(ctx, op, imgSpec, dir); err != nil {
 					o.Log.Error(errMsg, err.Error())
 				}
 			}
 		}
 
-		// it's in oci format so we can go directly to the index.json file
-		oci, err := o.Manifest.GetImageIndex(dir)
+		var converted bool
+		label, converted, err = o.extractCatalogConfig(op, imgSpec, dir, cacheDir)
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
 			return v2alpha1.CollectorSchema{}, err
 		}
-
 		var catalogImage string
-		if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
-			err = o.Manifest.ConvertIndexToSingleManifest(dir, oci)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				return v2alpha1.CollectorSchema{}, err
-			}
-
-			oci, err = o.Manifest.GetImageIndex(dir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				return v2alpha1.CollectorSchema{}, err
-			}
-
+		if converted {
 			catalogImage = ociProtocol + dir
 		} else {
 			catalogImage = op.Catalog
 		}
 
-		if len(oci.Manifests) == 0 {
-			o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
-			return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
-		}
-
-		validDigest, err := digest.Parse(oci.Manifests[0].Digest)
-		if err != nil {
-			o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-			return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-		}
-
-		manifest := validDigest.Encoded()
-		o.Log.Debug(collectorPrefix+"manifest %s", manifest)
-		// read the operator image manifest
-		manifestDir := filepath.Join(dir, blobsDir, manifest)
-		oci, err = o.Manifest.GetImageManifest(manifestDir)
-		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
-		}
-
-		// we need to check if oci returns multi manifests
-		// (from manifest list) also oci.Config will be nil
-		// we are only interested in the first manifest as all
-		// architecture "configs" will be exactly the same
-		if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
-			subDigest, err := digest.Parse(oci.Manifests[0].Digest)
-			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-			}
-			manifestDir := filepath.Join(dir, blobsDir, subDigest.Encoded())
-			oci, err = o.Manifest.GetImageManifest(manifestDir)
-			if err != nil {
-				o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
-			}
-		}
-
-		// read the config digest to get the detailed manifest
-		// looking for the lable to search for a specific folder
-		configDigest, err := digest.Parse(oci.Config.Digest)
-		if err != nil {
-			o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-			return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-		}
-		catalogDir := filepath.Join(dir, blobsDir, configDigest.Encoded())
-		ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
-		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
-		}
-
-		label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
-		o.Log.Debug(collectorPrefix+"label %s", label)
-
-		// untar all the blobs for the operator
-		// if the layer with "label (from previous step) is found to a specific folder"
-		fromDir := strings.Join([]string{dir, blobsDir}, "/")
-		err = o.Manifest.ExtractLayersOCI(fromDir, cacheDir, label, oci)
-		if err != nil {
-			return v2alpha1.CollectorSchema{}, err
-		}
-
 		operatorCatalog, err := o.ctlgHandler.getCatalog(filepath.Join(cacheDir, label))
 		if err != nil {
 			return v2alpha1.CollectorSchema{}, err
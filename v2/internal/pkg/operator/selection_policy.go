@@ -0,0 +1,323 @@
+package operator
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+const (
+	propertyMaxOpenShiftVersion = "olm.maxOpenShiftVersion"
+	propertyDeprecated          = "olm.deprecated"
+	annotationProperties        = "olm.properties"
+	// anyVersionRange selects all the bundles of a channel, pre-releases included
+	anyVersionRange = ">=0.0.0-0"
+)
+
+// channelPolicy is the selection policy of a channel, merged with the one of its package
+type channelPolicy struct {
+	v2alpha1.SelectionPolicy
+	// headsOnly is true when the channel is filtered neither by version nor with full:
+	// the policies then select a single bundle
+	headsOnly bool
+}
+
+// selectionPolicy returns the selection policy of a channel of a package of op
+func selectionPolicy(op v2alpha1.Operator, pkg v2alpha1.IncludePackage, channel string) channelPolicy {
+	policy := channelPolicy{SelectionPolicy: pkg.SelectionPolicy}
+	versioned := pkg.MinVersion != "" || pkg.MaxVersion != ""
+	for _, ch := range pkg.Channels {
+		if ch.Name == channel {
+			policy.SelectionPolicy = policy.Merge(ch.SelectionPolicy)
+			versioned = versioned || ch.MinVersion != "" || ch.MaxVersion != ""
+		}
+	}
+	policy.headsOnly = !op.Full && !versioned
+	return policy
+}
+
+// usesPlatformCompatibility returns true when a package or channel of op selects its bundles
+// by compatibility with the platform
+func usesPlatformCompatibility(op v2alpha1.Operator) bool {
+	for _, pkg := range op.Packages {
+		if pkg.CompatibleWithPlatform || slices.ContainsFunc(pkg.Channels, func(ch v2alpha1.IncludeChannel) bool { return ch.CompatibleWithPlatform }) {
+			return true
+		}
+	}
+	return false
+}
+
+// platformMinor returns the highest OpenShift minor of the platform channels, found in their
+// maxVersion, their name (stable-4.16) or their minVersion
+func platformMinor(platform v2alpha1.Platform) (semver.Version, error) {
+	var highest semver.Version
+	found := false
+	for _, ch := range platform.Channels {
+		for _, candidate := range []string{ch.MaxVersion, ch.Name[strings.LastIndex(ch.Name, "-")+1:], ch.MinVersion} {
+			v, err := semver.ParseTolerant(candidate)
+			if candidate == "" || err != nil {
+				continue
+			}
+			if minor := (semver.Version{Major: v.Major, Minor: v.Minor}); !found || minor.GT(highest) {
+				highest = minor
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return semver.Version{}, fmt.Errorf("compatibleWithPlatform: unable to find the OpenShift version of the platform channels")
+	}
+	return highest, nil
+}
+
+// platformVersion returns the OpenShift minor the bundles of op must be compatible with,
+// or an empty string when no package or channel of op requires it
+func (o OperatorCollector) platformVersion(op v2alpha1.Operator) (string, error) {
+	if !usesPlatformCompatibility(op) {
+		return "", nil
+	}
+	minor, err := platformMinor(o.Config.Mirror.Platform)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d", minor.Major, minor.Minor), nil
+}
+
+// filterDigest returns the digest of the filter of op, which depends on the platform
+// when its bundles must be compatible with it
+func (o OperatorCollector) filterDigest(op v2alpha1.Operator) (string, error) {
+	filterDigest, err := digestOfFilter(op)
+	if err != nil {
+		return "", err
+	}
+	platformVersion, err := o.platformVersion(op)
+	if err != nil || platformVersion == "" {
+		return filterDigest, err
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(filterDigest+"|"+platformVersion)))[0:32], nil
+}
+
+// applySelectionPolicies narrows the bundles of the channels of a filtered declarative config
+// according to the selection policies of op, then rewires the upgrade edges of these channels so
+// that each keeps a single head with all its bundles reachable.
+// Empty channels are removed, except the default channel of a package which is an error.
+func applySelectionPolicies(dc *declcfg.DeclarativeConfig, op v2alpha1.Operator, platformVersion string) error {
+	var platform semver.Version
+	if platformVersion != "" {
+		v, err := semver.ParseTolerant(platformVersion)
+		if err != nil {
+			return err
+		}
+		platform = v
+	}
+	pkgs := map[string]v2alpha1.IncludePackage{}
+	for _, pkg := range op.Packages {
+		pkgs[pkg.Name] = pkg
+	}
+	defaultChannels := map[string]string{}
+	for _, pkg := range dc.Packages {
+		defaultChannels[pkg.Name] = pkg.DefaultChannel
+	}
+	bundles := map[string]map[string]declcfg.Bundle{}
+	for _, b := range dc.Bundles {
+		if _, ok := bundles[b.Package]; !ok {
+			bundles[b.Package] = map[string]declcfg.Bundle{}
+		}
+		bundles[b.Package][b.Name] = b
+	}
+	deprecated := deprecatedBundles(dc)
+
+	narrowed := map[string]bool{}
+	channels := []declcfg.Channel{}
+	for _, ch := range dc.Channels {
+		pkg, ok := pkgs[ch.Package]
+		policy := selectionPolicy(op, pkg, ch.Name)
+		if !ok || !policy.IsSet() {
+			channels = append(channels, ch)
+			continue
+		}
+		narrowed[ch.Package] = true
+
+		selected := []BundleInfo{}
+		for _, entry := range ch.Entries {
+			b := bundles[ch.Package][entry.Name]
+			switch {
+			case policy.SkipDeprecated && deprecated[ch.Package+"/"+entry.Name]:
+				continue
+			case policy.CompatibleWithPlatform && !isCompatibleWithPlatform(b, platform):
+				continue
+			}
+			selected = append(selected, BundleInfo{Name: entry.Name, Version: bundleVersion(b)})
+		}
+		sortBundles(selected)
+		latest := policy.Latest
+		if latest == 0 && policy.headsOnly {
+			latest = 1
+		}
+		if latest > 0 && len(selected) > latest {
+			selected = selected[len(selected)-latest:]
+		}
+
+		if len(selected) == 0 {
+			if ch.Name == defaultChannels[ch.Package] {
+				return fmt.Errorf("package %q channel %q: no bundle matches the selection policies of the default channel", ch.Package, ch.Name)
+			}
+			continue
+		}
+		ch.Entries = rewireChannel(ch.Entries, selected)
+		channels = append(channels, ch)
+	}
+	dc.Channels = channels
+
+	// bundles of the narrowed packages are only kept when still in a channel
+	inChannel := map[string]bool{}
+	for _, ch := range dc.Channels {
+		for _, entry := range ch.Entries {
+			inChannel[ch.Package+"/"+entry.Name] = true
+		}
+	}
+	dc.Bundles = slices.DeleteFunc(dc.Bundles, func(b declcfg.Bundle) bool {
+		return narrowed[b.Package] && !inChannel[b.Package+"/"+b.Name]
+	})
+	for i := range dc.Deprecations {
+		dc.Deprecations[i].Entries = slices.DeleteFunc(dc.Deprecations[i].Entries, func(e declcfg.DeprecationEntry) bool {
+			return narrowed[dc.Deprecations[i].Package] && e.Reference.Schema == declcfg.SchemaBundle && !inChannel[dc.Deprecations[i].Package+"/"+e.Reference.Name]
+		})
+	}
+	return nil
+}
+
+// rewireChannel keeps the selected entries of a channel, ordered by version.
+// An entry replacing a dropped one replaces its nearest selected ancestor instead.
+// The newest head skips the other heads and the entries no longer reachable from it,
+// as OLM requires a single head and no dangling entry.
+func rewireChannel(entries []declcfg.ChannelEntry, selected []BundleInfo) []declcfg.ChannelEntry {
+	byName := map[string]declcfg.ChannelEntry{}
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+	keep := map[string]bool{}
+	for _, b := range selected {
+		keep[b.Name] = true
+	}
+
+	kept := make([]declcfg.ChannelEntry, 0, len(selected))
+	for _, b := range selected {
+		entry := byName[b.Name]
+		entry.Skips = slices.Clone(entry.Skips)
+		seen := map[string]bool{}
+		for entry.Replaces != "" && !keep[entry.Replaces] && !seen[entry.Replaces] {
+			seen[entry.Replaces] = true
+			ancestor, ok := byName[entry.Replaces]
+			if !ok {
+				// replacing a bundle outside of the channel is valid
+				break
+			}
+			entry.Replaces = ancestor.Replaces
+		}
+		kept = append(kept, entry)
+	}
+
+	referenced := map[string]bool{}
+	for _, entry := range kept {
+		referenced[entry.Replaces] = true
+		for _, skip := range entry.Skips {
+			referenced[skip] = true
+		}
+	}
+	// kept is ordered by version: the last head is the newest
+	head := -1
+	for i, entry := range kept {
+		if !referenced[entry.Name] {
+			head = i
+		}
+	}
+	if head < 0 {
+		// the replaces chain of the channel is a cycle, which the catalog validation reports
+		return kept
+	}
+
+	keptByName := map[string]declcfg.ChannelEntry{}
+	for _, entry := range kept {
+		keptByName[entry.Name] = entry
+	}
+	// OLM follows the replaces chain from the head, each entry of the chain reaching its skips
+	reachable := map[string]bool{}
+	visited := map[string]bool{}
+	for name := kept[head].Name; name != "" && !visited[name]; {
+		entry, ok := keptByName[name]
+		if !ok {
+			break
+		}
+		visited[name] = true
+		reachable[name] = true
+		for _, skip := range entry.Skips {
+			reachable[skip] = true
+		}
+		name = entry.Replaces
+	}
+	for _, entry := range kept {
+		if !reachable[entry.Name] {
+			kept[head].Skips = append(kept[head].Skips, entry.Name)
+		}
+	}
+	return kept
+}
+
+// deprecatedBundles returns the bundles marked deprecated, by package/name
+func deprecatedBundles(dc *declcfg.DeclarativeConfig) map[string]bool {
+	deprecated := map[string]bool{}
+	for _, d := range dc.Deprecations {
+		for _, entry := range d.Entries {
+			if entry.Reference.Schema == declcfg.SchemaBundle {
+				deprecated[d.Package+"/"+entry.Reference.Name] = true
+			}
+		}
+	}
+	for _, b := range dc.Bundles {
+		if slices.ContainsFunc(b.Properties, func(p property.Property) bool { return p.Type == propertyDeprecated }) {
+			deprecated[b.Package+"/"+b.Name] = true
+		}
+	}
+	return deprecated
+}
+
+// isCompatibleWithPlatform returns false when the olm.maxOpenShiftVersion of a bundle, as a property
+// or in the olm.properties annotation of its CSV, is lower than the platform minor
+func isCompatibleWithPlatform(b declcfg.Bundle, platform semver.Version) bool {
+	props := slices.Clone(b.Properties)
+	for _, prop := range b.Properties {
+		if prop.Type != property.TypeCSVMetadata {
+			continue
+		}
+		var csv property.CSVMetadata
+		if err := json.Unmarshal(prop.Value, &csv); err != nil {
+			continue
+		}
+		var annotated []property.Property
+		if err := json.Unmarshal([]byte(csv.Annotations[annotationProperties]), &annotated); err == nil {
+			props = append(props, annotated...)
+		}
+	}
+	for _, prop := range props {
+		if prop.Type != propertyMaxOpenShiftVersion {
+			continue
+		}
+		// the value is either a string or a number
+		maxVersion, err := semver.ParseTolerant(strings.Trim(string(prop.Value), `"`))
+		if err != nil {
+			continue
+		}
+		return maxVersion.Major > platform.Major || maxVersion.Major == platform.Major && maxVersion.Minor >= platform.Minor
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func TestSelectionPolicies(t *testing.T) {
+	const pkgName = "aws-load-balancer-operator"
+	bundle := func(version string, props ...property.Property) declcfg.Bundle {
+		return declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Package:    pkgName,
+			Name:       "albo.v" + version,
+			Image:      "registry.redhat.io/albo/bundle:v" + version,
+			Properties: append([]property.Property{property.MustBuildPackage(pkgName, version)}, props...),
+		}
+	}
+	maxOpenShiftVersion := func(version string) property.Property {
+		return property.Property{Type: propertyMaxOpenShiftVersion, Value: json.RawMessage(version)}
+	}
+	// stable-v1: 1.0.0 <- 1.1.0 <- 1.2.0 (skips 1.1.0-rc) <- 1.3.0, stable-v0: 0.9.0
+	newDC := func() *declcfg.DeclarativeConfig {
+		return &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: pkgName, DefaultChannel: "stable-v1"}},
+			Channels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Package: pkgName, Name: "stable-v0", Entries: []declcfg.ChannelEntry{{Name: "albo.v0.9.0"}}},
+				{Schema: declcfg.SchemaChannel, Package: pkgName, Name: "stable-v1", Entries: []declcfg.ChannelEntry{
+					{Name: "albo.v1.0.0"},
+					{Name: "albo.v1.1.0-rc", Replaces: "albo.v1.0.0"},
+					{Name: "albo.v1.1.0", Replaces: "albo.v1.0.0"},
+					{Name: "albo.v1.2.0", Replaces: "albo.v1.1.0", Skips: []string{"albo.v1.1.0-rc"}},
+					{Name: "albo.v1.3.0", Replaces: "albo.v1.2.0"},
+				}},
+			},
+			Bundles: []declcfg.Bundle{
+				bundle("0.9.0", maxOpenShiftVersion(`"4.14"`)),
+				bundle("1.0.0"),
+				bundle("1.1.0-rc"),
+				bundle("1.1.0", maxOpenShiftVersion("4.16")),
+				bundle("1.2.0"),
+				bundle("1.3.0", maxOpenShiftVersion(`"4.15"`)),
+			},
+			Deprecations: []declcfg.Deprecation{{Schema: declcfg.SchemaDeprecation, Package: pkgName, Entries: []declcfg.DeprecationEntry{
+				{Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaBundle, Name: "albo.v1.2.0"}, Message: "albo.v1.2.0 is deprecated"},
+			}}},
+		}
+	}
+	operatorWith := func(pkg v2alpha1.IncludePackage) v2alpha1.Operator {
+		pkg.Name = pkgName
+		return v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{pkg}}}
+	}
+	channelEntries := func(t *testing.T, dc *declcfg.DeclarativeConfig, channel string) []declcfg.ChannelEntry {
+		// the filtered catalog must remain valid for OLM
+		m, err := declcfg.ConvertToModel(*dc)
+		require.NoError(t, err)
+		require.NoError(t, m.Validate())
+		for _, ch := range dc.Channels {
+			if ch.Name == channel {
+				return ch.Entries
+			}
+		}
+		return nil
+	}
+
+	t.Run("Testing applySelectionPolicies - should keep the latest bundles of each channel", func(t *testing.T) {
+		dc := newDC()
+		require.NoError(t, applySelectionPolicies(dc, operatorWith(v2alpha1.IncludePackage{Channels: []v2alpha1.IncludeChannel{
+			{Name: "stable-v1", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "1.0.0"}, SelectionPolicy: v2alpha1.SelectionPolicy{Latest: 3}},
+			{Name: "stable-v0"},
+		}}), ""))
+		assert.Equal(t, []declcfg.ChannelEntry{
+			{Name: "albo.v1.1.0"},
+			{Name: "albo.v1.2.0", Replaces: "albo.v1.1.0", Skips: []string{"albo.v1.1.0-rc"}},
+			{Name: "albo.v1.3.0", Replaces: "albo.v1.2.0"},
+		}, channelEntries(t, dc, "stable-v1"))
+		assert.Equal(t, []declcfg.ChannelEntry{{Name: "albo.v0.9.0"}}, channelEntries(t, dc, "stable-v0"))
+		assert.Len(t, dc.Bundles, 4)
+	})
+
+	t.Run("Testing applySelectionPolicies - should rewire the edges around the skipped bundles", func(t *testing.T) {
+		dc := newDC()
+		require.NoError(t, applySelectionPolicies(dc, operatorWith(v2alpha1.IncludePackage{SelectionPolicy: v2alpha1.SelectionPolicy{Latest: 10, SkipDeprecated: true}}), ""))
+		assert.Equal(t, []declcfg.ChannelEntry{
+			{Name: "albo.v1.0.0"},
+			{Name: "albo.v1.1.0-rc", Replaces: "albo.v1.0.0"},
+			{Name: "albo.v1.1.0", Replaces: "albo.v1.0.0"},
+			{Name: "albo.v1.3.0", Replaces: "albo.v1.1.0", Skips: []string{"albo.v1.1.0-rc"}},
+		}, channelEntries(t, dc, "stable-v1"))
+		assert.Empty(t, dc.Deprecations[0].Entries)
+	})
+
+	t.Run("Testing applySelectionPolicies - should select the newest compatible bundle of the heads-only channels", func(t *testing.T) {
+		dc := newDC()
+		require.NoError(t, applySelectionPolicies(dc, operatorWith(v2alpha1.IncludePackage{SelectionPolicy: v2alpha1.SelectionPolicy{CompatibleWithPlatform: true, SkipDeprecated: true}}), "4.16"))
+		assert.Equal(t, []declcfg.ChannelEntry{{Name: "albo.v1.1.0"}}, channelEntries(t, dc, "stable-v1"))
+		assert.Nil(t, channelEntries(t, dc, "stable-v0"))
+	})
+
+	t.Run("Testing applySelectionPolicies - should fail on an empty default channel", func(t *testing.T) {
+		dc := newDC()
+		dc.Packages[0].DefaultChannel = "stable-v0"
+		assert.EqualError(t, applySelectionPolicies(dc, operatorWith(v2alpha1.IncludePackage{SelectionPolicy: v2alpha1.SelectionPolicy{CompatibleWithPlatform: true}}), "4.15"),
+			`package "aws-load-balancer-operator" channel "stable-v0": no bundle matches the selection policies of the default channel`)
+	})
+
+	t.Run("Testing rewireChannel - should keep a single head", func(t *testing.T) {
+		entries := []declcfg.ChannelEntry{
+			{Name: "a.v1.0.0"},
+			{Name: "a.v1.1.0", Skips: []string{"a.v1.0.0"}},
+			{Name: "a.v2.0.0", Replaces: "a.v1.1.0"},
+		}
+		assert.Equal(t, []declcfg.ChannelEntry{
+			{Name: "a.v1.0.0"},
+			{Name: "a.v2.0.0", Skips: []string{"a.v1.0.0"}},
+		}, rewireChannel(entries, []BundleInfo{{Name: "a.v1.0.0", Version: "1.0.0"}, {Name: "a.v2.0.0", Version: "2.0.0"}}))
+	})
+
+	t.Run("Testing filterFromImageSetConfig - should select all the bundles of the heads-only channels with policies", func(t *testing.T) {
+		cfg, err := filterFromImageSetConfig(operatorWith(v2alpha1.IncludePackage{
+			SelectionPolicy: v2alpha1.SelectionPolicy{SkipDeprecated: true},
+			Channels: []v2alpha1.IncludeChannel{
+				{Name: "stable-v1"},
+				{Name: "stable-v0", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "0.9.0"}},
+			},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, anyVersionRange, cfg.Packages[0].Channels[0].VersionRange)
+		assert.Equal(t, ">=0.9.0", cfg.Packages[0].Channels[1].VersionRange)
+	})
+
+	t.Run("Testing platformMinor - should return the highest minor of the platform channels", func(t *testing.T) {
+		minor, err := platformMinor(v2alpha1.Platform{Channels: []v2alpha1.ReleaseChannel{
+			{Name: "stable-4.14", MinVersion: "4.14.10", MaxVersion: "4.14.20"},
+			{Name: "stable-4.16"},
+			{Name: "okd"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, semver.Version{Major: 4, Minor: 16}, minor)
+
+		_, err = platformMinor(v2alpha1.Platform{})
+		assert.EqualError(t, err, "compatibleWithPlatform: unable to find the OpenShift version of the platform channels")
+	})
+
+	t.Run("Testing filterDigest - should depend on the platform only with compatibleWithPlatform", func(t *testing.T) {
+		op := operatorWith(v2alpha1.IncludePackage{SelectionPolicy: v2alpha1.SelectionPolicy{Latest: 2}})
+		collector := OperatorCollector{Config: v2alpha1.ImageSetConfiguration{ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{Mirror: v2alpha1.Mirror{
+			Platform: v2alpha1.Platform{Channels: []v2alpha1.ReleaseChannel{{Name: "stable-4.16"}}},
+		}}}}
+		digest, err := collector.filterDigest(op)
+		require.NoError(t, err)
+		expected, err := digestOfFilter(op)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+
+		op = operatorWith(v2alpha1.IncludePackage{SelectionPolicy: v2alpha1.SelectionPolicy{CompatibleWithPlatform: true}})
+		digest416, err := collector.filterDigest(op)
+		require.NoError(t, err)
+		collector.Config.Mirror.Platform.Channels[0].Name = "stable-4.17"
+		digest417, err := collector.filterDigest(op)
+		require.NoError(t, err)
+		assert.NotEqual(t, digest416, digest417)
+	})
+}
@@ -0,0 +1,407 @@
+package release
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/imagebuilder"
+)
+
+// coreosStream is the coreos stream metadata found in the release payload file
+// 0000_50_installer_coreos-bootimages, for all architectures and platforms.
+// Unlike InstallerBootableImages, it only models the downloadable artifacts.
+type coreosStream struct {
+	Stream        string                      `json:"stream"`
+	Architectures map[string]coreosStreamArch `json:"architectures"`
+}
+
+type coreosStreamArch struct {
+	Artifacts map[string]coreosStreamPlatform `json:"artifacts"`
+}
+
+type coreosStreamPlatform struct {
+	Release string `json:"release"`
+	// format (iso, pxe, qcow2.gz...) => file (disk, kernel, initramfs, rootfs) => artifact
+	Formats map[string]map[string]coreosStreamArtifact `json:"formats"`
+}
+
+type coreosStreamArtifact struct {
+	Location string `json:"location"`
+	Sha256   string `json:"sha256"`
+}
+
+// bootArtifact is a boot artifact selected by platform.bootImages
+type bootArtifact struct {
+	Architecture string
+	Platform     string
+	Format       string
+	Release      string
+	Location     string
+	Sha256       string
+}
+
+// fileName returns the name of the artifact, as found at the end of its location
+func (b bootArtifact) fileName() (string, error) {
+	location, err := url.Parse(b.Location)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(location.Path)
+	if name == "/" || name == "." {
+		return "", fmt.Errorf("no file name found in %s", b.Location)
+	}
+	return name, nil
+}
+
+// selectBootArtifacts returns the artifacts of the stream matching the architectures, platforms and formats of bootImages
+func selectBootArtifacts(stream coreosStream, bootImages v2alpha1.BootImages) ([]bootArtifact, error) {
+	artifacts := []bootArtifact{}
+	for _, arch := range bootImages.GetArchitectures() {
+		streamArch, ok := stream.Architectures[arch]
+		if !ok {
+			return nil, fmt.Errorf("architecture %s not found in the coreos stream metadata", arch)
+		}
+		for _, platform := range bootImages.Platforms {
+			streamPlatform, ok := streamArch.Artifacts[platform]
+			if !ok {
+				return nil, fmt.Errorf("platform %s not found in the coreos stream metadata of %s", platform, arch)
+			}
+			formats := bootImages.Formats
+			if len(formats) == 0 {
+				formats = slices.Sorted(maps.Keys(streamPlatform.Formats))
+			}
+			selected := 0
+			for _, format := range formats {
+				files, ok := streamPlatform.Formats[format]
+				if !ok {
+					continue
+				}
+				for _, file := range slices.Sorted(maps.Keys(files)) {
+					artifacts = append(artifacts, bootArtifact{
+						Architecture: arch,
+						Platform:     platform,
+						Format:       format,
+						Release:      streamPlatform.Release,
+						Location:     files[file].Location,
+						Sha256:       files[file].Sha256,
+					})
+					selected++
+				}
+			}
+			if selected == 0 {
+				return nil, fmt.Errorf("none of the formats %v found for platform %s of %s in the coreos stream metadata", formats, platform, arch)
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+// readInstallerStream returns the coreos stream metadata (json) of the release extracted in releaseArtifactsDir
+func readInstallerStream(releaseArtifactsDir string) ([]byte, error) {
+	var icm v2alpha1.InstallerConfigMap
+	biFile := filepath.Join(releaseArtifactsDir, releaseBootableImagesFullPath)
+	file, err := os.ReadFile(biFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading coreos bootimages yaml file %v", err)
+	}
+	if err := yaml.Unmarshal(file, &icm); err != nil {
+		return nil, fmt.Errorf("marshalling coreos bootimages yaml file %v", err)
+	}
+	return []byte(icm.Data.Stream), nil
+}
+
+// collectBootImages downloads to the working-dir the boot artifacts listed in platform.bootImages,
+// from the coreos stream metadata of the release extracted in releaseArtifactsDir
+func (o LocalStorageCollector) collectBootImages(ctx context.Context, releaseArtifactsDir string) error {
+	streamData, err := readInstallerStream(releaseArtifactsDir)
+	if err != nil {
+		return err
+	}
+	var stream coreosStream
+	if err := json.Unmarshal(streamData, &stream); err != nil {
+		return fmt.Errorf("parsing json from coreos bootimages configmap data %v", err)
+	}
+	artifacts, err := selectBootArtifacts(stream, *o.Config.Mirror.Platform.BootImages)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		fileName, err := artifact.fileName()
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(o.Opts.Global.WorkingDir, bootImagesDir, artifact.Release, artifact.Architecture, fileName)
+		if err := o.downloadBootArtifact(ctx, artifact, dest); err != nil {
+			return fmt.Errorf("unable to download boot artifact %s: %v", artifact.Location, err)
+		}
+	}
+	return nil
+}
+
+// downloadBootArtifact downloads the artifact to dest, unless dest already has the expected sha256
+func (o LocalStorageCollector) downloadBootArtifact(ctx context.Context, artifact bootArtifact, dest string) error {
+	if sum, err := fileSha256(dest); err == nil && sum == artifact.Sha256 {
+		o.Log.Debug(collectorPrefix+"boot artifact %s already downloaded", dest)
+		return nil
+	}
+
+	o.Log.Info(emoji.Package+" Downloading boot artifact %s (%s %s %s)", path.Base(dest), artifact.Architecture, artifact.Platform, artifact.Format)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.Location, nil)
+	if err != nil {
+		return err
+	}
+	client, err := o.bootArtifactsClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	partial := dest + bootImagesPartialSuffix
+	f, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partial)
+	idleTimeout := bootImagesIdleTimeout
+	timer := time.AfterFunc(idleTimeout, func() {
+		cancel(fmt.Errorf("no data received for %v", idleTimeout))
+	})
+	defer timer.Stop()
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), idleTimeoutReader{reader: resp.Body, timer: timer, idleTimeout: idleTimeout})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if cause := context.Cause(ctx); err != nil && cause != nil {
+		return cause
+	}
+	if err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != artifact.Sha256 {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", artifact.Sha256, sum)
+	}
+	return os.Rename(partial, dest)
+}
+
+// bootArtifactsClient returns the HTTP client downloading the boot artifacts. It uses the proxy
+// of the environment and the certificate authorities of the system, plus the ones of
+// platform.bootImages.caBundle. The artifacts are several GB large, so the client sets no
+// overall timeout: the download is bounded by the context and by bootImagesIdleTimeout.
+func (o LocalStorageCollector) bootArtifactsClient() (*http.Client, error) {
+	tlsConfig, err := getTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if caBundle := o.Config.Mirror.Platform.BootImages.CABundle; caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, err
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in the boot images CA bundle %s", caBundle)
+		}
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   bootImagesHandshakeTimeout,
+		ResponseHeaderTimeout: bootImagesResponseTimeout,
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// idleTimeoutReader cancels a download when no data is read from it during idleTimeout
+type idleTimeoutReader struct {
+	reader      io.Reader
+	timer       *time.Timer
+	idleTimeout time.Duration
+}
+
+func (r idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.idleTimeout)
+	}
+	return n, err
+}
+
+// PublishBootImages makes the boot artifacts of the working-dir available on the disconnected side:
+// they are copied to platform.bootImages.httpDir, and/or pushed to the destination registry
+// as an image per coreos release and architecture, with one layer per artifact.
+func (o *LocalStorageCollector) PublishBootImages(ctx context.Context) error {
+	bootImages := o.Config.Mirror.Platform.BootImages
+	if bootImages == nil || (bootImages.HTTPDir == "" && !bootImages.OCIArtifact) {
+		return nil
+	}
+	artifacts, err := o.bootImagesInWorkingDir()
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		o.Log.Warn("no boot artifact found in the working-dir: skipping boot images publication")
+		return nil
+	}
+
+	if bootImages.HTTPDir != "" {
+		o.Log.Info(emoji.Package+" Copying boot artifacts to %s", bootImages.HTTPDir)
+		for _, key := range slices.Sorted(maps.Keys(artifacts)) {
+			for _, artifact := range artifacts[key] {
+				if err := copyBootArtifact(artifact, filepath.Join(bootImages.HTTPDir, key, filepath.Base(artifact))); err != nil {
+					return fmt.Errorf("unable to copy boot artifact %s: %v", artifact, err)
+				}
+			}
+		}
+	}
+
+	if bootImages.OCIArtifact {
+		for _, key := range slices.Sorted(maps.Keys(artifacts)) {
+			imageRef, err := o.pushBootImages(ctx, key, artifacts[key])
+			if err != nil {
+				return fmt.Errorf("unable to push boot artifacts of %s: %v", key, err)
+			}
+			o.Log.Info(emoji.Package+" Boot artifacts pushed to %s", imageRef)
+		}
+	}
+	return nil
+}
+
+// bootImagesInWorkingDir lists the boot artifacts found in the working-dir, by <coreos release>/<architecture> directory
+func (o LocalStorageCollector) bootImagesInWorkingDir() (map[string][]string, error) {
+	root := filepath.Join(o.Opts.Global.WorkingDir, bootImagesDir)
+	artifacts := map[string][]string{}
+	files, err := filepath.Glob(filepath.Join(root, "*", "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() || filepath.Ext(file) == bootImagesPartialSuffix {
+			continue
+		}
+		key, err := filepath.Rel(root, filepath.Dir(file))
+		if err != nil {
+			return nil, err
+		}
+		artifacts[key] = append(artifacts[key], file)
+	}
+	return artifacts, nil
+}
+
+// pushBootImages pushes the artifacts of a <coreos release>/<architecture> directory
+// to the destination registry, and returns the reference of the image
+func (o *LocalStorageCollector) pushBootImages(ctx context.Context, key string, artifacts []string) (string, error) {
+	release, arch := filepath.Split(key)
+	layoutDir := filepath.Join(o.Opts.Global.WorkingDir, bootImagesPreparationDir)
+	if err := os.RemoveAll(layoutDir); err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(layoutDir)
+	layoutPath, err := imagebuilder.ScratchImageLayout(layoutDir, v1.Platform{OS: "linux", Architecture: goArchitecture(arch)})
+	if err != nil {
+		return "", err
+	}
+
+	layers := []v1.Layer{}
+	for _, artifact := range artifacts {
+		layer, err := imagebuilder.LayerFromFile("/"+filepath.Base(artifact), artifact, 0644)
+		if err != nil {
+			return "", err
+		}
+		layers = append(layers, layer)
+	}
+
+	imageRef := filepath.Join(o.destinationRegistry(), bootImagesImageName) + ":" + filepath.Clean(release) + "-" + arch
+	if _, err := o.ImageBuilder.BuildAndPush(ctx, imageRef, layoutPath, nil, layers...); err != nil {
+		return "", err
+	}
+	return imageRef, nil
+}
+
+// goArchitecture converts a coreos architecture to its image platform counterpart
+func goArchitecture(arch string) string {
+	switch arch {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	default:
+		return arch
+	}
+}
+
+// copyBootArtifact copies src to dest, unless dest already has the same size
+func copyBootArtifact(src, dest string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if destInfo, err := os.Stat(dest); err == nil && destInfo.Size() == srcInfo.Size() {
+		return nil
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
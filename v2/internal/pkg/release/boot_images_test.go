@@ -0,0 +1,253 @@
+package release
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestBootImages(t *testing.T) {
+	artifacts := map[string]string{
+		"/rhcos-416.94.202405291527-0-live.x86_64.iso":           "iso content",
+		"/rhcos-416.94.202405291527-0-live-kernel-x86_64":        "kernel content",
+		"/rhcos-416.94.202405291527-0-qemu.x86_64.qcow2.gz":      "qcow2 content",
+		"/rhcos-416.94.202405291527-0-live.aarch64.iso":          "aarch64 iso content",
+		"/rhcos-416.94.202405291527-0-live-initramfs.x86_64.img": "initramfs content",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := artifacts[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	sum := func(content string) string {
+		hash := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(hash[:])
+	}
+	artifact := func(name string) coreosStreamArtifact {
+		return coreosStreamArtifact{Location: server.URL + name, Sha256: sum(artifacts[name])}
+	}
+	stream := coreosStream{
+		Stream: "rhcos-4.16",
+		Architectures: map[string]coreosStreamArch{
+			"x86_64": {Artifacts: map[string]coreosStreamPlatform{
+				"metal": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+					"iso": {"disk": artifact("/rhcos-416.94.202405291527-0-live.x86_64.iso")},
+					"pxe": {
+						"kernel":    artifact("/rhcos-416.94.202405291527-0-live-kernel-x86_64"),
+						"initramfs": artifact("/rhcos-416.94.202405291527-0-live-initramfs.x86_64.img"),
+					},
+				}},
+				"qemu": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+					"qcow2.gz": {"disk": artifact("/rhcos-416.94.202405291527-0-qemu.x86_64.qcow2.gz")},
+				}},
+			}},
+			"aarch64": {Artifacts: map[string]coreosStreamPlatform{
+				"metal": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+					"iso": {"disk": artifact("/rhcos-416.94.202405291527-0-live.aarch64.iso")},
+				}},
+			}},
+		},
+	}
+
+	// writes the coreos-bootimages config map of a release extracted in a temporary directory
+	releaseDir := func(t *testing.T, stream coreosStream) string {
+		dir := t.TempDir()
+		streamData, err := json.Marshal(stream)
+		assert.NoError(t, err)
+		configMap, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"data":       map[string]string{"releaseVersion": "4.16.0", "stream": string(streamData)},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, releaseManifests), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, releaseBootableImagesFullPath), configMap, 0644))
+		return dir
+	}
+	newCollector := func(t *testing.T, bootImages *v2alpha1.BootImages) *LocalStorageCollector {
+		return &LocalStorageCollector{
+			Log: clog.New("trace"),
+			Config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{Platform: v2alpha1.Platform{BootImages: bootImages}},
+				},
+			},
+			Opts: mirror.CopyOptions{
+				Mode:        mirror.DiskToMirror,
+				Destination: "docker://localhost:5000",
+				Global:      &mirror.GlobalOptions{WorkingDir: t.TempDir()},
+			},
+			ImageBuilder: &mockImageBuilder{},
+		}
+	}
+
+	t.Run("Testing selectBootArtifacts - should select the formats of the platforms", func(t *testing.T) {
+		selected, err := selectBootArtifacts(stream, v2alpha1.BootImages{Platforms: []string{"metal", "qemu"}, Formats: []string{"iso", "qcow2.gz"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []bootArtifact{
+			{Architecture: "x86_64", Platform: "metal", Format: "iso", Release: "416.94.202405291527-0", Location: server.URL + "/rhcos-416.94.202405291527-0-live.x86_64.iso", Sha256: sum("iso content")},
+			{Architecture: "x86_64", Platform: "qemu", Format: "qcow2.gz", Release: "416.94.202405291527-0", Location: server.URL + "/rhcos-416.94.202405291527-0-qemu.x86_64.qcow2.gz", Sha256: sum("qcow2 content")},
+		}, selected)
+	})
+
+	t.Run("Testing selectBootArtifacts - should select all the formats when none is set", func(t *testing.T) {
+		selected, err := selectBootArtifacts(stream, v2alpha1.BootImages{Architectures: []string{"x86_64", "aarch64"}, Platforms: []string{"metal"}})
+		assert.NoError(t, err)
+		assert.Len(t, selected, 4)
+		assert.Equal(t, "pxe", selected[2].Format)
+		assert.Equal(t, "aarch64", selected[3].Architecture)
+	})
+
+	t.Run("Testing selectBootArtifacts - should fail on missing architectures, platforms and formats", func(t *testing.T) {
+		_, err := selectBootArtifacts(stream, v2alpha1.BootImages{Architectures: []string{"s390x"}, Platforms: []string{"metal"}})
+		assert.EqualError(t, err, "architecture s390x not found in the coreos stream metadata")
+		_, err = selectBootArtifacts(stream, v2alpha1.BootImages{Platforms: []string{"vmware"}})
+		assert.EqualError(t, err, "platform vmware not found in the coreos stream metadata of x86_64")
+		_, err = selectBootArtifacts(stream, v2alpha1.BootImages{Platforms: []string{"qemu"}, Formats: []string{"iso"}})
+		assert.EqualError(t, err, "none of the formats [iso] found for platform qemu of x86_64 in the coreos stream metadata")
+	})
+
+	t.Run("Testing collectBootImages - should download and verify the artifacts", func(t *testing.T) {
+		ex := newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}, Formats: []string{"pxe"}})
+		assert.NoError(t, ex.collectBootImages(context.Background(), releaseDir(t, stream)))
+
+		archDir := filepath.Join(ex.Opts.Global.WorkingDir, bootImagesDir, "416.94.202405291527-0", "x86_64")
+		content, err := os.ReadFile(filepath.Join(archDir, "rhcos-416.94.202405291527-0-live-kernel-x86_64"))
+		assert.NoError(t, err)
+		assert.Equal(t, "kernel content", string(content))
+		entries, err := os.ReadDir(archDir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		// already downloaded artifacts are kept
+		server.Close()
+		assert.NoError(t, ex.collectBootImages(context.Background(), releaseDir(t, stream)))
+	})
+
+	t.Run("Testing collectBootImages - should fail on sha256 mismatch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("tampered content"))
+		}))
+		defer server.Close()
+		tampered := coreosStream{Architectures: map[string]coreosStreamArch{
+			"x86_64": {Artifacts: map[string]coreosStreamPlatform{
+				"metal": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+					"iso": {"disk": {Location: server.URL + "/rhcos-live.x86_64.iso", Sha256: sum("iso content")}},
+				}},
+			}},
+		}}
+
+		ex := newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}})
+		err := ex.collectBootImages(context.Background(), releaseDir(t, tampered))
+		assert.ErrorContains(t, err, "sha256 mismatch: expected "+sum("iso content")+", got "+sum("tampered content"))
+		entries, err := os.ReadDir(filepath.Join(ex.Opts.Global.WorkingDir, bootImagesDir, "416.94.202405291527-0", "x86_64"))
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Testing collectBootImages - should trust the system and caBundle certificate authorities", func(t *testing.T) {
+		tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("iso content"))
+		}))
+		defer tlsServer.Close()
+		tlsStream := coreosStream{Architectures: map[string]coreosStreamArch{
+			"x86_64": {Artifacts: map[string]coreosStreamPlatform{
+				"metal": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+					"iso": {"disk": {Location: tlsServer.URL + "/rhcos-live.x86_64.iso", Sha256: sum("iso content")}},
+				}},
+			}},
+		}}
+		caBundle := filepath.Join(t.TempDir(), "ca-bundle.pem")
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+		assert.NoError(t, os.WriteFile(caBundle, caCert, 0644))
+
+		// the TLS options of the source registries don't apply to the hosts of the artifacts
+		ex := newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}})
+		_, sharedOpts := mirror.SharedImageFlags()
+		_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+		srcFlags, srcOpts := mirror.ImageSrcFlags(ex.Opts.Global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+		assert.NoError(t, srcFlags.Parse([]string{"--src-tls-verify=false"}))
+		ex.Opts.SrcImage = srcOpts
+		assert.ErrorContains(t, ex.collectBootImages(context.Background(), releaseDir(t, tlsStream)), "certificate")
+
+		ex = newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}, CABundle: caBundle})
+		assert.NoError(t, ex.collectBootImages(context.Background(), releaseDir(t, tlsStream)))
+
+		assert.NoError(t, os.WriteFile(caBundle, []byte("not a certificate"), 0644))
+		ex = newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}, CABundle: caBundle})
+		assert.ErrorContains(t, ex.collectBootImages(context.Background(), releaseDir(t, tlsStream)), "no certificate found in the boot images CA bundle")
+	})
+
+	t.Run("Testing collectBootImages - should not time out a slow download, only a stalled one", func(t *testing.T) {
+		defer func(idleTimeout time.Duration) { bootImagesIdleTimeout = idleTimeout }(bootImagesIdleTimeout)
+		bootImagesIdleTimeout = 200 * time.Millisecond
+
+		slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			// the whole download lasts longer than the idle timeout, but data keeps coming
+			for _, chunk := range []string{"iso ", "con", "tent"} {
+				_, _ = w.Write([]byte(chunk))
+				flusher.Flush()
+				time.Sleep(bootImagesIdleTimeout / 2)
+			}
+			if r.URL.Path == "/stalled.iso" {
+				<-r.Context().Done()
+			}
+		}))
+		defer slowServer.Close()
+		slowStream := func(name string) coreosStream {
+			return coreosStream{Architectures: map[string]coreosStreamArch{
+				"x86_64": {Artifacts: map[string]coreosStreamPlatform{
+					"metal": {Release: "416.94.202405291527-0", Formats: map[string]map[string]coreosStreamArtifact{
+						"iso": {"disk": {Location: slowServer.URL + "/" + name, Sha256: sum("iso content")}},
+					}},
+				}},
+			}}
+		}
+
+		ex := newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}})
+		assert.NoError(t, ex.collectBootImages(context.Background(), releaseDir(t, slowStream("slow.iso"))))
+
+		ex = newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}})
+		assert.ErrorContains(t, ex.collectBootImages(context.Background(), releaseDir(t, slowStream("stalled.iso"))), "no data received for 200ms")
+	})
+
+	t.Run("Testing PublishBootImages - should copy the artifacts to the HTTP directory and push them", func(t *testing.T) {
+		httpDir := t.TempDir()
+		ex := newCollector(t, &v2alpha1.BootImages{Platforms: []string{"metal"}, HTTPDir: httpDir, OCIArtifact: true})
+		archDir := filepath.Join(ex.Opts.Global.WorkingDir, bootImagesDir, "416.94.202405291527-0", "x86_64")
+		assert.NoError(t, os.MkdirAll(archDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(archDir, "rhcos-live.x86_64.iso"), []byte("iso content"), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(archDir, "rhcos-live.x86_64.iso"+bootImagesPartialSuffix), []byte("iso"), 0644))
+
+		assert.NoError(t, ex.PublishBootImages(context.Background()))
+		content, err := os.ReadFile(filepath.Join(httpDir, "416.94.202405291527-0", "x86_64", "rhcos-live.x86_64.iso"))
+		assert.NoError(t, err)
+		assert.Equal(t, "iso content", string(content))
+		_, err = os.Stat(filepath.Join(httpDir, "416.94.202405291527-0", "x86_64", "rhcos-live.x86_64.iso"+bootImagesPartialSuffix))
+		assert.True(t, os.IsNotExist(err))
+
+		ex.ImageBuilder = &mockImageBuilder{Fail: true}
+		assert.ErrorContains(t, ex.PublishBootImages(context.Background()), "unable to push boot artifacts of 416.94.202405291527-0/x86_64")
+	})
+}
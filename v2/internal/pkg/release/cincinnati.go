@@ -2,14 +2,18 @@ package release
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/google/uuid"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	//nolint
 )
@@ -83,6 +87,7 @@ type CincinnatiSchema struct {
 	Opts             mirror.CopyOptions
 	Client           Client
 	Signature        SignatureInterface
+	Manifest         manifest.ManifestInterface
 	Fail             bool
 	CincinnatiParams CincinnatiParams
 }
@@ -92,8 +97,8 @@ type CincinnatiParams struct {
 	Arch         string
 }
 
-func NewCincinnati(log clog.PluggableLoggerInterface, config *v2alpha1.ImageSetConfiguration, opts mirror.CopyOptions, c Client, b bool, sig SignatureInterface) CincinnatiInterface {
-	return &CincinnatiSchema{Log: log, Config: config, Opts: opts, Client: c, Fail: b, Signature: sig}
+func NewCincinnati(log clog.PluggableLoggerInterface, config *v2alpha1.ImageSetConfiguration, opts mirror.CopyOptions, c Client, b bool, sig SignatureInterface, manifest manifest.ManifestInterface) CincinnatiInterface {
+	return &CincinnatiSchema{Log: log, Config: config, Opts: opts, Client: c, Fail: b, Signature: sig, Manifest: manifest}
 }
 
 func (o *CincinnatiSchema) NewOCPClient() error {
@@ -164,10 +169,10 @@ func (o *CincinnatiSchema) GetReleaseReferenceImages(ctx context.Context) ([]v2a
 			if len(ch.MaxVersion) > 0 && len(ch.MinVersion) > 0 {
 				max := semver.MustParse(ch.MaxVersion)
 				min := semver.MustParse(ch.MinVersion)
-				if strings.Contains(ch.Name, "eus") && ((max.Minor - min.Minor) >= 2) && !flagReport {
+				if strings.Contains(ch.Name, "eus") && !ch.EUSPath && ((max.Minor - min.Minor) >= 2) && !flagReport {
 					msg := "Extended Update Support (EUS) channel detected with minor version range >= 2\n" +
 						"\t\t\t\tPlease refer to the web console https://access.redhat.com/labs/ocpupgradegraph/update_path\n" +
-						"\t\t\t\tTo correctly determine the upgrade path for EUS releases"
+						"\t\t\t\tTo correctly determine the upgrade path for EUS releases, or set eusPath: true on the channel"
 					flagReport = true
 					o.Log.Warn(msg)
 				}
@@ -243,6 +248,17 @@ func (o *CincinnatiSchema) GetReleaseReferenceImages(ctx context.Context) ([]v2a
 		}
 	}
 
+	// the explicit release payloads are verified as the releases of the channels
+	payloads, err := o.releasePayloadImages(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, payload := range payloads {
+		if !slices.ContainsFunc(allImages, func(img v2alpha1.CopyImageSchema) bool { return img.Source == payload.Source }) {
+			allImages = append(allImages, payload)
+		}
+	}
+
 	imgs, err := o.Signature.GenerateReleaseSignatures(ctx, allImages)
 	if err != nil {
 		o.Log.Error("%v", err)
@@ -258,6 +274,68 @@ func (o *CincinnatiSchema) GetReleaseReferenceImages(ctx context.Context) ([]v2a
 	return imgs, nil
 }
 
+// releasePayloadImages returns the payloads of platform.releases, pinned by digest, without any update graph lookup.
+// The payloads which are resolved are returned along with the errors of the ones which are not.
+func (o *CincinnatiSchema) releasePayloadImages(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
+	var allImages []v2alpha1.CopyImageSchema
+	var errs []error
+	for _, payload := range o.Config.Mirror.Platform.Releases {
+		src := payload.Image
+		if payload.Version != "" {
+			var err error
+			src, err = o.resolveReleasePayload(ctx, payload)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("[GetReleaseReferenceImages] unable to resolve release %s (%s): %v", payload.Version, payload.GetArchitecture(), err))
+				continue
+			}
+		}
+		o.Log.Debug("release payload %s", src)
+		allImages = append(allImages, v2alpha1.CopyImageSchema{Source: src, Destination: "", Origin: src})
+	}
+	return allImages, errors.Join(errs...)
+}
+
+// resolveReleasePayload returns the pullspec, by digest, of the payload of a release version.
+// The digest is found in the signatures cached in the working-dir, so that diskToMirror
+// does not need to reach the source registry, or otherwise from the source registry.
+func (o *CincinnatiSchema) resolveReleasePayload(ctx context.Context, payload v2alpha1.ReleasePayload) (string, error) {
+	tag := payload.Version + "-" + releasePayloadArchitecture(payload.GetArchitecture())
+	sigFiles, err := os.ReadDir(o.Opts.Global.WorkingDir + SignatureDir)
+	if err != nil {
+		o.Log.Debug("[GetReleaseReferenceImages] no directory found for signatures %v", err)
+	}
+	for _, file := range sigFiles {
+		if digest, found := strings.CutPrefix(file.Name(), tag+"-sha256-"); found {
+			return v2alpha1.ReleasePayloadRepository + "@sha256:" + digest, nil
+		}
+	}
+
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return "", err
+	}
+	digest, err := o.Manifest.GetDigest(ctx, sourceCtx, dockerProtocol+v2alpha1.ReleasePayloadRepository+":"+tag)
+	if err != nil {
+		return "", err
+	}
+	if digest == "" {
+		return "", fmt.Errorf("no digest found for %s:%s", v2alpha1.ReleasePayloadRepository, tag)
+	}
+	return v2alpha1.ReleasePayloadRepository + "@sha256:" + digest, nil
+}
+
+// releasePayloadArchitecture converts an architecture to its release payload tag counterpart
+func releasePayloadArchitecture(arch string) string {
+	switch arch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	default:
+		return arch
+	}
+}
+
 // getDownloads will prepare the downloads map for mirroring
 func getChannelDownloads(ctx context.Context, cs CincinnatiSchema, lastChannels []v2alpha1.ReleaseChannel, channel v2alpha1.ReleaseChannel) ([]v2alpha1.CopyImageSchema, error) {
 	var allImages []v2alpha1.CopyImageSchema
@@ -280,6 +358,9 @@ func getChannelDownloads(ctx context.Context, cs CincinnatiSchema, lastChannels
 	}
 
 	var newDownloads []v2alpha1.CopyImageSchema
+	if channel.EUSPath {
+		return getEUSPathDownloads(ctx, cs, channel)
+	}
 	if channel.ShortestPath {
 		current, newest, updates, err := CalculateUpgrades(ctx, cs, channel.Name, channel.Name, first, last)
 		if err != nil {
@@ -360,3 +441,34 @@ func gatherUpdates(log clog.PluggableLoggerInterface, current, newest Update, up
 	}
 	return allImages
 }
+
+// ChannelVersions returns the sorted releases of a channel of the update graph for an architecture.
+// A channel which does not exist in the update graph has no release.
+func (o *CincinnatiSchema) ChannelVersions(ctx context.Context, ch v2alpha1.ReleaseChannel, arch string) ([]semver.Version, error) {
+	o.CincinnatiParams = CincinnatiParams{
+		GraphDataDir: filepath.Join(o.Opts.Global.WorkingDir, releaseImageExtractDir, cincinnatiGraphDataDir),
+		Arch:         arch,
+	}
+	if err := os.MkdirAll(o.CincinnatiParams.GraphDataDir, 0755); err != nil {
+		return nil, err
+	}
+	var err error
+	switch ch.Type {
+	case v2alpha1.TypeOCP:
+		err = o.NewOCPClient()
+	case v2alpha1.TypeOKD:
+		err = o.NewOKDClient()
+	default:
+		err = fmt.Errorf("invalid platform type %v", ch.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := GetVersions(ctx, *o, ch.Name)
+	var cincinnatiErr *Error
+	if errors.As(err, &cincinnatiErr) && cincinnatiErr.Reason == "NoVersionsFound" {
+		return nil, nil
+	}
+	return versions, err
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
 )
 
 type mockSignature struct {
@@ -119,7 +120,7 @@ func TestGetReleaseReferenceImages(t *testing.T) {
 			t.Fatalf("should not fail endpoint parse")
 		}
 		c.url = endpoint
-		sch := NewCincinnati(log, &cfg, opts, c, false, signature)
+		sch := NewCincinnati(log, &cfg, opts, c, false, signature, &MockManifest{Log: log})
 		res, _ := sch.GetReleaseReferenceImages(context.Background())
 		if res == nil {
 			t.Fatalf("should return a related images")
@@ -143,7 +144,7 @@ func TestGetReleaseReferenceImages(t *testing.T) {
 			t.Fatalf("should not fail endpoint parse")
 		}
 		c.url = endpoint
-		sch := NewCincinnati(log, &cfgNoChannels, opts, c, false, signature)
+		sch := NewCincinnati(log, &cfgNoChannels, opts, c, false, signature, &MockManifest{Log: log})
 		res, err := sch.GetReleaseReferenceImages(context.Background())
 
 		log.Debug("result from cincinnati %v", res)
@@ -169,7 +170,7 @@ func TestGetReleaseReferenceImages(t *testing.T) {
 			t.Fatalf("should not fail endpoint parse")
 		}
 		c.url = endpoint
-		sch := NewCincinnati(log, &cfg, opts, c, true, signature)
+		sch := NewCincinnati(log, &cfg, opts, c, true, signature, &MockManifest{Log: log})
 		res, _ := sch.GetReleaseReferenceImages(context.Background())
 
 		log.Debug("result from cincinnati %v", res)
@@ -195,7 +196,7 @@ func TestGetReleaseReferenceImages(t *testing.T) {
 			t.Fatalf("should not fail endpoint parse")
 		}
 		c.url = endpoint
-		sch := NewCincinnati(log, &cfgReleaseKubeVirt, opts, c, true, signature)
+		sch := NewCincinnati(log, &cfgReleaseKubeVirt, opts, c, true, signature, &MockManifest{Log: log})
 		res, _ := sch.GetReleaseReferenceImages(context.Background())
 
 		log.Debug("result from cincinnati %v", res)
@@ -210,3 +211,74 @@ func (o mockSignature) GenerateReleaseSignatures(ctx context.Context, rd []v2alp
 	o.Log.Info("signature verification (mock)")
 	return []v2alpha1.CopyImageSchema{}, nil
 }
+
+func TestReleasePayloadImages(t *testing.T) {
+	log := clog.New("trace")
+
+	global := &mirror.GlobalOptions{SecurePolicy: false, WorkingDir: t.TempDir()}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	opts := mirror.CopyOptions{
+		Global:   global,
+		SrcImage: srcOpts,
+		Mode:     mirror.DiskToMirror,
+	}
+
+	// the payload of 4.17.0-ec.2 for arm64 was already verified: its signature is in the working-dir
+	assert.NoError(t, os.MkdirAll(global.WorkingDir+SignatureDir, 0755))
+	assert.NoError(t, os.WriteFile(global.WorkingDir+SignatureDir+"4.17.0-ec.2-aarch64-sha256-9d2b5c5f1a0b3e4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c", []byte("signature"), 0644))
+
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Platform: v2alpha1.Platform{
+					Releases: []v2alpha1.ReleasePayload{
+						{Version: "4.16.3"},
+						{Version: "4.17.0-ec.2", Architecture: "arm64"},
+						{Image: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d"},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Testing releasePayloadImages - should resolve versions without update graph", func(t *testing.T) {
+		sch := &CincinnatiSchema{Log: log, Config: &cfg, Opts: opts, Manifest: &MockManifest{Log: log}}
+		res, err := sch.releasePayloadImages(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.CopyImageSchema{
+			{
+				Source: "quay.io/openshift-release-dev/ocp-release@sha256:3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419",
+				Origin: "quay.io/openshift-release-dev/ocp-release@sha256:3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419",
+			},
+			{
+				Source: "quay.io/openshift-release-dev/ocp-release@sha256:9d2b5c5f1a0b3e4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c",
+				Origin: "quay.io/openshift-release-dev/ocp-release@sha256:9d2b5c5f1a0b3e4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c",
+			},
+			{
+				Source: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d",
+				Origin: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d",
+			},
+		}, res)
+	})
+
+	t.Run("Testing releasePayloadImages - should fail when a version cannot be resolved and return the resolved ones", func(t *testing.T) {
+		failCfg := cfg
+		failCfg.Mirror.Platform.Releases = append([]v2alpha1.ReleasePayload{{Version: "4.16.4"}}, cfg.Mirror.Platform.Releases...)
+		sch := &CincinnatiSchema{Log: log, Config: &failCfg, Opts: opts, Manifest: &MockManifest{Log: log, FailDigest: true}}
+		res, err := sch.releasePayloadImages(context.Background())
+		assert.ErrorContains(t, err, "unable to resolve release 4.16.4 (amd64)")
+		assert.ErrorContains(t, err, "unable to resolve release 4.16.3 (amd64)")
+		assert.Equal(t, []v2alpha1.CopyImageSchema{
+			{
+				Source: "quay.io/openshift-release-dev/ocp-release@sha256:9d2b5c5f1a0b3e4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c",
+				Origin: "quay.io/openshift-release-dev/ocp-release@sha256:9d2b5c5f1a0b3e4c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c",
+			},
+			{
+				Source: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d",
+				Origin: "quay.io/openshift-release-dev/ocp-release@sha256:0e4b8c1cd6b7b7a6d8e5a5b8e8f1b9a2d7c8e4f1a2b3c4d5e6f708192a3b4c5d",
+			},
+		}, res)
+	})
+}
@@ -1,13 +1,19 @@
 package release
 
+import "time"
+
 const (
-	graphBaseImage                 = "registry.access.redhat.com/ubi9/ubi:latest"
 	graphURL                       = "https://api.openshift.com/api/upgrades_info/graph-data"
 	graphArchive                   = "cincinnati-graph-data.tar"
 	graphPreparationDir            = "graph-preparation"
 	buildGraphDataDir              = "/var/lib/cincinnati-graph-data"
 	graphDataMountPath             = "/var/lib/cincinnati/graph-data"
 	graphImageName                 = "openshift/graph-image"
+	graphCopyHelperPath            = "/graph-data-copier"
+	graphImageTag                  = "latest"
+	graphImageTrimmedTag           = "trimmed"
+	graphChannelsDir               = "channels"
+	graphBlockedEdgesDir           = "blocked-edges"
 	indexJson                      = "manifest.json"
 	operatorImageExtractDir        = "hold-operator"
 	workingDir                     = "working-dir"
@@ -26,6 +32,15 @@ const (
 	releaseBootableImagesFullPath  = releaseManifests + "/" + releaseBootableImages
 	imageReferences                = "image-references"
 	releaseImageExtractFullPath    = releaseManifests + "/" + imageReferences
+	releaseMetadata                = "release-metadata"
+	releaseMetadataFullPath        = releaseManifests + "/" + releaseMetadata
+	eusUpgradePathsDir             = "eus-upgrade-paths"
+	bootImagesDir                  = "boot-images"
+	bootImagesPreparationDir       = "boot-images-preparation"
+	bootImagesPartialSuffix        = ".part"
+	bootImagesHandshakeTimeout     = 10 * time.Second
+	bootImagesResponseTimeout      = time.Minute
+	bootImagesImageName            = "openshift/rhcos-boot-images"
 	blobsDir                       = "blobs/sha256"
 	collectorPrefix                = "[ReleaseImageCollector] "
 	errMsg                         = collectorPrefix + "%s"
@@ -33,3 +48,6 @@ const (
 	releaseImagePathComponents     = "openshift/release-images"
 	releaseComponentPathComponents = "openshift/release"
 )
+
+// bootImagesIdleTimeout cancels the download of a boot artifact when no data is received for its duration
+var bootImagesIdleTimeout = time.Minute
@@ -845,6 +845,64 @@ func getHandlerMulti(t *testing.T, requestQuery chan<- string) http.HandlerFunc
 				t.Fatal(err)
 				return
 			}
+		case ch == "eus-4.2":
+			_, err := w.Write([]byte(`{
+				"nodes": [
+				{
+					"version": "4.0.1",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.0.1"
+				},
+				{
+					"version": "4.0.2",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.0.2"
+				},
+				{
+					"version": "4.1.0",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.1.0"
+				},
+				{
+					"version": "4.1.1",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.1.1"
+				},
+				{
+					"version": "4.2.0",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.2.0"
+				},
+				{
+					"version": "4.2.1",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.2.1"
+				}
+				],
+				"edges": [[0,1],[0,2],[1,3],[2,3],[3,4],[3,5],[4,5]]
+			}`))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Fatal(err)
+				return
+			}
+		case ch == "eus-4.4":
+			_, err := w.Write([]byte(`{
+				"nodes": [
+				{
+					"version": "4.2.1",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.2.1"
+				},
+				{
+					"version": "4.3.0",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.3.0"
+				},
+				{
+					"version": "4.4.0",
+					"payload": "quay.io/openshift-release-dev/ocp-release:4.4.0"
+				}
+				],
+				"edges": [[0,1],[1,2]]
+			}`))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Fatal(err)
+				return
+			}
 		default:
 			t.Fail()
 		}
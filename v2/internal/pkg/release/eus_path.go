@@ -0,0 +1,115 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// eusPath is the EUS-to-EUS update path written to the working-dir
+type eusPath struct {
+	Channel  string           `json:"channel"`
+	Arch     string           `json:"arch"`
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Releases []eusPathRelease `json:"releases"`
+}
+
+type eusPathRelease struct {
+	Version string `json:"version"`
+	Image   string `json:"image"`
+	// ControlPlaneOnly is set for the releases of the odd intermediate minors:
+	// only the control plane is updated to them, while the worker pools are paused
+	ControlPlaneOnly bool `json:"controlPlaneOnly"`
+}
+
+// getEUSPathDownloads returns the minimal releases for an EUS-to-EUS update,
+// from the minimum to the maximum version of the channel.
+// The update path goes through the EUS channel of each EUS minor in between,
+// and stops when the starting version of a hop is blocked in its channel.
+func getEUSPathDownloads(ctx context.Context, cs CincinnatiSchema, channel v2alpha1.ReleaseChannel) ([]v2alpha1.CopyImageSchema, error) {
+	first, err := semver.Parse(channel.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	last, err := semver.Parse(channel.MaxVersion)
+	if err != nil {
+		return nil, err
+	}
+	_, _, prefix, err := getSemverFromChannels(channel.Name, channel.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	start := first
+	for minor := first.Minor + 2; minor <= last.Minor; minor += 2 {
+		hopChannel := channel.Name
+		hopTarget := last
+		if minor < last.Minor {
+			hopChannel = fmt.Sprintf("%s-%v.%v", prefix, last.Major, minor)
+			hopTarget, err = GetChannelMinOrMax(ctx, cs, hopChannel, false)
+			if err != nil {
+				return nil, fmt.Errorf(ChannelInfo, hopChannel, err)
+			}
+		}
+
+		isBlocked, err := handleBlockedEdges(ctx, cs, hopChannel, start)
+		if err != nil {
+			return nil, fmt.Errorf(ChannelInfo, hopChannel, err)
+		}
+		if isBlocked {
+			return nil, fmt.Errorf("no EUS-to-EUS upgrade path for %s in channel %s", start.String(), hopChannel)
+		}
+		cs.Log.Debug("Getting EUS-to-EUS updates from %s to %s in channel %s", start.String(), hopTarget.String(), hopChannel)
+		_, _, hopUpdates, err := GetUpdates(ctx, cs, hopChannel, start, hopTarget)
+		if err != nil {
+			return nil, fmt.Errorf(ChannelInfo, hopChannel, err)
+		}
+		if len(hopUpdates) == 0 {
+			return nil, fmt.Errorf("no EUS-to-EUS upgrade path from %s to %s in channel %s", start.String(), hopTarget.String(), hopChannel)
+		}
+		// the first release of a hop is the last release of the previous one
+		if len(updates) > 0 {
+			hopUpdates = hopUpdates[1:]
+		}
+		updates = append(updates, hopUpdates...)
+		start = hopTarget
+	}
+
+	path := eusPath{Channel: channel.Name, Arch: cs.CincinnatiParams.Arch, From: first.String(), To: last.String()}
+	for _, update := range updates {
+		controlPlaneOnly := update.Version.Minor%2 == 1 && update.Version.Minor > first.Minor && update.Version.Minor < last.Minor
+		if controlPlaneOnly {
+			cs.Log.Info("EUS-to-EUS upgrade path %s: %s (control plane only)", channel.Name, update.Version.String())
+		} else {
+			cs.Log.Info("EUS-to-EUS upgrade path %s: %s", channel.Name, update.Version.String())
+		}
+		path.Releases = append(path.Releases, eusPathRelease{Version: update.Version.String(), Image: update.Image, ControlPlaneOnly: controlPlaneOnly})
+	}
+	if err := writeEUSPath(cs.Opts.Global.WorkingDir, path); err != nil {
+		return nil, err
+	}
+
+	return gatherUpdates(cs.Log, Update{}, Update{}, updates), nil
+}
+
+// writeEUSPath writes the EUS-to-EUS update path to working-dir/eus-upgrade-paths/<channel>-<arch>.yaml,
+// as each architecture of the platform has its own update path
+func writeEUSPath(workingDir string, path eusPath) error {
+	content, err := yaml.Marshal(path)
+	if err != nil {
+		return err
+	}
+	pathFile := filepath.Join(workingDir, eusUpgradePathsDir, path.Channel+"-"+path.Arch+".yaml")
+	if err := os.MkdirAll(filepath.Dir(pathFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(pathFile, content, 0644)
+}
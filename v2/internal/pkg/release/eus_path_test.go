@@ -0,0 +1,109 @@
+package release
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestGetEUSPathDownloads(t *testing.T) {
+	requestQuery := make(chan string, 1)
+	defer close(requestQuery)
+	ts := httptest.NewServer(getHandlerMulti(t, requestQuery))
+	t.Cleanup(ts.Close)
+	endpoint, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+
+	newSchema := func(t *testing.T) CincinnatiSchema {
+		return CincinnatiSchema{
+			Log:              clog.New("trace"),
+			Client:           &mockClient{url: endpoint},
+			Opts:             mirror.CopyOptions{Mode: mirror.MirrorToDisk, Global: &mirror.GlobalOptions{WorkingDir: t.TempDir()}},
+			CincinnatiParams: CincinnatiParams{Arch: "test-arch", GraphDataDir: t.TempDir()},
+		}
+	}
+	readPath := func(t *testing.T, cs CincinnatiSchema, channel string) eusPath {
+		content, err := os.ReadFile(filepath.Join(cs.Opts.Global.WorkingDir, eusUpgradePathsDir, channel+"-"+cs.CincinnatiParams.Arch+".yaml"))
+		assert.NoError(t, err)
+		var path eusPath
+		assert.NoError(t, yaml.Unmarshal(content, &path))
+		return path
+	}
+
+	t.Run("Testing getEUSPathDownloads - should select the minimal releases of the channel", func(t *testing.T) {
+		cs := newSchema(t)
+		images, err := getEUSPathDownloads(context.Background(), cs, v2alpha1.ReleaseChannel{Name: "eus-4.2", MinVersion: "4.0.1", MaxVersion: "4.2.1", EUSPath: true})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []v2alpha1.CopyImageSchema{
+			{Source: "quay.io/openshift-release-dev/ocp-release:4.0.1"},
+			{Source: "quay.io/openshift-release-dev/ocp-release:4.1.0"},
+			{Source: "quay.io/openshift-release-dev/ocp-release:4.1.1"},
+			{Source: "quay.io/openshift-release-dev/ocp-release:4.2.1"},
+		}, images)
+		assert.Equal(t, eusPath{Channel: "eus-4.2", Arch: "test-arch", From: "4.0.1", To: "4.2.1", Releases: []eusPathRelease{
+			{Version: "4.0.1", Image: "quay.io/openshift-release-dev/ocp-release:4.0.1"},
+			{Version: "4.1.0", Image: "quay.io/openshift-release-dev/ocp-release:4.1.0", ControlPlaneOnly: true},
+			{Version: "4.1.1", Image: "quay.io/openshift-release-dev/ocp-release:4.1.1", ControlPlaneOnly: true},
+			{Version: "4.2.1", Image: "quay.io/openshift-release-dev/ocp-release:4.2.1"},
+		}}, readPath(t, cs, "eus-4.2"))
+	})
+
+	t.Run("Testing getEUSPathDownloads - should go through the intermediate EUS channels", func(t *testing.T) {
+		cs := newSchema(t)
+		images, err := getEUSPathDownloads(context.Background(), cs, v2alpha1.ReleaseChannel{Name: "eus-4.4", MinVersion: "4.0.2", MaxVersion: "4.4.0", EUSPath: true})
+		assert.NoError(t, err)
+		assert.Len(t, images, 5)
+		path := readPath(t, cs, "eus-4.4")
+		versions := []string{}
+		controlPlaneOnly := []string{}
+		for _, release := range path.Releases {
+			versions = append(versions, release.Version)
+			if release.ControlPlaneOnly {
+				controlPlaneOnly = append(controlPlaneOnly, release.Version)
+			}
+		}
+		assert.Equal(t, []string{"4.0.2", "4.1.1", "4.2.1", "4.3.0", "4.4.0"}, versions)
+		assert.Equal(t, []string{"4.1.1", "4.3.0"}, controlPlaneOnly)
+	})
+
+	t.Run("Testing getEUSPathDownloads - should fail when the starting version is blocked", func(t *testing.T) {
+		cs := newSchema(t)
+		_, err := getEUSPathDownloads(context.Background(), cs, v2alpha1.ReleaseChannel{Name: "eus-4.4", MinVersion: "4.2.0", MaxVersion: "4.4.0", EUSPath: true})
+		assert.EqualError(t, err, "no EUS-to-EUS upgrade path for 4.2.0 in channel eus-4.4")
+		_, err = os.Stat(filepath.Join(cs.Opts.Global.WorkingDir, eusUpgradePathsDir, "eus-4.4-test-arch.yaml"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Testing getEUSPathDownloads - should write one update path per architecture", func(t *testing.T) {
+		cs := newSchema(t)
+		channel := v2alpha1.ReleaseChannel{Name: "eus-4.2", MinVersion: "4.0.1", MaxVersion: "4.2.1", EUSPath: true}
+		for _, arch := range []string{"amd64", "arm64"} {
+			cs.CincinnatiParams.Arch = arch
+			_, err := getEUSPathDownloads(context.Background(), cs, channel)
+			assert.NoError(t, err)
+		}
+		entries, err := os.ReadDir(filepath.Join(cs.Opts.Global.WorkingDir, eusUpgradePathsDir))
+		assert.NoError(t, err)
+		files := []string{}
+		for _, entry := range entries {
+			files = append(files, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"eus-4.2-amd64.yaml", "eus-4.2-arm64.yaml"}, files)
+		for _, arch := range []string{"amd64", "arm64"} {
+			cs.CincinnatiParams.Arch = arch
+			path := readPath(t, cs, "eus-4.2")
+			assert.Equal(t, arch, path.Arch)
+			assert.Len(t, path.Releases, 4)
+		}
+	})
+}
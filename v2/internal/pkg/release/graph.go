@@ -2,13 +2,20 @@ package release
 
 import (
 	"context"
+	"debug/elf"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/containers/image/v5/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/otiai10/copy"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/imagebuilder"
 )
 
@@ -28,6 +35,16 @@ func (o *LocalStorageCollector) CreateGraphImage(ctx context.Context, url string
 		return "", err
 	}
 
+	if o.Config.Mirror.Platform.TrimGraph {
+		if len(o.releaseVersions) == 0 {
+			return "", fmt.Errorf("no release collected: unable to trim the graph data")
+		}
+		body, err = trimGraphData(body, o.releaseVersions, o.Config.Mirror.Platform.GraphLocalChannel)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	// save graph data in a container layer modifying UID and GID to root.
 	archiveDestination := filepath.Join(o.Opts.Global.WorkingDir, graphArchive)
 	graphLayer, err := imagebuilder.LayerFromGzipByteArray(body, archiveDestination, buildGraphDataDir, 0644, 0, 0)
@@ -36,30 +53,92 @@ func (o *LocalStorageCollector) CreateGraphImage(ctx context.Context, url string
 	}
 	defer os.Remove(archiveDestination)
 
-	// Create a local directory for saving the OCI image layout of UBI9
+	// Create a local directory for saving the OCI image layout of the base image
 	layoutDir := filepath.Join(o.Opts.Global.WorkingDir, graphPreparationDir)
+	if err := os.RemoveAll(layoutDir); err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(layoutDir, os.ModePerm); err != nil {
 		return "", err
 	}
 
-	// Use the imgBuilder to pull the ubi9 image to layoutDir
-	layoutPath, err := o.ImageBuilder.SaveImageLayoutToDir(ctx, graphBaseImage, layoutDir)
+	layoutPath, err := o.graphBaseImageLayout(ctx, layoutDir)
 	if err != nil {
 		return "", err
 	}
 
+	layers := []v1.Layer{graphLayer}
 	// preprare the CMD to []string{"/bin/bash", "-c", fmt.Sprintf("exec cp -rp %s/* %s", graphDataDir, graphDataMountPath)}
 	cmd := []string{"/bin/bash", "-c", fmt.Sprintf("exec cp -rp %s/* %s", buildGraphDataDir, graphDataMountPath)}
+	if o.Config.Mirror.Platform.GetGraphBaseImage() == v2alpha1.ScratchGraphBaseImage {
+		// there is no shell in a scratch image: the copy is done by the helper
+		helperLayer, err := imagebuilder.LayerFromFile(graphCopyHelperPath, o.Config.Mirror.Platform.GraphCopyHelper, 0755)
+		if err != nil {
+			return "", fmt.Errorf("unable to add graph copy helper %s: %v", o.Config.Mirror.Platform.GraphCopyHelper, err)
+		}
+		layers = append(layers, helperLayer)
+		cmd = []string{graphCopyHelperPath, buildGraphDataDir, graphDataMountPath}
+	}
 
-	// update a ubi9 image with this new graphLayer and new cmd
-	graphImageRef := filepath.Join(o.destinationRegistry(), graphImageName) + ":latest"
-	_, err = o.ImageBuilder.BuildAndPush(ctx, graphImageRef, layoutPath, cmd, graphLayer)
+	// update the base image with this new graphLayer and new cmd
+	graphImageRef := filepath.Join(o.destinationRegistry(), graphImageName) + ":" + o.graphImageTag()
+	_, err = o.ImageBuilder.BuildAndPush(ctx, graphImageRef, layoutPath, cmd, layers...)
 	if err != nil {
 		return "", err
 	}
 	return dockerProtocol + graphImageRef, nil
 }
 
+// graphBaseImageLayout saves in layoutDir the OCI layout of the base image of the graph image,
+// as configured in platform.graphBaseImage.
+func (o *LocalStorageCollector) graphBaseImageLayout(ctx context.Context, layoutDir string) (layout.Path, error) {
+	platform := o.Config.Mirror.Platform
+	baseImage := platform.GetGraphBaseImage()
+	switch {
+	case baseImage == v2alpha1.ScratchGraphBaseImage:
+		arch, err := staticExecutableArch(platform.GraphCopyHelper)
+		if err != nil {
+			return "", fmt.Errorf("graph copy helper %s: %v", platform.GraphCopyHelper, err)
+		}
+		return imagebuilder.ScratchImageLayout(layoutDir, v1.Platform{OS: "linux", Architecture: arch})
+	case strings.HasPrefix(baseImage, ociProtocolTrimmed):
+		ociLayout := strings.TrimPrefix(strings.TrimPrefix(baseImage, ociProtocol), ociProtocolTrimmed)
+		// the layout is modified when building the graph image: work on a copy
+		if err := copy.Copy(ociLayout, layoutDir); err != nil {
+			return "", fmt.Errorf("unable to copy graph base image %s: %v", baseImage, err)
+		}
+		return layout.FromPath(layoutDir)
+	default:
+		return o.ImageBuilder.SaveImageLayoutToDir(ctx, strings.TrimPrefix(baseImage, dockerProtocol), layoutDir)
+	}
+}
+
+// staticExecutableArch returns the architecture of the statically linked executable found at path.
+func staticExecutableArch(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return "", fmt.Errorf("executable is not statically linked")
+		}
+	}
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64", nil
+	case elf.EM_AARCH64:
+		return "arm64", nil
+	case elf.EM_PPC64:
+		return "ppc64le", nil
+	case elf.EM_S390:
+		return "s390x", nil
+	default:
+		return "", fmt.Errorf("unsupported architecture %s", f.Machine)
+	}
+}
+
 func (o *LocalStorageCollector) graphImageInWorkingDir(ctx context.Context) (string, error) {
 	layoutDir := filepath.Join(o.Opts.Global.WorkingDir, graphPreparationDir)
 	graphImageRef := ociProtocol + layoutDir
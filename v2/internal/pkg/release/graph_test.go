@@ -2,7 +2,11 @@ package release
 
 import (
 	"context"
+	"debug/elf"
+	"encoding/binary"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -11,6 +15,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
 )
 
 type mockImageBuilder struct {
@@ -193,6 +198,94 @@ func TestCreateGraphImage(t *testing.T) {
 
 }
 
+func TestGraphBaseImageLayout(t *testing.T) {
+	log := clog.New("trace")
+	ctx := context.Background()
+
+	// minimal statically linked x86_64 ELF executable header
+	staticHelper := filepath.Join(t.TempDir(), "graph-data-copier")
+	elfHeader := make([]byte, 64)
+	copy(elfHeader, []byte{0x7f, 'E', 'L', 'F', 2, 1, 1})
+	binary.LittleEndian.PutUint16(elfHeader[16:], uint16(elf.ET_EXEC))
+	binary.LittleEndian.PutUint16(elfHeader[18:], uint16(elf.EM_X86_64))
+	binary.LittleEndian.PutUint32(elfHeader[20:], uint32(elf.EV_CURRENT))
+	binary.LittleEndian.PutUint16(elfHeader[52:], 64)
+	if err := os.WriteFile(staticHelper, elfHeader, 0755); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+
+	newCollector := func(platform v2alpha1.Platform) *LocalStorageCollector {
+		return &LocalStorageCollector{
+			Log: log,
+			Config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{Platform: platform},
+				},
+			},
+			ImageBuilder: &mockImageBuilder{},
+		}
+	}
+
+	t.Run("Testing graphBaseImageLayout - scratch: should pass", func(t *testing.T) {
+		ex := newCollector(v2alpha1.Platform{Graph: true, GraphBaseImage: v2alpha1.ScratchGraphBaseImage, GraphCopyHelper: staticHelper})
+		layoutPath, err := ex.graphBaseImageLayout(ctx, t.TempDir())
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		idx, err := layoutPath.ImageIndex()
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		idxManifest, err := idx.IndexManifest()
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Len(t, idxManifest.Manifests, 1)
+		assert.Equal(t, "amd64", idxManifest.Manifests[0].Platform.Architecture)
+		img, err := idx.Image(idxManifest.Manifests[0].Digest)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Empty(t, layers)
+	})
+
+	t.Run("Testing graphBaseImageLayout - scratch with dynamically linked helper: should fail", func(t *testing.T) {
+		ex := newCollector(v2alpha1.Platform{Graph: true, GraphBaseImage: v2alpha1.ScratchGraphBaseImage, GraphCopyHelper: "/bin/sh"})
+		_, err := ex.graphBaseImageLayout(ctx, t.TempDir())
+		assert.ErrorContains(t, err, "executable is not statically linked")
+	})
+
+	t.Run("Testing graphBaseImageLayout - oci layout: should pass", func(t *testing.T) {
+		ociLayout, err := filepath.Abs(common.TestFolder + "test-untar")
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		layoutDir := t.TempDir()
+		ex := newCollector(v2alpha1.Platform{Graph: true, GraphBaseImage: ociProtocolTrimmed + ociLayout})
+		layoutPath, err := ex.graphBaseImageLayout(ctx, layoutDir)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		// the layout is copied, so that the original one is not modified
+		assert.Equal(t, layout.Path(layoutDir), layoutPath)
+		_, err = os.Stat(filepath.Join(layoutDir, "index.json"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Testing graphBaseImageLayout - default: should pass", func(t *testing.T) {
+		ex := newCollector(v2alpha1.Platform{Graph: true})
+		layoutPath, err := ex.graphBaseImageLayout(ctx, t.TempDir())
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		assert.Equal(t, layout.Path(common.TestFolder+"test-untar"), layoutPath)
+	})
+}
+
 func (o mockImageBuilder) BuildAndPush(ctx context.Context, targetRef string, layoutPath layout.Path, cmd []string, layers ...v1.Layer) (string, error) {
 	if o.Fail {
 		return "", fmt.Errorf("forced error")
@@ -0,0 +1,185 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"sigs.k8s.io/yaml"
+)
+
+// cincinnatiMetadata is the release-metadata file of a release payload
+type cincinnatiMetadata struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+// releaseVersion returns the version of the release extracted in releaseExtractDir
+func releaseVersion(releaseExtractDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(releaseExtractDir, releaseMetadataFullPath))
+	if err != nil {
+		return "", err
+	}
+	metadata := cincinnatiMetadata{}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return "", err
+	}
+	if metadata.Version == "" {
+		return "", fmt.Errorf("no version found in %s", releaseMetadataFullPath)
+	}
+	return metadata.Version, nil
+}
+
+// trimGraphData rewrites the gzipped Cincinnati graph data archive, so that
+// its channels and its blocked edges only reference the mirrored versions.
+// When localChannel is set, a channel with this name, listing all the mirrored versions, is added.
+func trimGraphData(content []byte, versions []string, localChannel string) ([]byte, error) {
+	mirrored := map[string]bool{}
+	for _, version := range versions {
+		mirrored[baseVersion(version)] = true
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	channelsDir := graphChannelsDir
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && path.Ext(header.Name) == ".yaml" {
+			switch path.Base(path.Dir(header.Name)) {
+			case graphChannelsDir:
+				channelsDir = path.Dir(header.Name)
+				if localChannel != "" && path.Base(header.Name) == localChannel+".yaml" {
+					// replaced by the local channel
+					continue
+				}
+				data, err = trimChannel(data, mirrored)
+			case graphBlockedEdgesDir:
+				data, err = trimBlockedEdge(data, mirrored)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to trim graph data %s: %v", header.Name, err)
+			}
+			if data == nil {
+				continue
+			}
+			header.Size = int64(len(data))
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if localChannel != "" {
+		data, err := yaml.Marshal(map[string]interface{}{
+			"name":     localChannel,
+			"versions": sortVersions(versions),
+		})
+		if err != nil {
+			return nil, err
+		}
+		header := &tar.Header{
+			Name:     path.Join(channelsDir, localChannel+".yaml"),
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// trimChannel keeps the mirrored versions of a channel file, and returns nil when none are left
+func trimChannel(data []byte, mirrored map[string]bool) ([]byte, error) {
+	channel := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &channel); err != nil {
+		return nil, err
+	}
+	versions, _ := channel["versions"].([]interface{})
+	trimmed := []interface{}{}
+	for _, version := range versions {
+		if mirrored[baseVersion(fmt.Sprint(version))] {
+			trimmed = append(trimmed, version)
+		}
+	}
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	channel["versions"] = trimmed
+	return yaml.Marshal(channel)
+}
+
+// trimBlockedEdge returns nil for blocked edges to versions that are not mirrored
+func trimBlockedEdge(data []byte, mirrored map[string]bool) ([]byte, error) {
+	edge := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &edge); err != nil {
+		return nil, err
+	}
+	if to, ok := edge["to"]; ok && !mirrored[baseVersion(fmt.Sprint(to))] {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// baseVersion removes the build metadata (architecture) of a version, such as 4.16.1+amd64
+func baseVersion(version string) string {
+	return strings.SplitN(version, "+", 2)[0]
+}
+
+func sortVersions(versions []string) []string {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int {
+		va, errA := semver.ParseTolerant(a)
+		vb, errB := semver.ParseTolerant(b)
+		if errA != nil || errB != nil {
+			return strings.Compare(a, b)
+		}
+		return va.Compare(vb)
+	})
+	return slices.Compact(sorted)
+}
@@ -0,0 +1,101 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestTrimGraphData(t *testing.T) {
+	graphData := map[string]string{
+		"version":                        "1.0.0\n",
+		"channels/stable-4.15.yaml":      "name: stable-4.15\nversions:\n- 4.14.10\n- 4.15.0\n- 4.15.1\n",
+		"channels/fast-4.16.yaml":        "name: fast-4.16\nversions:\n- 4.16.0\n",
+		"blocked-edges/4.15.0-bug.yaml":  "to: 4.15.0\nfrom: 4\\.14\\..*\n",
+		"blocked-edges/4.16.0-bug.yaml":  "to: 4.16.0\nfrom: 4\\.15\\..*\n",
+		"raw/metadata.json":              "{}\n",
+		"build-suggestions/4.15.yaml":    "default:\n  minor_min: 4.14.0\n",
+		"channels/mirrored-release.yaml": "name: mirrored-release\nversions:\n- 4.12.0\n",
+	}
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range []string{"version", "channels/stable-4.15.yaml", "channels/fast-4.16.yaml", "blocked-edges/4.15.0-bug.yaml", "blocked-edges/4.16.0-bug.yaml", "raw/metadata.json", "build-suggestions/4.15.yaml", "channels/mirrored-release.yaml"} {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(graphData[name])), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte(graphData[name]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	readArchive := func(t *testing.T, content []byte) map[string]string {
+		files := map[string]string{}
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		assert.NoError(t, err)
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			data, err := io.ReadAll(tarReader)
+			assert.NoError(t, err)
+			files[header.Name] = string(data)
+		}
+		return files
+	}
+
+	t.Run("Testing trimGraphData - should only keep mirrored releases", func(t *testing.T) {
+		trimmed, err := trimGraphData(archive.Bytes(), []string{"4.15.1", "4.15.0"}, "")
+		assert.NoError(t, err)
+		files := readArchive(t, trimmed)
+
+		channel := map[string]interface{}{}
+		assert.NoError(t, yaml.Unmarshal([]byte(files["channels/stable-4.15.yaml"]), &channel))
+		assert.Equal(t, []interface{}{"4.15.0", "4.15.1"}, channel["versions"])
+		assert.NotContains(t, files, "channels/fast-4.16.yaml")
+		assert.NotContains(t, files, "channels/mirrored-release.yaml")
+		assert.Equal(t, graphData["blocked-edges/4.15.0-bug.yaml"], files["blocked-edges/4.15.0-bug.yaml"])
+		assert.NotContains(t, files, "blocked-edges/4.16.0-bug.yaml")
+		assert.Equal(t, graphData["raw/metadata.json"], files["raw/metadata.json"])
+		assert.Equal(t, graphData["build-suggestions/4.15.yaml"], files["build-suggestions/4.15.yaml"])
+		assert.Equal(t, graphData["version"], files["version"])
+	})
+
+	t.Run("Testing trimGraphData - should add the local channel", func(t *testing.T) {
+		trimmed, err := trimGraphData(archive.Bytes(), []string{"4.16.0", "4.15.1", "4.15.0", "4.16.0"}, "mirrored-release")
+		assert.NoError(t, err)
+		files := readArchive(t, trimmed)
+
+		channel := map[string]interface{}{}
+		assert.NoError(t, yaml.Unmarshal([]byte(files["channels/mirrored-release.yaml"]), &channel))
+		assert.Equal(t, "mirrored-release", channel["name"])
+		assert.Equal(t, []interface{}{"4.15.0", "4.15.1", "4.16.0"}, channel["versions"])
+		assert.Contains(t, files, "channels/fast-4.16.yaml")
+	})
+
+	t.Run("Testing trimGraphData - invalid archive : should fail", func(t *testing.T) {
+		_, err := trimGraphData([]byte("not an archive"), []string{"4.15.0"}, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestReleaseVersion(t *testing.T) {
+	t.Run("Testing releaseVersion - should pass", func(t *testing.T) {
+		version, err := releaseVersion(common.TestFolder + "working-dir-fake/hold-release/ocp-release/4.14.1-x86_64")
+		assert.NoError(t, err)
+		assert.Equal(t, "4.12.0", version)
+	})
+
+	t.Run("Testing releaseVersion - no release metadata : should fail", func(t *testing.T) {
+		_, err := releaseVersion(t.TempDir())
+		assert.Error(t, err)
+	})
+}
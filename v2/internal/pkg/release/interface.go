@@ -23,6 +23,13 @@ type CollectorInterface interface {
 	// This works because oc-mirror doesn't know how to mix OKD and OCP
 	// release mirroring.
 	ReleaseImage(context.Context) (string, error)
+	// Returns the release image installed by the install-config snippet:
+	// the only release mirrored, or the one matching the selector
+	// (a version, an architecture or both), failing when ambiguous.
+	InstallReleaseImage(ctx context.Context, selector string) (string, error)
+	// Publishes the boot artifacts of the working-dir, as configured
+	// in platform.bootImages, on the mirror side
+	PublishBootImages(context.Context) error
 }
 
 type GraphBuilderInterface interface {
@@ -19,7 +19,6 @@ import (
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
-	"gopkg.in/yaml.v2"
 )
 
 type LocalStorageCollector struct {
@@ -36,6 +35,8 @@ type LocalStorageCollector struct {
 	Releases         []string
 	GraphDataImage   string
 	destReg          string
+	// versions of the releases collected, used to trim the graph data
+	releaseVersions []string
 }
 
 func (o LocalStorageCollector) destinationRegistry() string {
@@ -131,6 +132,14 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 			}
 			o.Log.Debug("extracted layer %s ", cacheDir)
 
+			if o.Config.Mirror.Platform.TrimGraph {
+				version, err := releaseVersion(cacheDir)
+				if err != nil {
+					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(collectorPrefix+"unable to read the version of release %s: %v", value.Source, err)
+				}
+				o.releaseVersions = append(o.releaseVersions, version)
+			}
+
 			// overkill but its used for consistency
 			releaseDir := strings.Join([]string{cacheDir, releaseImageExtractFullPath}, "/")
 			allRelatedImages, err := o.Manifest.GetReleaseSchema(releaseDir)
@@ -149,6 +158,13 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				}
 			}
 
+			// the boot artifacts are stored in the working-dir, and carried by the archive
+			if o.Config.Mirror.Platform.BootImages != nil {
+				if err := o.collectBootImages(ctx, cacheDir); err != nil {
+					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(collectorPrefix+"release %s: %v", value.Source, err)
+				}
+			}
+
 			//add the release image itself
 			allRelatedImages = append(allRelatedImages, v2alpha1.RelatedImage{Image: value.Source, Name: value.Source, Type: v2alpha1.TypeOCPRelease})
 			tmpAllImages, err := o.prepareM2DCopyBatch(allRelatedImages, releaseTag)
@@ -237,7 +253,7 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				// Supposing that the mirror to disk saved the image with the latest tag
 				// If this supposition is false, then we need to implement a mechanism to save
 				// the digest of the graph image and use it here
-				Image: dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest",
+				Image: dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":" + o.graphImageTag(),
 				Type:  v2alpha1.TypeCincinnatiGraph,
 			}
 			// OCPBUGS-38037: Check the graph image is in the cache before adding it
@@ -379,12 +395,21 @@ func (o LocalStorageCollector) identifyReleases(ctx context.Context) ([]v2alpha1
 	return releaseImages, releaseFolders, nil
 }
 
+// graphImageTag returns the tag of the graph image: the trimmed graph image
+// only describes the mirrored releases, and does not replace the complete one.
+func (o LocalStorageCollector) graphImageTag() string {
+	if o.Config.Mirror.Platform.TrimGraph {
+		return graphImageTrimmedTag
+	}
+	return graphImageTag
+}
+
 // assumes this is called during DiskToMirror workflow.
 // this method doesn't verify if the graphImage has been generated
 // by the collector.
 func (o *LocalStorageCollector) GraphImage() (string, error) {
 	if o.GraphDataImage == "" {
-		sourceGraphDataImage := filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest"
+		sourceGraphDataImage := filepath.Join(o.LocalStorageFQDN, graphImageName) + ":" + o.graphImageTag()
 		graphRelatedImage := []v2alpha1.RelatedImage{
 			{
 				Name:  "release",
@@ -406,60 +431,113 @@ func (o *LocalStorageCollector) GraphImage() (string, error) {
 // to get the list of releases to mirror (saved during mirrorToDisk
 // after the call to cincinnati API)
 func (o *LocalStorageCollector) ReleaseImage(ctx context.Context) (string, error) {
+	releases, err := o.mirroredReleases(ctx)
+	if err != nil {
+		return "", err
+	}
+	return o.releaseDestination(releases[0])
+}
+
+// ErrSeveralReleases is returned by InstallReleaseImage when several releases are mirrored
+// and none is selected
+var ErrSeveralReleases = errors.New("[release collector] several releases are mirrored")
+
+// InstallReleaseImage returns the destination of the release installed by the install-config snippet:
+// the only release mirrored, or the one matching selector, which is a version (4.16.3),
+// an architecture (x86_64), both (4.16.3-x86_64) or the release image itself.
+// It fails with ErrSeveralReleases when several releases are mirrored and selector is empty,
+// and when selector is ambiguous or matches no mirrored release.
+func (o *LocalStorageCollector) InstallReleaseImage(ctx context.Context, selector string) (string, error) {
+	releases, err := o.mirroredReleases(ctx)
+	if err != nil {
+		return "", err
+	}
+	candidates := releases
+	if selector != "" {
+		candidates = []string{}
+		for _, release := range releases {
+			if matchesRelease(release, selector) {
+				candidates = append(candidates, release)
+			}
+		}
+	}
+	switch {
+	case len(candidates) == 1:
+		return o.releaseDestination(candidates[0])
+	case len(candidates) == 0:
+		return "", fmt.Errorf("[release collector] release %s is not mirrored, mirrored releases: %s", selector, strings.Join(releases, ", "))
+	case selector == "":
+		return "", fmt.Errorf("%w (%s): select the one installed with --install-release", ErrSeveralReleases, strings.Join(releases, ", "))
+	default:
+		return "", fmt.Errorf("[release collector] several mirrored releases match %s (%s): select the one installed with --install-release", selector, strings.Join(candidates, ", "))
+	}
+}
+
+// matchesRelease returns true when the release image, tagged <version>-<architecture>,
+// matches the version, the architecture or both, or is the selected image
+func matchesRelease(release, selector string) bool {
+	if strings.TrimPrefix(release, dockerProtocol) == strings.TrimPrefix(selector, dockerProtocol) {
+		return true
+	}
+	imgSpec, err := image.ParseRef(release)
+	if err != nil || imgSpec.Tag == "" {
+		return false
+	}
+	return imgSpec.Tag == selector || strings.HasPrefix(imgSpec.Tag, selector+"-") || strings.HasSuffix(imgSpec.Tag, "-"+selector)
+}
+
+// mirroredReleases returns the release images of the run, or the ones saved during mirrorToDisk
+func (o *LocalStorageCollector) mirroredReleases(ctx context.Context) ([]string, error) {
 	if len(o.Releases) == 0 {
 		releaseImages, _, err := o.identifyReleases(ctx)
 		if err != nil {
-			return "", fmt.Errorf("[release collector] could not establish the destination for the release image: %v", err)
+			return nil, fmt.Errorf("[release collector] could not establish the destination for the release image: %v", err)
 		}
 		o.Releases = []string{}
 		for _, img := range releaseImages {
 			o.Releases = append(o.Releases, img.Image)
 		}
 	}
-	if len(o.Releases) > 0 {
-		releaseRelatedImage := []v2alpha1.RelatedImage{
-			{
-				Name:  "release",
-				Image: o.Releases[0],
-				Type:  v2alpha1.TypeOCPRelease,
-			},
-		}
-		releaseTag := o.Releases[0][:strings.LastIndex(o.Releases[0], ":")]
+	if len(o.Releases) == 0 {
+		return nil, fmt.Errorf("[release collector] could not establish the destination for the release image")
+	}
+	return o.Releases, nil
+}
 
-		releaseCopyImage, err := o.prepareD2MCopyBatch(releaseRelatedImage, releaseTag)
-		if err != nil {
-			return "", fmt.Errorf("[release collector] could not establish the destination for the release image: %v", err)
-		}
-		return releaseCopyImage[0].Destination, nil
+// releaseDestination returns the destination of a release image
+func (o *LocalStorageCollector) releaseDestination(release string) (string, error) {
+	releaseRelatedImage := []v2alpha1.RelatedImage{
+		{
+			Name:  "release",
+			Image: release,
+			Type:  v2alpha1.TypeOCPRelease,
+		},
+	}
+	releaseTag := release[:strings.LastIndex(release, ":")]
 
-	} else {
-		return "", fmt.Errorf("[release collector] could not establish the destination for the release image")
+	releaseCopyImage, err := o.prepareD2MCopyBatch(releaseRelatedImage, releaseTag)
+	if err != nil {
+		return "", fmt.Errorf("[release collector] could not establish the destination for the release image: %v", err)
 	}
+	return releaseCopyImage[0].Destination, nil
 }
 
 // getKubeVirtImage - CLID-179 : include coreos-bootable container image
 // if set it will be across the board for all releases
 func (o LocalStorageCollector) getKubeVirtImage(releaseArtifactsDir string) (v2alpha1.RelatedImage, error) {
 	var ibi v2alpha1.InstallerBootableImages
-	var icm v2alpha1.InstallerConfigMap
 
 	// parse the main yaml file
-	biFile := strings.Join([]string{releaseArtifactsDir, releaseBootableImagesFullPath}, "/")
-	file, err := os.ReadFile(biFile)
+	stream, err := readInstallerStream(releaseArtifactsDir)
 	if err != nil {
-		return v2alpha1.RelatedImage{}, fmt.Errorf("reading kubevirt yaml file %v", err)
-	}
-
-	errs := yaml.Unmarshal(file, &icm)
-	if errs != nil {
 		// this should not break the release process
 		// we just report the error and continue
-		return v2alpha1.RelatedImage{}, fmt.Errorf("marshalling kubevirt yaml file %v", errs)
+		return v2alpha1.RelatedImage{}, err
 	}
 
-	o.Log.Trace(fmt.Sprintf("data %v", icm.Data.Stream))
+	o.Log.Trace(fmt.Sprintf("data %v", string(stream)))
 	// now parse the json section
-	errs = json.Unmarshal([]byte(icm.Data.Stream), &ibi)
+	errs := json.Unmarshal(stream, &ibi)
 	if errs != nil {
 		// this should not break the release process
 		// we just report the error and continue
@@ -485,10 +563,10 @@ func (o LocalStorageCollector) handleGraphImage(ctx context.Context) (v2alpha1.C
 		// OCPBUGS-38037: this indicates that the official cincinnati API is not reacheable
 		// and that graph image cannot be rebuilt on top the complete graph in tar.gz format
 
-		graphImgRef := dockerProtocol + filepath.Join(o.destinationRegistry(), graphImageName) + ":latest"
+		graphImgRef := dockerProtocol + filepath.Join(o.destinationRegistry(), graphImageName) + ":" + o.graphImageTag()
 
 		// 1. check if graph image is already in cache
-		cachedImageRef := dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest"
+		cachedImageRef := dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":" + o.graphImageTag()
 		alreadyInCache, err := o.imageExists(ctx, cachedImageRef)
 		if err != nil {
 			o.Log.Warn("graph image not found in cache: %v", err)
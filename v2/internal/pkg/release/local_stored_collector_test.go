@@ -28,6 +28,7 @@ type MockManifest struct {
 	FailImageIndex    bool
 	FailImageManifest bool
 	FailExtract       bool
+	FailDigest        bool
 }
 
 type MockCincinnati struct {
@@ -197,7 +198,7 @@ func TestReleaseLocalStoredCollector(t *testing.T) {
 		client := &ocpClient{}
 		client.SetQueryParams(ex.Config.Mirror.Platform.Architectures[0], ex.Config.Mirror.Platform.Channels[0].Name, "")
 		sig := MockCincinnati{}
-		cn := NewCincinnati(ex.Log, &ex.Config, ex.Opts, client, false, sig)
+		cn := NewCincinnati(ex.Log, &ex.Config, ex.Opts, client, false, sig, &MockManifest{Log: ex.Log})
 
 		ex.Cincinnati = cn
 
@@ -293,6 +294,87 @@ func TestReleaseImage(t *testing.T) {
 	})
 }
 
+func TestInstallReleaseImage(t *testing.T) {
+	log := clog.New("trace")
+
+	tempDir := t.TempDir()
+	releases := []string{
+		"docker://quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64",
+		"docker://quay.io/openshift-release-dev/ocp-release:4.16.1-aarch64",
+		"docker://quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64",
+	}
+	type testCase struct {
+		caseName      string
+		releases      []string
+		selector      string
+		expectedTag   string
+		expectedError string
+	}
+	testCases := []testCase{
+		{
+			caseName:    "Testing InstallReleaseImage - single release: should pass",
+			releases:    releases[:1],
+			expectedTag: "4.16.1-x86_64",
+		},
+		{
+			caseName:    "Testing InstallReleaseImage - version and architecture: should pass",
+			releases:    releases,
+			selector:    "4.16.1-aarch64",
+			expectedTag: "4.16.1-aarch64",
+		},
+		{
+			caseName:    "Testing InstallReleaseImage - version: should pass",
+			releases:    releases,
+			selector:    "4.16.3",
+			expectedTag: "4.16.3-x86_64",
+		},
+		{
+			caseName:    "Testing InstallReleaseImage - architecture: should pass",
+			releases:    releases,
+			selector:    "aarch64",
+			expectedTag: "4.16.1-aarch64",
+		},
+		{
+			caseName:    "Testing InstallReleaseImage - release image: should pass",
+			releases:    releases,
+			selector:    "quay.io/openshift-release-dev/ocp-release:4.16.3-x86_64",
+			expectedTag: "4.16.3-x86_64",
+		},
+		{
+			caseName:      "Testing InstallReleaseImage - several releases without selector: should fail",
+			releases:      releases,
+			expectedError: "several releases are mirrored",
+		},
+		{
+			caseName:      "Testing InstallReleaseImage - ambiguous selector: should fail",
+			releases:      releases,
+			selector:      "x86_64",
+			expectedError: "several mirrored releases match x86_64",
+		},
+		{
+			caseName:      "Testing InstallReleaseImage - release not mirrored: should fail",
+			releases:      releases,
+			selector:      "4.15.0",
+			expectedError: "release 4.15.0 is not mirrored",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := setupCollector_DiskToMirror(tempDir, log)
+			ex.Releases = testCase.releases
+
+			res, err := ex.InstallReleaseImage(context.Background(), testCase.selector)
+			if testCase.expectedError != "" {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, res, "localhost:5000/test/openshift/release-images")
+			assert.True(t, strings.HasSuffix(res, ":"+testCase.expectedTag), res)
+		})
+	}
+}
+
 func TestHandleGraphImage(t *testing.T) {
 	type testCase struct {
 		name              string
@@ -739,6 +821,9 @@ func (o MockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCI
 }
 
 func (o MockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	if o.FailDigest {
+		return "", fmt.Errorf("forced digest error")
+	}
 	return "3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419", nil
 }
 
@@ -0,0 +1,22 @@
+package samples
+
+const (
+	dockerProtocol         = "docker://"
+	ociProtocolTrimmed     = "oci:"
+	releaseImageExtractDir = "hold-release"
+	releaseManifests       = "release-manifests"
+	imageReferences        = "image-references"
+	samplesImageDir        = "samples-images"
+	samplesImageExtractDir = "hold-samples"
+	samplesOperatorName    = "cluster-samples-operator"
+	samplesOperatorAssets  = "opt/openshift/operator"
+	imageStreamsDir        = "imagestreams"
+	imageStreamKind        = "ImageStream"
+	imageStreamListKind    = "ImageStreamList"
+	listKind               = "List"
+	dockerImageKind        = "DockerImage"
+	imageStreamTagKind     = "ImageStreamTag"
+	blobsDir               = "blobs/sha256"
+	collectorPrefix        = "[SampleImagesCollector] "
+	errMsg                 = collectorPrefix + "%s"
+)
@@ -0,0 +1,19 @@
+package samples
+
+import (
+	"context"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+type CollectorInterface interface {
+	// SampleImagesCollector returns the images referenced by the sample ImageStreams
+	// selected in the ImageSetConfiguration. The ImageStreams are read from the
+	// samples operator of each release in releaseImages.
+	SampleImagesCollector(ctx context.Context, releaseImages []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error)
+	// ImageStreams returns the sample ImageStreams, filtered according to the
+	// ImageSetConfiguration, as found during the last call to SampleImagesCollector.
+	ImageStreams() []imagev1.ImageStream
+}
@@ -0,0 +1,403 @@
+package samples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	imagev1 "github.com/openshift/api/image/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// samplesArchitectureDirs maps the platform architectures of the ImageSetConfiguration
+// to the directories used by the samples operator to store its assets.
+var samplesArchitectureDirs = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+type LocalStorageCollector struct {
+	Log              clog.PluggableLoggerInterface
+	Mirror           mirror.MirrorInterface
+	Manifest         manifest.ManifestInterface
+	Config           v2alpha1.ImageSetConfiguration
+	Opts             mirror.CopyOptions
+	LocalStorageFQDN string
+	imageStreams     []imagev1.ImageStream
+}
+
+// destinationRegistry returns the registry the sample images are copied to
+func (o LocalStorageCollector) destinationRegistry() string {
+	if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == mirror.MirrorToMirror {
+		return strings.TrimPrefix(o.Opts.Destination, dockerProtocol)
+	}
+	return o.LocalStorageFQDN
+}
+
+// SampleImagesCollector - reads the ImageStreams shipped by the samples operator
+// of each release being mirrored, keeps the streams and tags selected in the
+// samples field of the ImageSetConfiguration, and returns the images they reference.
+// During mirrorToDisk and mirrorToMirror, the samples operator image is pulled and its
+// assets are extracted to the working-dir. During diskToMirror, the assets extracted
+// during mirrorToDisk are reused.
+func (o *LocalStorageCollector) SampleImagesCollector(ctx context.Context, releaseImages []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
+	o.imageStreams = []imagev1.ImageStream{}
+	if len(o.Config.Mirror.Samples) == 0 {
+		return []v2alpha1.CopyImageSchema{}, nil
+	}
+
+	streamsByName := map[string]*imagev1.ImageStream{}
+	for _, releaseImg := range releaseImages {
+		if releaseImg.Type != v2alpha1.TypeOCPRelease {
+			continue
+		}
+		releaseDir, err := releaseIndexDir(releaseImg.Origin)
+		if err != nil {
+			return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+		}
+
+		assetsDir := filepath.Join(o.Opts.Global.WorkingDir, samplesImageExtractDir, releaseDir)
+		if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+			if err := o.extractSamplesOperator(ctx, releaseDir, assetsDir); err != nil {
+				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+			}
+		}
+
+		streams, err := o.readImageStreams(assetsDir)
+		if err != nil {
+			return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+		}
+		o.Log.Debug(collectorPrefix+"found %d sample imagestreams for release %s", len(streams), releaseImg.Origin)
+
+		for _, is := range streams {
+			filtered, ok := o.filterImageStream(is)
+			if !ok {
+				continue
+			}
+			// the same stream can be shipped by several releases:
+			// the tags of all releases are merged
+			if existing, found := streamsByName[filtered.Name]; found {
+				for _, tag := range filtered.Spec.Tags {
+					if !slices.ContainsFunc(existing.Spec.Tags, func(t imagev1.TagReference) bool { return t.Name == tag.Name }) {
+						existing.Spec.Tags = append(existing.Spec.Tags, tag)
+					}
+				}
+			} else {
+				streamsByName[filtered.Name] = &filtered
+			}
+		}
+	}
+
+	for _, sample := range o.Config.Mirror.Samples {
+		found := false
+		for name, is := range streamsByName {
+			for _, tag := range is.Spec.Tags {
+				if sample.Matches(name, tag.Name) {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			o.Log.Warn(collectorPrefix+"no sample imagestream found for name %q and tags %v", sample.Name, sample.Tags)
+		}
+	}
+
+	names := make([]string, 0, len(streamsByName))
+	for name := range streamsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var allImages []v2alpha1.CopyImageSchema
+	for _, name := range names {
+		is := streamsByName[name]
+		o.imageStreams = append(o.imageStreams, *is)
+		for _, tag := range is.Spec.Tags {
+			if tag.From == nil || tag.From.Kind != dockerImageKind {
+				continue
+			}
+			copyImage, err := o.prepareCopy(tag.From.Name)
+			if err != nil {
+				// same behavior as additional images: skip the references that can't be parsed
+				o.Log.Warn(collectorPrefix+"imagestream %s tag %s: %v : SKIPPING", is.Name, tag.Name, err)
+				continue
+			}
+			o.Log.Debug(collectorPrefix+"source %s", copyImage.Source)
+			o.Log.Debug(collectorPrefix+"destination %s", copyImage.Destination)
+			allImages = append(allImages, copyImage)
+		}
+	}
+
+	slices.SortFunc(allImages, func(a, b v2alpha1.CopyImageSchema) int {
+		return strings.Compare(a.Origin, b.Origin)
+	})
+	allImages = slices.Compact(allImages)
+
+	return allImages, nil
+}
+
+// ImageStreams returns the filtered sample ImageStreams found by the last
+// call to SampleImagesCollector.
+func (o *LocalStorageCollector) ImageStreams() []imagev1.ImageStream {
+	return o.imageStreams
+}
+
+// extractSamplesOperator pulls the samples operator image referenced by the release
+// (already extracted by the release collector in the hold-release folder), and extracts
+// the samples operator assets to assetsDir.
+func (o LocalStorageCollector) extractSamplesOperator(ctx context.Context, releaseDir, assetsDir string) error {
+	if _, err := os.Stat(filepath.Join(assetsDir, samplesOperatorAssets)); err == nil {
+		o.Log.Debug(collectorPrefix+"samples operator assets already extracted in %s", assetsDir)
+		return nil
+	}
+
+	imageReferencesFile := filepath.Join(o.Opts.Global.WorkingDir, releaseImageExtractDir, releaseDir, releaseManifests, imageReferences)
+	releaseRelatedImages, err := o.Manifest.GetReleaseSchema(imageReferencesFile)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(releaseRelatedImages, func(img v2alpha1.RelatedImage) bool {
+		return img.Name == samplesOperatorName
+	})
+	if idx < 0 {
+		return fmt.Errorf("%s not found in release %s", samplesOperatorName, releaseDir)
+	}
+	samplesOperatorImage := releaseRelatedImages[idx].Image
+
+	dir := filepath.Join(o.Opts.Global.WorkingDir, samplesImageDir, releaseDir)
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		o.Log.Debug(collectorPrefix+"copying samples operator image %s", samplesOperatorImage)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		optsCopy := o.Opts
+		optsCopy.Stdout = io.Discard
+		// the assets are identical for all platforms
+		optsCopy.MultiArch = "system"
+		if err := o.Mirror.Run(ctx, dockerProtocol+samplesOperatorImage, ociProtocolTrimmed+dir, "copy", &optsCopy); err != nil {
+			return err
+		}
+	}
+
+	oci, err := o.Manifest.GetImageIndex(dir)
+	if err != nil {
+		return err
+	}
+	if len(oci.Manifests) == 0 {
+		return fmt.Errorf("image index not found for %s", samplesOperatorImage)
+	}
+	validDigest, err := digest.Parse(oci.Manifests[0].Digest)
+	if err != nil {
+		return fmt.Errorf("invalid digest for image index %s: %s", oci.Manifests[0].Digest, err.Error())
+	}
+	mfst, err := o.Manifest.GetImageManifest(filepath.Join(dir, blobsDir, validDigest.Encoded()))
+	if err != nil {
+		return err
+	}
+	return o.Manifest.ExtractLayersOCI(filepath.Join(dir, blobsDir), assetsDir, samplesOperatorAssets, mfst)
+}
+
+// readImageStreams parses all the ImageStream definitions found under the
+// imagestreams folders of the samples operator assets, for the architectures
+// set in the ImageSetConfiguration.
+func (o LocalStorageCollector) readImageStreams(assetsDir string) ([]imagev1.ImageStream, error) {
+	rootDir := filepath.Join(assetsDir, samplesOperatorAssets)
+	if _, err := os.Stat(rootDir); err != nil {
+		return nil, fmt.Errorf("samples operator assets not found in %s: %v", assetsDir, err)
+	}
+
+	var streams []imagev1.ImageStream
+	for _, archDir := range o.architectureDirs(rootDir) {
+		err := filepath.WalkDir(filepath.Join(rootDir, archDir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Base(filepath.Dir(path)) != imageStreamsDir {
+				return nil
+			}
+			switch filepath.Ext(path) {
+			case ".json", ".yaml", ".yml":
+			default:
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			fileStreams, err := parseImageStreams(data)
+			if err != nil {
+				o.Log.Warn(collectorPrefix+"unable to parse %s : SKIPPING: %v", path, err)
+				return nil
+			}
+			streams = append(streams, fileStreams...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return streams, nil
+}
+
+// architectureDirs returns the asset directories to read, depending on the
+// architectures of the platform. All architectures are read for `multi`.
+func (o LocalStorageCollector) architectureDirs(rootDir string) []string {
+	archs := o.Config.Mirror.Platform.Architectures
+	if len(archs) == 0 {
+		archs = []string{v2alpha1.DefaultPlatformArchitecture}
+	}
+	dirs := []string{}
+	for _, arch := range archs {
+		if arch == "multi" {
+			entries, err := os.ReadDir(rootDir)
+			if err != nil {
+				return dirs
+			}
+			dirs = []string{}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					dirs = append(dirs, entry.Name())
+				}
+			}
+			return dirs
+		}
+		if dir, ok := samplesArchitectureDirs[arch]; ok && !slices.Contains(dirs, dir) {
+			if _, err := os.Stat(filepath.Join(rootDir, dir)); err == nil {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// filterImageStream keeps the tags of the ImageStream that are selected by
+// the samples field of the ImageSetConfiguration. The tags referenced by a selected
+// alias (kind ImageStreamTag) are also kept, so that the alias can be resolved.
+func (o LocalStorageCollector) filterImageStream(is imagev1.ImageStream) (imagev1.ImageStream, bool) {
+	selected := []imagev1.TagReference{}
+	for _, tag := range is.Spec.Tags {
+		if slices.ContainsFunc(o.Config.Mirror.Samples, func(s v2alpha1.SampleImages) bool { return s.Matches(is.Name, tag.Name) }) {
+			selected = append(selected, tag)
+		}
+	}
+	if len(selected) == 0 {
+		return imagev1.ImageStream{}, false
+	}
+	for _, tag := range selected {
+		if tag.From == nil || tag.From.Kind != imageStreamTagKind {
+			continue
+		}
+		target := strings.TrimPrefix(tag.From.Name, is.Name+":")
+		if slices.ContainsFunc(selected, func(t imagev1.TagReference) bool { return t.Name == target }) {
+			continue
+		}
+		if idx := slices.IndexFunc(is.Spec.Tags, func(t imagev1.TagReference) bool { return t.Name == target }); idx >= 0 {
+			selected = append(selected, is.Spec.Tags[idx])
+		}
+	}
+	filtered := *is.DeepCopy()
+	filtered.Spec.Tags = selected
+	filtered.Status = imagev1.ImageStreamStatus{}
+	return filtered, true
+}
+
+// prepareCopy computes the source and destination of a sample image,
+// taking into account the mode we are in (mirrorToDisk, mirrorToMirror, diskToMirror)
+func (o LocalStorageCollector) prepareCopy(ref string) (v2alpha1.CopyImageSchema, error) {
+	imgSpec, err := image.ParseRef(ref)
+	if err != nil {
+		return v2alpha1.CopyImageSchema{}, err
+	}
+	if imgSpec.Transport != dockerProtocol {
+		return v2alpha1.CopyImageSchema{}, fmt.Errorf("unsupported transport %s", imgSpec.Transport)
+	}
+
+	var pathAndTag string
+	if imgSpec.IsImageByDigestOnly() {
+		pathAndTag = imgSpec.PathComponent + ":" + imgSpec.Algorithm + "-" + imgSpec.Digest
+	} else {
+		pathAndTag = imgSpec.PathComponent + ":" + imgSpec.Tag
+	}
+
+	var tmpSrc, tmpDest string
+	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+		tmpSrc = imgSpec.ReferenceWithTransport
+		if imgSpec.IsImageByTagAndDigest() {
+			tmpSrc = strings.Join([]string{imgSpec.Domain, imgSpec.PathComponent}, "/") + "@" + imgSpec.Algorithm + ":" + imgSpec.Digest
+		}
+		tmpDest = strings.Join([]string{o.destinationRegistry(), pathAndTag}, "/")
+	} else {
+		tmpSrc = strings.Join([]string{o.LocalStorageFQDN, pathAndTag}, "/")
+		tmpDest = strings.Join([]string{o.Opts.Destination, pathAndTag}, "/")
+	}
+
+	srcSpec, err := image.ParseRef(tmpSrc)
+	if err != nil {
+		return v2alpha1.CopyImageSchema{}, err
+	}
+	destSpec, err := image.ParseRef(tmpDest)
+	if err != nil {
+		return v2alpha1.CopyImageSchema{}, err
+	}
+	return v2alpha1.CopyImageSchema{
+		Source:      srcSpec.ReferenceWithTransport,
+		Destination: destSpec.ReferenceWithTransport,
+		Origin:      ref,
+		Type:        v2alpha1.TypeSampleImage,
+	}, nil
+}
+
+// releaseIndexDir returns the relative folder in which the release collector
+// stores the release, i.e ocp-release/4.14.1-x86_64
+func releaseIndexDir(releaseRef string) (string, error) {
+	imgSpec, err := image.ParseRef(releaseRef)
+	if err != nil {
+		return "", err
+	}
+	hld := strings.Split(imgSpec.Reference, "/")
+	return strings.ReplaceAll(hld[len(hld)-1], ":", "/"), nil
+}
+
+// parseImageStreams parses a samples operator asset, which can either contain
+// a single ImageStream, or a list of ImageStreams.
+func parseImageStreams(data []byte) ([]imagev1.ImageStream, error) {
+	var is imagev1.ImageStream
+	if err := yaml.Unmarshal(data, &is); err != nil {
+		return nil, err
+	}
+	switch is.Kind {
+	case imageStreamKind:
+		return []imagev1.ImageStream{is}, nil
+	case imageStreamListKind, listKind:
+		var list imagev1.ImageStreamList
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		streams := []imagev1.ImageStream{}
+		for _, item := range list.Items {
+			if item.Kind == "" || item.Kind == imageStreamKind {
+				streams = append(streams, item)
+			}
+		}
+		return streams, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind %q", is.Kind)
+	}
+}
@@ -0,0 +1,255 @@
+package samples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
+)
+
+// setup mocks
+// we need to mock Manifest, Mirror
+
+type MockMirror struct{}
+type MockManifest struct {
+	Log clog.PluggableLoggerInterface
+}
+
+const rubyImageStream = `{
+  "kind": "ImageStream",
+  "apiVersion": "image.openshift.io/v1",
+  "metadata": {
+    "name": "ruby"
+  },
+  "spec": {
+    "tags": [
+      {
+        "name": "latest",
+        "from": {"kind": "ImageStreamTag", "name": "3.3-ubi9"}
+      },
+      {
+        "name": "3.3-ubi9",
+        "from": {"kind": "DockerImage", "name": "registry.access.redhat.com/ubi9/ruby-33:latest"}
+      },
+      {
+        "name": "3.1-ubi8",
+        "from": {"kind": "DockerImage", "name": "registry.access.redhat.com/ubi8/ruby-31@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"}
+      }
+    ]
+  }
+}`
+
+const imageStreamList = `
+kind: List
+apiVersion: v1
+items:
+- kind: ImageStream
+  apiVersion: image.openshift.io/v1
+  metadata:
+    name: nodejs
+  spec:
+    tags:
+    - name: "20-ubi9"
+      from:
+        kind: DockerImage
+        name: registry.access.redhat.com/ubi9/nodejs-20:latest
+`
+
+func TestSampleImagesCollector(t *testing.T) {
+	log := clog.New("trace")
+
+	global := &mirror.GlobalOptions{SecurePolicy: false}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+	_, retryOpts := mirror.RetryFlags()
+
+	releaseImages := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64",
+			Destination: "docker://test.registry.com/openshift-release-dev/ocp-release:4.16.0-x86_64",
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64",
+			Type:        v2alpha1.TypeOCPRelease,
+		},
+	}
+
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Samples: []v2alpha1.SampleImages{
+					{Image: v2alpha1.Image{Name: "ruby"}, Tags: []string{"latest"}},
+					{Image: v2alpha1.Image{Name: "nodejs"}},
+					{Image: v2alpha1.Image{Name: "perl"}},
+				},
+			},
+		},
+	}
+
+	newOpts := func(mode, workingDir string) mirror.CopyOptions {
+		globalCopy := *global
+		globalCopy.WorkingDir = workingDir
+		return mirror.CopyOptions{
+			Global:              &globalCopy,
+			DeprecatedTLSVerify: deprecatedTLSVerifyOpt,
+			SrcImage:            srcOpts,
+			DestImage:           destOpts,
+			RetryOpts:           retryOpts,
+			Destination:         "docker://myregistry/mynamespace",
+			Dev:                 false,
+			Mode:                mode,
+			LocalStorageFQDN:    "localhost:9999",
+		}
+	}
+
+	t.Run("Testing SampleImagesCollector : mirrorToDisk should pass", func(t *testing.T) {
+		workingDir := t.TempDir()
+		ex := New(log, cfg, newOpts(mirror.MirrorToDisk, workingDir), MockMirror{}, MockManifest{Log: log})
+
+		res, err := ex.SampleImagesCollector(context.Background(), releaseImages)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		expected := []v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://registry.access.redhat.com/ubi9/nodejs-20:latest",
+				Destination: "docker://localhost:9999/ubi9/nodejs-20:latest",
+				Origin:      "registry.access.redhat.com/ubi9/nodejs-20:latest",
+				Type:        v2alpha1.TypeSampleImage,
+			},
+			{
+				Source:      "docker://registry.access.redhat.com/ubi9/ruby-33:latest",
+				Destination: "docker://localhost:9999/ubi9/ruby-33:latest",
+				Origin:      "registry.access.redhat.com/ubi9/ruby-33:latest",
+				Type:        v2alpha1.TypeSampleImage,
+			},
+		}
+		assert.Equal(t, expected, res)
+
+		streams := ex.ImageStreams()
+		assert.Len(t, streams, 2)
+		assert.Equal(t, "nodejs", streams[0].Name)
+		assert.Equal(t, "ruby", streams[1].Name)
+		// the alias and the tag it points to are kept
+		assert.Len(t, streams[1].Spec.Tags, 2)
+	})
+
+	t.Run("Testing SampleImagesCollector : diskToMirror should pass", func(t *testing.T) {
+		workingDir := t.TempDir()
+		writeSamplesAssets(t, workingDir)
+		ex := New(log, cfg, newOpts(mirror.DiskToMirror, workingDir), MockMirror{}, MockManifest{Log: log})
+
+		res, err := ex.SampleImagesCollector(context.Background(), releaseImages)
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		expected := []v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://localhost:9999/ubi9/nodejs-20:latest",
+				Destination: "docker://myregistry/mynamespace/ubi9/nodejs-20:latest",
+				Origin:      "registry.access.redhat.com/ubi9/nodejs-20:latest",
+				Type:        v2alpha1.TypeSampleImage,
+			},
+			{
+				Source:      "docker://localhost:9999/ubi9/ruby-33:latest",
+				Destination: "docker://myregistry/mynamespace/ubi9/ruby-33:latest",
+				Origin:      "registry.access.redhat.com/ubi9/ruby-33:latest",
+				Type:        v2alpha1.TypeSampleImage,
+			},
+		}
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("Testing SampleImagesCollector : diskToMirror without assets should fail", func(t *testing.T) {
+		workingDir := t.TempDir()
+		ex := New(log, cfg, newOpts(mirror.DiskToMirror, workingDir), MockMirror{}, MockManifest{Log: log})
+
+		_, err := ex.SampleImagesCollector(context.Background(), releaseImages)
+		assert.ErrorContains(t, err, "samples operator assets not found")
+	})
+}
+
+// writeSamplesAssets simulates the extraction of the samples operator assets
+// for the release ocp-release:4.16.0-x86_64
+func writeSamplesAssets(t *testing.T, workingDir string) {
+	isDir := filepath.Join(workingDir, samplesImageExtractDir, "ocp-release", "4.16.0-x86_64", samplesOperatorAssets, "x86_64", "ruby", imageStreamsDir)
+	if err := os.MkdirAll(isDir, 0755); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(isDir, "ruby-rhel.json"), []byte(rubyImageStream), 0644); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(isDir, "nodejs-rhel.yaml"), []byte(imageStreamList), 0644); err != nil {
+		t.Fatalf("should not fail: %v", err)
+	}
+}
+
+func (o MockMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
+	return nil
+}
+
+func (o MockMirror) Check(ctx context.Context, image string, opts *mirror.CopyOptions, asCopySrc bool) (bool, error) {
+	return true, nil
+}
+
+func (o MockManifest) GetOperatorConfig(file string) (*v2alpha1.OperatorConfigSchema, error) {
+	return &v2alpha1.OperatorConfigSchema{}, nil
+}
+
+func (o MockManifest) GetReleaseSchema(filePath string) ([]v2alpha1.RelatedImage, error) {
+	relatedImages := []v2alpha1.RelatedImage{
+		{Name: "cli", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"},
+		{Name: samplesOperatorName, Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:44d75007b39e0e1bbf1bcfd0721245add54c54c3f83903f8926fb4bef6827aa2"},
+	}
+	return relatedImages, nil
+}
+
+func (o MockManifest) GetImageIndex(name string) (*v2alpha1.OCISchema, error) {
+	return &v2alpha1.OCISchema{
+		SchemaVersion: 2,
+		Manifests: []v2alpha1.OCIManifest{
+			{
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Digest:    "sha256:3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419",
+				Size:      567,
+			},
+		},
+	}, nil
+}
+
+func (o MockManifest) GetImageManifest(name string) (*v2alpha1.OCISchema, error) {
+	return &v2alpha1.OCISchema{
+		SchemaVersion: 2,
+		Config: v2alpha1.OCIManifest{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    "sha256:3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419",
+			Size:      567,
+		},
+	}, nil
+}
+
+// ExtractLayersOCI simulates the extraction of the samples operator assets
+func (o MockManifest) ExtractLayersOCI(filePath, toPath, label string, oci *v2alpha1.OCISchema) error {
+	isDir := filepath.Join(toPath, label, "x86_64", "ruby", imageStreamsDir)
+	if err := os.MkdirAll(isDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(isDir, "ruby-rhel.json"), []byte(rubyImageStream), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(isDir, "nodejs-rhel.yaml"), []byte(imageStreamList), 0644)
+}
+
+func (o MockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCISchema) error {
+	return nil
+}
+
+func (o MockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	return "123456", nil
+}
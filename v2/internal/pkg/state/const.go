@@ -0,0 +1,43 @@
+package state
+
+import "time"
+
+const (
+	fileProtocol   = "file://"
+	dockerProtocol = "docker://"
+	s3Protocol     = "s3://"
+	stateObject    = "state.tar.gz"
+	lockObject     = "state.lock"
+	// the lock of a runner which did not renew it is stale after lockTTL
+	lockTTL = 10 * time.Minute
+	// lockRenewInterval leaves several renewals to a runner before its lock is stale
+	lockRenewInterval   = lockTTL / 5
+	stateLayerMediaType = "application/vnd.openshift.oc-mirror.state.v1.tar+gzip"
+	lockLayerMediaType  = "application/vnd.openshift.oc-mirror.state.lock.v1+json"
+	lockTagSuffix       = "-lock"
+	// registryCreateSettle leaves the runners pushing the same lock tag the time to push it,
+	// before each reads it back to find which push won
+	registryCreateSettle = 2 * time.Second
+)
+
+// stateDirs are the directories of the working-dir saved in the state
+var stateDirs = []string{
+	".history",
+	"cluster-resources",
+	"operator-catalogs",
+	"signatures",
+	"eus-upgrade-paths",
+	"release-images/cincinnati-graph-data",
+}
+
+// stateFiles are the files of the working-dir saved in the state
+var stateFiles = []string{
+	"mirror-set-state.json",
+}
+
+// skippedDirs are the directories of the state directories that are not saved,
+// as they are downloaded again by the collectors
+var skippedDirs = []string{
+	"catalog-config",
+	"catalog-image",
+}
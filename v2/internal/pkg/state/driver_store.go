@@ -0,0 +1,249 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	storagedriver "github.com/distribution/distribution/v3/registry/storage/driver"
+	"github.com/distribution/distribution/v3/registry/storage/driver/factory"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/filesystem"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/s3-aws"
+	"github.com/google/uuid"
+)
+
+// driverStore keeps the state objects in a storage driver of the distribution registry
+type driverStore struct {
+	driver      storagedriver.StorageDriver
+	description string
+}
+
+// localStore is a driverStore in a local directory, creating objects with O_EXCL
+type localStore struct {
+	driverStore
+	rootDir string
+}
+
+// s3Store is a driverStore in an S3-compatible bucket, creating objects with If-None-Match
+type s3Store struct {
+	driverStore
+	client      *s3.S3
+	bucket      string
+	prefix      string
+	conditional *conditionalProbe
+}
+
+// conditionalProbe holds whether the server supports If-None-Match, probed once
+type conditionalProbe struct {
+	once sync.Once
+	err  error
+}
+
+func newLocalStore(ctx context.Context, dir string) (store, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := factory.Create(ctx, "filesystem", map[string]interface{}{"rootdirectory": absDir})
+	if err != nil {
+		return nil, fmt.Errorf("unable to setup state backend %s: %v", dir, err)
+	}
+	return localStore{driverStore: driverStore{driver: driver, description: fileProtocol + absDir}, rootDir: absDir}, nil
+}
+
+// newS3Store uses the bucket and prefix of the URL, and the region, endpoint
+// and forcepathstyle query parameters. The credentials are read from the
+// usual AWS environment variables and shared files.
+func newS3Store(ctx context.Context, u *url.URL, tlsVerify bool) (store, error) {
+	query := u.Query()
+	parameters := map[string]interface{}{
+		"bucket":        u.Host,
+		"region":        query.Get("region"),
+		"skipverify":    !tlsVerify,
+		"rootdirectory": strings.Trim(u.Path, "/"),
+	}
+	if parameters["region"] == "" {
+		// S3-compatible servers usually ignore the region
+		parameters["region"] = "us-east-1"
+	}
+	awsConfig := aws.NewConfig().WithRegion(parameters["region"].(string))
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		parameters["regionendpoint"] = endpoint
+		parameters["secure"] = !strings.HasPrefix(endpoint, "http://")
+		awsConfig.WithEndpoint(endpoint).WithDisableSSL(strings.HasPrefix(endpoint, "http://"))
+	}
+	if forcePathStyle := query.Get("forcepathstyle"); forcePathStyle != "" {
+		parameters["forcepathstyle"] = forcePathStyle
+		awsConfig.WithS3ForcePathStyle(forcePathStyle == "true")
+	}
+	if !tlsVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		// nolint: gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+		awsConfig.WithHTTPClient(&http.Client{Transport: transport})
+	}
+	driver, err := factory.Create(ctx, "s3aws", parameters)
+	if err != nil {
+		return nil, fmt.Errorf("unable to setup state backend %s: %v", u.Redacted(), err)
+	}
+	// the driver does not expose its client, needed for the conditional writes of the lock
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to setup state backend %s: %v", u.Redacted(), err)
+	}
+	return s3Store{
+		driverStore: driverStore{driver: driver, description: s3Protocol + u.Host + u.Path},
+		client:      s3.New(sess),
+		bucket:      u.Host,
+		prefix:      strings.Trim(u.Path, "/"),
+		conditional: &conditionalProbe{},
+	}, nil
+}
+
+func (o driverStore) get(ctx context.Context, name string) ([]byte, error) {
+	data, err := o.driver.GetContent(ctx, "/"+name)
+	if errors.As(err, &storagedriver.PathNotFoundError{}) {
+		return nil, errNotFound
+	}
+	return data, err
+}
+
+func (o driverStore) put(ctx context.Context, name string, data []byte) error {
+	return o.driver.PutContent(ctx, "/"+name, data)
+}
+
+func (o driverStore) create(context.Context, string, []byte) error {
+	return errNoConditionalCreate
+}
+
+func (o driverStore) reader(ctx context.Context, name string) (io.ReadCloser, error) {
+	reader, err := o.driver.Reader(ctx, "/"+name, 0)
+	if errors.As(err, &storagedriver.PathNotFoundError{}) {
+		return nil, errNotFound
+	}
+	return reader, err
+}
+
+func (o driverStore) putFile(ctx context.Context, name string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer, err := o.driver.Writer(ctx, "/"+name, false)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, file); err != nil {
+		_ = writer.Cancel(ctx)
+		writer.Close()
+		return err
+	}
+	if err := writer.Commit(ctx); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (o driverStore) delete(ctx context.Context, name string) error {
+	err := o.driver.Delete(ctx, "/"+name)
+	if errors.As(err, &storagedriver.PathNotFoundError{}) {
+		return nil
+	}
+	return err
+}
+
+func (o driverStore) String() string {
+	return o.description
+}
+
+func (o localStore) create(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(o.rootDir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(o.rootDir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return errExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// create writes the object with If-None-Match: *, once the server is known to support it
+func (o s3Store) create(ctx context.Context, name string, data []byte) error {
+	if err := o.probeConditionalCreate(ctx); err != nil {
+		return err
+	}
+	return o.putIfNoneMatch(ctx, name, data)
+}
+
+// probeConditionalCreate writes a scratch object twice with If-None-Match: *, the second
+// write must fail. Some S3-compatible servers ignore the header: writing the lock itself
+// would then overwrite the lock of another runner.
+func (o s3Store) probeConditionalCreate(ctx context.Context) error {
+	o.conditional.once.Do(func() {
+		name := lockObject + ".probe-" + uuid.New().String()
+		defer func() {
+			_, _ = o.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key(name))})
+		}()
+		if err := o.putIfNoneMatch(ctx, name, nil); err != nil {
+			o.conditional.err = err
+			return
+		}
+		switch err := o.putIfNoneMatch(ctx, name, nil); {
+		case errors.Is(err, errExists):
+		case err == nil:
+			o.conditional.err = errNoConditionalCreate
+		default:
+			o.conditional.err = err
+		}
+	})
+	return o.conditional.err
+}
+
+// key returns the key of an object in the storage driver
+func (o s3Store) key(name string) string {
+	return strings.TrimLeft(o.prefix+"/"+name, "/")
+}
+
+func (o s3Store) putIfNoneMatch(ctx context.Context, name string, data []byte) error {
+	_, err := o.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(o.bucket),
+		Key:    aws.String(o.key(name)),
+		Body:   bytes.NewReader(data),
+	}, func(r *request.Request) {
+		r.HTTPRequest.Header.Set("If-None-Match", "*")
+	})
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) {
+		switch requestErr.StatusCode() {
+		case http.StatusPreconditionFailed, http.StatusConflict:
+			return errExists
+		case http.StatusNotImplemented:
+			return errNoConditionalCreate
+		}
+	}
+	return err
+}
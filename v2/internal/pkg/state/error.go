@@ -0,0 +1,35 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// errNotFound is returned by the stores when the object does not exist
+	errNotFound = errors.New("not found")
+	// errExists is returned by the stores when the object to create already exists
+	errExists = errors.New("already exists")
+	// errNoConditionalCreate is returned by the stores unable to create an object atomically
+	errNoConditionalCreate = errors.New("conditional writes are not supported")
+)
+
+// LockedError is returned when the state is locked by another runner
+type LockedError struct {
+	message string
+}
+
+func (e *LockedError) Error() string {
+	return e.message
+}
+
+func LockedErrorf(format string, a ...any) *LockedError {
+	return &LockedError{
+		message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *LockedError) Is(err error) bool {
+	_, ok := err.(*LockedError)
+	return ok
+}
@@ -0,0 +1,33 @@
+package state
+
+import (
+	"context"
+	"io"
+)
+
+// Backend stores the state of the working-dir, so that any runner can continue
+// an incremental mirroring sequence
+type Backend interface {
+	// Lock acquires the state for this runner, until Unlock
+	Lock(ctx context.Context) error
+	// Unlock releases the state acquired by Lock
+	Unlock(ctx context.Context) error
+	// Pull restores the state into the working-dir
+	Pull(ctx context.Context, workingDir string) error
+	// Push saves the state of the working-dir
+	Push(ctx context.Context, workingDir string) error
+}
+
+// store reads and writes the objects of the state
+type store interface {
+	get(ctx context.Context, name string) ([]byte, error)
+	put(ctx context.Context, name string, data []byte) error
+	// create writes a new object, failing with errExists when it already exists,
+	// or with errNoConditionalCreate when the store can not guarantee it
+	create(ctx context.Context, name string, data []byte) error
+	// reader and putFile stream the objects too large to be held in memory
+	reader(ctx context.Context, name string) (io.ReadCloser, error)
+	putFile(ctx context.Context, name string, path string) error
+	delete(ctx context.Context, name string) error
+	String() string
+}
@@ -0,0 +1,196 @@
+package state
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// registryStore keeps each state object as a single layer OCI artifact:
+// the state under the tag of the reference, and its lock under the tag suffixed by -lock
+type registryStore struct {
+	tag        name.Tag
+	remoteOpts []remote.Option
+	settle     time.Duration
+}
+
+func newRegistryStore(reference string, tlsVerify bool) (store, error) {
+	nameOpts := []name.Option{name.StrictValidation}
+	remoteOpts := []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	if !tlsVerify {
+		nameOpts = append(nameOpts, name.Insecure)
+		transport := remote.DefaultTransport.(*http.Transport).Clone()
+		// nolint: gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+		remoteOpts = append(remoteOpts, remote.WithTransport(transport))
+	}
+	tag, err := name.NewTag(reference, nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state backend %s%s: %v", dockerProtocol, reference, err)
+	}
+	return registryStore{tag: tag, remoteOpts: remoteOpts, settle: registryCreateSettle}, nil
+}
+
+func (o registryStore) ref(objectName string) name.Tag {
+	switch {
+	case objectName == stateObject:
+		return o.tag
+	case objectName == lockObject:
+		return o.tag.Tag(o.tag.TagStr() + lockTagSuffix)
+	default:
+		// the other objects of the lock, such as state.lock.takeover-<id> under the tag suffixed by -lock-takeover-<id>
+		return o.tag.Tag(o.tag.TagStr() + lockTagSuffix + "-" + strings.TrimPrefix(objectName, lockObject+"."))
+	}
+}
+
+func (o registryStore) options(ctx context.Context) []remote.Option {
+	return append(o.remoteOpts, remote.WithContext(ctx))
+}
+
+func (o registryStore) get(ctx context.Context, objectName string) ([]byte, error) {
+	reader, err := o.reader(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func (o registryStore) reader(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	img, err := remote.Image(o.ref(objectName), o.options(ctx)...)
+	if isNotFound(err) {
+		return nil, errNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != 1 {
+		return nil, fmt.Errorf("%s is not an oc-mirror state artifact", o.ref(objectName))
+	}
+	return layers[0].Compressed()
+}
+
+func (o registryStore) put(ctx context.Context, objectName string, data []byte) error {
+	return o.write(ctx, objectName, o.layer(objectName, data))
+}
+
+// create pushes the object only when its tag does not exist. As registries can not push
+// a tag conditionally, the tag is read back after settle: of the runners which pushed it
+// meanwhile, only the one whose push is read back creates it, the others fail with errExists.
+func (o registryStore) create(ctx context.Context, objectName string, data []byte) error {
+	_, err := remote.Head(o.ref(objectName), o.options(ctx)...)
+	if err == nil {
+		return errExists
+	}
+	if !isNotFound(err) {
+		return err
+	}
+	img, err := o.image(objectName, o.layer(objectName, data))
+	if err != nil {
+		return err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	if err := remote.Write(o.ref(objectName), img, o.options(ctx)...); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(o.settle):
+	}
+	desc, err := remote.Head(o.ref(objectName), o.options(ctx)...)
+	if isNotFound(err) {
+		return errExists
+	}
+	if err != nil {
+		return err
+	}
+	if desc.Digest != digest {
+		return errExists
+	}
+	return nil
+}
+
+func (o registryStore) layer(objectName string, data []byte) v1.Layer {
+	if objectName == stateObject {
+		return static.NewLayer(data, stateLayerMediaType)
+	}
+	return static.NewLayer(data, lockLayerMediaType)
+}
+
+func (o registryStore) putFile(ctx context.Context, objectName string, path string) error {
+	// the gzip archive is pushed as is, read from the file when uploaded
+	layer, err := tarball.LayerFromFile(path, tarball.WithMediaType(stateLayerMediaType))
+	if err != nil {
+		return err
+	}
+	return o.write(ctx, objectName, layer)
+}
+
+func (o registryStore) write(ctx context.Context, objectName string, layer v1.Layer) error {
+	img, err := o.image(objectName, layer)
+	if err != nil {
+		return err
+	}
+	return remote.Write(o.ref(objectName), img, o.options(ctx)...)
+}
+
+func (o registryStore) image(objectName string, layer v1.Layer) (v1.Image, error) {
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer:       layer,
+		Annotations: map[string]string{"org.opencontainers.image.title": objectName},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mutate.ConfigMediaType(img, types.OCIConfigJSON), nil
+}
+
+func (o registryStore) delete(ctx context.Context, objectName string) error {
+	desc, err := remote.Head(o.ref(objectName), o.options(ctx)...)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// some registries delete tags, the others only delete manifests by digest
+	if err := remote.Delete(o.ref(objectName), o.options(ctx)...); err == nil {
+		return nil
+	}
+	return remote.Delete(o.tag.Context().Digest(desc.Digest.String()), o.options(ctx)...)
+}
+
+func (o registryStore) String() string {
+	return dockerProtocol + o.tag.String()
+}
+
+func isNotFound(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,427 @@
+package state
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// lockInfo identifies the runner holding the lock of the state
+type lockInfo struct {
+	Owner    string    `json:"owner"`
+	Host     string    `json:"host"`
+	Acquired time.Time `json:"acquired"`
+	Renewed  time.Time `json:"renewed,omitempty"`
+}
+
+// isStale returns true when the runner holding the lock did not renew it during lockTTL
+func (l lockInfo) isStale() bool {
+	last := l.Renewed
+	if last.IsZero() {
+		last = l.Acquired
+	}
+	return time.Since(last) > lockTTL
+}
+
+type backend struct {
+	Log           clog.PluggableLoggerInterface
+	store         store
+	renewInterval time.Duration
+	mu            sync.Mutex
+	lock          *lockInfo
+	stopRenewal   context.CancelFunc
+	renewalDone   chan struct{}
+}
+
+// New returns the state backend of stateURL, one of:
+//   - file://<directory>
+//   - docker://<registry>/<repository>[:<tag>], an OCI artifact in a registry
+//   - s3://<bucket>[/<prefix>][?region=<region>&endpoint=<url>&forcepathstyle=true], an S3-compatible bucket
+//
+// tlsVerify applies to the registry and to the S3 endpoint.
+// The state is locked with a conditional write: S3-compatible servers without If-None-Match
+// support can not be locked. Registries are locked by reading the pushed lock back, which
+// makes concurrent runners unlikely, not impossible: Push still fails when the lock was lost.
+func New(ctx context.Context, log clog.PluggableLoggerInterface, stateURL string, tlsVerify bool) (Backend, error) {
+	var s store
+	var err error
+	switch {
+	case strings.HasPrefix(stateURL, fileProtocol):
+		s, err = newLocalStore(ctx, strings.TrimPrefix(stateURL, fileProtocol))
+	case strings.HasPrefix(stateURL, dockerProtocol):
+		s, err = newRegistryStore(strings.TrimPrefix(stateURL, dockerProtocol), tlsVerify)
+	case strings.HasPrefix(stateURL, s3Protocol):
+		var u *url.URL
+		u, err = url.Parse(stateURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state backend %s: %v", stateURL, err)
+		}
+		s, err = newS3Store(ctx, u, tlsVerify)
+	default:
+		return nil, fmt.Errorf("state backend %s must have a file://, docker:// or s3:// prefix", stateURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &backend{Log: log, store: s, renewInterval: lockRenewInterval}, nil
+}
+
+// Lock creates the lock only if it does not exist, and renews it until Unlock.
+// A stale lock is taken over, by a single one of the runners which found it stale.
+func (o *backend) Lock(ctx context.Context) error {
+	host, _ := os.Hostname()
+	lock := &lockInfo{Owner: uuid.New().String(), Host: host, Acquired: time.Now().UTC()}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	for retry := false; ; retry = true {
+		err := o.store.create(ctx, lockObject, data)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errExists) {
+			return fmt.Errorf("unable to lock state %s: %v", o.store, err)
+		}
+		if retry {
+			return LockedErrorf("state %s was locked concurrently by another runner", o.store)
+		}
+		current, err := o.readLock(ctx)
+		if errors.Is(err, errNotFound) {
+			// released in the meantime
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !current.isStale() {
+			return LockedErrorf("state %s is locked by %s since %s", o.store, current.Host, current.Acquired.Format(time.RFC3339))
+		}
+		if err := o.takeOver(ctx, current, data); err != nil {
+			return err
+		}
+	}
+
+	renewalCtx, cancel := context.WithCancel(ctx)
+	o.mu.Lock()
+	o.lock = lock
+	o.stopRenewal = cancel
+	o.renewalDone = make(chan struct{})
+	o.mu.Unlock()
+	go o.renew(renewalCtx, o.renewalDone)
+	o.Log.Debug("state %s locked", o.store)
+	return nil
+}
+
+// takeOver removes a stale lock. The runners taking over the same stale lock first create
+// its takeover marker, so that only one of them removes it: the others, which read it before,
+// would remove the lock created since. The markers are kept for the runners late to take over.
+func (o *backend) takeOver(ctx context.Context, stale *lockInfo, data []byte) error {
+	o.Log.Warn("state %s: taking over the stale lock of %s, acquired %s", o.store, stale.Host, stale.Acquired.Format(time.RFC3339))
+	err := o.store.create(ctx, takeOverObject(stale.Owner), data)
+	if errors.Is(err, errExists) {
+		return LockedErrorf("the stale lock of state %s was taken over by another runner", o.store)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to take over the stale lock of state %s: %v", o.store, err)
+	}
+	// the stale runner may have unlocked, and another runner locked, since the lock was read
+	current, err := o.readLock(ctx)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current.Owner != stale.Owner {
+		return LockedErrorf("state %s is locked by %s since %s", o.store, current.Host, current.Acquired.Format(time.RFC3339))
+	}
+	if err := o.store.delete(ctx, lockObject); err != nil {
+		return fmt.Errorf("unable to remove the stale lock of state %s: %v", o.store, err)
+	}
+	return nil
+}
+
+// takeOverObject is the name of the takeover marker of the lock of owner
+func takeOverObject(owner string) string {
+	sum := sha256.Sum256([]byte(owner))
+	return lockObject + ".takeover-" + hex.EncodeToString(sum[:16])
+}
+
+func (o *backend) Unlock(ctx context.Context) error {
+	o.mu.Lock()
+	locked := o.lock != nil
+	o.mu.Unlock()
+	if !locked {
+		return nil
+	}
+	o.stopRenewing()
+	err := o.checkLock(ctx)
+	o.mu.Lock()
+	o.lock = nil
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := o.store.delete(ctx, lockObject); err != nil {
+		return fmt.Errorf("unable to unlock state %s: %v", o.store, err)
+	}
+	o.Log.Debug("state %s unlocked", o.store)
+	return nil
+}
+
+func (o *backend) Pull(ctx context.Context, workingDir string) error {
+	reader, err := o.store.reader(ctx, stateObject)
+	if errors.Is(err, errNotFound) {
+		o.Log.Info("no state found in %s, starting from the working-dir %s", o.store, workingDir)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to pull state from %s: %v", o.store, err)
+	}
+	defer reader.Close()
+	// the state replaces the local content of the state directories
+	for _, entry := range slices.Concat(stateDirs, stateFiles) {
+		if err := os.RemoveAll(filepath.Join(workingDir, filepath.FromSlash(entry))); err != nil {
+			return err
+		}
+	}
+	if err := unpackState(reader, workingDir); err != nil {
+		return fmt.Errorf("unable to restore state from %s: %v", o.store, err)
+	}
+	for _, dir := range stateDirs {
+		if err := os.MkdirAll(filepath.Join(workingDir, filepath.FromSlash(dir)), 0755); err != nil {
+			return err
+		}
+	}
+	o.Log.Info("state restored from %s", o.store)
+	return nil
+}
+
+func (o *backend) Push(ctx context.Context, workingDir string) error {
+	if err := o.checkLock(ctx); err != nil {
+		return err
+	}
+	// the archive is written to a temporary file, then streamed to the store
+	archive, err := os.CreateTemp("", "oc-mirror-state-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	err = packState(workingDir, archive)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to save state of %s: %v", workingDir, err)
+	}
+	if err := o.store.putFile(ctx, stateObject, archive.Name()); err != nil {
+		return fmt.Errorf("unable to push state to %s: %v", o.store, err)
+	}
+	o.Log.Info("state saved to %s", o.store)
+	return nil
+}
+
+// renew renews the lock every renewInterval, until it is stopped or the lock is lost
+func (o *backend) renew(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(o.renewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := o.renewLock(ctx)
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, &LockedError{}):
+			// Push and Unlock fail as well
+			o.Log.Error("state %s: lock lost: %v", o.store, err)
+			return
+		default:
+			o.Log.Warn("state %s: unable to renew the lock: %v", o.store, err)
+		}
+	}
+}
+
+func (o *backend) renewLock(ctx context.Context) error {
+	if err := o.checkLock(ctx); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	lock := *o.lock
+	o.mu.Unlock()
+	lock.Renewed = time.Now().UTC()
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return o.store.put(ctx, lockObject, data)
+}
+
+func (o *backend) stopRenewing() {
+	o.mu.Lock()
+	stop, done := o.stopRenewal, o.renewalDone
+	o.stopRenewal, o.renewalDone = nil, nil
+	o.mu.Unlock()
+	if stop != nil {
+		stop()
+		<-done
+	}
+}
+
+func (o *backend) readLock(ctx context.Context) (*lockInfo, error) {
+	data, err := o.store.get(ctx, lockObject)
+	if err != nil {
+		return nil, err
+	}
+	var lock lockInfo
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid lock of state %s: %v", o.store, err)
+	}
+	return &lock, nil
+}
+
+// checkLock ensures the state is still locked by this runner
+func (o *backend) checkLock(ctx context.Context) error {
+	o.mu.Lock()
+	lock := o.lock
+	o.mu.Unlock()
+	if lock == nil {
+		return fmt.Errorf("state %s is not locked", o.store)
+	}
+	current, err := o.readLock(ctx)
+	if errors.Is(err, errNotFound) {
+		return LockedErrorf("the lock of state %s was removed", o.store)
+	}
+	if err != nil {
+		return err
+	}
+	if current.Owner != lock.Owner {
+		return LockedErrorf("state %s is locked by %s since %s", o.store, current.Host, current.Acquired.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// packState writes the state entries of the working-dir to w as a tar.gz
+func packState(workingDir string, w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, entry := range slices.Concat(stateDirs, stateFiles) {
+		err := filepath.WalkDir(filepath.Join(workingDir, filepath.FromSlash(entry)), func(path string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && slices.Contains(skippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(workingDir, path)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(relPath)
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tarWriter, file)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// unpackState extracts the state archive read from r into the working-dir
+func unpackState(r io.Reader, workingDir string) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(workingDir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(workingDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path %s in state", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			// nolint: gosec
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package state
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestState(t *testing.T) {
+	log := clog.New("trace")
+	newBackend := func(t *testing.T, stateURL string) *backend {
+		b, err := New(context.Background(), log, stateURL, false)
+		assert.NoError(t, err)
+		return b.(*backend)
+	}
+	writeFile := func(t *testing.T, path, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	// a working-dir after a mirroring
+	newWorkingDir := func(t *testing.T) string {
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		writeFile(t, filepath.Join(workingDir, ".history", ".history-2024-06-01T10:00:00Z"), "sha256:abc\n")
+		writeFile(t, filepath.Join(workingDir, "cluster-resources", "idms-oc-mirror.yaml"), "kind: ImageDigestMirrorSet\n")
+		writeFile(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "fbc-digest"), "abc")
+		writeFile(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "filtered-catalogs", "def", "index.json"), "{}")
+		writeFile(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "catalog-image", "index.json"), "{}")
+		writeFile(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "catalog-config", "foo", "catalog.json"), "{}")
+		writeFile(t, filepath.Join(workingDir, "release-images", "release.json"), "{}")
+		writeFile(t, filepath.Join(workingDir, "release-images", "cincinnati-graph-data", "x86_64-eus-4.16.json"), "{}")
+		writeFile(t, filepath.Join(workingDir, "signatures", "sha256-abc"), "signature")
+		writeFile(t, filepath.Join(workingDir, "mirror-set-state.json"), "{}")
+		return workingDir
+	}
+	assertRestored := func(t *testing.T, workingDir string) {
+		content, err := os.ReadFile(filepath.Join(workingDir, ".history", ".history-2024-06-01T10:00:00Z"))
+		assert.NoError(t, err)
+		assert.Equal(t, "sha256:abc\n", string(content))
+		assert.FileExists(t, filepath.Join(workingDir, "cluster-resources", "idms-oc-mirror.yaml"))
+		assert.FileExists(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "fbc-digest"))
+		assert.FileExists(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "filtered-catalogs", "def", "index.json"))
+		assert.NoDirExists(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "catalog-image"))
+		assert.NoDirExists(t, filepath.Join(workingDir, "operator-catalogs", "redhat-operator-index", "abc", "catalog-config"))
+		assert.NoFileExists(t, filepath.Join(workingDir, "release-images", "release.json"))
+		assert.FileExists(t, filepath.Join(workingDir, "release-images", "cincinnati-graph-data", "x86_64-eus-4.16.json"))
+		assert.FileExists(t, filepath.Join(workingDir, "signatures", "sha256-abc"))
+		assert.FileExists(t, filepath.Join(workingDir, "mirror-set-state.json"))
+	}
+
+	t.Run("Testing New - should fail on unknown backends", func(t *testing.T) {
+		_, err := New(context.Background(), log, "/tmp/state", true)
+		assert.EqualError(t, err, "state backend /tmp/state must have a file://, docker:// or s3:// prefix")
+		_, err = New(context.Background(), log, "docker://Registry/State:latest", true)
+		assert.ErrorContains(t, err, "invalid state backend docker://Registry/State:latest")
+	})
+
+	t.Run("Testing New - should setup an S3-compatible bucket", func(t *testing.T) {
+		b, err := New(context.Background(), log, "s3://oc-mirror/ci/state?endpoint=http://127.0.0.1:9000&forcepathstyle=true", true)
+		assert.NoError(t, err)
+		assert.Equal(t, "s3://oc-mirror/ci/state", b.(*backend).store.String())
+	})
+
+	t.Run("Testing local backend - should save and restore the state of the working-dir", func(t *testing.T) {
+		stateDir := t.TempDir()
+		runner1 := newBackend(t, "file://"+stateDir)
+
+		// nothing to restore on the first run
+		assert.NoError(t, runner1.Lock(context.Background()))
+		emptyDir := t.TempDir()
+		assert.NoError(t, runner1.Pull(context.Background(), emptyDir))
+		entries, err := os.ReadDir(emptyDir)
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+
+		assert.NoError(t, runner1.Push(context.Background(), newWorkingDir(t)))
+		assert.NoError(t, runner1.Unlock(context.Background()))
+		assert.NoFileExists(t, filepath.Join(stateDir, lockObject))
+
+		runner2 := newBackend(t, "file://"+stateDir)
+		assert.NoError(t, runner2.Lock(context.Background()))
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		writeFile(t, filepath.Join(workingDir, "cluster-resources", "outdated.yaml"), "")
+		assert.NoError(t, runner2.Pull(context.Background(), workingDir))
+		assertRestored(t, workingDir)
+		assert.NoFileExists(t, filepath.Join(workingDir, "cluster-resources", "outdated.yaml"))
+		assert.NoError(t, runner2.Unlock(context.Background()))
+	})
+
+	t.Run("Testing local backend - should not allow concurrent runners", func(t *testing.T) {
+		stateDir := t.TempDir()
+		runner1 := newBackend(t, "file://"+stateDir)
+		runner2 := newBackend(t, "file://"+stateDir)
+
+		assert.NoError(t, runner1.Lock(context.Background()))
+		err := runner2.Lock(context.Background())
+		assert.ErrorIs(t, err, &LockedError{})
+		assert.ErrorContains(t, err, "state file://"+stateDir+" is locked by")
+		assert.EqualError(t, runner2.Push(context.Background(), newWorkingDir(t)), "state file://"+stateDir+" is not locked")
+
+		assert.NoError(t, runner1.Unlock(context.Background()))
+		assert.NoError(t, runner2.Lock(context.Background()))
+		assert.NoError(t, runner2.Unlock(context.Background()))
+	})
+
+	t.Run("Testing local backend - should not push nor unlock when the lock was taken over", func(t *testing.T) {
+		stateDir := t.TempDir()
+		runner := newBackend(t, "file://"+stateDir)
+		assert.NoError(t, runner.Lock(context.Background()))
+
+		other, err := json.Marshal(lockInfo{Owner: "other", Host: "other-runner", Acquired: time.Now()})
+		assert.NoError(t, err)
+		writeFile(t, filepath.Join(stateDir, lockObject), string(other))
+
+		assert.ErrorIs(t, runner.Push(context.Background(), newWorkingDir(t)), &LockedError{})
+		assert.NoFileExists(t, filepath.Join(stateDir, stateObject))
+		assert.ErrorIs(t, runner.Unlock(context.Background()), &LockedError{})
+		assert.FileExists(t, filepath.Join(stateDir, lockObject))
+	})
+
+	t.Run("Testing local backend - should take over a stale lock", func(t *testing.T) {
+		stateDir := t.TempDir()
+		stale, err := json.Marshal(lockInfo{Owner: "gone", Host: "ephemeral-runner", Acquired: time.Now().Add(-lockTTL - time.Minute)})
+		assert.NoError(t, err)
+		writeFile(t, filepath.Join(stateDir, lockObject), string(stale))
+
+		runner := newBackend(t, "file://"+stateDir)
+		assert.NoError(t, runner.Lock(context.Background()))
+		assert.NoError(t, runner.Push(context.Background(), newWorkingDir(t)))
+
+		// the stale runner can not push anymore
+		stalled := newBackend(t, "file://"+stateDir)
+		stalled.lock = &lockInfo{Owner: "gone"}
+		assert.ErrorIs(t, stalled.Push(context.Background(), newWorkingDir(t)), &LockedError{})
+	})
+
+	t.Run("Testing local backend - should let a single one of concurrent runners lock", func(t *testing.T) {
+		stateDir := t.TempDir()
+		var wg sync.WaitGroup
+		var locked atomic.Int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := newBackend(t, "file://"+stateDir).Lock(context.Background()); err == nil {
+					locked.Add(1)
+				} else {
+					assert.ErrorIs(t, err, &LockedError{})
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), locked.Load())
+	})
+
+	t.Run("Testing local backend - should renew the lock until unlocked", func(t *testing.T) {
+		stateDir := t.TempDir()
+		runner := newBackend(t, "file://"+stateDir)
+		runner.renewInterval = 10 * time.Millisecond
+		assert.NoError(t, runner.Lock(context.Background()))
+		assert.Eventually(t, func() bool {
+			lock, err := runner.readLock(context.Background())
+			return err == nil && !lock.Renewed.IsZero()
+		}, time.Second, 10*time.Millisecond)
+		assert.NoError(t, runner.Unlock(context.Background()))
+		assert.NoFileExists(t, filepath.Join(stateDir, lockObject))
+	})
+
+	t.Run("Testing local backend - should let a single one of two runners take over the same stale lock", func(t *testing.T) {
+		stateDir := t.TempDir()
+		stale, err := json.Marshal(lockInfo{Owner: "gone", Host: "ephemeral-runner", Acquired: time.Now().Add(-lockTTL - time.Minute)})
+		assert.NoError(t, err)
+		writeFile(t, filepath.Join(stateDir, lockObject), string(stale))
+
+		runner1 := newBackend(t, "file://"+stateDir)
+		runner2 := newBackend(t, "file://"+stateDir)
+		// runner2 finds the lock stale, then runner1 takes it over before runner2 does
+		staleLock, err := runner2.readLock(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, staleLock.isStale())
+		assert.NoError(t, runner1.Lock(context.Background()))
+
+		err = runner2.takeOver(context.Background(), staleLock, []byte(`{"owner":"runner2"}`))
+		assert.ErrorIs(t, err, &LockedError{})
+		assert.ErrorContains(t, err, "was taken over by another runner")
+		assert.NoError(t, runner1.checkLock(context.Background()))
+		assert.ErrorIs(t, runner2.Lock(context.Background()), &LockedError{})
+		assert.NoError(t, runner1.Unlock(context.Background()))
+	})
+
+	t.Run("Testing registry backend - should lock and stream the state as an OCI artifact", func(t *testing.T) {
+		server := httptest.NewServer(registry.New())
+		defer server.Close()
+		stateURL := "docker://" + strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/state:ci"
+
+		runner1 := newBackend(t, stateURL)
+		runner1.store = withSettle(runner1.store, time.Millisecond)
+		runner2 := newBackend(t, stateURL)
+		runner2.store = withSettle(runner2.store, time.Millisecond)
+		assert.Equal(t, stateURL, runner1.store.String())
+
+		assert.NoError(t, runner1.Lock(context.Background()))
+		assert.ErrorIs(t, runner2.Lock(context.Background()), &LockedError{})
+		assert.NoError(t, runner1.Push(context.Background(), newWorkingDir(t)))
+		assert.NoError(t, runner1.Unlock(context.Background()))
+
+		assert.NoError(t, runner2.Lock(context.Background()))
+		workingDir := t.TempDir()
+		assert.NoError(t, runner2.Pull(context.Background(), workingDir))
+		assertRestored(t, workingDir)
+		assert.NoError(t, runner2.Unlock(context.Background()))
+	})
+
+	t.Run("Testing registry backend - should not create a tag pushed meanwhile by another runner", func(t *testing.T) {
+		server := httptest.NewServer(registry.New())
+		defer server.Close()
+		stateURL := "docker://" + strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/state:ci"
+
+		runner1 := newBackend(t, stateURL)
+		runner2 := newBackend(t, stateURL)
+		store1 := runner1.store.(registryStore)
+		store1.settle = 500 * time.Millisecond
+		done := make(chan error)
+		go func() {
+			done <- store1.create(context.Background(), lockObject, []byte(`{"owner":"runner1"}`))
+		}()
+		// runner2 pushes the lock while runner1 lets its push settle
+		assert.Eventually(t, func() bool {
+			_, err := runner2.readLock(context.Background())
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		assert.NoError(t, runner2.store.put(context.Background(), lockObject, []byte(`{"owner":"runner2"}`)))
+		assert.ErrorIs(t, <-done, errExists)
+	})
+
+	t.Run("Testing S3 backend - should lock with If-None-Match and fail closed without it", func(t *testing.T) {
+		t.Setenv("AWS_ACCESS_KEY_ID", "oc-mirror")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "oc-mirror")
+		newS3Server := func(conditional bool, objects map[string]bool) *httptest.Server {
+			var mu sync.Mutex
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch r.Method {
+				case http.MethodPut:
+				case http.MethodDelete:
+					delete(objects, r.URL.Path)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				default:
+					w.WriteHeader(http.StatusNotImplemented)
+					return
+				}
+				if conditional && r.Header.Get("If-None-Match") == "*" && objects[r.URL.Path] {
+					w.WriteHeader(http.StatusPreconditionFailed)
+					return
+				}
+				objects[r.URL.Path] = true
+			}))
+		}
+		for _, conditional := range []bool{true, false} {
+			objects := map[string]bool{}
+			server := newS3Server(conditional, objects)
+			defer server.Close()
+			runner := newBackend(t, "s3://oc-mirror/ci?endpoint="+server.URL+"&forcepathstyle=true")
+			data := []byte(`{"owner":"runner"}`)
+			err := runner.store.create(context.Background(), lockObject, data)
+			if conditional {
+				assert.NoError(t, err)
+				assert.ErrorIs(t, runner.store.create(context.Background(), lockObject, data), errExists)
+				// the probe object is removed
+				assert.Equal(t, map[string]bool{"/oc-mirror/ci/" + lockObject: true}, objects)
+			} else {
+				assert.ErrorIs(t, err, errNoConditionalCreate)
+				// the lock is never written, it could be the lock of another runner
+				assert.Empty(t, objects)
+			}
+		}
+	})
+
+	t.Run("Testing unpackState - should refuse paths outside of the working-dir", func(t *testing.T) {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		tarWriter := tar.NewWriter(gzipWriter)
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644}))
+		assert.NoError(t, tarWriter.Close())
+		assert.NoError(t, gzipWriter.Close())
+
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		assert.EqualError(t, unpackState(&buf, workingDir), "invalid path ../escape in state")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(workingDir), "escape"))
+	})
+}
+
+// withSettle shortens the time a registry store lets its created tags settle
+func withSettle(s store, settle time.Duration) store {
+	registry := s.(registryStore)
+	registry.settle = settle
+	return registry
+}